@@ -0,0 +1,309 @@
+package billing
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/storage"
+)
+
+// CUR columns, as named in Parquet reports. The columns of CSV reports are
+// normalized to these names by normalizeCURColumn.
+const (
+	curUsageStartDate           = "line_item_usage_start_date"
+	curUsageEndDate             = "line_item_usage_end_date"
+	curLineItemType             = "line_item_line_item_type"
+	curResourceID               = "line_item_resource_id"
+	curUsageAccountID           = "line_item_usage_account_id"
+	curProductCode              = "line_item_product_code"
+	curUnblendedCost            = "line_item_unblended_cost"
+	curProductFamily            = "product_product_family"
+	curReservationEffectiveCost = "reservation_effective_cost"
+	curSavingsPlanEffectiveCost = "savings_plan_savings_plan_effective_cost"
+	curResourceTagsPrefix       = "resource_tags_"
+)
+
+// curManifestSuffix is the suffix of the names of CUR manifests, in lower case
+const curManifestSuffix = "-manifest.json"
+
+// curManifest is the subset of a CUR manifest listing the report files
+type curManifest struct {
+	ReportKeys []string `json:"reportKeys"`
+}
+
+// selectCURFiles selects the report files of the current delivery of each
+// CUR. Unless a report is overwritten, AWS delivers each version of the report
+// of a billing period to a new assembly directory, e.g.
+// "report/20221001-20221101/<assemblyId>/", and keeps the old versions, so
+// only the files listed by the manifest of the billing period, e.g.
+// "report/20221001-20221101/report-Manifest.json", are selected. Each
+// assembly directory also has a copy of its manifest, which is ignored in
+// favour of the manifest closest to the root. Files without a manifest in
+// their directory or its parents, e.g. reports integrated with Athena, are
+// all selected.
+func selectCURFiles(store storage.Storage, files []*storage.StorageInfo) ([]*storage.StorageInfo, error) {
+	manifests := []string{}
+	for _, file := range files {
+		if strings.HasSuffix(strings.ToLower(file.Name), curManifestSuffix) {
+			manifests = append(manifests, file.Name)
+		}
+	}
+	sort.Slice(manifests, func(i, j int) bool {
+		return strings.Count(manifests[i], "/") < strings.Count(manifests[j], "/")
+	})
+
+	// Report keys of each directory which has a manifest closest to the root
+	reportKeys := map[string][]string{}
+	for _, name := range manifests {
+		dir := path.Dir(name)
+		if manifestDir, ok := curManifestDir(reportKeys, dir); ok && manifestDir != dir {
+			continue
+		}
+
+		data, err := store.Read(name)
+		if err != nil {
+			return nil, fmt.Errorf("reading CUR manifest %s: %s", name, err)
+		}
+		manifest := &curManifest{}
+		if err := json.Unmarshal(data, manifest); err != nil {
+			return nil, fmt.Errorf("parsing CUR manifest %s: %s", name, err)
+		}
+		reportKeys[dir] = append(reportKeys[dir], manifest.ReportKeys...)
+	}
+
+	selected := []*storage.StorageInfo{}
+	for _, file := range files {
+		if strings.HasSuffix(strings.ToLower(file.Name), curManifestSuffix) {
+			continue
+		}
+
+		dir, ok := curManifestDir(reportKeys, path.Dir(file.Name))
+		if !ok {
+			selected = append(selected, file)
+			continue
+		}
+		for _, key := range reportKeys[dir] {
+			if isReportKey(file.Name, key) {
+				selected = append(selected, file)
+				break
+			}
+		}
+	}
+
+	return selected, nil
+}
+
+// curManifestDir returns the directory of the manifest which lists the report
+// files of the given directory, i.e. the directory itself or the parent
+// closest to the root which has a manifest.
+func curManifestDir(reportKeys map[string][]string, dir string) (string, bool) {
+	found, ok := "", false
+	for d := dir; ; d = path.Dir(d) {
+		if _, has := reportKeys[d]; has {
+			found, ok = d, true
+		}
+		if d == "." || d == "/" || d == "" {
+			return found, ok
+		}
+	}
+}
+
+// isReportKey returns true if the file with the given name in the storage is
+// the report file with the given key. Keys are relative to the root of the
+// bucket, which may differ from the root of the storage, so they match if
+// either ends with the other.
+func isReportKey(name, key string) bool {
+	name = strings.TrimPrefix(name, "/")
+	key = strings.TrimPrefix(key, "/")
+	return name == key || strings.HasSuffix(name, "/"+key) || strings.HasSuffix(key, "/"+name)
+}
+
+// normalizeCURColumn converts the name of a column of a CSV CUR, e.g.
+// "lineItem/UsageStartDate", to the name of the column in a Parquet CUR, e.g.
+// "line_item_usage_start_date". Tag columns, e.g. "resourceTags/user:team",
+// keep the tag key as-is, e.g. "resource_tags_user:team".
+func normalizeCURColumn(column string) string {
+	split := strings.SplitN(column, "/", 2)
+	if len(split) != 2 {
+		return column
+	}
+
+	category := camelToSnake(split[0])
+	if category+"_" == curResourceTagsPrefix {
+		return curResourceTagsPrefix + split[1]
+	}
+
+	return category + "_" + camelToSnake(split[1])
+}
+
+// camelToSnake converts a camel case name to snake case, with an underscore
+// before each upper case letter, as the columns of Parquet CURs are named;
+// e.g. "ReservationARN" becomes "reservation_a_r_n".
+func camelToSnake(s string) string {
+	var sb strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				sb.WriteRune('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// parseAWSCUR parses an AWS Cost and Usage Report in CSV or Parquet format.
+//
+// Usage covered by a reservation or savings plan is costed at its effective
+// cost, which includes the amortized commitment, so the commitment fees and
+// the savings plan negations are skipped to avoid counting them twice.
+// Credits, refunds and discounts are recorded as credits.
+func parseAWSCUR(name string, data []byte) ([]*lineItem, error) {
+	var t *table
+	var err error
+	switch {
+	case strings.HasSuffix(name, ".csv"):
+		t, err = readCSVTable(data, normalizeCURColumn)
+	case strings.HasSuffix(name, ".parquet"):
+		t, err = readParquetTable(data, normalizeCURColumn)
+	default:
+		// Manifests are not parsed, but select the report files to parse
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, column := range []string{curUsageStartDate, curUsageEndDate, curUnblendedCost} {
+		if !t.has(column) {
+			return nil, fmt.Errorf("missing column %s", column)
+		}
+	}
+
+	items := []*lineItem{}
+	for n, record := range t.records {
+		item, err := parseCURRecord(t, record)
+		if err != nil {
+			return nil, fmt.Errorf("line item %d: %s", n+1, err)
+		}
+		if item != nil {
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+// parseCURRecord parses a line item of a CUR, returning nil if it should be
+// skipped.
+func parseCURRecord(t *table, record []string) (*lineItem, error) {
+	start, err := parseTime(t.get(record, curUsageStartDate))
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseTime(t.get(record, curUsageEndDate))
+	if err != nil {
+		return nil, err
+	}
+	if !end.After(start) {
+		return nil, nil
+	}
+
+	unblended, err := t.getFloat(record, curUnblendedCost)
+	if err != nil {
+		return nil, err
+	}
+
+	var cost, credit float64
+	switch t.get(record, curLineItemType) {
+	case "Usage", "Tax", "":
+		cost = unblended
+	case "DiscountedUsage":
+		cost, err = t.getFloat(record, curReservationEffectiveCost)
+	case "SavingsPlanCoveredUsage":
+		cost, err = t.getFloat(record, curSavingsPlanEffectiveCost)
+	case "Credit", "Refund", "EdpDiscount", "BundledDiscount", "PrivateRateDiscount", "Discount":
+		credit = unblended
+	default:
+		// e.g. RIFee, Fee, SavingsPlanRecurringFee, SavingsPlanNegation
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	labels := kubecost.CloudCostItemLabels{}
+	isKubernetes := false
+	for column, i := range t.columns {
+		if !strings.HasPrefix(column, curResourceTagsPrefix) || i >= len(record) || record[i] == "" {
+			continue
+		}
+		tag := strings.TrimPrefix(column, curResourceTagsPrefix)
+		if isAWSKubernetesTag(tag) {
+			isKubernetes = true
+		}
+		if key := strings.TrimPrefix(strings.TrimPrefix(tag, "user:"), "user_"); key != tag {
+			labels[key] = record[i]
+		}
+	}
+
+	resourceID := t.get(record, curResourceID)
+	productCode := t.get(record, curProductCode)
+	if productCode == "AmazonEKS" {
+		isKubernetes = true
+	}
+
+	return &lineItem{
+		start: start,
+		end:   end,
+		properties: kubecost.CloudCostItemProperties{
+			ProviderID: resourceID,
+			Provider:   kubecost.AWSProvider,
+			Account:    t.get(record, curUsageAccountID),
+			Service:    productCode,
+			Category:   awsCategory(t.get(record, curProductFamily), resourceID),
+			Labels:     labels,
+		},
+		isKubernetes: isKubernetes,
+		cost:         cost,
+		credit:       credit,
+	}, nil
+}
+
+// isAWSKubernetesTag returns true if the tag is one which EKS or Kubernetes
+// sets on the resources of a cluster, in either CSV or Parquet form.
+func isAWSKubernetesTag(tag string) bool {
+	tag = strings.NewReplacer(":", "_", "/", "_", ".", "_", "-", "_").Replace(strings.ToLower(tag))
+	return strings.Contains(tag, "eks_cluster_name") ||
+		strings.Contains(tag, "kubernetes_io_cluster") ||
+		strings.Contains(tag, "eks_nodegroup_name")
+}
+
+// awsCategory returns the asset category of a line item, given its product
+// family, or the prefix of its resource ID if the family is unknown.
+func awsCategory(productFamily, resourceID string) string {
+	switch productFamily {
+	case "Compute Instance", "Compute":
+		return kubecost.ComputeCategory
+	case "Storage", "Storage Snapshot", "System Operation":
+		return kubecost.StorageCategory
+	case "Data Transfer", "Load Balancer", "Load Balancer-Network", "Load Balancer-Application", "NAT Gateway", "IP Address":
+		return kubecost.NetworkCategory
+	}
+
+	switch {
+	case strings.HasPrefix(resourceID, "i-"):
+		return kubecost.ComputeCategory
+	case strings.HasPrefix(resourceID, "vol-"), strings.HasPrefix(resourceID, "snap-"):
+		return kubecost.StorageCategory
+	}
+
+	return kubecost.OtherCategory
+}
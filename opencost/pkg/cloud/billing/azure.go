@@ -0,0 +1,149 @@
+package billing
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/util/timeutil"
+)
+
+// normalizeAzureColumn converts the name of a column of an Azure cost export
+// to lower case, since the case varies between export versions.
+func normalizeAzureColumn(column string) string {
+	return strings.ToLower(strings.TrimSpace(column))
+}
+
+// azureColumn returns the first of the given columns which the table has, as
+// the names of some columns vary between export versions.
+func azureColumn(t *table, columns ...string) string {
+	for _, column := range columns {
+		if t.has(column) {
+			return column
+		}
+	}
+	return ""
+}
+
+// parseAzureCostExport parses an Azure Cost Management export in CSV or
+// Parquet format. Each row is the cost of a resource for one day.
+//
+// Amortized exports spread reservation and savings plan purchases across the
+// usage they cover, so purchases and unused commitments are skipped. Refunds
+// are recorded as credits.
+func parseAzureCostExport(name string, data []byte) ([]*lineItem, error) {
+	var t *table
+	var err error
+	switch {
+	case strings.HasSuffix(name, ".csv"):
+		t, err = readCSVTable(data, normalizeAzureColumn)
+	case strings.HasSuffix(name, ".parquet"):
+		t, err = readParquetTable(data, normalizeAzureColumn)
+	default:
+		return nil, fmt.Errorf("unsupported format: Azure cost exports must be CSV or Parquet")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dateColumn := azureColumn(t, "date", "usagedatetime", "usagedate")
+	costColumn := azureColumn(t, "costinbillingcurrency", "pretaxcost", "cost")
+	if dateColumn == "" || costColumn == "" {
+		return nil, fmt.Errorf("missing date or cost column")
+	}
+	resourceIDColumn := azureColumn(t, "resourceid", "instanceid")
+	subscriptionColumn := azureColumn(t, "subscriptionid", "subscriptionguid")
+	resourceGroupColumn := azureColumn(t, "resourcegroup", "resourcegroupname")
+
+	items := []*lineItem{}
+	for n, record := range t.records {
+		start, err := parseTime(t.get(record, dateColumn))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %s", n+1, err)
+		}
+
+		amount, err := t.getFloat(record, costColumn)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %s", n+1, err)
+		}
+
+		var cost, credit float64
+		switch strings.ToLower(t.get(record, "chargetype")) {
+		case "usage", "":
+			cost = amount
+		case "refund":
+			credit = amount
+		default:
+			// e.g. Purchase, UnusedReservation, UnusedSavingsPlan
+			continue
+		}
+
+		labels, err := parseAzureTags(t.get(record, "tags"))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %s", n+1, err)
+		}
+
+		isKubernetes := strings.HasPrefix(strings.ToLower(t.get(record, resourceGroupColumn)), "mc_") ||
+			strings.EqualFold(t.get(record, "consumedservice"), "Microsoft.ContainerService")
+		for key := range labels {
+			if strings.HasPrefix(key, "aks-managed-") {
+				isKubernetes = true
+			}
+		}
+
+		items = append(items, &lineItem{
+			start: start,
+			end:   start.Add(timeutil.Day),
+			properties: kubecost.CloudCostItemProperties{
+				ProviderID: t.get(record, resourceIDColumn),
+				Provider:   kubecost.AzureProvider,
+				Account:    t.get(record, subscriptionColumn),
+				Project:    t.get(record, resourceGroupColumn),
+				Service:    t.get(record, "consumedservice"),
+				Category:   azureCategory(t.get(record, "metercategory")),
+				Labels:     labels,
+			},
+			isKubernetes: isKubernetes,
+			cost:         cost,
+			credit:       credit,
+		})
+	}
+
+	return items, nil
+}
+
+// parseAzureTags parses the tags of a row, which are a JSON object, with or
+// without the enclosing braces.
+func parseAzureTags(s string) (kubecost.CloudCostItemLabels, error) {
+	labels := kubecost.CloudCostItemLabels{}
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return labels, nil
+	}
+	if !strings.HasPrefix(s, "{") {
+		s = "{" + s + "}"
+	}
+
+	if err := json.Unmarshal([]byte(s), &labels); err != nil {
+		return nil, fmt.Errorf("parsing tags: %s", err)
+	}
+
+	return labels, nil
+}
+
+// azureCategory returns the asset category of a row, given its meter category
+func azureCategory(meterCategory string) string {
+	switch meterCategory {
+	case "Virtual Machines", "Virtual Machines Licenses":
+		return kubecost.ComputeCategory
+	case "Storage":
+		return kubecost.StorageCategory
+	case "Bandwidth", "Virtual Network", "Load Balancer", "Networking":
+		return kubecost.NetworkCategory
+	case "Azure Kubernetes Service":
+		return kubecost.ManagementCategory
+	}
+	return kubecost.OtherCategory
+}
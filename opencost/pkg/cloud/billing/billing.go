@@ -0,0 +1,368 @@
+// Package billing ingests the billing exports of cloud providers, i.e. AWS
+// Cost and Usage Reports (CUR), GCP billing exports from BigQuery and Azure
+// cost exports, into CloudCostItems, and reconciles AssetSets and
+// AllocationSets against the billed costs.
+package billing
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/storage"
+)
+
+// lineItem is the cost of a cloud resource over the period from start to end,
+// as billed by the provider.
+type lineItem struct {
+	start        time.Time
+	end          time.Time
+	properties   kubecost.CloudCostItemProperties
+	isKubernetes bool
+	cost         float64
+	credit       float64
+}
+
+// key identifies line items which can be summed into one
+func (li *lineItem) key() string {
+	return fmt.Sprintf("%s/%d/%d/%t", li.properties.Key(), li.start.Unix(), li.end.Unix(), li.isKubernetes)
+}
+
+// parser parses the billing export file with the given name, which has
+// already been decompressed, into line items.
+type parser func(name string, data []byte) ([]*lineItem, error)
+
+// parsers are the billing export parsers of each supported provider
+var parsers = map[string]parser{
+	kubecost.AWSProvider:   parseAWSCUR,
+	kubecost.GCPProvider:   parseGCPBillingExport,
+	kubecost.AzureProvider: parseAzureCostExport,
+}
+
+// selector selects the billing export files to parse from all those found,
+// e.g. the current version of reports which are delivered more than once.
+type selector func(store storage.Storage, files []*storage.StorageInfo) ([]*storage.StorageInfo, error)
+
+// selectors are the billing export file selectors of each provider which
+// needs one
+var selectors = map[string]selector{
+	kubecost.AWSProvider: selectCURFiles,
+}
+
+// ingestedFile is a billing export file which has been parsed
+type ingestedFile struct {
+	modTime  time.Time
+	items    []*lineItem
+	coverage []kubecost.Window
+}
+
+// Ingestor reads the billing export files of a provider from a directory of a
+// storage.Storage, including its sub-directories, and serves the billed costs
+// as CloudCostItems. Files are parsed once, and again only if modified.
+type Ingestor struct {
+	lock        sync.RWMutex
+	store       storage.Storage
+	provider    string
+	dir         string
+	parse       parser
+	selectFiles selector
+	files       map[string]*ingestedFile
+}
+
+// NewIngestor creates an Ingestor of the billing exports of the given
+// provider, e.g. kubecost.AWSProvider, in the given directory of the storage.
+func NewIngestor(store storage.Storage, provider, dir string) (*Ingestor, error) {
+	provider = kubecost.ParseProvider(provider)
+	parse, ok := parsers[provider]
+	if !ok {
+		return nil, fmt.Errorf("unsupported billing export provider: %s", provider)
+	}
+
+	return &Ingestor{
+		store:       store,
+		provider:    provider,
+		dir:         dir,
+		parse:       parse,
+		selectFiles: selectors[provider],
+		files:       map[string]*ingestedFile{},
+	}, nil
+}
+
+// Provider returns the provider of the billing exports
+func (i *Ingestor) Provider() string {
+	return i.provider
+}
+
+// Refresh parses the billing export files which are new or have been modified
+// since the last refresh, and forgets those which have been removed. Files
+// which cannot be parsed are logged and skipped.
+func (i *Ingestor) Refresh() error {
+	files, err := i.listFiles(i.dir)
+	if err != nil {
+		return fmt.Errorf("listing billing exports in %s: %s", i.store.FullPath(i.dir), err)
+	}
+	if i.selectFiles != nil {
+		files, err = i.selectFiles(i.store, files)
+		if err != nil {
+			return fmt.Errorf("selecting billing exports in %s: %s", i.store.FullPath(i.dir), err)
+		}
+	}
+
+	i.lock.RLock()
+	toParse := []*storage.StorageInfo{}
+	for _, file := range files {
+		if ingested, ok := i.files[file.Name]; !ok || !ingested.modTime.Equal(file.ModTime) {
+			toParse = append(toParse, file)
+		}
+	}
+	i.lock.RUnlock()
+
+	parsed := map[string]*ingestedFile{}
+	for _, file := range toParse {
+		items, err := i.parseFile(file.Name)
+		if err != nil {
+			log.Warnf("Billing: failed to parse %s: %s", i.store.FullPath(file.Name), err)
+			continue
+		}
+		windows := make([]kubecost.Window, 0, len(items))
+		for _, item := range items {
+			windows = append(windows, kubecost.NewClosedWindow(item.start, item.end))
+		}
+		parsed[file.Name] = &ingestedFile{
+			modTime:  file.ModTime,
+			items:    items,
+			coverage: mergeWindows(windows),
+		}
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	current := make(map[string]bool, len(files))
+	for _, file := range files {
+		current[file.Name] = true
+	}
+	for name := range i.files {
+		if !current[name] {
+			delete(i.files, name)
+		}
+	}
+	for name, ingested := range parsed {
+		i.files[name] = ingested
+	}
+
+	return nil
+}
+
+// listFiles returns the billing export files in the given directory and its
+// sub-directories, named by their path in the storage.
+func (i *Ingestor) listFiles(dir string) ([]*storage.StorageInfo, error) {
+	infos, err := i.store.List(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []*storage.StorageInfo{}
+	for _, info := range infos {
+		name := path.Join(dir, path.Base(info.Name))
+		if !isBillingExportFile(name) {
+			continue
+		}
+		files = append(files, &storage.StorageInfo{
+			Name:    name,
+			Size:    info.Size,
+			ModTime: info.ModTime,
+		})
+	}
+
+	dirs, err := i.store.ListDirectories(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dirs {
+		subDir := strings.TrimSuffix(d.Name, storage.DirDelim)
+		if subDir == "" || subDir == strings.TrimSuffix(dir, storage.DirDelim) {
+			continue
+		}
+		subFiles, err := i.listFiles(subDir)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, subFiles...)
+	}
+
+	return files, nil
+}
+
+// isBillingExportFile returns true if the file has the extension of a billing
+// export file, i.e. CSV, Parquet or JSON, optionally gzipped.
+func isBillingExportFile(name string) bool {
+	name = strings.TrimSuffix(strings.ToLower(name), ".gz")
+	for _, ext := range []string{".csv", ".parquet", ".json", ".jsonl"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFile reads and parses the given billing export file, summing line
+// items with the same properties and period.
+func (i *Ingestor) parseFile(name string) ([]*lineItem, error) {
+	data, err := i.store.Read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	name, data, err = gunzip(strings.ToLower(name), data)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing: %s", err)
+	}
+
+	items, err := i.parse(name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	summed := map[string]*lineItem{}
+	keys := []string{}
+	for _, item := range items {
+		key := item.key()
+		if s, ok := summed[key]; ok {
+			s.cost += item.cost
+			s.credit += item.credit
+			continue
+		}
+		summed[key] = item
+		keys = append(keys, key)
+	}
+
+	result := make([]*lineItem, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, summed[key])
+	}
+
+	return result, nil
+}
+
+// items calls the given function on each ingested line item which overlaps
+// the window defined by the given start and end times.
+func (i *Ingestor) items(start, end time.Time, f func(*lineItem)) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	names := make([]string, 0, len(i.files))
+	for name := range i.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, item := range i.files[name].items {
+			if item.start.Before(end) && item.end.After(start) {
+				f(item)
+			}
+		}
+	}
+}
+
+// Coverage returns the windows covered by the ingested line items, sorted by
+// start. Periods without line items, e.g. a month missing from the billing
+// exports, are gaps between the windows.
+func (i *Ingestor) Coverage() []kubecost.Window {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	windows := []kubecost.Window{}
+	for _, file := range i.files {
+		windows = append(windows, file.coverage...)
+	}
+
+	return mergeWindows(windows)
+}
+
+// mergeWindows merges the given closed windows which overlap or are adjacent,
+// returning the merged windows sorted by start.
+func mergeWindows(windows []kubecost.Window) []kubecost.Window {
+	sort.Slice(windows, func(a, b int) bool {
+		return windows[a].Start().Before(*windows[b].Start())
+	})
+
+	merged := []kubecost.Window{}
+	for _, w := range windows {
+		last := len(merged) - 1
+		if last >= 0 && !w.Start().After(*merged[last].End()) {
+			if w.End().After(*merged[last].End()) {
+				merged[last] = kubecost.NewClosedWindow(*merged[last].Start(), *w.End())
+			}
+			continue
+		}
+		merged = append(merged, w)
+	}
+
+	return merged
+}
+
+// CloudCostItemSet returns the billed costs of the window defined by the given
+// start and end times. Line items which partially overlap the window are
+// prorated by the minutes they overlap.
+func (i *Ingestor) CloudCostItemSet(start, end time.Time) *kubecost.CloudCostItemSet {
+	ccis := kubecost.NewCloudCostItemSet(start, end)
+	ccis.Integration = i.provider
+
+	i.items(start, end, func(item *lineItem) {
+		kubecost.LoadCloudCostItemSets(item.start, item.end, item.properties, item.isKubernetes, item.cost, item.credit, []*kubecost.CloudCostItemSet{ccis})
+	})
+
+	return ccis
+}
+
+// CloudCostItemSetRange returns the billed costs of the window defined by the
+// given start and end times, in one CloudCostItemSet per window of the given
+// resolution.
+func (i *Ingestor) CloudCostItemSetRange(start, end time.Time, resolution time.Duration) (*kubecost.CloudCostItemSetRange, error) {
+	sets, err := kubecost.GetCloudCostItemSets(start, end, resolution, i.provider)
+	if err != nil {
+		return nil, err
+	}
+
+	i.items(start, end, func(item *lineItem) {
+		overlapping := []*kubecost.CloudCostItemSet{}
+		for _, ccis := range sets {
+			w := ccis.GetWindow()
+			if item.start.Before(*w.End()) && item.end.After(*w.Start()) {
+				overlapping = append(overlapping, ccis)
+			}
+		}
+		kubecost.LoadCloudCostItemSets(item.start, item.end, item.properties, item.isKubernetes, item.cost, item.credit, overlapping)
+	})
+
+	return &kubecost.CloudCostItemSetRange{
+		CloudCostItemSets: sets,
+		Window:            kubecost.NewClosedWindow(start, end),
+	}, nil
+}
+
+// parseTime parses a time of a billing export, which may be given in RFC 3339,
+// or as a date or date and time without a time zone, in UTC.
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05 MST",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+		"01/02/2006",
+	} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time: %s", s)
+}
@@ -0,0 +1,366 @@
+package billing
+
+import (
+	"bytes"
+	"compress/gzip"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/storage"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+var (
+	oct1 = time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	oct2 = time.Date(2022, 10, 2, 0, 0, 0, 0, time.UTC)
+	oct3 = time.Date(2022, 10, 3, 0, 0, 0, 0, time.UTC)
+)
+
+// itemsByProviderID returns the CloudCostItems of the set by provider ID
+func itemsByProviderID(t *testing.T, ccis *kubecost.CloudCostItemSet) map[string]*kubecost.CloudCostItem {
+	items := map[string]*kubecost.CloudCostItem{}
+	for _, cci := range ccis.CloudCostItems {
+		if _, ok := items[cci.Properties.ProviderID]; ok {
+			t.Fatalf("unexpected duplicate provider ID: %s", cci.Properties.ProviderID)
+		}
+		items[cci.Properties.ProviderID] = cci
+	}
+	return items
+}
+
+type expectedItem struct {
+	cost         float64
+	credit       float64
+	category     string
+	isKubernetes bool
+	labels       kubecost.CloudCostItemLabels
+}
+
+func checkItems(t *testing.T, ccis *kubecost.CloudCostItemSet, expected map[string]expectedItem) {
+	t.Helper()
+
+	items := itemsByProviderID(t, ccis)
+	if len(items) != len(expected) {
+		t.Errorf("expected %d items; got %d: %v", len(expected), len(items), items)
+	}
+
+	for id, exp := range expected {
+		cci, ok := items[id]
+		if !ok {
+			t.Errorf("missing item %s", id)
+			continue
+		}
+		if math.Abs(cci.Cost-exp.cost) > 0.0001 || math.Abs(cci.Credit-exp.credit) > 0.0001 {
+			t.Errorf("%s: expected cost %f and credit %f; got %f and %f", id, exp.cost, exp.credit, cci.Cost, cci.Credit)
+		}
+		if cci.Properties.Category != exp.category {
+			t.Errorf("%s: expected category %s; got %s", id, exp.category, cci.Properties.Category)
+		}
+		if cci.IsKubernetes != exp.isKubernetes {
+			t.Errorf("%s: expected isKubernetes %t; got %t", id, exp.isKubernetes, cci.IsKubernetes)
+		}
+		if exp.labels != nil && !cci.Properties.Labels.Equal(exp.labels) {
+			t.Errorf("%s: expected labels %v; got %v", id, exp.labels, cci.Properties.Labels)
+		}
+	}
+}
+
+func newTestIngestor(t *testing.T, store storage.Storage, provider, dir string) *Ingestor {
+	t.Helper()
+
+	ingestor, err := NewIngestor(store, provider, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := ingestor.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return ingestor
+}
+
+func TestIngestorAWSCSV(t *testing.T) {
+	ingestor := newTestIngestor(t, storage.NewFileStorage("testdata"), "aws", "aws")
+
+	checkItems(t, ingestor.CloudCostItemSet(oct1, oct2), map[string]expectedItem{
+		"i-node1": {
+			cost:         4.8,
+			category:     kubecost.ComputeCategory,
+			isKubernetes: true,
+			labels:       kubecost.CloudCostItemLabels{"team": "team-a"},
+		},
+		// Reserved instance, at the effective cost
+		"i-node2": {cost: 3.0, category: kubecost.ComputeCategory, isKubernetes: true},
+		// Savings plan, at the effective cost, without the negation
+		"i-node3":   {cost: 2.0, category: kubecost.ComputeCategory, isKubernetes: true},
+		"vol-disk1": {cost: 1.0, credit: -0.25, category: kubecost.StorageCategory, isKubernetes: true},
+	})
+
+	checkItems(t, ingestor.CloudCostItemSet(oct2, oct3), map[string]expectedItem{
+		"i-other": {cost: 10.0, category: kubecost.ComputeCategory},
+	})
+
+	coverage := ingestor.Coverage()
+	if len(coverage) != 1 || !coverage[0].Equal(kubecost.NewClosedWindow(oct1, oct3)) {
+		t.Errorf("expected coverage %s; got %v", kubecost.NewClosedWindow(oct1, oct3), coverage)
+	}
+}
+
+func TestIngestorCoverageGap(t *testing.T) {
+	sep1 := time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)
+	nov1 := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+	dec1 := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	// The exports of September and November, but not of October
+	store := storage.NewFileStorage(t.TempDir())
+	exports := map[string]string{
+		"export/2022-09.json": `{"service":{"description":"Compute Engine"},"usage_start_time":"2022-09-01T00:00:00Z","usage_end_time":"2022-10-01T00:00:00Z","resource":{"name":"gke-node-1"},"cost":30.0}`,
+		"export/2022-11.json": `{"service":{"description":"Compute Engine"},"usage_start_time":"2022-11-01T00:00:00Z","usage_end_time":"2022-11-15T00:00:00Z","resource":{"name":"gke-node-1"},"cost":15.0}
+{"service":{"description":"Compute Engine"},"usage_start_time":"2022-11-15T00:00:00Z","usage_end_time":"2022-12-01T00:00:00Z","resource":{"name":"gke-node-1"},"cost":15.0}`,
+	}
+	for name, export := range exports {
+		if err := store.Write(name, []byte(export)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	ingestor := newTestIngestor(t, store, kubecost.GCPProvider, "export")
+
+	coverage := ingestor.Coverage()
+	expected := []kubecost.Window{
+		kubecost.NewClosedWindow(sep1, oct1),
+		kubecost.NewClosedWindow(nov1, dec1),
+	}
+	if len(coverage) != len(expected) {
+		t.Fatalf("expected coverage %v; got %v", expected, coverage)
+	}
+	for i := range expected {
+		if !coverage[i].Equal(expected[i]) {
+			t.Errorf("expected coverage %v; got %v", expected, coverage)
+		}
+	}
+}
+
+func TestIngestorAWSAssemblies(t *testing.T) {
+	expected := map[string]expectedItem{
+		"i-node1":   {cost: 2.0, category: kubecost.ComputeCategory},
+		"vol-disk1": {cost: 0.5, category: kubecost.StorageCategory},
+	}
+
+	// Only the current assembly of the billing period manifest is ingested,
+	// whether the storage is rooted at the root of the bucket or below it
+	ingestor := newTestIngestor(t, storage.NewFileStorage("testdata/aws-assemblies"), kubecost.AWSProvider, "cur")
+	checkItems(t, ingestor.CloudCostItemSet(oct1, oct2), expected)
+
+	ingestor = newTestIngestor(t, storage.NewFileStorage("testdata/aws-assemblies/cur"), kubecost.AWSProvider, "report")
+	checkItems(t, ingestor.CloudCostItemSet(oct1, oct2), expected)
+}
+
+// curParquetRow is a subset of the columns of a Parquet CUR
+type curParquetRow struct {
+	LineItemType           string  `parquet:"name=line_item_line_item_type, type=BYTE_ARRAY, convertedtype=UTF8"`
+	UsageStartDate         int64   `parquet:"name=line_item_usage_start_date, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	UsageEndDate           int64   `parquet:"name=line_item_usage_end_date, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	ResourceID             string  `parquet:"name=line_item_resource_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	UnblendedCost          float64 `parquet:"name=line_item_unblended_cost, type=DOUBLE"`
+	ProductFamily          string  `parquet:"name=product_product_family, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ReservationEffective   float64 `parquet:"name=reservation_effective_cost, type=DOUBLE"`
+	ResourceTagsUserTeam   string  `parquet:"name=resource_tags_user_team, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ResourceTagsEKSCluster string  `parquet:"name=resource_tags_aws_eks_cluster_name, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+func TestIngestorAWSParquet(t *testing.T) {
+	toMillis := func(t time.Time) int64 {
+		return t.UnixNano() / int64(time.Millisecond)
+	}
+
+	var buf bytes.Buffer
+	pw, err := writer.NewParquetWriterFromWriter(&buf, new(curParquetRow), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rows := []curParquetRow{
+		{
+			LineItemType:           "Usage",
+			UsageStartDate:         toMillis(oct1),
+			UsageEndDate:           toMillis(oct2),
+			ResourceID:             "i-node1",
+			UnblendedCost:          4.8,
+			ProductFamily:          "Compute Instance",
+			ResourceTagsUserTeam:   "team-a",
+			ResourceTagsEKSCluster: "cluster-one",
+		},
+		{
+			LineItemType:         "DiscountedUsage",
+			UsageStartDate:       toMillis(oct1),
+			UsageEndDate:         toMillis(oct2),
+			ResourceID:           "i-node2",
+			UnblendedCost:        4.8,
+			ProductFamily:        "Compute Instance",
+			ReservationEffective: 3.0,
+		},
+	}
+	for _, row := range rows {
+		if err := pw.Write(row); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if err := pw.WriteStop(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	store := storage.NewFileStorage(t.TempDir())
+	if err := store.Write("cur/cur-00001.snappy.parquet", buf.Bytes()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ingestor := newTestIngestor(t, store, kubecost.AWSProvider, "cur")
+
+	checkItems(t, ingestor.CloudCostItemSet(oct1, oct2), map[string]expectedItem{
+		"i-node1": {
+			cost:         4.8,
+			category:     kubecost.ComputeCategory,
+			isKubernetes: true,
+			labels:       kubecost.CloudCostItemLabels{"team": "team-a"},
+		},
+		"i-node2": {cost: 3.0, category: kubecost.ComputeCategory, labels: kubecost.CloudCostItemLabels{}},
+	})
+}
+
+func TestIngestorGCP(t *testing.T) {
+	ingestor := newTestIngestor(t, storage.NewFileStorage("testdata"), "gcp", "gcp")
+
+	checkItems(t, ingestor.CloudCostItemSet(oct1, oct2), map[string]expectedItem{
+		"gke-node-1": {
+			cost:         5.0,
+			credit:       -1.5,
+			category:     kubecost.ComputeCategory,
+			isKubernetes: true,
+			labels:       kubecost.CloudCostItemLabels{"goog-k8s-cluster-name": "cluster-one", "team": "team-a"},
+		},
+		"gke-cluster-one-pvc-1": {cost: 0.8, category: kubecost.StorageCategory},
+	})
+
+	checkItems(t, ingestor.CloudCostItemSet(oct2, oct3), map[string]expectedItem{
+		"bucket-one": {cost: 0.1, category: kubecost.StorageCategory},
+	})
+}
+
+func TestIngestorAzure(t *testing.T) {
+	ingestor := newTestIngestor(t, storage.NewFileStorage("testdata"), "azure", "azure")
+
+	vmss := "/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/MC_rg_cluster-one_eastus/providers/Microsoft.Compute/virtualMachineScaleSets/aks-pool1-12345678-vmss"
+	disk := "/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/MC_rg_cluster-one_eastus/providers/Microsoft.Compute/disks/pvc-disk-1"
+
+	checkItems(t, ingestor.CloudCostItemSet(oct1, oct2), map[string]expectedItem{
+		vmss: {
+			cost:         6.0,
+			category:     kubecost.ComputeCategory,
+			isKubernetes: true,
+			labels:       kubecost.CloudCostItemLabels{"aks-managed-poolName": "pool1", "team": "team-a"},
+		},
+		disk: {cost: 0.5, credit: -0.1, category: kubecost.StorageCategory, isKubernetes: true},
+	})
+
+	vm := "/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/rg-other/providers/Microsoft.Compute/virtualMachines/vm-other"
+	checkItems(t, ingestor.CloudCostItemSet(oct2, oct3), map[string]expectedItem{
+		vm: {cost: 3.0, category: kubecost.ComputeCategory, labels: kubecost.CloudCostItemLabels{"team": "team-b"}},
+	})
+}
+
+func TestIngestorRefresh(t *testing.T) {
+	data, err := os.ReadFile("testdata/gcp/billing-export.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var gzipped bytes.Buffer
+	zw := gzip.NewWriter(&gzipped)
+	zw.Write(data)
+	zw.Close()
+
+	store := storage.NewFileStorage(t.TempDir())
+	if err := store.Write("export/2022/billing-export.json.gz", gzipped.Bytes()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ingestor := newTestIngestor(t, store, kubecost.GCPProvider, "export")
+	if n := ingestor.CloudCostItemSet(oct1, oct2).Length(); n != 2 {
+		t.Errorf("expected 2 items from gzipped export; got %d", n)
+	}
+
+	// New files are parsed on refresh
+	extra := `{"service":{"description":"Compute Engine"},"usage_start_time":"2022-10-01T00:00:00Z","usage_end_time":"2022-10-02T00:00:00Z","resource":{"name":"gke-node-2"},"cost":2.0}`
+	if err := store.Write("export/extra.json", []byte(extra)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := ingestor.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := ingestor.CloudCostItemSet(oct1, oct2).Length(); n != 3 {
+		t.Errorf("expected 3 items after adding an export; got %d", n)
+	}
+
+	// Removed files are forgotten on refresh
+	if err := store.Remove("export/2022/billing-export.json.gz"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := ingestor.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := ingestor.CloudCostItemSet(oct1, oct2).Length(); n != 1 {
+		t.Errorf("expected 1 item after removing an export; got %d", n)
+	}
+}
+
+func TestIngestorCloudCostItemSetRange(t *testing.T) {
+	ingestor := newTestIngestor(t, storage.NewFileStorage("testdata"), kubecost.AWSProvider, "aws")
+
+	ccisr, err := ingestor.CloudCostItemSetRange(oct1, oct1.Add(24*time.Hour), time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(ccisr.CloudCostItemSets) != 24 {
+		t.Fatalf("expected 24 sets; got %d", len(ccisr.CloudCostItemSets))
+	}
+
+	// Daily line items are prorated across each hour
+	for _, ccis := range ccisr.CloudCostItemSets {
+		items := itemsByProviderID(t, ccis)
+		if cci, ok := items["i-node1"]; !ok || math.Abs(cci.Cost-0.2) > 0.0001 {
+			t.Errorf("%s: expected i-node1 cost 0.2; got %v", ccis.Window, cci)
+		}
+		if cci, ok := items["i-node2"]; !ok || math.Abs(cci.Cost-0.125) > 0.0001 {
+			t.Errorf("%s: expected i-node2 cost 0.125; got %v", ccis.Window, cci)
+		}
+		if _, ok := items["i-other"]; ok {
+			t.Errorf("%s: unexpected item i-other", ccis.Window)
+		}
+	}
+}
+
+func TestNewIngestorUnsupportedProvider(t *testing.T) {
+	if _, err := NewIngestor(storage.NewFileStorage("testdata"), "scaleway", "scaleway"); err == nil {
+		t.Errorf("expected error for unsupported provider")
+	}
+}
+
+func TestNormalizeCURColumn(t *testing.T) {
+	cases := map[string]string{
+		"lineItem/UsageStartDate":              "line_item_usage_start_date",
+		"lineItem/LineItemType":                "line_item_line_item_type",
+		"product/productFamily":                "product_product_family",
+		"reservation/ReservationARN":           "reservation_reservation_a_r_n",
+		"savingsPlan/SavingsPlanEffectiveCost": "savings_plan_savings_plan_effective_cost",
+		"resourceTags/user:kubernetes.io/name": "resource_tags_user:kubernetes.io/name",
+		"line_item_usage_start_date":           "line_item_usage_start_date",
+	}
+
+	for column, expected := range cases {
+		if actual := normalizeCURColumn(column); actual != expected {
+			t.Errorf("%s: expected %s; got %s", column, expected, actual)
+		}
+	}
+}
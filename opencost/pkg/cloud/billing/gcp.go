@@ -0,0 +1,177 @@
+package billing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+// gcpFloat is a FLOAT column of a BigQuery export, which may be encoded as a
+// JSON number or string.
+type gcpFloat float64
+
+func (f *gcpFloat) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*f = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*f = gcpFloat(v)
+	return nil
+}
+
+type gcpLabel struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// gcpBillingRow is a row of the standard or detailed usage cost table of a GCP
+// billing export to BigQuery.
+type gcpBillingRow struct {
+	BillingAccountID string `json:"billing_account_id"`
+	Service          struct {
+		Description string `json:"description"`
+	} `json:"service"`
+	SKU struct {
+		Description string `json:"description"`
+	} `json:"sku"`
+	UsageStartTime string `json:"usage_start_time"`
+	UsageEndTime   string `json:"usage_end_time"`
+	Project        struct {
+		ID string `json:"id"`
+	} `json:"project"`
+	Labels       []gcpLabel `json:"labels"`
+	SystemLabels []gcpLabel `json:"system_labels"`
+	Resource     struct {
+		Name       string `json:"name"`
+		GlobalName string `json:"global_name"`
+	} `json:"resource"`
+	Cost    gcpFloat `json:"cost"`
+	Credits []struct {
+		Amount gcpFloat `json:"amount"`
+	} `json:"credits"`
+}
+
+// parseGCPBillingExport parses a GCP billing export table, exported from
+// BigQuery as newline-delimited JSON. Other formats cannot represent the
+// nested columns of the table, e.g. labels and credits.
+func parseGCPBillingExport(name string, data []byte) ([]*lineItem, error) {
+	if !strings.HasSuffix(name, ".json") && !strings.HasSuffix(name, ".jsonl") {
+		return nil, fmt.Errorf("unsupported format: GCP billing exports must be newline-delimited JSON")
+	}
+
+	items := []*lineItem{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		row := &gcpBillingRow{}
+		if err := json.Unmarshal(line, row); err != nil {
+			return nil, fmt.Errorf("row %d: %s", n, err)
+		}
+
+		item, err := parseGCPBillingRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %s", n, err)
+		}
+		if item != nil {
+			items = append(items, item)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// parseGCPBillingRow parses a row of a GCP billing export, returning nil if it
+// should be skipped.
+func parseGCPBillingRow(row *gcpBillingRow) (*lineItem, error) {
+	start, err := parseTime(row.UsageStartTime)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseTime(row.UsageEndTime)
+	if err != nil {
+		return nil, err
+	}
+	if !end.After(start) {
+		return nil, nil
+	}
+
+	credit := 0.0
+	for _, c := range row.Credits {
+		credit += float64(c.Amount)
+	}
+
+	labels := kubecost.CloudCostItemLabels{}
+	isKubernetes := row.Service.Description == "Kubernetes Engine"
+	for _, label := range row.Labels {
+		labels[label.Key] = label.Value
+		if strings.HasPrefix(label.Key, "goog-k8s-") || strings.HasPrefix(label.Key, "goog-gke-") {
+			isKubernetes = true
+		}
+	}
+	for _, label := range row.SystemLabels {
+		if strings.HasPrefix(label.Key, "goog-k8s-") || strings.HasPrefix(label.Key, "goog-gke-") {
+			isKubernetes = true
+		}
+	}
+
+	// The name of a resource is e.g. the name of an instance, which matches
+	// the provider ID of its node.
+	providerID := row.Resource.Name
+	if providerID == "" && row.Resource.GlobalName != "" {
+		providerID = row.Resource.GlobalName[strings.LastIndex(row.Resource.GlobalName, "/")+1:]
+	}
+
+	return &lineItem{
+		start: start,
+		end:   end,
+		properties: kubecost.CloudCostItemProperties{
+			ProviderID: providerID,
+			Provider:   kubecost.GCPProvider,
+			Account:    row.BillingAccountID,
+			Project:    row.Project.ID,
+			Service:    row.Service.Description,
+			Category:   gcpCategory(row.Service.Description, row.SKU.Description),
+			Labels:     labels,
+		},
+		isKubernetes: isKubernetes,
+		cost:         float64(row.Cost),
+		credit:       credit,
+	}, nil
+}
+
+// gcpCategory returns the asset category of a row, given its service and SKU
+func gcpCategory(service, sku string) string {
+	sku = strings.ToLower(sku)
+	switch {
+	case strings.Contains(sku, "pd capacity"), strings.Contains(sku, "storage"), strings.Contains(sku, "snapshot"):
+		return kubecost.StorageCategory
+	case strings.Contains(sku, "egress"), strings.Contains(sku, "network"), strings.Contains(sku, "load balancing"), strings.Contains(sku, "ip charge"):
+		return kubecost.NetworkCategory
+	case service == "Compute Engine":
+		return kubecost.ComputeCategory
+	case service == "Kubernetes Engine":
+		return kubecost.ManagementCategory
+	case service == "Cloud Storage":
+		return kubecost.StorageCategory
+	}
+	return kubecost.OtherCategory
+}
@@ -0,0 +1,156 @@
+package billing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+// reconciliationKey converts the provider ID of an asset or a CloudCostItem to
+// a key by which they can be matched. The provider IDs of assets have already
+// been parsed to match those of the billing exports of AWS and GCP, e.g.
+// "i-0fea4fd46592d050b", but Azure nodes keep the scheme of the Kubernetes
+// provider ID, and the nodes of a scale set are billed as the scale set.
+func reconciliationKey(providerID string) string {
+	key := strings.ToLower(strings.TrimSpace(providerID))
+	key = strings.TrimPrefix(key, "azure://")
+
+	const vmss = "/virtualmachinescalesets/"
+	if i := strings.Index(key, vmss); i >= 0 {
+		if j := strings.Index(key[i+len(vmss):], "/virtualmachines/"); j >= 0 {
+			key = key[:i+len(vmss)+j]
+		}
+	}
+
+	return key
+}
+
+// ReconcileAssetSet sets the adjustment of each Node and Disk of the given
+// AssetSet which has a billed cost in the given CloudCostItemSet, so that its
+// total cost is the billed cost, including credits. If several assets have the
+// same billed resource, e.g. the nodes of an Azure scale set, the billed cost
+// is shared in proportion to their costs before adjustment. It returns the
+// number of assets which were reconciled.
+func ReconcileAssetSet(as *kubecost.AssetSet, ccis *kubecost.CloudCostItemSet) (int, error) {
+	if as == nil || ccis == nil {
+		return 0, nil
+	}
+	if !as.Window.Equal(ccis.Window) {
+		return 0, fmt.Errorf("cannot reconcile AssetSet %s with CloudCostItemSet %s", as.Window, ccis.Window)
+	}
+
+	billed := map[string]float64{}
+	for _, cci := range ccis.CloudCostItems {
+		key := reconciliationKey(cci.Properties.ProviderID)
+		if key == "" {
+			continue
+		}
+		billed[key] += cci.Cost + cci.Credit
+	}
+
+	groups := map[string][]kubecost.Asset{}
+	for _, asset := range as.Assets {
+		switch asset.(type) {
+		case *kubecost.Node, *kubecost.Disk:
+		default:
+			continue
+		}
+
+		props := asset.GetProperties()
+		if props == nil {
+			continue
+		}
+		key := reconciliationKey(props.ProviderID)
+		if _, ok := billed[key]; !ok || key == "" {
+			continue
+		}
+		groups[key] = append(groups[key], asset)
+	}
+
+	reconciled := 0
+	for key, assets := range groups {
+		unadjustedTotal := 0.0
+		for _, asset := range assets {
+			unadjustedTotal += asset.TotalCost() - asset.GetAdjustment()
+		}
+
+		for _, asset := range assets {
+			unadjusted := asset.TotalCost() - asset.GetAdjustment()
+
+			share := 1.0 / float64(len(assets))
+			if unadjustedTotal > 0 {
+				share = unadjusted / unadjustedTotal
+			}
+
+			asset.SetAdjustment(billed[key]*share - unadjusted)
+			reconciled++
+		}
+	}
+
+	return reconciled, nil
+}
+
+// assetKey identifies a Node or Disk by its cluster and name
+type assetKey struct {
+	cluster string
+	name    string
+}
+
+// ReconcileAllocationSet adjusts the costs of each Allocation of the given
+// AllocationSet by the reconciliation of the Node it ran on, and of the Disks
+// of its PVs, in the given AssetSet, which has been reconciled by
+// ReconcileAssetSet. The CPU, GPU and RAM costs of an Allocation are adjusted
+// in proportion to the adjustment of its Node, and the PV costs in proportion
+// to the adjustments of its Disks.
+func ReconcileAllocationSet(allocs *kubecost.AllocationSet, as *kubecost.AssetSet) error {
+	if allocs == nil || as == nil {
+		return nil
+	}
+	if !allocs.Window.Equal(as.Window) {
+		return fmt.Errorf("cannot reconcile AllocationSet %s with AssetSet %s", allocs.Window, as.Window)
+	}
+
+	// Ratios of the cost after adjustment to the cost before
+	nodeRatios := map[assetKey]float64{}
+	diskRatios := map[assetKey]float64{}
+	for _, asset := range as.Assets {
+		props := asset.GetProperties()
+		if props == nil || asset.GetAdjustment() == 0 {
+			continue
+		}
+		unadjusted := asset.TotalCost() - asset.GetAdjustment()
+		if unadjusted <= 0 {
+			continue
+		}
+		ratio := asset.TotalCost() / unadjusted
+		key := assetKey{cluster: props.Cluster, name: props.Name}
+
+		switch asset.(type) {
+		case *kubecost.Node:
+			nodeRatios[key] = ratio
+		case *kubecost.Disk:
+			diskRatios[key] = ratio
+		}
+	}
+
+	for _, alloc := range allocs.Allocations {
+		if alloc.Properties == nil {
+			continue
+		}
+
+		if ratio, ok := nodeRatios[assetKey{cluster: alloc.Properties.Cluster, name: alloc.Properties.Node}]; ok {
+			alloc.CPUCostAdjustment += alloc.CPUCost * (ratio - 1)
+			alloc.GPUCostAdjustment += alloc.GPUCost * (ratio - 1)
+			alloc.RAMCostAdjustment += alloc.RAMCost * (ratio - 1)
+		}
+
+		for pvKey, pv := range alloc.PVs {
+			if ratio, ok := diskRatios[assetKey{cluster: pvKey.Cluster, name: pvKey.Name}]; ok {
+				alloc.PVCostAdjustment += pv.Cost * (ratio - 1)
+			}
+		}
+	}
+
+	return nil
+}
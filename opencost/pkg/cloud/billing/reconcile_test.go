@@ -0,0 +1,164 @@
+package billing
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+func newTestCloudCostItem(providerID string, cost, credit float64) *kubecost.CloudCostItem {
+	return &kubecost.CloudCostItem{
+		Properties: kubecost.CloudCostItemProperties{
+			ProviderID: providerID,
+			Category:   kubecost.ComputeCategory,
+		},
+		Window: kubecost.NewClosedWindow(oct1, oct2),
+		Cost:   cost,
+		Credit: credit,
+	}
+}
+
+func newTestNode(name, providerID string, cpuCost, ramCost float64) *kubecost.Node {
+	node := kubecost.NewNode(name, "cluster1", providerID, oct1, oct2, kubecost.NewClosedWindow(oct1, oct2))
+	node.CPUCost = cpuCost
+	node.RAMCost = ramCost
+	return node
+}
+
+func newTestDisk(name, providerID string, cost float64) *kubecost.Disk {
+	disk := kubecost.NewDisk(name, "cluster1", providerID, oct1, oct2, kubecost.NewClosedWindow(oct1, oct2))
+	disk.Cost = cost
+	return disk
+}
+
+func TestReconciliationKey(t *testing.T) {
+	cases := map[string]string{
+		"i-0fea4fd46592d050b": "i-0fea4fd46592d050b",
+		"gke-node-1":          "gke-node-1",
+		"azure:///subscriptions/sub/resourceGroups/MC_rg/providers/Microsoft.Compute/virtualMachineScaleSets/aks-pool1-vmss/virtualMachines/0": "/subscriptions/sub/resourcegroups/mc_rg/providers/microsoft.compute/virtualmachinescalesets/aks-pool1-vmss",
+		"/subscriptions/sub/resourceGroups/MC_rg/providers/Microsoft.Compute/virtualMachineScaleSets/aks-pool1-vmss":                           "/subscriptions/sub/resourcegroups/mc_rg/providers/microsoft.compute/virtualmachinescalesets/aks-pool1-vmss",
+		"": "",
+	}
+
+	for providerID, expected := range cases {
+		if actual := reconciliationKey(providerID); actual != expected {
+			t.Errorf("%s: expected %s; got %s", providerID, expected, actual)
+		}
+	}
+}
+
+func TestReconcileAssetSet(t *testing.T) {
+	vmss := "/subscriptions/sub/resourceGroups/MC_rg/providers/Microsoft.Compute/virtualMachineScaleSets/aks-pool1-vmss"
+
+	// Listed at 3.0, billed at 2.0
+	node1 := newTestNode("node1", "i-node1", 2.0, 1.0)
+	// Not billed
+	node2 := newTestNode("node2", "i-node2", 2.0, 1.0)
+	// Listed at 1.0 and 3.0, billed together at 2.0
+	node3 := newTestNode("node3", "azure://"+vmss+"/virtualMachines/0", 0.5, 0.5)
+	node4 := newTestNode("node4", "azure://"+vmss+"/virtualMachines/1", 2.0, 1.0)
+	// Listed at 1.0, billed at 1.0 less a credit of 0.25
+	disk1 := newTestDisk("disk1", "vol-disk1", 1.0)
+
+	as := kubecost.NewAssetSet(oct1, oct2, node1, node2, node3, node4, disk1)
+
+	ccis := kubecost.NewCloudCostItemSet(oct1, oct2,
+		newTestCloudCostItem("i-node1", 2.0, 0.0),
+		newTestCloudCostItem(vmss, 2.0, 0.0),
+		newTestCloudCostItem("vol-disk1", 1.0, -0.25),
+		newTestCloudCostItem("i-other", 10.0, 0.0),
+	)
+
+	reconciled, err := ReconcileAssetSet(as, ccis)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if reconciled != 4 {
+		t.Errorf("expected 4 reconciled assets; got %d", reconciled)
+	}
+
+	expected := map[kubecost.Asset]float64{
+		node1: 2.0,
+		node2: 3.0,
+		node3: 0.5,
+		node4: 1.5,
+		disk1: 0.75,
+	}
+	for asset, cost := range expected {
+		if math.Abs(asset.TotalCost()-cost) > 0.0001 {
+			t.Errorf("%s: expected total cost %f; got %f", asset.GetProperties().Name, cost, asset.TotalCost())
+		}
+	}
+
+	// Reconciling again has the same result
+	if _, err := ReconcileAssetSet(as, ccis); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if math.Abs(node1.TotalCost()-2.0) > 0.0001 {
+		t.Errorf("node1: expected total cost 2.0 after reconciling again; got %f", node1.TotalCost())
+	}
+
+	// Windows must match
+	if _, err := ReconcileAssetSet(as, kubecost.NewCloudCostItemSet(oct2, oct3)); err == nil {
+		t.Errorf("expected error reconciling mismatched windows")
+	}
+}
+
+func TestReconcileAllocationSet(t *testing.T) {
+	node1 := newTestNode("node1", "i-node1", 2.0, 1.0)
+	node1.SetAdjustment(-1.0)
+	disk1 := newTestDisk("disk1", "vol-disk1", 1.0)
+	disk1.SetAdjustment(-0.25)
+	as := kubecost.NewAssetSet(oct1, oct2, node1, disk1)
+
+	alloc := kubecost.NewMockUnitAllocation("", oct1, 24*time.Hour, &kubecost.AllocationProperties{
+		Cluster:   "cluster1",
+		Node:      "node1",
+		Namespace: "namespace1",
+		Pod:       "pod1",
+		Container: "container1",
+	})
+	alloc.CPUCost = 3.0
+	alloc.RAMCost = 1.5
+	alloc.GPUCost = 0.0
+	alloc.PVs = kubecost.PVAllocations{
+		{Cluster: "cluster1", Name: "disk1"}: {ByteHours: 1, Cost: 0.4},
+		{Cluster: "cluster1", Name: "disk2"}: {ByteHours: 1, Cost: 0.4},
+	}
+
+	other := kubecost.NewMockUnitAllocation("cluster1/namespace1/pod2/container1", oct1, 24*time.Hour, &kubecost.AllocationProperties{
+		Cluster:   "cluster1",
+		Node:      "node2",
+		Namespace: "namespace1",
+		Pod:       "pod2",
+		Container: "container1",
+	})
+	otherTotal := other.TotalCost()
+
+	allocs := kubecost.NewAllocationSet(oct1, oct2, alloc, other)
+
+	if err := ReconcileAllocationSet(allocs, as); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// node1 is billed at 2/3 of its list cost, and disk1 at 3/4
+	if math.Abs(alloc.CPUTotalCost()-2.0) > 0.0001 {
+		t.Errorf("expected CPU cost 2.0; got %f", alloc.CPUTotalCost())
+	}
+	if math.Abs(alloc.RAMTotalCost()-1.0) > 0.0001 {
+		t.Errorf("expected RAM cost 1.0; got %f", alloc.RAMTotalCost())
+	}
+	if math.Abs(alloc.PVTotalCost()-0.7) > 0.0001 {
+		t.Errorf("expected PV cost 0.7; got %f", alloc.PVTotalCost())
+	}
+	if math.Abs(other.TotalCost()-otherTotal) > 0.0001 {
+		t.Errorf("expected unchanged total cost %f; got %f", otherTotal, other.TotalCost())
+	}
+
+	// Windows must match
+	if err := ReconcileAllocationSet(allocs, kubecost.NewAssetSet(oct2, oct3)); err == nil {
+		t.Errorf("expected error reconciling mismatched windows")
+	}
+}
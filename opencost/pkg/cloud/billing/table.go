@@ -0,0 +1,233 @@
+package billing
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/types"
+)
+
+// table is the content of a CSV or Parquet billing export file, with every
+// value as a string. Column names are normalized by the provider's parser.
+type table struct {
+	columns map[string]int
+	records [][]string
+}
+
+// newTable creates a table with the given header, normalizing each column name
+// with the given function.
+func newTable(header []string, normalize func(string) string) *table {
+	t := &table{
+		columns: make(map[string]int, len(header)),
+	}
+	for i, column := range header {
+		t.columns[normalize(column)] = i
+	}
+	return t
+}
+
+// has returns true if the table has the given column
+func (t *table) has(column string) bool {
+	_, ok := t.columns[column]
+	return ok
+}
+
+// get returns the value of the given column of the given record, or the empty
+// string if the table has no such column.
+func (t *table) get(record []string, column string) string {
+	i, ok := t.columns[column]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
+
+// getFloat returns the value of the given column of the given record as a
+// float64, or 0.0 if it is empty.
+func (t *table) getFloat(record []string, column string) (float64, error) {
+	s := t.get(record, column)
+	if s == "" {
+		return 0.0, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0.0, fmt.Errorf("column %s: %s", column, err)
+	}
+	return f, nil
+}
+
+// gunzip decompresses the given data if the file name has a ".gz" suffix, and
+// returns the name without the suffix.
+func gunzip(name string, data []byte) (string, []byte, error) {
+	if len(name) < 3 || name[len(name)-3:] != ".gz" {
+		return name, data, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return name, nil, err
+	}
+	defer zr.Close()
+
+	unzipped, err := io.ReadAll(zr)
+	if err != nil {
+		return name, nil, err
+	}
+
+	return name[:len(name)-3], unzipped, nil
+}
+
+// readCSVTable reads a CSV file with a header row
+func readCSVTable(data []byte, normalize func(string) string) (*table, error) {
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return newTable(nil, normalize), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %s", err)
+	}
+	// Strip the byte order mark which some exports begin with
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	t := newTable(header, normalize)
+	t.records, err = cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV: %s", err)
+	}
+
+	return t, nil
+}
+
+// readParquetTable reads every top-level column of a Parquet file. Timestamps
+// are formatted as RFC 3339, so that they can be parsed like those of a CSV.
+func readParquetTable(data []byte, normalize func(string) string) (*table, error) {
+	file, err := buffer.NewBufferFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, err := reader.NewParquetColumnReader(file, 1)
+	if err != nil {
+		return nil, fmt.Errorf("reading Parquet footer: %s", err)
+	}
+	defer pr.ReadStop()
+
+	numRows := pr.GetNumRows()
+	sh := pr.SchemaHandler
+
+	// The root of the schema is at index 0, so the top-level columns are its
+	// children.
+	header := []string{}
+	columns := [][]string{}
+	for i := 1; i < len(sh.SchemaElements); i++ {
+		element := sh.SchemaElements[i]
+		inPath := sh.IndexMap[int32(i)]
+		if element.GetNumChildren() > 0 || len(sh.InPathToExPath[inPath]) == 0 {
+			continue
+		}
+		// Skip nested columns, which billing exports do not use
+		if len(common.StrToPath(inPath)) != 2 {
+			continue
+		}
+
+		values := make([]string, numRows)
+		if numRows > 0 {
+			raw, _, _, err := pr.ReadColumnByPath(inPath, numRows)
+			if err != nil {
+				return nil, fmt.Errorf("reading Parquet column %s: %s", element.GetName(), err)
+			}
+			for j := 0; j < len(raw) && j < len(values); j++ {
+				values[j] = formatParquetValue(element, raw[j])
+			}
+		}
+
+		header = append(header, sh.Infos[i].ExName)
+		columns = append(columns, values)
+	}
+
+	t := newTable(header, normalize)
+	t.records = make([][]string, numRows)
+	for j := range t.records {
+		record := make([]string, len(columns))
+		for i := range columns {
+			record[i] = columns[i][j]
+		}
+		t.records[j] = record
+	}
+
+	return t, nil
+}
+
+// formatParquetValue formats a value of the given column as a string
+func formatParquetValue(element *parquet.SchemaElement, value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		if element.GetType() == parquet.Type_INT96 {
+			return types.INT96ToTime(v).UTC().Format(time.RFC3339)
+		}
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case bool:
+		return strconv.FormatBool(v)
+	case int32:
+		if element.ConvertedType != nil && *element.ConvertedType == parquet.ConvertedType_DATE {
+			return time.Unix(int64(v)*86400, 0).UTC().Format(time.RFC3339)
+		}
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		if unit, ok := timestampUnit(element); ok {
+			return time.Unix(0, v*int64(unit)).UTC().Format(time.RFC3339)
+		}
+		return strconv.FormatInt(v, 10)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// timestampUnit returns the unit of the given INT64 column, if it is a
+// timestamp.
+func timestampUnit(element *parquet.SchemaElement) (time.Duration, bool) {
+	if lt := element.GetLogicalType(); lt != nil && lt.IsSetTIMESTAMP() {
+		unit := lt.GetTIMESTAMP().GetUnit()
+		switch {
+		case unit.IsSetMILLIS():
+			return time.Millisecond, true
+		case unit.IsSetMICROS():
+			return time.Microsecond, true
+		case unit.IsSetNANOS():
+			return time.Nanosecond, true
+		}
+	}
+
+	if element.ConvertedType != nil {
+		switch *element.ConvertedType {
+		case parquet.ConvertedType_TIMESTAMP_MILLIS:
+			return time.Millisecond, true
+		case parquet.ConvertedType_TIMESTAMP_MICROS:
+			return time.Microsecond, true
+		}
+	}
+
+	return 0, false
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/opencost/opencost/pkg/prom"
 	"github.com/opencost/opencost/pkg/thanos"
 	"github.com/opencost/opencost/pkg/util"
+	allocationfilterutil "github.com/opencost/opencost/pkg/util/allocationfilterutil/v2"
 	"github.com/opencost/opencost/pkg/util/json"
 	"github.com/patrickmn/go-cache"
 	prometheusClient "github.com/prometheus/client_golang/api"
@@ -2128,6 +2129,31 @@ func ParseAggregationProperties(qp httputil.QueryParams, key string) ([]string,
 	return aggregateBy, nil
 }
 
+// ParseAllocationFilter attempts to parse and return an AllocationFilter
+// encoded in the V2 filter language under the given key, e.g.
+// `namespace:"kubecost"+label[app]:"cost-analyzer"|controllerKind!:"job"`.
+// If no filter is given, nil is returned.
+func ParseAllocationFilter(qp httputil.QueryParams, key string) (kubecost.AllocationFilter, error) {
+	raw := strings.TrimSpace(qp.Get(key, ""))
+	if raw == "" {
+		return nil, nil
+	}
+
+	return allocationfilterutil.ParseAllocationFilter(raw)
+}
+
+// filterAllocationSetRange removes the Allocations which do not match the
+// given filter from each AllocationSet of the range, without aggregating.
+func filterAllocationSetRange(asr *kubecost.AllocationSetRange, filter kubecost.AllocationFilter) {
+	for _, as := range asr.Allocations {
+		for name, alloc := range as.Allocations {
+			if !filter.Matches(alloc) {
+				as.Delete(name)
+			}
+		}
+	}
+}
+
 func (a *Accesses) ComputeAllocationHandlerSummary(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -2158,10 +2184,24 @@ func (a *Accesses) ComputeAllocationHandlerSummary(w http.ResponseWriter, r *htt
 		http.Error(w, fmt.Sprintf("Invalid 'aggregate' parameter: %s", err), http.StatusBadRequest)
 	}
 
+	// Filter is an optional expression in the V2 filter language which
+	// restricts the results to matching Allocations.
+	// Examples: `namespace:"kubecost"`, `cluster:"a"+label[app]!:"b"|pod:"c"`
+	filter, err := ParseAllocationFilter(qp, "filter")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid 'filter' parameter: %s", err), http.StatusBadRequest)
+		return
+	}
+
 	// Accumulate is an optional parameter, defaulting to false, which if true
 	// sums each Set in the Range, producing one Set.
 	accumulate := qp.GetBool("accumulate", false)
 
+	// Reconcile is an optional parameter, defaulting to true, which if true
+	// adjusts the costs of each Set by the billed costs of its nodes and disks,
+	// if cloud billing ingestion is enabled and the Set's window is billed.
+	reconcile := qp.GetBool("reconcile", true)
+
 	// Query for AllocationSets in increments of the given step duration,
 	// appending each to the AllocationSetRange.
 	asr := kubecost.NewAllocationSetRange()
@@ -2170,23 +2210,32 @@ func (a *Accesses) ComputeAllocationHandlerSummary(w http.ResponseWriter, r *htt
 		stepEnd := stepStart.Add(step)
 		stepWindow := kubecost.NewWindow(&stepStart, &stepEnd)
 
-		as, err := a.Model.ComputeAllocation(*stepWindow.Start(), *stepWindow.End(), resolution)
+		as, err := a.computeAllocation(*stepWindow.Start(), *stepWindow.End(), resolution)
 		if err != nil {
 			WriteError(w, InternalServerError(err.Error()))
 			return
 		}
+		if reconcile {
+			if err := a.reconcileAllocation(as); err != nil {
+				WriteError(w, InternalServerError(err.Error()))
+				return
+			}
+		}
 		asr.Append(as)
 
 		stepStart = stepEnd
 	}
 
-	// Aggregate, if requested
+	// Aggregate and filter, if requested. Without aggregation, the filter is
+	// applied to the unaggregated Allocations.
 	if len(aggregateBy) > 0 {
-		err = asr.AggregateBy(aggregateBy, nil)
+		err = asr.AggregateBy(aggregateBy, &kubecost.AllocationAggregationOptions{Filter: filter})
 		if err != nil {
 			WriteError(w, InternalServerError(err.Error()))
 			return
 		}
+	} else if filter != nil {
+		filterAllocationSetRange(asr, filter)
 	}
 
 	// Accumulate, if requested
@@ -2240,10 +2289,24 @@ func (a *Accesses) ComputeAllocationHandler(w http.ResponseWriter, r *http.Reque
 		http.Error(w, fmt.Sprintf("Invalid 'aggregate' parameter: %s", err), http.StatusBadRequest)
 	}
 
+	// Filter is an optional expression in the V2 filter language which
+	// restricts the results to matching Allocations.
+	// Examples: `namespace:"kubecost"`, `cluster:"a"+label[app]!:"b"|pod:"c"`
+	filter, err := ParseAllocationFilter(qp, "filter")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid 'filter' parameter: %s", err), http.StatusBadRequest)
+		return
+	}
+
 	// Accumulate is an optional parameter, defaulting to false, which if true
 	// sums each Set in the Range, producing one Set.
 	accumulate := qp.GetBool("accumulate", false)
 
+	// Reconcile is an optional parameter, defaulting to true, which if true
+	// adjusts the costs of each Set by the billed costs of its nodes and disks,
+	// if cloud billing ingestion is enabled and the Set's window is billed.
+	reconcile := qp.GetBool("reconcile", true)
+
 	// AccumulateBy is an optional parameter that accumulates an AllocationSetRange
 	// by the resolution of the given time duration.
 	// Defaults to 0. If a value is not passed then the parameter is not used.
@@ -2257,23 +2320,32 @@ func (a *Accesses) ComputeAllocationHandler(w http.ResponseWriter, r *http.Reque
 		stepEnd := stepStart.Add(step)
 		stepWindow := kubecost.NewWindow(&stepStart, &stepEnd)
 
-		as, err := a.Model.ComputeAllocation(*stepWindow.Start(), *stepWindow.End(), resolution)
+		as, err := a.computeAllocation(*stepWindow.Start(), *stepWindow.End(), resolution)
 		if err != nil {
 			WriteError(w, InternalServerError(err.Error()))
 			return
 		}
+		if reconcile {
+			if err := a.reconcileAllocation(as); err != nil {
+				WriteError(w, InternalServerError(err.Error()))
+				return
+			}
+		}
 		asr.Append(as)
 
 		stepStart = stepEnd
 	}
 
-	// Aggregate, if requested
+	// Aggregate and filter, if requested. Without aggregation, the filter is
+	// applied to the unaggregated Allocations.
 	if len(aggregateBy) > 0 {
-		err = asr.AggregateBy(aggregateBy, nil)
+		err = asr.AggregateBy(aggregateBy, &kubecost.AllocationAggregationOptions{Filter: filter})
 		if err != nil {
 			WriteError(w, InternalServerError(err.Error()))
 			return
 		}
+	} else if filter != nil {
+		filterAllocationSetRange(asr, filter)
 	}
 
 	// Accumulate, if requested
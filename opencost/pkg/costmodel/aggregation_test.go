@@ -1,9 +1,19 @@
 package costmodel
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/storage"
 	"github.com/opencost/opencost/pkg/util"
+	"github.com/opencost/opencost/pkg/util/timeutil"
 )
 
 func TestScaleHourlyCostData(t *testing.T) {
@@ -193,3 +203,113 @@ func TestScaleHourlyCostData(t *testing.T) {
 		}
 	}
 }
+
+// mockNamespacesAllocationSource returns an AllocationSet containing one unit
+// Allocation in each of the namespaces "a", "b" and "c" for any window.
+type mockNamespacesAllocationSource struct{}
+
+func (mnas *mockNamespacesAllocationSource) ComputeAllocation(start, end time.Time, resolution time.Duration) (*kubecost.AllocationSet, error) {
+	as := kubecost.NewAllocationSet(start, end)
+	for _, ns := range []string{"a", "b", "c"} {
+		alloc := kubecost.NewMockUnitAllocation("cluster1/node1/"+ns+"/pod1/container1", start, end.Sub(start), &kubecost.AllocationProperties{
+			Cluster:   "cluster1",
+			Node:      "node1",
+			Namespace: ns,
+			Pod:       "pod1",
+			Container: "container1",
+		})
+		as.Set(alloc)
+	}
+	return as, nil
+}
+
+// allocationNamesFromResponse returns the sorted names of the Allocations in
+// the single AllocationSet returned by either allocation handler.
+func allocationNamesFromResponse(t *testing.T, body []byte, summary bool) []string {
+	var allocs map[string]json.RawMessage
+	if summary {
+		var resp struct {
+			Data struct {
+				Sets []struct {
+					Allocations map[string]json.RawMessage `json:"allocations"`
+				} `json:"sets"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(resp.Data.Sets) != 1 {
+			t.Fatalf("expected 1 set; got %d: %s", len(resp.Data.Sets), body)
+		}
+		allocs = resp.Data.Sets[0].Allocations
+	} else {
+		var resp struct {
+			Data []map[string]json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(resp.Data) != 1 {
+			t.Fatalf("expected 1 set; got %d: %s", len(resp.Data), body)
+		}
+		allocs = resp.Data[0]
+	}
+
+	names := []string{}
+	for name := range allocs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestComputeAllocationHandler_Filter(t *testing.T) {
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(timeutil.Day)
+
+	// The window is served entirely from the store, so the CostModel is
+	// never queried.
+	store := NewAllocationStore(&mockNamespacesAllocationSource{}, storage.NewFileStorage(t.TempDir()), timeutil.Day, timeutil.Day)
+	store.Build(end.Add(time.Hour))
+	a := &Accesses{AllocationStores: []*AllocationStore{store}}
+
+	cases := map[string]struct {
+		aggregate string
+		expected  []string
+	}{
+		"filter without aggregate": {
+			expected: []string{
+				"cluster1/node1/a/pod1/container1",
+				"cluster1/node1/b/pod1/container1",
+			},
+		},
+		"filter with aggregate": {
+			aggregate: "namespace",
+			expected:  []string{"a", "b"},
+		},
+	}
+
+	for name, c := range cases {
+		query := url.Values{}
+		query.Set("window", start.Format(time.RFC3339)+","+end.Format(time.RFC3339))
+		query.Set("filter", `namespace:"a"|namespace:"b"`)
+		if c.aggregate != "" {
+			query.Set("aggregate", c.aggregate)
+		}
+
+		for _, summary := range []bool{false, true} {
+			r := httptest.NewRequest(http.MethodGet, "/allocation?"+query.Encode(), nil)
+			w := httptest.NewRecorder()
+			if summary {
+				a.ComputeAllocationHandlerSummary(w, r, nil)
+			} else {
+				a.ComputeAllocationHandler(w, r, nil)
+			}
+
+			names := allocationNamesFromResponse(t, w.Body.Bytes(), summary)
+			if strings.Join(names, ",") != strings.Join(c.expected, ",") {
+				t.Errorf("%s (summary: %t): expected allocations %v; got %v", name, summary, c.expected, names)
+			}
+		}
+	}
+}
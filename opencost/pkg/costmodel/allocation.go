@@ -117,6 +117,14 @@ func (cm *CostModel) ComputeAllocation(start, end time.Time, resolution time.Dur
 		s = e
 	}
 
+	return accumulateAllocationSetRange(asr, start, end)
+}
+
+// accumulateAllocationSetRange accumulates the AllocationSets of the given
+// range into a single AllocationSet for the window defined by the given start
+// and end times, maintaining the labels, annotations, services and maximum
+// usage values of each Allocation which are not maintained by Accumulate.
+func accumulateAllocationSetRange(asr *kubecost.AllocationSetRange, start, end time.Time) (*kubecost.AllocationSet, error) {
 	// Populate annotations, labels, and services on each Allocation. This is
 	// necessary because Properties.Intersection does not propagate any values
 	// stored in maps or slices for performance reasons. In this case, however,
@@ -168,7 +176,7 @@ func (cm *CostModel) ComputeAllocation(start, end time.Time, resolution time.Dur
 	// recomputed.
 	result, err := asr.Accumulate()
 	if err != nil {
-		return kubecost.NewAllocationSet(start, end), fmt.Errorf("error accumulating data for %s: %s", kubecost.NewClosedWindow(s, e), err)
+		return kubecost.NewAllocationSet(start, end), fmt.Errorf("error accumulating data for %s: %s", kubecost.NewClosedWindow(start, end), err)
 	}
 
 	// Apply the annotations, labels, and services to the post-accumulation
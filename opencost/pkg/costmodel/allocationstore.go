@@ -0,0 +1,536 @@
+package costmodel
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opencost/opencost/pkg/env"
+	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/storage"
+	"github.com/opencost/opencost/pkg/util/httputil"
+	"github.com/opencost/opencost/pkg/util/stringutil"
+	"github.com/opencost/opencost/pkg/util/timeutil"
+)
+
+// allocationStoreDir is the directory of the storage in which AllocationSets
+// are persisted. Each AllocationStore uses a sub-directory named after its
+// resolution, e.g. "allocation/1d".
+const allocationStoreDir = "allocation"
+
+// AllocationSource computes an AllocationSet for the window defined by the
+// given start and end times. It is implemented by CostModel.
+type AllocationSource interface {
+	ComputeAllocation(start, end time.Time, resolution time.Duration) (*kubecost.AllocationSet, error)
+}
+
+// AllocationStore computes one AllocationSet per window of its resolution
+// (e.g. one per day) in the background, and persists each to a
+// storage.Storage, encoded with the bingen codecs. Only complete windows are
+// stored, and windows older than the duration of the store are removed.
+type AllocationStore struct {
+	lock            sync.RWMutex
+	source          AllocationSource
+	store           storage.Storage
+	resolution      time.Duration
+	duration        time.Duration
+	queryResolution time.Duration
+	offset          time.Duration
+	refreshRate     time.Duration
+	// windows records the start time (in unix seconds) of each stored set
+	windows   map[int64]bool
+	startTime time.Time
+	lastRun   time.Time
+	progress  float64
+	rebuild   chan struct{}
+	stop      chan struct{}
+}
+
+// NewAllocationStore creates an AllocationStore which stores AllocationSets of
+// the given resolution, computed by the given source, covering the given
+// duration.
+func NewAllocationStore(source AllocationSource, store storage.Storage, resolution, duration time.Duration) *AllocationStore {
+	return &AllocationStore{
+		source:          source,
+		store:           store,
+		resolution:      resolution,
+		duration:        duration,
+		queryResolution: env.GetETLResolution(),
+		offset:          env.GetParsedUTCOffset(),
+		refreshRate:     env.GetETLRefreshRate(),
+		windows:         map[int64]bool{},
+		rebuild:         make(chan struct{}, 1),
+		stop:            make(chan struct{}),
+	}
+}
+
+// Resolution returns the duration of each AllocationSet in the store
+func (s *AllocationStore) Resolution() time.Duration {
+	return s.resolution
+}
+
+// dir returns the directory of the storage containing the store's files
+func (s *AllocationStore) dir() string {
+	return path.Join(allocationStoreDir, timeutil.DurationString(s.resolution))
+}
+
+// fileName returns the name of the file storing the set starting at start
+func (s *AllocationStore) fileName(start time.Time) string {
+	return fmt.Sprintf("%d-%d", start.Unix(), start.Add(s.resolution).Unix())
+}
+
+// parseFileName returns the start time of the set stored in the given file
+func (s *AllocationStore) parseFileName(name string) (time.Time, error) {
+	split := strings.Split(name, "-")
+	if len(split) != 2 {
+		return time.Time{}, fmt.Errorf("illegal file name: %s", name)
+	}
+	start, err := strconv.ParseInt(split[0], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("illegal file name: %s", name)
+	}
+	end, err := strconv.ParseInt(split[1], 10, 64)
+	if err != nil || time.Duration(end-start)*time.Second != s.resolution {
+		return time.Time{}, fmt.Errorf("illegal file name: %s", name)
+	}
+	return time.Unix(start, 0).UTC(), nil
+}
+
+// alignStart returns the start of the window of the store's resolution which
+// contains t, respecting the configured UTC offset.
+func (s *AllocationStore) alignStart(t time.Time) time.Time {
+	return t.Add(s.offset).Truncate(s.resolution).Add(-s.offset).UTC()
+}
+
+// isAligned returns true if t is the start of a window of the store
+func (s *AllocationStore) isAligned(t time.Time) bool {
+	return s.alignStart(t).Equal(t)
+}
+
+// Load reads the set of stored windows from storage
+func (s *AllocationStore) Load() error {
+	files, err := s.store.List(s.dir())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("listing %s: %s", s.store.FullPath(s.dir()), err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.windows = map[int64]bool{}
+	for _, file := range files {
+		start, err := s.parseFileName(file.Name)
+		if err != nil {
+			log.Warnf("AllocationStore: ignoring file in %s: %s", s.dir(), err)
+			continue
+		}
+		s.windows[start.Unix()] = true
+	}
+
+	return nil
+}
+
+// Start loads the stored windows, then builds the store in the background
+// at the configured refresh rate until Stop is called.
+func (s *AllocationStore) Start() {
+	s.lock.Lock()
+	s.startTime = time.Now().UTC()
+	s.lock.Unlock()
+
+	if err := s.Load(); err != nil {
+		log.Errorf("AllocationStore: failed to load %s store: %s", timeutil.DurationString(s.resolution), err)
+	}
+
+	go func() {
+		defer errors.HandlePanic()
+
+		for {
+			s.Build(time.Now())
+
+			select {
+			case <-s.stop:
+				return
+			case <-s.rebuild:
+			case <-time.After(s.refreshRate):
+			}
+		}
+	}()
+}
+
+// Stop stops building the store in the background
+func (s *AllocationStore) Stop() {
+	close(s.stop)
+}
+
+// Build computes and stores each complete window, up to the given time, which
+// is missing from the store, starting with the most recent. It then removes
+// windows which are older than the duration of the store.
+func (s *AllocationStore) Build(now time.Time) {
+	end := s.alignStart(now)
+	start := s.alignStart(end.Add(-s.duration))
+
+	missing := []time.Time{}
+	for t := end.Add(-s.resolution); !t.Before(start); t = t.Add(-s.resolution) {
+		if !s.Has(t) {
+			missing = append(missing, t)
+		}
+	}
+
+	for i, t := range missing {
+		s.lock.Lock()
+		s.progress = float64(i) / float64(len(missing))
+		s.lock.Unlock()
+
+		if err := s.compute(t); err != nil {
+			log.Errorf("AllocationStore: failed to build %s: %s", kubecost.NewClosedWindow(t, t.Add(s.resolution)), err)
+		}
+	}
+
+	s.lock.Lock()
+	s.progress = 1.0
+	s.lastRun = now.UTC()
+	expired := []int64{}
+	for w := range s.windows {
+		if w < start.Unix() {
+			expired = append(expired, w)
+		}
+	}
+	s.lock.Unlock()
+
+	for _, w := range expired {
+		t := time.Unix(w, 0).UTC()
+		if err := s.store.Remove(path.Join(s.dir(), s.fileName(t))); err != nil {
+			log.Warnf("AllocationStore: failed to remove expired %s: %s", kubecost.NewClosedWindow(t, t.Add(s.resolution)), err)
+			continue
+		}
+
+		s.lock.Lock()
+		delete(s.windows, w)
+		s.lock.Unlock()
+	}
+}
+
+// compute computes the AllocationSet of the window starting at start and
+// writes it to storage.
+func (s *AllocationStore) compute(start time.Time) error {
+	end := start.Add(s.resolution)
+
+	as, err := s.source.ComputeAllocation(start, end, s.queryResolution)
+	if err != nil {
+		return fmt.Errorf("computing allocation: %s", err)
+	}
+
+	data, err := as.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("encoding allocation: %s", err)
+	}
+
+	if err := s.store.Write(path.Join(s.dir(), s.fileName(start)), data); err != nil {
+		return fmt.Errorf("writing allocation: %s", err)
+	}
+
+	s.lock.Lock()
+	s.windows[start.Unix()] = true
+	s.lock.Unlock()
+
+	return nil
+}
+
+// Has returns true if the store contains the set starting at start
+func (s *AllocationStore) Has(start time.Time) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.windows[start.Unix()]
+}
+
+// Get reads the stored AllocationSet starting at start
+func (s *AllocationStore) Get(start time.Time) (*kubecost.AllocationSet, error) {
+	if !s.Has(start) {
+		return nil, fmt.Errorf("no allocation stored for %s", kubecost.NewClosedWindow(start, start.Add(s.resolution)))
+	}
+
+	data, err := s.store.Read(path.Join(s.dir(), s.fileName(start)))
+	if err != nil {
+		return nil, fmt.Errorf("reading allocation: %s", err)
+	}
+
+	as := &kubecost.AllocationSet{}
+	if err := as.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("decoding allocation: %s", err)
+	}
+
+	return as, nil
+}
+
+// Rebuild removes the stored windows which overlap the window defined by the
+// given start and end times, then triggers a build to recompute them. Queries
+// for the removed windows use the source until they are recomputed.
+func (s *AllocationStore) Rebuild(start, end time.Time) {
+	s.lock.Lock()
+	for w := range s.windows {
+		t := time.Unix(w, 0)
+		if t.Before(end) && t.Add(s.resolution).After(start) {
+			delete(s.windows, w)
+		}
+	}
+	s.lock.Unlock()
+
+	select {
+	case s.rebuild <- struct{}{}:
+	default:
+		// A rebuild has already been triggered
+	}
+}
+
+// Status returns the status of the store, including the files in storage
+func (s *AllocationStore) Status() kubecost.ETLStatus {
+	s.lock.RLock()
+	windows := make([]int64, 0, len(s.windows))
+	for w := range s.windows {
+		windows = append(windows, w)
+	}
+	status := kubecost.ETLStatus{
+		LastRun:                    s.lastRun,
+		Progress:                   s.progress,
+		RefreshRate:                timeutil.DurationString(s.refreshRate),
+		Resolution:                 timeutil.DurationString(s.resolution),
+		MaxPrometheusQueryDuration: timeutil.DurationString(env.GetETLMaxPrometheusQueryDuration()),
+		StartTime:                  s.startTime,
+		UTCOffset:                  env.GetUTCOffset(),
+	}
+	s.lock.RUnlock()
+
+	if len(windows) > 0 {
+		sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+		start := time.Unix(windows[0], 0).UTC()
+		end := time.Unix(windows[len(windows)-1], 0).UTC().Add(s.resolution)
+		status.Coverage = kubecost.NewClosedWindow(start, end)
+	}
+
+	files, err := s.store.List(s.dir())
+	if err != nil && !os.IsNotExist(err) {
+		log.Warnf("AllocationStore: failed to list %s: %s", s.store.FullPath(s.dir()), err)
+		return status
+	}
+
+	dirStatus := &kubecost.DirectoryStatus{
+		Path:      s.store.FullPath(s.dir()),
+		FileCount: len(files),
+		Files:     []kubecost.FileStatus{},
+	}
+	var size int64
+	for _, file := range files {
+		size += file.Size
+		if file.ModTime.After(dirStatus.LastModified) {
+			dirStatus.LastModified = file.ModTime
+		}
+		dirStatus.Files = append(dirStatus.Files, kubecost.FileStatus{
+			Name:         file.Name,
+			Size:         stringutil.FormatBytes(file.Size),
+			LastModified: file.ModTime,
+		})
+	}
+	dirStatus.Size = stringutil.FormatBytes(size)
+	status.Backup = dirStatus
+
+	return status
+}
+
+// computeAllocationFromStores computes an AllocationSet for the window defined
+// by the given start and end times, using the AllocationSets of the given
+// stores wherever they cover part of the window, and the source for the rest.
+// Stores are tried in order, so they should be ordered from the largest
+// resolution to the smallest.
+func computeAllocationFromStores(source AllocationSource, stores []*AllocationStore, start, end time.Time, resolution time.Duration) (*kubecost.AllocationSet, error) {
+	if len(stores) == 0 {
+		return source.ComputeAllocation(start, end, resolution)
+	}
+
+	// The source is queried for each gap between stored sets. Gaps can only
+	// end at the boundaries of the store with the smallest resolution.
+	finest := stores[0]
+	for _, store := range stores {
+		if store.resolution < finest.resolution {
+			finest = store
+		}
+	}
+
+	// sets collects the stored and computed sets covering the window, in order
+	sets := &kubecost.SetRange[*kubecost.AllocationSet]{}
+
+	var gapStart *time.Time
+	computeGap := func(gapEnd time.Time) error {
+		if gapStart == nil {
+			return nil
+		}
+		as, err := source.ComputeAllocation(*gapStart, gapEnd, resolution)
+		if err != nil {
+			return err
+		}
+		sets.Append(as)
+		gapStart = nil
+		return nil
+	}
+
+	cursor := start
+	for cursor.Before(end) {
+		var stored *kubecost.AllocationSet
+		var storedEnd time.Time
+		for _, store := range stores {
+			e := cursor.Add(store.resolution)
+			if !store.isAligned(cursor) || e.After(end) || !store.Has(cursor) {
+				continue
+			}
+
+			as, err := store.Get(cursor)
+			if err != nil {
+				log.Warnf("AllocationStore: %s", err)
+				continue
+			}
+			stored, storedEnd = as, e
+			break
+		}
+
+		if stored != nil {
+			if err := computeGap(cursor); err != nil {
+				return kubecost.NewAllocationSet(start, end), err
+			}
+			sets.Append(stored)
+			cursor = storedEnd
+			continue
+		}
+
+		if gapStart == nil {
+			gs := cursor
+			gapStart = &gs
+		}
+		cursor = finest.alignStart(cursor).Add(finest.resolution)
+		if cursor.After(end) {
+			cursor = end
+		}
+	}
+	if err := computeGap(end); err != nil {
+		return kubecost.NewAllocationSet(start, end), err
+	}
+
+	if sets.Length() == 1 {
+		return sets.Get(0)
+	}
+
+	asr := kubecost.NewAllocationSetRange()
+	sets.Each(func(_ int, as *kubecost.AllocationSet) {
+		asr.Append(as)
+	})
+	return accumulateAllocationSetRange(asr, start, end)
+}
+
+// newAllocationStores creates the daily and hourly AllocationStores, persisted
+// to the configured ETL bucket, or to the local ETL path if no bucket is
+// configured.
+func newAllocationStores(source AllocationSource) []*AllocationStore {
+	store := newBucketOrFileStorage(env.GetETLBucketConfig(), env.GetETLPath())
+
+	return []*AllocationStore{
+		NewAllocationStore(source, store, timeutil.Day, env.GetETLDailyStoreDuration()),
+		NewAllocationStore(source, store, time.Hour, env.GetETLHourlyStoreDuration()),
+	}
+}
+
+// newBucketOrFileStorage creates a bucket storage from the given bucket
+// configuration file, or a file storage in the given local directory if the
+// bucket configuration file is empty or the bucket storage cannot be created.
+func newBucketOrFileStorage(bucketConfigFile, localPath string) storage.Storage {
+	if bucketConfigFile != "" {
+		bucketConfig, err := os.ReadFile(bucketConfigFile)
+		if err != nil {
+			log.Warnf("Failed to initialize bucket storage: %s", err)
+		} else {
+			store, err := storage.NewBucketStorage(bucketConfig)
+			if err != nil {
+				log.Warnf("Failed to create bucket storage: %s", err)
+			} else {
+				return store
+			}
+		}
+	}
+
+	return storage.NewFileStorage(localPath)
+}
+
+// computeAllocation computes an AllocationSet for the window defined by the
+// given start and end times, using the AllocationStores if the ETL store is
+// enabled, and the CostModel otherwise.
+func (a *Accesses) computeAllocation(start, end time.Time, resolution time.Duration) (*kubecost.AllocationSet, error) {
+	return computeAllocationFromStores(a.Model, a.AllocationStores, start, end, resolution)
+}
+
+// AllocationStoreStatusHandler returns the status of each AllocationStore,
+// keyed by its resolution.
+func (a *Accesses) AllocationStoreStatusHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if len(a.AllocationStores) == 0 {
+		http.Error(w, "ETL store is not enabled", http.StatusNotFound)
+		return
+	}
+
+	status := map[string]kubecost.ETLStatus{}
+	for _, store := range a.AllocationStores {
+		status[timeutil.DurationString(store.Resolution())] = store.Status()
+	}
+
+	w.Write(WrapData(status, nil))
+}
+
+// AllocationStoreRebuildHandler recomputes the stored AllocationSets which
+// overlap the given window in the background. If a resolution is given, only
+// the store of that resolution is rebuilt.
+func (a *Accesses) AllocationStoreRebuildHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if len(a.AllocationStores) == 0 {
+		http.Error(w, "ETL store is not enabled", http.StatusNotFound)
+		return
+	}
+
+	qp := httputil.NewQueryParams(r.URL.Query())
+
+	window, err := kubecost.ParseWindowWithOffset(qp.Get("window", ""), env.GetParsedUTCOffset())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid 'window' parameter: %s", err), http.StatusBadRequest)
+		return
+	}
+	if window.IsOpen() {
+		http.Error(w, fmt.Sprintf("Invalid 'window' parameter: %s is not closed", window), http.StatusBadRequest)
+		return
+	}
+
+	// Resolution is an optional parameter, defaulting to 0, which rebuilds
+	// every store.
+	resolution := qp.GetDuration("resolution", 0)
+
+	rebuilding := []string{}
+	for _, store := range a.AllocationStores {
+		if resolution != 0 && store.Resolution() != resolution {
+			continue
+		}
+		store.Rebuild(*window.Start(), *window.End())
+		rebuilding = append(rebuilding, timeutil.DurationString(store.Resolution()))
+	}
+	if len(rebuilding) == 0 {
+		http.Error(w, fmt.Sprintf("Invalid 'resolution' parameter: no store with resolution %s", timeutil.DurationString(resolution)), http.StatusBadRequest)
+		return
+	}
+
+	w.Write(WrapDataWithMessage(rebuilding, nil, fmt.Sprintf("Rebuilding %s", window)))
+}
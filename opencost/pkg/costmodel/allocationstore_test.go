@@ -0,0 +1,153 @@
+package costmodel
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/storage"
+	"github.com/opencost/opencost/pkg/util/timeutil"
+)
+
+// mockAllocationSource returns an AllocationSet containing one unit Allocation
+// for any window, and records the windows it was queried for.
+type mockAllocationSource struct {
+	queries []kubecost.Window
+}
+
+func (mas *mockAllocationSource) ComputeAllocation(start, end time.Time, resolution time.Duration) (*kubecost.AllocationSet, error) {
+	mas.queries = append(mas.queries, kubecost.NewClosedWindow(start, end))
+	alloc := kubecost.NewMockUnitAllocation("", start, end.Sub(start), nil)
+	return kubecost.NewAllocationSet(start, end, alloc), nil
+}
+
+func TestAllocationStore(t *testing.T) {
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(2*timeutil.Day + 3*time.Hour + 30*time.Minute)
+
+	source := &mockAllocationSource{}
+	store := storage.NewFileStorage(t.TempDir())
+	daily := NewAllocationStore(source, store, timeutil.Day, 2*timeutil.Day)
+	hourly := NewAllocationStore(source, store, time.Hour, 3*time.Hour)
+
+	daily.Build(now)
+	hourly.Build(now)
+
+	// Only complete windows within the duration of each store are computed
+	if len(source.queries) != 5 {
+		t.Fatalf("expected 5 queries; got %d: %v", len(source.queries), source.queries)
+	}
+	for _, s := range []time.Time{start, start.Add(timeutil.Day)} {
+		if !daily.Has(s) {
+			t.Errorf("expected daily store to have %s", s)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		s := start.Add(2*timeutil.Day + time.Duration(i)*time.Hour)
+		if !hourly.Has(s) {
+			t.Errorf("expected hourly store to have %s", s)
+		}
+	}
+	if hourly.Has(start.Add(2*timeutil.Day + 3*time.Hour)) {
+		t.Errorf("expected hourly store not to have incomplete window")
+	}
+
+	// Stored sets are read back from storage
+	as, err := daily.Get(start)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !as.Window.Equal(kubecost.NewClosedWindow(start, start.Add(timeutil.Day))) {
+		t.Errorf("expected window %s; got %s", kubecost.NewClosedWindow(start, start.Add(timeutil.Day)), as.Window)
+	}
+
+	// A new store loads the windows already in storage
+	reloaded := NewAllocationStore(source, store, timeutil.Day, 2*timeutil.Day)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reloaded.Has(start) || !reloaded.Has(start.Add(timeutil.Day)) {
+		t.Errorf("expected reloaded store to have both days")
+	}
+
+	// Queries use stored sets and only query the source for the remainder
+	source.queries = nil
+	as, err = computeAllocationFromStores(source, []*AllocationStore{daily, hourly}, start, now, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expectedQuery := kubecost.NewClosedWindow(start.Add(2*timeutil.Day+3*time.Hour), now)
+	if len(source.queries) != 1 || !source.queries[0].Equal(expectedQuery) {
+		t.Errorf("expected one query for %s; got %v", expectedQuery, source.queries)
+	}
+	if !as.Window.Equal(kubecost.NewClosedWindow(start, now)) {
+		t.Errorf("expected window %s; got %s", kubecost.NewClosedWindow(start, now), as.Window)
+	}
+	unitCost := kubecost.NewMockUnitAllocation("", start, time.Hour, nil).TotalCost()
+	if math.Abs(as.TotalCost()-6*unitCost) > 0.0001 {
+		t.Errorf("expected total cost %f; got %f", 6*unitCost, as.TotalCost())
+	}
+
+	// Rebuilding recomputes the windows overlapping the given window
+	source.queries = nil
+	daily.Rebuild(start.Add(time.Hour), start.Add(2*time.Hour))
+	if daily.Has(start) {
+		t.Errorf("expected rebuilt window to be removed")
+	}
+	daily.Build(now)
+	if len(source.queries) != 1 || !daily.Has(start) {
+		t.Errorf("expected rebuilt window to be recomputed; got %v", source.queries)
+	}
+
+	// Windows older than the duration of the store are removed
+	source.queries = nil
+	daily.Build(now.Add(timeutil.Day))
+	if daily.Has(start) {
+		t.Errorf("expected expired window to be removed")
+	}
+	if len(source.queries) != 1 || !daily.Has(start.Add(2*timeutil.Day)) {
+		t.Errorf("expected new window to be computed; got %v", source.queries)
+	}
+
+	status := daily.Status()
+	expectedCoverage := kubecost.NewClosedWindow(start.Add(timeutil.Day), start.Add(3*timeutil.Day))
+	if !status.Coverage.Equal(expectedCoverage) {
+		t.Errorf("expected coverage %s; got %s", expectedCoverage, status.Coverage)
+	}
+	if status.Backup == nil || status.Backup.FileCount != 2 {
+		t.Errorf("expected 2 files; got %+v", status.Backup)
+	}
+	if status.Resolution != "1d" || status.Progress != 1.0 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestComputeAllocationFromStoresMisaligned(t *testing.T) {
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	source := &mockAllocationSource{}
+	daily := NewAllocationStore(source, storage.NewFileStorage(t.TempDir()), timeutil.Day, 3*timeutil.Day)
+	daily.Build(start.Add(3 * timeutil.Day))
+	source.queries = nil
+
+	// The stored day is only used if it is entirely within the query window
+	queryStart, queryEnd := start.Add(12*time.Hour), start.Add(2*timeutil.Day+12*time.Hour)
+	_, err := computeAllocationFromStores(source, []*AllocationStore{daily}, queryStart, queryEnd, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []kubecost.Window{
+		kubecost.NewClosedWindow(queryStart, start.Add(timeutil.Day)),
+		kubecost.NewClosedWindow(start.Add(2*timeutil.Day), queryEnd),
+	}
+	if len(source.queries) != len(expected) {
+		t.Fatalf("expected queries %v; got %v", expected, source.queries)
+	}
+	for i := range expected {
+		if !source.queries[i].Equal(expected[i]) {
+			t.Errorf("expected query %s; got %s", expected[i], source.queries[i])
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package costmodel
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opencost/opencost/pkg/env"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/assetfilterutil"
+	"github.com/opencost/opencost/pkg/util/httputil"
+)
+
+// networkAssetName is the name of the Network Asset of each cluster
+const networkAssetName = "network-egress"
+
+// ComputeAssets uses the CostModel instance to compute an AssetSet for the
+// window defined by the given start and end times. The AssetSet contains one
+// Node, Disk and LoadBalancer per cluster resource, computed with the same
+// Prometheus queries as the cluster cost endpoints, one Network per cluster
+// for its network egress, computed with the same queries as the network costs
+// of allocations, plus a ClusterManagement Asset for any configured cluster
+// management fee.
+func (cm *CostModel) ComputeAssets(start, end time.Time) (*kubecost.AssetSet, error) {
+	window := kubecost.NewClosedWindow(start, end)
+
+	nodeMap, err := ClusterNodes(cm.Provider, cm.PrometheusClient, start, end)
+	if err != nil {
+		return kubecost.NewAssetSet(start, end), fmt.Errorf("error computing nodes for %s: %s", window, err)
+	}
+
+	diskMap, err := ClusterDisks(cm.PrometheusClient, cm.Provider, start, end)
+	if err != nil {
+		return kubecost.NewAssetSet(start, end), fmt.Errorf("error computing disks for %s: %s", window, err)
+	}
+
+	lbMap, err := ClusterLoadBalancers(cm.PrometheusClient, start, end)
+	if err != nil {
+		return kubecost.NewAssetSet(start, end), fmt.Errorf("error computing load balancers for %s: %s", window, err)
+	}
+
+	networkMap, err := ClusterNetworks(cm.PrometheusClient, start, end)
+	if err != nil {
+		return kubecost.NewAssetSet(start, end), fmt.Errorf("error computing networks for %s: %s", window, err)
+	}
+
+	provider := ""
+	if info, err := cm.Provider.ClusterInfo(); err != nil {
+		log.Warnf("ComputeAssets: failed to get cluster info: %s", err)
+	} else {
+		provider = info["provider"]
+	}
+
+	// Cluster management is priced hourly by the provider, so it is charged
+	// for the whole window, up to the current time.
+	clusterManagementCost := 0.0
+	if _, hourly, err := cm.Provider.ClusterManagementPricing(); err != nil {
+		log.Warnf("ComputeAssets: failed to get cluster management pricing: %s", err)
+	} else {
+		_, e := clampToWindow(start, time.Now(), start, end)
+		clusterManagementCost = hourly * e.Sub(start).Hours()
+	}
+
+	return buildAssetSet(start, end, provider, nodeMap, diskMap, lbMap, networkMap, clusterManagementCost)
+}
+
+// buildAssetSet converts the results of the cluster queries into an AssetSet
+func buildAssetSet(
+	start, end time.Time,
+	provider string,
+	nodeMap map[NodeIdentifier]*Node,
+	diskMap map[DiskIdentifier]*Disk,
+	lbMap map[LoadBalancerIdentifier]*LoadBalancer,
+	networkMap map[string]*Network,
+	clusterManagementCost float64,
+) (*kubecost.AssetSet, error) {
+	window := kubecost.NewClosedWindow(start, end)
+	as := kubecost.NewAssetSet(start, end)
+
+	for _, n := range nodeMap {
+		s, e := clampToWindow(n.Start, n.End, start, end)
+		hours := e.Sub(s).Hours()
+
+		node := kubecost.NewNode(n.Name, n.Cluster, n.ProviderID, s, e, window)
+		node.Properties.Provider = kubecost.ParseProvider(provider)
+		node.NodeType = n.NodeType
+		node.CPUCoreHours = n.CPUCores * hours
+		node.RAMByteHours = n.RAMBytes * hours
+		node.GPUHours = n.GPUCount * hours
+		node.GPUCount = n.GPUCount
+		node.CPUCost = n.CPUCost
+		node.GPUCost = n.GPUCost
+		node.RAMCost = n.RAMCost
+		node.Discount = n.Discount
+		if n.Preemptible {
+			node.Preemptible = 1.0
+		}
+		if n.CPUBreakdown != nil {
+			node.CPUBreakdown = toBreakdown(n.CPUBreakdown)
+		}
+		if n.RAMBreakdown != nil {
+			node.RAMBreakdown = toBreakdown(n.RAMBreakdown)
+		}
+		node.SetLabels(nodeAssetLabels(n.Labels))
+
+		if err := as.Insert(node, nil); err != nil {
+			return as, fmt.Errorf("error inserting node %s: %s", n.Name, err)
+		}
+	}
+
+	for _, d := range diskMap {
+		s, e := clampToWindow(d.Start, d.End, start, end)
+		hours := e.Sub(s).Hours()
+
+		disk := kubecost.NewDisk(d.Name, d.Cluster, d.ProviderID, s, e, window)
+		disk.Properties.Provider = kubecost.ParseProvider(provider)
+		disk.Cost = d.Cost
+		disk.ByteHours = d.Bytes * hours
+		if d.BytesUsedAvgPtr != nil {
+			byteHoursUsed := *d.BytesUsedAvgPtr * hours
+			disk.ByteHoursUsed = &byteHoursUsed
+		}
+		if d.BytesUsedMaxPtr != nil {
+			byteUsageMax := *d.BytesUsedMaxPtr
+			disk.ByteUsageMax = &byteUsageMax
+		}
+		if d.Local {
+			disk.Local = 1.0
+		}
+		if d.Breakdown != nil {
+			disk.Breakdown = toBreakdown(d.Breakdown)
+		}
+		disk.StorageClass = d.StorageClass
+		disk.VolumeName = d.VolumeName
+		disk.ClaimName = d.ClaimName
+		disk.ClaimNamespace = d.ClaimNamespace
+
+		if err := as.Insert(disk, nil); err != nil {
+			return as, fmt.Errorf("error inserting disk %s: %s", d.Name, err)
+		}
+	}
+
+	for _, l := range lbMap {
+		s, e := clampToWindow(l.Start, l.End, start, end)
+
+		// The name of a load balancer, its namespace and service name, is
+		// unique within its cluster.
+		lb := kubecost.NewLoadBalancer(l.Name, l.Cluster, l.ProviderID, s, e, window)
+		lb.Properties.Provider = kubecost.ParseProvider(provider)
+		lb.Cost = l.Cost
+
+		if err := as.Insert(lb, nil); err != nil {
+			return as, fmt.Errorf("error inserting load balancer %s: %s", l.Name, err)
+		}
+	}
+
+	for _, n := range networkMap {
+		// Network egress is only known for the whole window
+		network := kubecost.NewNetwork(networkAssetName, n.Cluster, "", start, end, window)
+		network.Properties.Provider = kubecost.ParseProvider(provider)
+		network.Cost = n.Cost
+
+		if err := as.Insert(network, nil); err != nil {
+			return as, fmt.Errorf("error inserting network of cluster %s: %s", n.Cluster, err)
+		}
+	}
+
+	if clusterManagementCost > 0 {
+		cmAsset := kubecost.NewClusterManagement(provider, env.GetClusterID(), window)
+		cmAsset.Cost = clusterManagementCost
+
+		if err := as.Insert(cmAsset, nil); err != nil {
+			return as, fmt.Errorf("error inserting cluster management: %s", err)
+		}
+	}
+
+	return as, nil
+}
+
+// clampToWindow returns the given start and end, restricted to the window
+// defined by windowStart and windowEnd. Zero times, which indicate that no
+// activity data was found, are replaced by the window bounds.
+func clampToWindow(s, e, windowStart, windowEnd time.Time) (time.Time, time.Time) {
+	if s.IsZero() || s.Before(windowStart) {
+		s = windowStart
+	}
+	if e.IsZero() || e.After(windowEnd) {
+		e = windowEnd
+	}
+	if e.Before(s) {
+		e = s
+	}
+	return s, e
+}
+
+func toBreakdown(ccb *ClusterCostsBreakdown) *kubecost.Breakdown {
+	return &kubecost.Breakdown{
+		Idle:   ccb.Idle,
+		Other:  ccb.Other,
+		System: ccb.System,
+		User:   ccb.User,
+	}
+}
+
+// nodeAssetLabels converts the Prometheus labels of kube_node_labels into
+// AssetLabels, keeping only the Kubernetes node labels.
+func nodeAssetLabels(promLabels map[string]string) kubecost.AssetLabels {
+	labels := kubecost.AssetLabels{}
+	for name, value := range promLabels {
+		if strings.HasPrefix(name, "label_") {
+			labels[strings.TrimPrefix(name, "label_")] = value
+		}
+	}
+	return labels
+}
+
+// ParseAssetAggregationProperties attempts to parse and return asset
+// aggregation properties encoded under the given key. Unrecognized properties
+// return an error, rather than aggregating by the remaining properties.
+func ParseAssetAggregationProperties(qp httputil.QueryParams, key string) ([]string, error) {
+	aggregateBy := []string{}
+	for _, agg := range qp.GetList(key, ",") {
+		aggregate := strings.TrimSpace(agg)
+		if aggregate == "" {
+			continue
+		}
+		if prop, err := kubecost.ParseAssetProperty(aggregate); err == nil {
+			aggregateBy = append(aggregateBy, string(prop))
+		} else if strings.HasPrefix(aggregate, "label:") && strings.TrimPrefix(aggregate, "label:") != "" {
+			aggregateBy = append(aggregateBy, aggregate)
+		} else {
+			return nil, fmt.Errorf("unrecognized asset property: %s", aggregate)
+		}
+	}
+	return aggregateBy, nil
+}
+
+// ComputeAssetsHandler computes an AssetSetRange from the CostModel.
+func (a *Accesses) ComputeAssetsHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	qp := httputil.NewQueryParams(r.URL.Query())
+
+	// Window is a required field describing the window of time over which to
+	// compute asset data.
+	window, err := kubecost.ParseWindowWithOffset(qp.Get("window", ""), env.GetParsedUTCOffset())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid 'window' parameter: %s", err), http.StatusBadRequest)
+		return
+	}
+	if window.IsOpen() {
+		http.Error(w, fmt.Sprintf("Invalid 'window' parameter: %s is not closed", window), http.StatusBadRequest)
+		return
+	}
+
+	// Step is an optional parameter that defines the duration per-set, i.e.
+	// the window for an AssetSet, of the AssetSetRange to be computed.
+	// Defaults to the window size, making one set.
+	step := qp.GetDuration("step", window.Duration())
+	if step <= 0 {
+		http.Error(w, "Invalid 'step' parameter: must be positive", http.StatusBadRequest)
+		return
+	}
+
+	// Aggregation is an optional comma-separated list of fields by which to
+	// aggregate results. Labels are given with a colon; e.g. "label:app".
+	// Examples: "type", "cluster,label:app"
+	aggregateBy, err := ParseAssetAggregationProperties(qp, "aggregate")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid 'aggregate' parameter: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// Filters are optional parameters, e.g. "filterTypes=node,disk" or
+	// "filterLabels=app:cost-analyzer", which restrict the returned Assets.
+	filterFuncs := assetfilterutil.AssetFilterFuncsFromParamsV1(qp)
+
+	// Accumulate is an optional parameter, defaulting to false, which if true
+	// sums each Set in the Range, producing one Set.
+	accumulate := qp.GetBool("accumulate", false)
+
+	// Reconcile is an optional parameter, defaulting to true, which if true
+	// adjusts the costs of nodes and disks to their billed costs, if cloud
+	// billing ingestion is enabled and the Set's window is billed.
+	reconcile := qp.GetBool("reconcile", true)
+
+	// Query for AssetSets in increments of the given step duration,
+	// appending each to the AssetSetRange.
+	asr := kubecost.NewAssetSetRange()
+	stepStart := *window.Start()
+	for window.End().After(stepStart) {
+		stepEnd := stepStart.Add(step)
+		if stepEnd.After(*window.End()) {
+			stepEnd = *window.End()
+		}
+
+		as, err := a.Model.ComputeAssets(stepStart, stepEnd)
+		if err != nil {
+			WriteError(w, InternalServerError(err.Error()))
+			return
+		}
+		if reconcile {
+			if err := a.reconcileAssets(as); err != nil {
+				WriteError(w, InternalServerError(err.Error()))
+				return
+			}
+		}
+		asr.Append(as)
+
+		stepStart = stepEnd
+	}
+
+	// Aggregate and filter, if requested. Aggregating by nil properties keys
+	// each Asset by all of its properties, which leaves the Assets as they
+	// are, so filtering without aggregating is possible.
+	if len(aggregateBy) > 0 || len(filterFuncs) > 0 {
+		if len(aggregateBy) == 0 {
+			aggregateBy = nil
+		}
+		err = asr.AggregateBy(aggregateBy, &kubecost.AssetAggregationOptions{
+			FilterFuncs: filterFuncs,
+		})
+		if err != nil {
+			WriteError(w, InternalServerError(err.Error()))
+			return
+		}
+	}
+
+	// Accumulate, if requested
+	if accumulate {
+		as, err := asr.Accumulate()
+		if err != nil {
+			WriteError(w, InternalServerError(err.Error()))
+			return
+		}
+		asr = kubecost.NewAssetSetRange(as)
+	}
+
+	w.Write(WrapData(asr, nil))
+}
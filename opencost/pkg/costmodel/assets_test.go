@@ -0,0 +1,248 @@
+package costmodel
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/prom"
+	"github.com/opencost/opencost/pkg/util"
+	"github.com/opencost/opencost/pkg/util/httputil"
+)
+
+func TestBuildAssetSet(t *testing.T) {
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	nodeMap := map[NodeIdentifier]*Node{
+		{Cluster: "cluster-one", Name: "node-one", ProviderID: "id-one"}: {
+			Cluster:      "cluster-one",
+			Name:         "node-one",
+			ProviderID:   "id-one",
+			NodeType:     "n1-standard-2",
+			CPUCores:     2,
+			RAMBytes:     4096,
+			CPUCost:      2.0,
+			RAMCost:      1.0,
+			Discount:     0.5,
+			Preemptible:  true,
+			CPUBreakdown: &ClusterCostsBreakdown{Idle: 0.5, User: 0.5},
+			RAMBreakdown: &ClusterCostsBreakdown{Idle: 0.25, System: 0.75},
+			// Active for the last 12 hours of the window, reported beyond
+			// the end of the window
+			Start:  start.Add(12 * time.Hour),
+			End:    end.Add(time.Hour),
+			Labels: map[string]string{"label_app": "web", "node": "node-one"},
+		},
+	}
+
+	usedAvg := 1024.0
+	diskMap := map[DiskIdentifier]*Disk{
+		{Cluster: "cluster-one", Name: "pv-one"}: {
+			Cluster:         "cluster-one",
+			Name:            "pv-one",
+			StorageClass:    "standard",
+			ClaimName:       "data",
+			ClaimNamespace:  "default",
+			Cost:            0.5,
+			Bytes:           2048,
+			BytesUsedAvgPtr: &usedAvg,
+			Local:           true,
+		},
+	}
+
+	lbMap := map[LoadBalancerIdentifier]*LoadBalancer{
+		{Cluster: "cluster-one", Namespace: "default", Name: "ingress"}: {
+			Cluster:   "cluster-one",
+			Namespace: "default",
+			// ClusterLoadBalancers names load balancers by their namespace
+			// and service name
+			Name:  "default/ingress",
+			Cost:  0.25,
+			Start: start,
+			End:   end,
+		},
+	}
+
+	networkMap := map[string]*Network{
+		"cluster-one": {Cluster: "cluster-one", Cost: 0.75},
+	}
+
+	as, err := buildAssetSet(start, end, "GCP", nodeMap, diskMap, lbMap, networkMap, 2.4)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if as.Length() != 5 {
+		t.Fatalf("expected 5 assets; got %d", as.Length())
+	}
+
+	if len(as.Nodes) != 1 {
+		t.Fatalf("expected 1 node; got %d", len(as.Nodes))
+	}
+	for _, node := range as.Nodes {
+		if node.Properties.Provider != kubecost.GCPProvider {
+			t.Errorf("expected provider %s; got %s", kubecost.GCPProvider, node.Properties.Provider)
+		}
+		if !node.Start.Equal(start.Add(12*time.Hour)) || !node.End.Equal(end) {
+			t.Errorf("expected node to run from %s to %s; got %s to %s", start.Add(12*time.Hour), end, node.Start, node.End)
+		}
+		if node.CPUCoreHours != 24 {
+			t.Errorf("expected 24 CPU core hours; got %f", node.CPUCoreHours)
+		}
+		if node.RAMByteHours != 4096*12 {
+			t.Errorf("expected %d RAM byte hours; got %f", 4096*12, node.RAMByteHours)
+		}
+		if node.Preemptible != 1.0 {
+			t.Errorf("expected node to be preemptible")
+		}
+		if node.TotalCost() != 1.5 {
+			t.Errorf("expected total cost 1.5; got %f", node.TotalCost())
+		}
+		if node.RAMBreakdown.System != 0.75 {
+			t.Errorf("expected RAM system breakdown 0.75; got %f", node.RAMBreakdown.System)
+		}
+		if len(node.Labels) != 1 || node.Labels["app"] != "web" {
+			t.Errorf("expected labels {app: web}; got %v", node.Labels)
+		}
+	}
+
+	if len(as.Disks) != 1 {
+		t.Fatalf("expected 1 disk; got %d", len(as.Disks))
+	}
+	for _, disk := range as.Disks {
+		// Disks without activity data are assumed to run for the whole window
+		if disk.ByteHours != 2048*24 {
+			t.Errorf("expected %d byte hours; got %f", 2048*24, disk.ByteHours)
+		}
+		if disk.ByteHoursUsed == nil || *disk.ByteHoursUsed != 1024*24 {
+			t.Errorf("expected %d byte hours used; got %v", 1024*24, disk.ByteHoursUsed)
+		}
+		if disk.ByteUsageMax != nil {
+			t.Errorf("expected no max byte usage; got %f", *disk.ByteUsageMax)
+		}
+		if disk.Local != 1.0 {
+			t.Errorf("expected disk to be local")
+		}
+		if disk.StorageClass != "standard" || disk.ClaimName != "data" || disk.ClaimNamespace != "default" {
+			t.Errorf("unexpected disk claim properties: %s %s/%s", disk.StorageClass, disk.ClaimNamespace, disk.ClaimName)
+		}
+	}
+
+	if len(as.LoadBalancers) != 1 {
+		t.Fatalf("expected 1 load balancer; got %d", len(as.LoadBalancers))
+	}
+	for _, lb := range as.LoadBalancers {
+		if lb.Properties.Name != "default/ingress" {
+			t.Errorf("expected load balancer name default/ingress; got %s", lb.Properties.Name)
+		}
+		if lb.Cost != 0.25 {
+			t.Errorf("expected load balancer cost 0.25; got %f", lb.Cost)
+		}
+	}
+
+	if len(as.Network) != 1 {
+		t.Fatalf("expected 1 network; got %d", len(as.Network))
+	}
+	for _, network := range as.Network {
+		if network.Properties.Cluster != "cluster-one" || network.Cost != 0.75 {
+			t.Errorf("expected network of cluster-one with cost 0.75; got %s, %f", network.Properties.Cluster, network.Cost)
+		}
+		if !network.Start.Equal(start) || !network.End.Equal(end) {
+			t.Errorf("expected network to run from %s to %s; got %s to %s", start, end, network.Start, network.End)
+		}
+	}
+
+	if len(as.ClusterManagement) != 1 {
+		t.Fatalf("expected 1 cluster management asset; got %d", len(as.ClusterManagement))
+	}
+	for _, cm := range as.ClusterManagement {
+		if cm.Cost != 2.4 {
+			t.Errorf("expected cluster management cost 2.4; got %f", cm.Cost)
+		}
+	}
+
+	// A cluster without a management fee has no ClusterManagement Asset
+	as, err = buildAssetSet(start, end, "GCP", nodeMap, diskMap, lbMap, networkMap, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(as.ClusterManagement) != 0 {
+		t.Errorf("expected no cluster management assets; got %d", len(as.ClusterManagement))
+	}
+}
+
+func TestApplyClusterNetworkCost(t *testing.T) {
+	gib := func(cluster, pod string, value float64) *prom.QueryResult {
+		return &prom.QueryResult{
+			Metric: map[string]interface{}{"cluster_id": cluster, "pod_name": pod, "namespace": "default"},
+			Values: []*util.Vector{{Value: value}},
+		}
+	}
+	costPerGiB := func(cluster string, value float64) *prom.QueryResult {
+		return &prom.QueryResult{
+			Metric: map[string]interface{}{"cluster_id": cluster},
+			Values: []*util.Vector{{Value: value}},
+		}
+	}
+
+	networkMap := map[string]*Network{}
+	// zone egress
+	applyClusterNetworkCost(networkMap,
+		[]*prom.QueryResult{gib("cluster1", "pod1", 10), gib("cluster1", "pod2", 5), gib("cluster2", "pod3", 2)},
+		[]*prom.QueryResult{costPerGiB("cluster1", 0.01), costPerGiB("cluster2", 0.02)})
+	// internet egress
+	applyClusterNetworkCost(networkMap,
+		[]*prom.QueryResult{gib("cluster1", "pod1", 1)},
+		[]*prom.QueryResult{costPerGiB("cluster1", 0.12)})
+
+	expected := map[string]float64{"cluster1": 0.27, "cluster2": 0.04}
+	if len(networkMap) != len(expected) {
+		t.Fatalf("expected %d networks; got %d", len(expected), len(networkMap))
+	}
+	for cluster, cost := range expected {
+		network, ok := networkMap[cluster]
+		if !ok {
+			t.Errorf("missing network of %s", cluster)
+			continue
+		}
+		if diff := network.Cost - cost; diff > 0.0001 || diff < -0.0001 {
+			t.Errorf("expected network cost of %s %f; got %f", cluster, cost, network.Cost)
+		}
+	}
+}
+
+func TestParseAssetAggregationProperties(t *testing.T) {
+	cases := map[string]struct {
+		aggregate string
+		expected  []string
+		err       bool
+	}{
+		"none":        {aggregate: "", expected: []string{}},
+		"properties":  {aggregate: "type, cluster", expected: []string{"type", "cluster"}},
+		"label":       {aggregate: "label:app", expected: []string{"label:app"}},
+		"unknown":     {aggregate: "type,clustr", err: true},
+		"empty label": {aggregate: "label:", err: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			qp := httputil.NewQueryParams(url.Values{"aggregate": []string{c.aggregate}})
+			aggregateBy, err := ParseAssetAggregationProperties(qp, "aggregate")
+			if c.err {
+				if err == nil {
+					t.Errorf("expected error; got %v", aggregateBy)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(aggregateBy, c.expected) {
+				t.Errorf("expected %v; got %v", c.expected, aggregateBy)
+			}
+		})
+	}
+}
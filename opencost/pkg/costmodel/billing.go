@@ -0,0 +1,149 @@
+package costmodel
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opencost/opencost/pkg/cloud/billing"
+	"github.com/opencost/opencost/pkg/env"
+	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/httputil"
+)
+
+// newBillingIngestor creates an Ingestor of the configured billing exports,
+// which are those of the cluster's provider unless a provider is configured.
+func (a *Accesses) newBillingIngestor() (*billing.Ingestor, error) {
+	provider := env.GetCloudBillingProvider()
+	if provider == "" {
+		info, err := a.CloudProvider.ClusterInfo()
+		if err != nil {
+			return nil, fmt.Errorf("getting cluster provider: %s", err)
+		}
+		provider = info["provider"]
+	}
+
+	store := newBucketOrFileStorage(env.GetCloudBillingBucketConfig(), env.GetCloudBillingPath())
+	return billing.NewIngestor(store, provider, env.GetCloudBillingPrefix())
+}
+
+// startBillingIngestor refreshes the given Ingestor in the background at the
+// configured refresh rate.
+func startBillingIngestor(ingestor *billing.Ingestor) {
+	go func() {
+		defer errors.HandlePanic()
+
+		for {
+			if err := ingestor.Refresh(); err != nil {
+				log.Errorf("Billing: failed to refresh %s billing exports: %s", ingestor.Provider(), err)
+			}
+
+			time.Sleep(env.GetCloudBillingRefreshRate())
+		}
+	}()
+}
+
+// isBilled returns true if the BillingIngestor has ingested billing exports
+// covering the whole window, so that the window can be reconciled. Windows
+// which are partially billed, e.g. the current day or a window spanning a gap
+// in the billing exports, are not reconciled, as their assets would appear to
+// be billed at less than their cost.
+func (a *Accesses) isBilled(window kubecost.Window) bool {
+	if a.BillingIngestor == nil || window.IsOpen() {
+		return false
+	}
+
+	for _, coverage := range a.BillingIngestor.Coverage() {
+		if coverage.ContainsWindow(window) {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileAssets adjusts the Nodes and Disks of the given AssetSet to their
+// billed costs, if its window has been billed.
+func (a *Accesses) reconcileAssets(as *kubecost.AssetSet) error {
+	if !a.isBilled(as.Window) {
+		return nil
+	}
+
+	ccis := a.BillingIngestor.CloudCostItemSet(*as.Window.Start(), *as.Window.End())
+	reconciled, err := billing.ReconcileAssetSet(as, ccis)
+	if err != nil {
+		return err
+	}
+	log.Debugf("Billing: reconciled %d assets in %s", reconciled, as.Window)
+
+	return nil
+}
+
+// reconcileAllocation adjusts the costs of the given AllocationSet by the
+// reconciliation of the Nodes and Disks of its window with their billed costs,
+// if its window has been billed.
+func (a *Accesses) reconcileAllocation(allocs *kubecost.AllocationSet) error {
+	if !a.isBilled(allocs.Window) {
+		return nil
+	}
+
+	as, err := a.Model.ComputeAssets(*allocs.Window.Start(), *allocs.Window.End())
+	if err != nil {
+		return fmt.Errorf("computing assets: %s", err)
+	}
+	if err := a.reconcileAssets(as); err != nil {
+		return err
+	}
+
+	return a.reconcileAllocationWithAssets(allocs, as)
+}
+
+// reconcileAllocationWithAssets adjusts the costs of the given AllocationSet
+// by the reconciliation of the Nodes and Disks of the given AssetSet, which
+// has been reconciled by reconcileAssets, if its window has been billed.
+func (a *Accesses) reconcileAllocationWithAssets(allocs *kubecost.AllocationSet, as *kubecost.AssetSet) error {
+	if !a.isBilled(allocs.Window) {
+		return nil
+	}
+
+	return billing.ReconcileAllocationSet(allocs, as)
+}
+
+// ComputeCloudCostItemsHandler returns the ingested billed costs as a
+// CloudCostItemSetRange.
+func (a *Accesses) ComputeCloudCostItemsHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if a.BillingIngestor == nil {
+		http.Error(w, "Cloud billing ingestion is not enabled", http.StatusNotFound)
+		return
+	}
+
+	qp := httputil.NewQueryParams(r.URL.Query())
+
+	// Window is a required field describing the window of time over which to
+	// return billed costs.
+	window, err := kubecost.ParseWindowWithOffset(qp.Get("window", ""), env.GetParsedUTCOffset())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid 'window' parameter: %s", err), http.StatusBadRequest)
+		return
+	}
+	if window.IsOpen() {
+		http.Error(w, fmt.Sprintf("Invalid 'window' parameter: %s is not closed", window), http.StatusBadRequest)
+		return
+	}
+
+	// Resolution is an optional parameter, defaulting to one day, which
+	// defines the window of each CloudCostItemSet of the range.
+	resolution := qp.GetDuration("resolution", 24*time.Hour)
+
+	ccisr, err := a.BillingIngestor.CloudCostItemSetRange(*window.Start(), *window.End(), resolution)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid 'window' or 'resolution' parameter: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Write(WrapData(ccisr, nil))
+}
@@ -0,0 +1,88 @@
+package costmodel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/cloud/billing"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/storage"
+)
+
+func TestReconcileAssets(t *testing.T) {
+	store := storage.NewFileStorage(t.TempDir())
+	export := `{"service":{"description":"Compute Engine"},"usage_start_time":"2022-10-01T00:00:00Z","usage_end_time":"2022-10-02T00:00:00Z","resource":{"name":"gke-node-1"},"cost":2.0}`
+	if err := store.Write("billing/export.json", []byte(export)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ingestor, err := billing.NewIngestor(store, kubecost.GCPProvider, "billing")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	a := &Accesses{BillingIngestor: ingestor}
+
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	newAssetSet := func(start, end time.Time) (*kubecost.AssetSet, *kubecost.Node) {
+		node := kubecost.NewNode("node1", "cluster1", "gke-node-1", start, end, kubecost.NewClosedWindow(start, end))
+		node.CPUCost = 2.0
+		node.RAMCost = 1.0
+		return kubecost.NewAssetSet(start, end, node), node
+	}
+
+	// Nothing is reconciled before the billing exports are ingested
+	as, node := newAssetSet(start, end)
+	if err := a.reconcileAssets(as); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if node.TotalCost() != 3.0 {
+		t.Errorf("expected unreconciled cost 3.0; got %f", node.TotalCost())
+	}
+
+	if err := ingestor.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	as, node = newAssetSet(start, end)
+	if err := a.reconcileAssets(as); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if node.TotalCost() != 2.0 {
+		t.Errorf("expected reconciled cost 2.0; got %f", node.TotalCost())
+	}
+
+	// Windows which are only partially billed are not reconciled
+	as, node = newAssetSet(start.Add(12*time.Hour), end.Add(12*time.Hour))
+	if err := a.reconcileAssets(as); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if node.TotalCost() != 3.0 {
+		t.Errorf("expected unreconciled cost 3.0; got %f", node.TotalCost())
+	}
+
+	// Windows spanning a gap in the billing exports are not reconciled
+	later := `{"service":{"description":"Compute Engine"},"usage_start_time":"2022-10-03T00:00:00Z","usage_end_time":"2022-10-04T00:00:00Z","resource":{"name":"gke-node-1"},"cost":2.0}`
+	if err := store.Write("billing/later.json", []byte(later)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := ingestor.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	as, node = newAssetSet(start, end.Add(48*time.Hour))
+	if err := a.reconcileAssets(as); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if node.TotalCost() != 3.0 {
+		t.Errorf("expected unreconciled cost 3.0; got %f", node.TotalCost())
+	}
+
+	// Nothing is reconciled if billing ingestion is disabled
+	as, node = newAssetSet(start, end)
+	if err := (&Accesses{}).reconcileAssets(as); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if node.TotalCost() != 3.0 {
+		t.Errorf("expected unreconciled cost 3.0; got %f", node.TotalCost())
+	}
+}
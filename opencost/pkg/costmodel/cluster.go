@@ -832,6 +832,82 @@ func ClusterLoadBalancers(client prometheus.Client, start, end time.Time) (map[L
 	return loadBalancerMap, nil
 }
 
+// Network is the network egress cost of a cluster
+type Network struct {
+	Cluster string
+	Cost    float64
+}
+
+// ClusterNetworks returns the network egress cost of each cluster in the
+// window defined by the given start and end times, computed with the same
+// queries as the network costs of allocations: the GiB egressed across zones,
+// across regions and to the internet, each at its price per GiB.
+func ClusterNetworks(client prometheus.Client, start, end time.Time) (map[string]*Network, error) {
+	// Query for the duration between start and end
+	durStr := timeutil.DurationString(end.Sub(start))
+	if durStr == "" {
+		return nil, fmt.Errorf("illegal duration value for %s", kubecost.NewClosedWindow(start, end))
+	}
+
+	ctx := prom.NewNamedContext(client, prom.ClusterContextName)
+
+	queryFmts := [][2]string{
+		{queryFmtNetZoneGiB, queryFmtNetZoneCostPerGiB},
+		{queryFmtNetRegionGiB, queryFmtNetRegionCostPerGiB},
+		{queryFmtNetInternetGiB, queryFmtNetInternetCostPerGiB},
+	}
+
+	resChs := make([][2]prom.QueryResultsChan, len(queryFmts))
+	for i, fmts := range queryFmts {
+		resChs[i][0] = ctx.QueryAtTime(fmt.Sprintf(fmts[0], durStr, env.GetPromClusterLabel()), end)
+		resChs[i][1] = ctx.QueryAtTime(fmt.Sprintf(fmts[1], durStr, env.GetPromClusterLabel()), end)
+	}
+
+	networkMap := map[string]*Network{}
+	for _, resCh := range resChs {
+		resGiB, _ := resCh[0].Await()
+		resCostPerGiB, _ := resCh[1].Await()
+		applyClusterNetworkCost(networkMap, resGiB, resCostPerGiB)
+	}
+
+	if ctx.HasErrors() {
+		return nil, ctx.ErrorCollection()
+	}
+
+	return networkMap, nil
+}
+
+// applyClusterNetworkCost adds the cost of the GiB egressed by the pods of
+// each cluster, at the price per GiB of the cluster, to its Network.
+func applyClusterNetworkCost(networkMap map[string]*Network, resGiB []*prom.QueryResult, resCostPerGiB []*prom.QueryResult) {
+	costPerGiBByCluster := map[string]float64{}
+	for _, res := range resCostPerGiB {
+		cluster, err := res.GetString(env.GetPromClusterLabel())
+		if err != nil {
+			cluster = env.GetClusterID()
+		}
+		if len(res.Values) == 0 {
+			continue
+		}
+		costPerGiBByCluster[cluster] = res.Values[0].Value
+	}
+
+	for _, res := range resGiB {
+		cluster, err := res.GetString(env.GetPromClusterLabel())
+		if err != nil {
+			cluster = env.GetClusterID()
+		}
+		if len(res.Values) == 0 {
+			continue
+		}
+
+		if _, ok := networkMap[cluster]; !ok {
+			networkMap[cluster] = &Network{Cluster: cluster}
+		}
+		networkMap[cluster].Cost += res.Values[0].Value * costPerGiBByCluster[cluster]
+	}
+}
+
 // ComputeClusterCosts gives the cumulative and monthly-rate cluster costs over a window of time for all clusters.
 func (a *Accesses) ComputeClusterCosts(client prometheus.Client, provider cloud.Provider, window, offset time.Duration, withBreakdown bool) (map[string]*ClusterCosts, error) {
 	if window < 10*time.Minute {
@@ -0,0 +1,621 @@
+package costmodel
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opencost/opencost/pkg/env"
+	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/storage"
+	"github.com/opencost/opencost/pkg/util/httputil"
+	"github.com/opencost/opencost/pkg/util/timeutil"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// FOCUS is the FinOps Open Cost & Usage Specification: https://focus.finops.org
+//
+// Allocations and Assets are exported as one row each, with the Kubernetes
+// metadata of the row in its Tags. Tags set by OpenCost are prefixed with
+// "k8s/", e.g. "k8s/namespace", and annotations with "k8s/annotation/", while
+// labels keep their own keys.
+//
+// The costs of Allocations are shares of the costs of Assets, so only Asset
+// rows have a BilledCost, EffectiveCost and ListCost, and summing any of them
+// gives the cost of the cluster. Allocation rows have costs of zero, with the
+// allocated cost in the custom column x_AllocatedCost. The custom column
+// x_RowType is "Asset" or "Allocation", the type of the row.
+
+const (
+	FocusFormatCSV     = "csv"
+	FocusFormatParquet = "parquet"
+)
+
+// The values of the x_RowType column
+const (
+	FocusRowTypeAsset      = "Asset"
+	FocusRowTypeAllocation = "Allocation"
+)
+
+// focusDir is the directory of the storage to which the FOCUS export is
+// written, one file per day.
+const focusDir = "focus"
+
+// focusDefaultProviderName is the ProviderName of rows for which the cloud
+// provider is unknown.
+const focusDefaultProviderName = "Kubernetes"
+
+// focusTagPrefix prefixes the Tags set by OpenCost
+const focusTagPrefix = "k8s/"
+
+// focusColumns are the FOCUS columns of the export, in order
+var focusColumns = []string{
+	"BilledCost",
+	"EffectiveCost",
+	"ListCost",
+	"BillingCurrency",
+	"BillingPeriodStart",
+	"BillingPeriodEnd",
+	"ChargePeriodStart",
+	"ChargePeriodEnd",
+	"ChargeCategory",
+	"ChargeDescription",
+	"ProviderName",
+	"ResourceId",
+	"ResourceName",
+	"ResourceType",
+	"ServiceName",
+	"ServiceCategory",
+	"SubAccountId",
+	"Tags",
+	"x_RowType",
+	"x_AllocatedCost",
+}
+
+// FocusRow is one row of a FOCUS export
+type FocusRow struct {
+	BilledCost         float64
+	EffectiveCost      float64
+	ListCost           float64
+	BillingCurrency    string
+	BillingPeriodStart time.Time
+	BillingPeriodEnd   time.Time
+	ChargePeriodStart  time.Time
+	ChargePeriodEnd    time.Time
+	ChargeCategory     string
+	ChargeDescription  string
+	ProviderName       string
+	ResourceID         string
+	ResourceName       string
+	ResourceType       string
+	ServiceName        string
+	ServiceCategory    string
+	SubAccountID       string
+	Tags               map[string]string
+	RowType            string
+	AllocatedCost      float64
+}
+
+// focusParquetRow is the Parquet schema of a FocusRow
+type focusParquetRow struct {
+	BilledCost         float64 `parquet:"name=BilledCost, type=DOUBLE"`
+	EffectiveCost      float64 `parquet:"name=EffectiveCost, type=DOUBLE"`
+	ListCost           float64 `parquet:"name=ListCost, type=DOUBLE"`
+	BillingCurrency    string  `parquet:"name=BillingCurrency, type=BYTE_ARRAY, convertedtype=UTF8"`
+	BillingPeriodStart int64   `parquet:"name=BillingPeriodStart, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	BillingPeriodEnd   int64   `parquet:"name=BillingPeriodEnd, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	ChargePeriodStart  int64   `parquet:"name=ChargePeriodStart, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	ChargePeriodEnd    int64   `parquet:"name=ChargePeriodEnd, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	ChargeCategory     string  `parquet:"name=ChargeCategory, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ChargeDescription  string  `parquet:"name=ChargeDescription, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ProviderName       string  `parquet:"name=ProviderName, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ResourceID         string  `parquet:"name=ResourceId, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ResourceName       string  `parquet:"name=ResourceName, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ResourceType       string  `parquet:"name=ResourceType, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ServiceName        string  `parquet:"name=ServiceName, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ServiceCategory    string  `parquet:"name=ServiceCategory, type=BYTE_ARRAY, convertedtype=UTF8"`
+	SubAccountID       string  `parquet:"name=SubAccountId, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Tags               string  `parquet:"name=Tags, type=BYTE_ARRAY, convertedtype=UTF8"`
+	RowType            string  `parquet:"name=x_RowType, type=BYTE_ARRAY, convertedtype=UTF8"`
+	AllocatedCost      float64 `parquet:"name=x_AllocatedCost, type=DOUBLE"`
+}
+
+// tagsJSON returns the Tags of the row as a JSON object, as required by FOCUS
+func (fr *FocusRow) tagsJSON() (string, error) {
+	if fr.Tags == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(fr.Tags)
+	if err != nil {
+		return "", fmt.Errorf("encoding tags of %s: %s", fr.ResourceID, err)
+	}
+	return string(data), nil
+}
+
+// billingPeriod returns the start and end of the calendar month, in UTC,
+// containing t.
+func billingPeriod(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// newFocusRow creates a FocusRow of the usage charge with the given cost and
+// charge period.
+func newFocusRow(billedCost, listCost float64, currency string, start, end time.Time) FocusRow {
+	billingStart, billingEnd := billingPeriod(start)
+
+	return FocusRow{
+		BilledCost:         billedCost,
+		EffectiveCost:      billedCost,
+		ListCost:           listCost,
+		BillingCurrency:    currency,
+		BillingPeriodStart: billingStart,
+		BillingPeriodEnd:   billingEnd,
+		ChargePeriodStart:  start.UTC(),
+		ChargePeriodEnd:    end.UTC(),
+		ChargeCategory:     "Usage",
+		Tags:               map[string]string{},
+	}
+}
+
+// AllocationSetToFocusRows converts each Allocation of the given set to a
+// FocusRow. The charge period of each row is the window of the set. The cost
+// of each Allocation is its AllocatedCost, as its share of the cost of Assets
+// is billed by the Asset rows.
+func AllocationSetToFocusRows(as *kubecost.AllocationSet, provider, currency string) []FocusRow {
+	if as == nil || as.Window.IsOpen() {
+		return []FocusRow{}
+	}
+	if provider == "" {
+		provider = focusDefaultProviderName
+	}
+
+	names := make([]string, 0, len(as.Allocations))
+	for name := range as.Allocations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := make([]FocusRow, 0, len(names))
+	for _, name := range names {
+		alloc := as.Allocations[name]
+		cost := alloc.TotalCost()
+
+		row := newFocusRow(0, 0, currency, *as.Window.Start(), *as.Window.End())
+		row.RowType = FocusRowTypeAllocation
+		row.AllocatedCost = cost
+		row.ChargeDescription = "Kubernetes workload cost allocation"
+		row.ProviderName = provider
+		row.ResourceID = alloc.Name
+		row.ResourceType = "Container"
+		row.ServiceName = kubecost.KubernetesService
+		row.ServiceCategory = "Compute"
+
+		if props := alloc.Properties; props != nil {
+			row.ResourceName = props.Container
+			for k, v := range props.Labels {
+				row.Tags[k] = v
+			}
+			for k, v := range props.Annotations {
+				row.Tags[focusTagPrefix+"annotation/"+k] = v
+			}
+			setFocusTag(row.Tags, "cluster", props.Cluster)
+			setFocusTag(row.Tags, "node", props.Node)
+			setFocusTag(row.Tags, "namespace", props.Namespace)
+			setFocusTag(row.Tags, "controllerKind", props.ControllerKind)
+			setFocusTag(row.Tags, "controller", props.Controller)
+			setFocusTag(row.Tags, "pod", props.Pod)
+			setFocusTag(row.Tags, "container", props.Container)
+		}
+		if row.ResourceName == "" {
+			row.ResourceName = alloc.Name
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// AssetSetToFocusRows converts each Asset of the given set to a FocusRow. The
+// charge period of each row is the window of the set.
+func AssetSetToFocusRows(as *kubecost.AssetSet, currency string) []FocusRow {
+	if as == nil || as.Window.IsOpen() {
+		return []FocusRow{}
+	}
+
+	keys := make([]string, 0, len(as.Assets))
+	for key := range as.Assets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	rows := make([]FocusRow, 0, len(keys))
+	for _, key := range keys {
+		asset := as.Assets[key]
+		cost := asset.TotalCost()
+
+		// The list cost excludes adjustments, e.g. by reconciliation with the
+		// billed cost, and the discounts of Nodes, the only assets with one.
+		listCost := cost - asset.GetAdjustment()
+		if node, ok := asset.(*kubecost.Node); ok {
+			listCost = node.CPUCost + node.RAMCost + node.GPUCost
+		}
+
+		row := newFocusRow(cost, listCost, currency, *as.Window.Start(), *as.Window.End())
+		row.RowType = FocusRowTypeAsset
+		row.ChargeDescription = fmt.Sprintf("Kubernetes %s cost", asset.Type())
+		row.ProviderName = focusDefaultProviderName
+		row.ResourceID = key
+		row.ResourceName = key
+		row.ResourceType = asset.Type().String()
+		// FOCUS requires a ServiceName, but only the assets of cloud
+		// services, e.g. a managed cluster, have one. The rest are
+		// resources of the Kubernetes cluster, as for allocations.
+		row.ServiceName = kubecost.KubernetesService
+		row.ServiceCategory = focusServiceCategory("")
+
+		for k, v := range asset.GetLabels() {
+			row.Tags[k] = v
+		}
+
+		if props := asset.GetProperties(); props != nil {
+			if props.Provider != "" {
+				row.ProviderName = props.Provider
+			}
+			if props.ProviderID != "" {
+				row.ResourceID = props.ProviderID
+			}
+			if props.Name != "" {
+				row.ResourceName = props.Name
+			}
+			if props.Service != "" {
+				row.ServiceName = props.Service
+			}
+			row.ServiceCategory = focusServiceCategory(props.Category)
+			row.SubAccountID = props.Account
+			setFocusTag(row.Tags, "cluster", props.Cluster)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// setFocusTag sets the OpenCost tag with the given key, if the value is set
+func setFocusTag(tags map[string]string, key, value string) {
+	if value != "" {
+		tags[focusTagPrefix+key] = value
+	}
+}
+
+// focusServiceCategory converts an asset category to a FOCUS ServiceCategory
+func focusServiceCategory(category string) string {
+	switch category {
+	case kubecost.ComputeCategory:
+		return "Compute"
+	case kubecost.StorageCategory:
+		return "Storage"
+	case kubecost.NetworkCategory:
+		return "Networking"
+	case kubecost.ManagementCategory:
+		return "Management and Governance"
+	default:
+		return "Other"
+	}
+}
+
+// WriteFocus writes the given rows in the given format, either FocusFormatCSV
+// or FocusFormatParquet.
+func WriteFocus(w io.Writer, rows []FocusRow, format string) error {
+	switch format {
+	case FocusFormatCSV:
+		return WriteFocusCSV(w, rows)
+	case FocusFormatParquet:
+		return WriteFocusParquet(w, rows)
+	default:
+		return fmt.Errorf("unsupported FOCUS format: %s", format)
+	}
+}
+
+// WriteFocusCSV writes the given rows as CSV, with a header of the FOCUS
+// column names. Times are formatted as RFC 3339.
+func WriteFocusCSV(w io.Writer, rows []FocusRow) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(focusColumns); err != nil {
+		return err
+	}
+
+	formatFloat := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	formatTime := func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	for _, row := range rows {
+		tags, err := row.tagsJSON()
+		if err != nil {
+			return err
+		}
+
+		err = cw.Write([]string{
+			formatFloat(row.BilledCost),
+			formatFloat(row.EffectiveCost),
+			formatFloat(row.ListCost),
+			row.BillingCurrency,
+			formatTime(row.BillingPeriodStart),
+			formatTime(row.BillingPeriodEnd),
+			formatTime(row.ChargePeriodStart),
+			formatTime(row.ChargePeriodEnd),
+			row.ChargeCategory,
+			row.ChargeDescription,
+			row.ProviderName,
+			row.ResourceID,
+			row.ResourceName,
+			row.ResourceType,
+			row.ServiceName,
+			row.ServiceCategory,
+			row.SubAccountID,
+			tags,
+			row.RowType,
+			formatFloat(row.AllocatedCost),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteFocusParquet writes the given rows as Parquet, with a column per FOCUS
+// column. Times are written as timestamps in milliseconds, and Tags as JSON.
+func WriteFocusParquet(w io.Writer, rows []FocusRow) error {
+	pw, err := writer.NewParquetWriterFromWriter(w, new(focusParquetRow), 1)
+	if err != nil {
+		return fmt.Errorf("creating parquet writer: %s", err)
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+
+	toMillis := func(t time.Time) int64 {
+		return t.UnixNano() / int64(time.Millisecond)
+	}
+
+	for _, row := range rows {
+		tags, err := row.tagsJSON()
+		if err != nil {
+			return err
+		}
+
+		err = pw.Write(focusParquetRow{
+			BilledCost:         row.BilledCost,
+			EffectiveCost:      row.EffectiveCost,
+			ListCost:           row.ListCost,
+			BillingCurrency:    row.BillingCurrency,
+			BillingPeriodStart: toMillis(row.BillingPeriodStart),
+			BillingPeriodEnd:   toMillis(row.BillingPeriodEnd),
+			ChargePeriodStart:  toMillis(row.ChargePeriodStart),
+			ChargePeriodEnd:    toMillis(row.ChargePeriodEnd),
+			ChargeCategory:     row.ChargeCategory,
+			ChargeDescription:  row.ChargeDescription,
+			ProviderName:       row.ProviderName,
+			ResourceID:         row.ResourceID,
+			ResourceName:       row.ResourceName,
+			ResourceType:       row.ResourceType,
+			ServiceName:        row.ServiceName,
+			ServiceCategory:    row.ServiceCategory,
+			SubAccountID:       row.SubAccountID,
+			Tags:               tags,
+			RowType:            row.RowType,
+			AllocatedCost:      row.AllocatedCost,
+		})
+		if err != nil {
+			return fmt.Errorf("writing parquet row: %s", err)
+		}
+	}
+
+	if err := pw.WriteStop(); err != nil {
+		return fmt.Errorf("writing parquet footer: %s", err)
+	}
+
+	return nil
+}
+
+// FocusSource computes the FOCUS rows for the window defined by the given
+// start and end times. It is implemented by Accesses.
+type FocusSource interface {
+	ComputeFocusRows(start, end time.Time) ([]FocusRow, error)
+}
+
+// ComputeFocusRows computes the allocations and assets of the window defined
+// by the given start and end times, and converts them to FOCUS rows.
+func (a *Accesses) ComputeFocusRows(start, end time.Time) ([]FocusRow, error) {
+	provider, currency := "", "USD"
+	if info, err := a.CloudProvider.ClusterInfo(); err != nil {
+		log.Warnf("ComputeFocusRows: failed to get cluster info: %s", err)
+	} else {
+		provider = info["provider"]
+	}
+	if config, err := a.CloudProvider.GetConfig(); err != nil {
+		log.Warnf("ComputeFocusRows: failed to get config: %s", err)
+	} else if config.CurrencyCode != "" {
+		currency = config.CurrencyCode
+	}
+
+	allocSet, err := a.computeAllocation(start, end, env.GetETLResolution())
+	if err != nil {
+		return nil, fmt.Errorf("computing allocation: %s", err)
+	}
+
+	assetSet, err := a.Model.ComputeAssets(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("computing assets: %s", err)
+	}
+
+	// Reconcile with the billed costs, so that discounts, reservations and
+	// savings plans are reflected, if the window has been billed
+	if err := a.reconcileAssets(assetSet); err != nil {
+		return nil, fmt.Errorf("reconciling assets: %s", err)
+	}
+	if err := a.reconcileAllocationWithAssets(allocSet, assetSet); err != nil {
+		return nil, fmt.Errorf("reconciling allocation: %s", err)
+	}
+
+	rows := AllocationSetToFocusRows(allocSet, provider, currency)
+	rows = append(rows, AssetSetToFocusRows(assetSet, currency)...)
+
+	return rows, nil
+}
+
+// FocusExporter writes a FOCUS export of each complete day to a
+// storage.Storage, in the background.
+type FocusExporter struct {
+	source      FocusSource
+	store       storage.Storage
+	format      string
+	days        int
+	offset      time.Duration
+	refreshRate time.Duration
+	stop        chan struct{}
+}
+
+// NewFocusExporter creates a FocusExporter which exports the given number of
+// past days, in the given format, from the given source to the given storage.
+func NewFocusExporter(source FocusSource, store storage.Storage, format string, days int) *FocusExporter {
+	return &FocusExporter{
+		source:      source,
+		store:       store,
+		format:      format,
+		days:        days,
+		offset:      env.GetParsedUTCOffset(),
+		refreshRate: time.Hour,
+		stop:        make(chan struct{}),
+	}
+}
+
+// fileName returns the path of the export of the day starting at start
+func (fe *FocusExporter) fileName(start time.Time) string {
+	return path.Join(focusDir, fmt.Sprintf("%s.%s", start.Add(fe.offset).Format("2006-01-02"), fe.format))
+}
+
+// Start exports in the background, checking for newly completed days at the
+// refresh rate, until Stop is called.
+func (fe *FocusExporter) Start() {
+	go func() {
+		defer errors.HandlePanic()
+
+		for {
+			fe.Export(time.Now())
+
+			select {
+			case <-fe.stop:
+				return
+			case <-time.After(fe.refreshRate):
+			}
+		}
+	}()
+}
+
+// Stop stops exporting in the background
+func (fe *FocusExporter) Stop() {
+	close(fe.stop)
+}
+
+// Export writes the export of each complete day, of the configured number of
+// days before the given time, which has not already been written.
+func (fe *FocusExporter) Export(now time.Time) {
+	end := now.Add(fe.offset).Truncate(timeutil.Day).Add(-fe.offset).UTC()
+
+	for i := fe.days; i > 0; i-- {
+		start := end.Add(-time.Duration(i) * timeutil.Day)
+		fileName := fe.fileName(start)
+
+		exists, err := fe.store.Exists(fileName)
+		if err != nil {
+			log.Warnf("FocusExporter: failed to check %s: %s", fileName, err)
+			continue
+		}
+		if exists {
+			continue
+		}
+
+		if err := fe.export(start, start.Add(timeutil.Day), fileName); err != nil {
+			log.Errorf("FocusExporter: failed to export %s: %s", fileName, err)
+		}
+	}
+}
+
+// export writes the export of the given window to the given file
+func (fe *FocusExporter) export(start, end time.Time, fileName string) error {
+	rows, err := fe.source.ComputeFocusRows(start, end)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := WriteFocus(&buf, rows, fe.format); err != nil {
+		return err
+	}
+
+	return fe.store.Write(fileName, buf.Bytes())
+}
+
+// ExportFocusHandler returns a FOCUS export of the allocations and assets of
+// the given window, as CSV or Parquet.
+func (a *Accesses) ExportFocusHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	qp := httputil.NewQueryParams(r.URL.Query())
+
+	window, err := kubecost.ParseWindowWithOffset(qp.Get("window", ""), env.GetParsedUTCOffset())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid 'window' parameter: %s", err), http.StatusBadRequest)
+		return
+	}
+	if window.IsOpen() {
+		http.Error(w, fmt.Sprintf("Invalid 'window' parameter: %s is not closed", window), http.StatusBadRequest)
+		return
+	}
+
+	// Format is an optional parameter, defaulting to CSV
+	format := qp.Get("format", FocusFormatCSV)
+	if format != FocusFormatCSV && format != FocusFormatParquet {
+		http.Error(w, fmt.Sprintf("Invalid 'format' parameter: %s", format), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := a.ComputeFocusRows(*window.Start(), *window.End())
+	if err != nil {
+		WriteError(w, InternalServerError(err.Error()))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := WriteFocus(&buf, rows, format); err != nil {
+		WriteError(w, InternalServerError(err.Error()))
+		return
+	}
+
+	if format == FocusFormatCSV {
+		w.Header().Set("Content-Type", "text/csv")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	fileName := fmt.Sprintf("focus-%d-%d.%s", window.Start().Unix(), window.End().Unix(), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+	w.Write(buf.Bytes())
+}
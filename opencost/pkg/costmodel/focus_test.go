@@ -0,0 +1,259 @@
+package costmodel
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/storage"
+	"github.com/opencost/opencost/pkg/util/timeutil"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+func TestAllocationSetToFocusRows(t *testing.T) {
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(timeutil.Day)
+
+	alloc := kubecost.NewMockUnitAllocation("", start, timeutil.Day, nil)
+	alloc.Properties.Labels = kubecost.AllocationLabels{"app": "web"}
+	alloc.Properties.Annotations = kubecost.AllocationAnnotations{"owner": "team-a"}
+	as := kubecost.NewAllocationSet(start, end, alloc)
+
+	rows := AllocationSetToFocusRows(as, "", "EUR")
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row; got %d", len(rows))
+	}
+	row := rows[0]
+
+	// Allocated costs are billed by the asset rows, so are not summed twice
+	if row.BilledCost != 0 || row.EffectiveCost != 0 || row.ListCost != 0 {
+		t.Errorf("expected costs of 0; got %f, %f, %f", row.BilledCost, row.EffectiveCost, row.ListCost)
+	}
+	if row.AllocatedCost != alloc.TotalCost() || row.RowType != FocusRowTypeAllocation {
+		t.Errorf("expected allocated cost %f and row type %s; got %f, %s", alloc.TotalCost(), FocusRowTypeAllocation, row.AllocatedCost, row.RowType)
+	}
+	if row.BillingCurrency != "EUR" || row.ProviderName != focusDefaultProviderName {
+		t.Errorf("unexpected currency or provider: %s, %s", row.BillingCurrency, row.ProviderName)
+	}
+	if !row.ChargePeriodStart.Equal(start) || !row.ChargePeriodEnd.Equal(end) {
+		t.Errorf("unexpected charge period: %s - %s", row.ChargePeriodStart, row.ChargePeriodEnd)
+	}
+	if !row.BillingPeriodStart.Equal(start) || !row.BillingPeriodEnd.Equal(time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected billing period: %s - %s", row.BillingPeriodStart, row.BillingPeriodEnd)
+	}
+	if row.ResourceID != alloc.Name || row.ResourceName != "container1" {
+		t.Errorf("unexpected resource: %s, %s", row.ResourceID, row.ResourceName)
+	}
+
+	expectedTags := map[string]string{
+		"app":                  "web",
+		"k8s/annotation/owner": "team-a",
+		"k8s/cluster":          "cluster1",
+		"k8s/node":             "node1",
+		"k8s/namespace":        "namespace1",
+		"k8s/controllerKind":   "deployment",
+		"k8s/controller":       "deployment1",
+		"k8s/pod":              "pod1",
+		"k8s/container":        "container1",
+	}
+	if len(row.Tags) != len(expectedTags) {
+		t.Errorf("expected tags %v; got %v", expectedTags, row.Tags)
+	}
+	for k, v := range expectedTags {
+		if row.Tags[k] != v {
+			t.Errorf("expected tag %s=%s; got %s", k, v, row.Tags[k])
+		}
+	}
+}
+
+func TestAssetSetToFocusRows(t *testing.T) {
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(timeutil.Day)
+	window := kubecost.NewClosedWindow(start, end)
+
+	node := kubecost.NewNode("node-one", "cluster-one", "id-one", start, end, window)
+	node.Properties.Provider = kubecost.GCPProvider
+	node.Properties.Service = kubecost.KubernetesService
+	node.CPUCost = 2.0
+	node.RAMCost = 1.0
+	node.Discount = 0.5
+	node.SetLabels(kubecost.AssetLabels{"app": "web"})
+
+	disk := kubecost.NewDisk("pv-one", "cluster-one", "", start, end, window)
+	disk.Cost = 0.5
+	disk.SetAdjustment(-0.1)
+
+	as := kubecost.NewAssetSet(start, end, node, disk)
+
+	rows := AssetSetToFocusRows(as, "USD")
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows; got %d", len(rows))
+	}
+
+	byType := map[string]FocusRow{}
+	for _, row := range rows {
+		byType[row.ResourceType] = row
+	}
+
+	nodeRow := byType["Node"]
+	if math.Abs(nodeRow.BilledCost-1.5) > 0.0001 || math.Abs(nodeRow.ListCost-3.0) > 0.0001 {
+		t.Errorf("expected node billed cost 1.5 and list cost 3.0; got %f, %f", nodeRow.BilledCost, nodeRow.ListCost)
+	}
+	if nodeRow.RowType != FocusRowTypeAsset || nodeRow.AllocatedCost != 0 {
+		t.Errorf("unexpected node row type or allocated cost: %s, %f", nodeRow.RowType, nodeRow.AllocatedCost)
+	}
+	if nodeRow.ResourceID != "id-one" || nodeRow.ResourceName != "node-one" {
+		t.Errorf("unexpected node resource: %s, %s", nodeRow.ResourceID, nodeRow.ResourceName)
+	}
+	if nodeRow.ProviderName != kubecost.GCPProvider || nodeRow.ServiceCategory != "Compute" {
+		t.Errorf("unexpected node provider or category: %s, %s", nodeRow.ProviderName, nodeRow.ServiceCategory)
+	}
+	if nodeRow.Tags["app"] != "web" || nodeRow.Tags["k8s/cluster"] != "cluster-one" {
+		t.Errorf("unexpected node tags: %v", nodeRow.Tags)
+	}
+
+	diskRow := byType["Disk"]
+	if math.Abs(diskRow.BilledCost-0.4) > 0.0001 || math.Abs(diskRow.ListCost-0.5) > 0.0001 {
+		t.Errorf("expected disk billed cost 0.4 and list cost 0.5; got %f, %f", diskRow.BilledCost, diskRow.ListCost)
+	}
+	if diskRow.ServiceCategory != "Storage" || diskRow.ProviderName != focusDefaultProviderName {
+		t.Errorf("unexpected disk category or provider: %s, %s", diskRow.ServiceCategory, diskRow.ProviderName)
+	}
+
+	// every row has a ServiceName, as FOCUS requires, even if the asset
+	// has no Service
+	for _, row := range rows {
+		if row.ServiceName != kubecost.KubernetesService {
+			t.Errorf("expected %s ServiceName %s; got %q", row.ResourceType, kubecost.KubernetesService, row.ServiceName)
+		}
+	}
+}
+
+func mockFocusRows(start, end time.Time) []FocusRow {
+	row := newFocusRow(0, 0, "USD", start, end)
+	row.RowType = FocusRowTypeAllocation
+	row.AllocatedCost = 1.5
+	row.ResourceID = "cluster1/node1/namespace1/pod1/container1"
+	row.Tags["k8s/namespace"] = "namespace1"
+	return []FocusRow{row}
+}
+
+func TestWriteFocusCSV(t *testing.T) {
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := WriteFocusCSV(&buf, mockFocusRows(start, start.Add(timeutil.Day))); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records; got %d", len(records))
+	}
+
+	record := map[string]string{}
+	for i, column := range records[0] {
+		record[column] = records[1][i]
+	}
+	expected := map[string]string{
+		"BilledCost":        "0",
+		"x_AllocatedCost":   "1.5",
+		"x_RowType":         "Allocation",
+		"ChargePeriodStart": "2022-10-01T00:00:00Z",
+		"ChargePeriodEnd":   "2022-10-02T00:00:00Z",
+		"ResourceId":        "cluster1/node1/namespace1/pod1/container1",
+		"Tags":              `{"k8s/namespace":"namespace1"}`,
+	}
+	for column, value := range expected {
+		if record[column] != value {
+			t.Errorf("expected %s=%s; got %s", column, value, record[column])
+		}
+	}
+}
+
+func TestWriteFocusParquet(t *testing.T) {
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := WriteFocusParquet(&buf, mockFocusRows(start, start.Add(timeutil.Day))); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	file, err := buffer.NewBufferFile(buf.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	pr, err := reader.NewParquetReader(file, new(focusParquetRow), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer pr.ReadStop()
+
+	if pr.GetNumRows() != 1 {
+		t.Fatalf("expected 1 row; got %d", pr.GetNumRows())
+	}
+	rows := make([]focusParquetRow, 1)
+	if err := pr.Read(&rows); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	row := rows[0]
+	if row.BilledCost != 0 || row.AllocatedCost != 1.5 || row.RowType != FocusRowTypeAllocation {
+		t.Errorf("unexpected costs or row type: %f, %f, %s", row.BilledCost, row.AllocatedCost, row.RowType)
+	}
+	if row.ChargePeriodStart != start.UnixNano()/int64(time.Millisecond) {
+		t.Errorf("unexpected charge period start: %d", row.ChargePeriodStart)
+	}
+	if row.Tags != `{"k8s/namespace":"namespace1"}` {
+		t.Errorf("unexpected tags: %s", row.Tags)
+	}
+}
+
+// mockFocusSource returns mockFocusRows for any window, and records the
+// windows it was queried for.
+type mockFocusSource struct {
+	queries []kubecost.Window
+}
+
+func (mfs *mockFocusSource) ComputeFocusRows(start, end time.Time) ([]FocusRow, error) {
+	mfs.queries = append(mfs.queries, kubecost.NewClosedWindow(start, end))
+	return mockFocusRows(start, end), nil
+}
+
+func TestFocusExporter(t *testing.T) {
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(3*timeutil.Day + 6*time.Hour)
+
+	source := &mockFocusSource{}
+	store := storage.NewFileStorage(t.TempDir())
+	exporter := NewFocusExporter(source, store, FocusFormatCSV, 2)
+
+	exporter.Export(now)
+
+	// Only the complete days within the configured number of days are exported
+	if len(source.queries) != 2 {
+		t.Fatalf("expected 2 queries; got %v", source.queries)
+	}
+	for _, day := range []string{"2022-10-02", "2022-10-03"} {
+		exists, err := store.Exists(fmt.Sprintf("focus/%s.csv", day))
+		if err != nil || !exists {
+			t.Errorf("expected export of %s to exist", day)
+		}
+	}
+
+	// Days which have already been exported are skipped
+	source.queries = nil
+	exporter.Export(now.Add(timeutil.Day))
+	expected := kubecost.NewClosedWindow(start.Add(3*timeutil.Day), start.Add(4*timeutil.Day))
+	if len(source.queries) != 1 || !source.queries[0].Equal(expected) {
+		t.Errorf("expected one query for %s; got %v", expected, source.queries)
+	}
+}
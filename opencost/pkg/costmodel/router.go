@@ -33,6 +33,7 @@ import (
 	sentry "github.com/getsentry/sentry-go"
 
 	"github.com/opencost/opencost/pkg/cloud"
+	"github.com/opencost/opencost/pkg/cloud/billing"
 	"github.com/opencost/opencost/pkg/clustercache"
 	"github.com/opencost/opencost/pkg/costmodel/clusters"
 	"github.com/opencost/opencost/pkg/env"
@@ -94,6 +95,13 @@ type Accesses struct {
 	ClusterCostsCache   *cache.Cache
 	CacheExpiration     map[time.Duration]time.Duration
 	AggAPI              Aggregator
+	// AllocationStores persist computed AllocationSets, ordered from the
+	// largest resolution to the smallest. Empty unless the ETL store is enabled.
+	AllocationStores []*AllocationStore
+	// BillingIngestor ingests the billing exports of the cloud provider, by
+	// which assets and allocations are reconciled. Nil unless cloud billing
+	// ingestion is enabled.
+	BillingIngestor *billing.Ingestor
 	// SettingsCache stores current state of app settings
 	SettingsCache *cache.Cache
 	// settingsSubscribers tracks channels through which changes to different
@@ -1700,11 +1708,46 @@ func Initialize(additionalConfigWatchers ...*watcher.ConfigMapWatcher) *Accesses
 		a.MetricsEmitter.Start()
 	}
 
+	if env.IsETLStoreEnabled() {
+		log.Infof("Init: ETL store enabled")
+		a.AllocationStores = newAllocationStores(costModel)
+		for _, store := range a.AllocationStores {
+			store.Start()
+		}
+	}
+
+	if env.IsFocusExportEnabled() {
+		format := env.GetFocusExportFormat()
+		if format != FocusFormatCSV && format != FocusFormatParquet {
+			log.Errorf("Init: FOCUS export disabled: unsupported format: %s", format)
+		} else {
+			log.Infof("Init: FOCUS export enabled")
+			store := newBucketOrFileStorage(env.GetFocusExportBucketConfig(), env.GetFocusExportPath())
+			NewFocusExporter(a, store, format, env.GetFocusExportDays()).Start()
+		}
+	}
+
+	if env.IsCloudBillingEnabled() {
+		ingestor, err := a.newBillingIngestor()
+		if err != nil {
+			log.Errorf("Init: cloud billing ingestion disabled: %s", err)
+		} else {
+			log.Infof("Init: cloud billing ingestion enabled for %s", ingestor.Provider())
+			a.BillingIngestor = ingestor
+			startBillingIngestor(ingestor)
+		}
+	}
+
 	a.Router.GET("/costDataModel", a.CostDataModel)
 	a.Router.GET("/costDataModelRange", a.CostDataModelRange)
 	a.Router.GET("/aggregatedCostModel", a.AggregateCostModelHandler)
 	a.Router.GET("/allocation/compute", a.ComputeAllocationHandler)
 	a.Router.GET("/allocation/compute/summary", a.ComputeAllocationHandlerSummary)
+	a.Router.GET("/assets", a.ComputeAssetsHandler)
+	a.Router.GET("/etl/allocation/status", a.AllocationStoreStatusHandler)
+	a.Router.POST("/etl/allocation/rebuild", a.AllocationStoreRebuildHandler)
+	a.Router.GET("/export/focus", a.ExportFocusHandler)
+	a.Router.GET("/cloudCostItems", a.ComputeCloudCostItemsHandler)
 	a.Router.GET("/allNodePricing", a.GetAllNodePricing)
 	a.Router.POST("/refreshPricing", a.RefreshPricingData)
 	a.Router.GET("/clusterCostsOverTime", a.ClusterCostsOverTime)
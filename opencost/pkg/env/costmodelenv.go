@@ -72,6 +72,23 @@ const (
 	ETLEnabledEnvVar                     = "ETL_ENABLED"
 	ETLMaxPrometheusQueryDurationMinutes = "ETL_MAX_PROMETHEUS_QUERY_DURATION_MINUTES"
 	ETLResolutionSeconds                 = "ETL_RESOLUTION_SECONDS"
+	ETLStoreEnabledEnvVar                = "ETL_STORE_ENABLED"
+	ETLBucketConfigEnvVar                = "ETL_BUCKET_CONFIG"
+	ETLPathEnvVar                        = "ETL_PATH"
+	ETLDailyStoreDurationDaysEnvVar      = "ETL_DAILY_STORE_DURATION_DAYS"
+	ETLHourlyStoreDurationHoursEnvVar    = "ETL_HOURLY_STORE_DURATION_HOURS"
+	ETLRefreshRateMinutesEnvVar          = "ETL_REFRESH_RATE_MINUTES"
+	FocusExportEnabledEnvVar             = "FOCUS_EXPORT_ENABLED"
+	FocusExportFormatEnvVar              = "FOCUS_EXPORT_FORMAT"
+	FocusExportBucketConfigEnvVar        = "FOCUS_EXPORT_BUCKET_CONFIG"
+	FocusExportPathEnvVar                = "FOCUS_EXPORT_PATH"
+	FocusExportDaysEnvVar                = "FOCUS_EXPORT_DAYS"
+	CloudBillingEnabledEnvVar            = "CLOUD_BILLING_ENABLED"
+	CloudBillingProviderEnvVar           = "CLOUD_BILLING_PROVIDER"
+	CloudBillingBucketConfigEnvVar       = "CLOUD_BILLING_BUCKET_CONFIG"
+	CloudBillingPathEnvVar               = "CLOUD_BILLING_PATH"
+	CloudBillingPrefixEnvVar             = "CLOUD_BILLING_PREFIX"
+	CloudBillingRefreshRateMinutesEnvVar = "CLOUD_BILLING_REFRESH_RATE_MINUTES"
 	LegacyExternalAPIDisabledVar         = "LEGACY_EXTERNAL_API_DISABLED"
 
 	PromClusterIDLabelEnvVar = "PROM_CLUSTER_ID_LABEL"
@@ -495,6 +512,112 @@ func GetETLResolution() time.Duration {
 	return secs * time.Second
 }
 
+// IsETLStoreEnabled returns true if AllocationSets are computed in the
+// background and persisted to the ETL store, from which queries are served.
+func IsETLStoreEnabled() bool {
+	return GetBool(ETLStoreEnabledEnvVar, false)
+}
+
+// GetETLBucketConfig returns a file location for a mounted bucket configuration
+// which is used to persist the ETL store. If empty, the ETL store is persisted
+// to the local file system at GetETLPath.
+func GetETLBucketConfig() string {
+	return Get(ETLBucketConfigEnvVar, "")
+}
+
+// GetETLPath returns the local directory the ETL store is persisted to when no
+// bucket configuration is provided.
+func GetETLPath() string {
+	return Get(ETLPathEnvVar, "/var/configs/etl")
+}
+
+// GetETLDailyStoreDuration returns the duration covered by the daily ETL store
+func GetETLDailyStoreDuration() time.Duration {
+	days := time.Duration(GetInt64(ETLDailyStoreDurationDaysEnvVar, 91))
+	return days * 24 * time.Hour
+}
+
+// GetETLHourlyStoreDuration returns the duration covered by the hourly ETL store
+func GetETLHourlyStoreDuration() time.Duration {
+	hours := time.Duration(GetInt64(ETLHourlyStoreDurationHoursEnvVar, 49))
+	return hours * time.Hour
+}
+
+// GetETLRefreshRate returns the interval at which the ETL store is built
+func GetETLRefreshRate() time.Duration {
+	mins := time.Duration(GetInt64(ETLRefreshRateMinutesEnvVar, 60))
+	return mins * time.Minute
+}
+
+// IsFocusExportEnabled returns true if a FOCUS export of each day's allocations
+// and assets is written to the FOCUS export storage.
+func IsFocusExportEnabled() bool {
+	return GetBool(FocusExportEnabledEnvVar, false)
+}
+
+// GetFocusExportFormat returns the file format of the FOCUS export, either
+// "csv" or "parquet".
+func GetFocusExportFormat() string {
+	return Get(FocusExportFormatEnvVar, "csv")
+}
+
+// GetFocusExportBucketConfig returns a file location for a mounted bucket
+// configuration which is used to write the FOCUS export. If empty, the FOCUS
+// export is written to the local file system at GetFocusExportPath.
+func GetFocusExportBucketConfig() string {
+	return Get(FocusExportBucketConfigEnvVar, "")
+}
+
+// GetFocusExportPath returns the local directory the FOCUS export is written to
+// when no bucket is configured.
+func GetFocusExportPath() string {
+	return Get(FocusExportPathEnvVar, "/var/configs/focus")
+}
+
+// GetFocusExportDays returns the number of past days which are exported, if
+// they have not been already.
+func GetFocusExportDays() int {
+	return GetInt(FocusExportDaysEnvVar, 7)
+}
+
+// IsCloudBillingEnabled returns true if the billing exports of the cloud
+// provider are ingested to reconcile assets and allocations with billed costs.
+func IsCloudBillingEnabled() bool {
+	return GetBool(CloudBillingEnabledEnvVar, false)
+}
+
+// GetCloudBillingProvider returns the provider of the billing exports, e.g.
+// "aws", "gcp" or "azure". If empty, the provider of the cluster is used.
+func GetCloudBillingProvider() string {
+	return Get(CloudBillingProviderEnvVar, "")
+}
+
+// GetCloudBillingBucketConfig returns a file location for a mounted bucket
+// configuration which is used to read the billing exports. If empty, the
+// billing exports are read from the local file system at GetCloudBillingPath.
+func GetCloudBillingBucketConfig() string {
+	return Get(CloudBillingBucketConfigEnvVar, "")
+}
+
+// GetCloudBillingPath returns the local directory the billing exports are read
+// from when no bucket is configured.
+func GetCloudBillingPath() string {
+	return Get(CloudBillingPathEnvVar, "/var/configs/billing")
+}
+
+// GetCloudBillingPrefix returns the directory of the billing exports within
+// the bucket or local directory, e.g. the path prefix of an AWS CUR.
+func GetCloudBillingPrefix() string {
+	return Get(CloudBillingPrefixEnvVar, "")
+}
+
+// GetCloudBillingRefreshRate returns the interval at which new billing exports
+// are ingested
+func GetCloudBillingRefreshRate() time.Duration {
+	mins := time.Duration(GetInt64(CloudBillingRefreshRateMinutesEnvVar, 360))
+	return mins * time.Minute
+}
+
 func LegacyExternalCostsAPIDisabled() bool {
 	return GetBool(LegacyExternalAPIDisabledVar, false)
 }
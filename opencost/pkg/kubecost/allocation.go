@@ -1758,6 +1758,17 @@ func (as *AllocationSet) Clone() *AllocationSet {
 	}
 }
 
+// CloneSet returns a deep copy of the caller, fulfilling the ETLSet interface
+func (as *AllocationSet) CloneSet() ETLSet {
+	return as.Clone()
+}
+
+// ConstructSet fulfills the ETLSet interface to provide an empty version of
+// itself so that it can be initialized in its generic form.
+func (as *AllocationSet) ConstructSet() ETLSet {
+	return &AllocationSet{}
+}
+
 // Delete removes the allocation with the given name from the set
 func (as *AllocationSet) Delete(name string) {
 	if as == nil {
@@ -22,6 +22,10 @@ const (
 	comma                  // ','
 	plus                   // '+'
 
+	pipe       // '|'
+	leftParen  // '('
+	rightParen // ')'
+
 	bangColon // '!:'
 
 	str // '"foo"'
@@ -60,6 +64,12 @@ func (tk tokenKind) String() string {
 		return "comma"
 	case plus:
 		return "plus"
+	case pipe:
+		return "pipe"
+	case leftParen:
+		return "leftParen"
+	case rightParen:
+		return "rightParen"
 	case bangColon:
 		return "bangColon"
 	case str:
@@ -169,6 +179,12 @@ func (s *scanner) scanToken() {
 		s.addToken(comma)
 	case '+':
 		s.addToken(plus)
+	case '|':
+		s.addToken(pipe)
+	case '(':
+		s.addToken(leftParen)
+	case ')':
+		s.addToken(rightParen)
 	case '!':
 		if s.match(':') {
 			s.addToken(bangColon)
@@ -197,6 +213,13 @@ func (s *scanner) scanToken() {
 			break
 		}
 
+		// Printable ASCII characters can be reported as-is, which is more
+		// helpful for typos like '=' instead of ':'.
+		if c >= ' ' && c <= '~' {
+			s.errors = append(s.errors, fmt.Errorf("unexpected character '%c' at position %d", c, s.nextByte-1))
+			break
+		}
+
 		// TODO: We could return a more exact error message for Unicode chars if
 		// we added extra handling:
 		// https://stackoverflow.com/questions/53069040/checking-a-string-contains-only-ascii-characters
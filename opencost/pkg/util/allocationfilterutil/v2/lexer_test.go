@@ -90,15 +90,27 @@ func TestLexer(t *testing.T) {
 				{kind: eof},
 			},
 		},
+		{
+			name:     "pipe and parens",
+			input:    "(+|)",
+			expected: []token{{kind: leftParen, s: "("}, {kind: plus, s: "+"}, {kind: pipe, s: "|"}, {kind: rightParen, s: ")"}, {kind: eof}},
+		},
+		{
+			name:        "unexpected character",
+			input:       `namespace="kubecost"`,
+			expectError: true,
+		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			t.Logf("Input: '%s'", c.input)
 			result, err := lexAllocationFilterV2(c.input)
-			if c.expectError && err == nil {
-				t.Errorf("expected error but got nil")
-			} else if !c.expectError && err != nil {
+			if c.expectError {
+				if err == nil {
+					t.Errorf("expected error but got nil")
+				}
+			} else if err != nil {
 				t.Errorf("unexpected error: %s", err)
 			} else {
 				if len(c.expected) != len(result) {
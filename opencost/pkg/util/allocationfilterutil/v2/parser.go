@@ -19,6 +19,8 @@ import (
 //   label[app]:"cost-analyzer"
 //   node!:"node1","node2"
 //   cluster:"cluster-one"+namespace!:"kube-system"
+//   namespace:"kube-system"+label[team]:"a","b"|controllerKind!:"job"
+//   cluster:"cluster-one"+(namespace:"kubecost"|label[app]:"cost-analyzer")
 //
 // The grammar is approximately as follows:
 //
@@ -27,14 +29,18 @@ import (
 //
 // [1] https://docs.google.com/document/d/1HKkp2bv3mnvfQoBZlpHjfZwQ0FzDLOHKpnwV9gQ_KgU/edit?pli=1
 //
-// <filter> ::= <comparison> ('+' <comparison>)*
-//              NOTE: Language can be extended to support ORs between
-//              comparisons by adding a '|' operator in between comparisons,
-//              though precedence will have to be carefully defined and it may
-//              require adding support for ()-enclosed statements to deal with
-//              precedence.
-//              This would allow for queries like:
-//                namespace:"x"|label[app]="foo"
+// <filter> ::= <or-group>
+//
+// <or-group> ::= <and-group> ('|' <and-group>)*
+//
+// <and-group> ::= <term> ('+' <term>)*
+//                 NOTE: '+' binds more tightly than '|', so
+//                   a+b|c
+//                 is equivalent to
+//                   (a+b)|c
+//
+// <term> ::= '(' <or-group> ')'
+//          | <comparison>
 //
 // <comparison> ::= <filter-key> <filter-op> <filter-value>
 //
@@ -148,13 +154,13 @@ func (p *parser) consume(tk tokenKind, message string) (token, error) {
 	return token{}, parseError(p.peek(), message)
 }
 
-// synchronize attempts to skip forward until the next '+', indicating the
-// start of a new <comparison>. This lets us do best-effort reporting of
-// multiple parse errors.
+// synchronize attempts to skip forward until the next '+', '|' or ')',
+// indicating the end of the current <comparison>. This lets us do best-effort
+// reporting of multiple parse errors.
 func (p *parser) synchronize() {
-	p.advance()
 	for !p.atEnd() {
-		if p.previous().kind == plus {
+		switch p.peek().kind {
+		case plus, pipe, rightParen:
 			return
 		}
 
@@ -171,26 +177,71 @@ func (p *parser) synchronize() {
 func (p *parser) filter() (kubecost.AllocationFilter, error) {
 	var errs *multierror.Error
 
-	// Currently, a filter is only a sequence of AND operations
+	f := p.orGroup(&errs)
+
+	// Anything left over is not part of the filter, e.g. a missing '+'
+	// between two comparisons or an unmatched ')'.
+	if !p.atEnd() {
+		errs = multierror.Append(errs, parseError(p.peek(), "expect '+', '|' or end of filter"))
+	}
+
+	return f, errs.ErrorOrNil()
+}
+
+// orGroup parses a sequence of <and-group>s separated by '|'. If there is only
+// one <and-group>, it is returned directly instead of being wrapped in an OR.
+func (p *parser) orGroup(errs **multierror.Error) kubecost.AllocationFilter {
+	left := p.andGroup(errs)
+	if !p.check(pipe) {
+		return left
+	}
+
+	f := kubecost.AllocationFilterOr{
+		Filters: []kubecost.AllocationFilter{left},
+	}
+	for p.match(pipe) {
+		f.Filters = append(f.Filters, p.andGroup(errs))
+	}
+
+	return f
+}
+
+// andGroup parses a sequence of <term>s separated by '+'
+func (p *parser) andGroup(errs **multierror.Error) kubecost.AllocationFilter {
 	f := kubecost.AllocationFilterAnd{}
-	comparison, err := p.comparison()
+
+	term, err := p.term(errs)
 	if err != nil {
-		errs = multierror.Append(errs, err)
+		*errs = multierror.Append(*errs, err)
 		p.synchronize()
 	} else {
-		f.Filters = append(f.Filters, comparison)
+		f.Filters = append(f.Filters, term)
 	}
 	for p.match(plus) {
-		right, err := p.comparison()
+		right, err := p.term(errs)
 		if err != nil {
-			errs = multierror.Append(errs, err)
+			*errs = multierror.Append(*errs, err)
 			p.synchronize()
 		} else {
 			f.Filters = append(f.Filters, right)
 		}
 	}
 
-	return f, errs.ErrorOrNil()
+	return f
+}
+
+// term parses either a ()-enclosed <or-group> or a <comparison>
+func (p *parser) term(errs **multierror.Error) (kubecost.AllocationFilter, error) {
+	if p.match(leftParen) {
+		inner := p.orGroup(errs)
+		if _, err := p.consume(rightParen, "expect ')' to close '('"); err != nil {
+			return nil, err
+		}
+
+		return inner, nil
+	}
+
+	return p.comparison()
 }
 
 func (p *parser) comparison() (kubecost.AllocationFilter, error) {
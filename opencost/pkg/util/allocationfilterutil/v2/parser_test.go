@@ -3,6 +3,7 @@ package allocationfilterutil
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/opencost/opencost/pkg/kubecost"
@@ -26,8 +27,8 @@ func TestParse(t *testing.T) {
 	}{
 		{
 			input: `namespace:"kubecost"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterNamespace,
 						Op:    kubecost.FilterEquals,
@@ -44,8 +45,8 @@ func TestParse(t *testing.T) {
 		},
 		{
 			input: `namespace!:"kubecost","kube-system"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterNamespace,
 						Op:    kubecost.FilterNotEquals,
@@ -68,8 +69,8 @@ func TestParse(t *testing.T) {
 		},
 		{
 			input: `namespace:"kubecost","kube-system"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterNamespace,
 						Op:    kubecost.FilterEquals,
@@ -92,8 +93,8 @@ func TestParse(t *testing.T) {
 		},
 		{
 			input: `node:"node a b c" , "node 12 3"` + string('\n') + "+" + string('\n') + string('\r') + `namespace : "kubecost"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterNode,
 						Op:    kubecost.FilterEquals,
@@ -105,7 +106,7 @@ func TestParse(t *testing.T) {
 						Value: "node 12 3",
 					},
 				}},
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterNamespace,
 						Op:    kubecost.FilterEquals,
@@ -116,8 +117,8 @@ func TestParse(t *testing.T) {
 		},
 		{
 			input: `label[app_abc]:"cost_analyzer"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterLabel,
 						Key:   "app_abc",
@@ -129,8 +130,8 @@ func TestParse(t *testing.T) {
 		},
 		{
 			input: `services:"123","abc"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterServices,
 						Op:    kubecost.FilterContains,
@@ -146,8 +147,8 @@ func TestParse(t *testing.T) {
 		},
 		{
 			input: `services!:"123","abc"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterServices,
 						Op:    kubecost.FilterNotContains,
@@ -163,8 +164,8 @@ func TestParse(t *testing.T) {
 		},
 		{
 			input: `label[app_abc]:"cost_analyzer"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterLabel,
 						Key:   "app_abc",
@@ -176,8 +177,8 @@ func TestParse(t *testing.T) {
 		},
 		{
 			input: `label[app_abc]:"cost_analyzer"+label[foo]:"bar"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterLabel,
 						Key:   "app_abc",
@@ -185,7 +186,7 @@ func TestParse(t *testing.T) {
 						Value: "cost_analyzer",
 					},
 				}},
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterLabel,
 						Key:   "foo",
@@ -215,15 +216,15 @@ container!:"123-abc_foo" +
 pod!:"aaaaaaaaaaaaaaaaaaaaaaaaa" +
 services!:"abc123"
 `,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterNamespace,
 						Op:    kubecost.FilterEquals,
 						Value: "kubecost",
 					},
 				}},
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterLabel,
 						Key:   "app",
@@ -231,7 +232,7 @@ services!:"abc123"
 						Value: "cost_analyzer",
 					},
 				}},
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterAnnotation,
 						Key:   "a1",
@@ -239,14 +240,14 @@ services!:"abc123"
 						Value: "b2",
 					},
 				}},
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterClusterID,
 						Op:    kubecost.FilterEquals,
 						Value: "cluster-one",
 					},
 				}},
-				kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
+				kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterNode,
 						Op:    kubecost.FilterNotEquals,
@@ -258,7 +259,7 @@ services!:"abc123"
 						Value: "node-456",
 					},
 				}},
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterControllerName,
 						Op:    kubecost.FilterEquals,
@@ -270,7 +271,7 @@ services!:"abc123"
 						Value: "kubecost-prometheus-server",
 					},
 				}},
-				kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
+				kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterControllerKind,
 						Op:    kubecost.FilterNotEquals,
@@ -287,21 +288,21 @@ services!:"abc123"
 						Value: "job",
 					},
 				}},
-				kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
+				kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterContainer,
 						Op:    kubecost.FilterNotEquals,
 						Value: "123-abc_foo",
 					},
 				}},
-				kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
+				kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterPod,
 						Op:    kubecost.FilterNotEquals,
 						Value: "aaaaaaaaaaaaaaaaaaaaaaaaa",
 					},
 				}},
-				kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
+				kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterServices,
 						Op:    kubecost.FilterNotContains,
@@ -312,8 +313,8 @@ services!:"abc123"
 		},
 		{
 			input: `namespace:"__unallocated__"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterNamespace,
 						Op:    kubecost.FilterEquals,
@@ -330,8 +331,8 @@ services!:"abc123"
 		},
 		{
 			input: `namespace!:"__unallocated__"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterNamespace,
 						Op:    kubecost.FilterNotEquals,
@@ -348,8 +349,8 @@ services!:"abc123"
 		},
 		{
 			input: `controllerKind:"__unallocated__"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterControllerKind,
 						Op:    kubecost.FilterEquals,
@@ -366,8 +367,8 @@ services!:"abc123"
 		},
 		{
 			input: `controllerKind!:"__unallocated__"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterControllerKind,
 						Op:    kubecost.FilterNotEquals,
@@ -384,8 +385,8 @@ services!:"abc123"
 		},
 		{
 			input: `label[app]:"__unallocated__"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterLabel,
 						Key:   "app",
@@ -403,8 +404,8 @@ services!:"abc123"
 		},
 		{
 			input: `label[app]!:"__unallocated__"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterLabel,
 						Key:   "app",
@@ -422,8 +423,8 @@ services!:"abc123"
 		},
 		{
 			input: `services:"__unallocated__"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterOr{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterServices,
 						Op:    kubecost.FilterContains,
@@ -440,8 +441,8 @@ services!:"abc123"
 		},
 		{
 			input: `services!:"__unallocated__"`,
-			expected: kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
-				kubecost.AllocationFilterAnd{[]kubecost.AllocationFilter{
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
 					kubecost.AllocationFilterCondition{
 						Field: kubecost.FilterServices,
 						Op:    kubecost.FilterNotContains,
@@ -456,6 +457,92 @@ services!:"abc123"
 				allocGenerator(kubecost.AllocationProperties{Services: []string{}}),
 			},
 		},
+		{
+			input: `namespace:"kube-system" + label[team]:"a","b" | controllerKind!:"job"`,
+			expected: kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+					kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
+						kubecost.AllocationFilterCondition{
+							Field: kubecost.FilterNamespace,
+							Op:    kubecost.FilterEquals,
+							Value: "kube-system",
+						},
+					}},
+					kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
+						kubecost.AllocationFilterCondition{
+							Field: kubecost.FilterLabel,
+							Key:   "team",
+							Op:    kubecost.FilterEquals,
+							Value: "a",
+						},
+						kubecost.AllocationFilterCondition{
+							Field: kubecost.FilterLabel,
+							Key:   "team",
+							Op:    kubecost.FilterEquals,
+							Value: "b",
+						},
+					}},
+				}},
+				kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+					kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+						kubecost.AllocationFilterCondition{
+							Field: kubecost.FilterControllerKind,
+							Op:    kubecost.FilterNotEquals,
+							Value: "job",
+						},
+					}},
+				}},
+			}},
+			shouldMatch: []kubecost.Allocation{
+				allocGenerator(kubecost.AllocationProperties{Namespace: "kube-system", ControllerKind: "job", Labels: map[string]string{"team": "a"}}),
+				allocGenerator(kubecost.AllocationProperties{Namespace: "kubecost", ControllerKind: "deployment"}),
+			},
+			shouldNotMatch: []kubecost.Allocation{
+				allocGenerator(kubecost.AllocationProperties{Namespace: "kube-system", ControllerKind: "job", Labels: map[string]string{"team": "c"}}),
+				allocGenerator(kubecost.AllocationProperties{Namespace: "kubecost", ControllerKind: "job", Labels: map[string]string{"team": "a"}}),
+			},
+		},
+		{
+			input: `namespace:"kube-system" + (label[team]:"a" | controllerKind!:"job")`,
+			expected: kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
+					kubecost.AllocationFilterCondition{
+						Field: kubecost.FilterNamespace,
+						Op:    kubecost.FilterEquals,
+						Value: "kube-system",
+					},
+				}},
+				kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
+					kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+						kubecost.AllocationFilterOr{Filters: []kubecost.AllocationFilter{
+							kubecost.AllocationFilterCondition{
+								Field: kubecost.FilterLabel,
+								Key:   "team",
+								Op:    kubecost.FilterEquals,
+								Value: "a",
+							},
+						}},
+					}},
+					kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+						kubecost.AllocationFilterAnd{Filters: []kubecost.AllocationFilter{
+							kubecost.AllocationFilterCondition{
+								Field: kubecost.FilterControllerKind,
+								Op:    kubecost.FilterNotEquals,
+								Value: "job",
+							},
+						}},
+					}},
+				}},
+			}},
+			shouldMatch: []kubecost.Allocation{
+				allocGenerator(kubecost.AllocationProperties{Namespace: "kube-system", ControllerKind: "job", Labels: map[string]string{"team": "a"}}),
+				allocGenerator(kubecost.AllocationProperties{Namespace: "kube-system", ControllerKind: "deployment"}),
+			},
+			shouldNotMatch: []kubecost.Allocation{
+				allocGenerator(kubecost.AllocationProperties{Namespace: "kube-system", ControllerKind: "job"}),
+				allocGenerator(kubecost.AllocationProperties{Namespace: "kubecost", ControllerKind: "deployment"}),
+			},
+		},
 	}
 
 	for i, c := range cases {
@@ -483,3 +570,56 @@ services!:"abc123"
 		})
 	}
 }
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]struct {
+		input    string
+		expected []string
+	}{
+		"empty": {
+			input:    ``,
+			expected: []string{"at end: expect filter field"},
+		},
+		"missing value": {
+			input:    `namespace:`,
+			expected: []string{"at end: expect string as filter value"},
+		},
+		"unknown field": {
+			input:    `namespaces:"kubecost"`,
+			expected: []string{"at 'namespaces': expect filter field"},
+		},
+		"missing operator between comparisons": {
+			input:    `namespace:"kubecost" node:"node1"`,
+			expected: []string{"at 'node': expect '+', '|' or end of filter"},
+		},
+		"unclosed paren": {
+			input:    `(namespace:"kubecost" | node:"node1"`,
+			expected: []string{"at end: expect ')' to close '('"},
+		},
+		"unmatched paren": {
+			input:    `namespace:"kubecost")`,
+			expected: []string{"at ')': expect '+', '|' or end of filter"},
+		},
+		"multiple errors": {
+			input: `namespace:"kubecost" + node + cluster!:"a" | pod:`,
+			expected: []string{
+				"at '+': expect filter op like ':' or '!:'",
+				"at end: expect string as filter value",
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseAllocationFilter(c.input)
+			if err == nil {
+				t.Fatalf("expected error for '%s'", c.input)
+			}
+			for _, expected := range c.expected {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error containing %q; got %q", expected, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package assetfilterutil
+
+import (
+	"strings"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/mapper"
+)
+
+// ============================================================================
+// This file contains:
+// Parsing (HTTP query params -> []AssetMatchFunc) for V1 of filters
+//
+// e.g. "filterClusters=cluster-one&filterTypes=node,disk"
+// ============================================================================
+
+// parseWildcardEnd checks if the given filter value is wildcarded, meaning
+// it ends in "*". If it does, it removes the suffix and returns the cleaned
+// string and true. Otherwise, it returns the same filter and false.
+//
+// parseWildcardEnd("kube*") = "kube", true
+// parseWildcardEnd("kube") = "kube", false
+func parseWildcardEnd(rawFilterValue string) (string, bool) {
+	return strings.TrimSuffix(rawFilterValue, "*"), strings.HasSuffix(rawFilterValue, "*")
+}
+
+// AssetFilterFuncsFromParamsV1 takes a set of HTTP query parameters and
+// converts them to a list of AssetMatchFuncs. An Asset passes the filters if
+// it matches every returned function.
+//
+// The HTTP query parameters are the "v1" filters attached to the Assets API:
+// "filterClusters=", "filterTypes=", etc. Values within a parameter are
+// combined with OR and may end in "*" to match by prefix. Parameters are
+// combined with AND.
+func AssetFilterFuncsFromParamsV1(qp mapper.PrimitiveMapReader) []kubecost.AssetMatchFunc {
+	filterFuncs := []kubecost.AssetMatchFunc{}
+
+	propertyFilters := []struct {
+		param string
+		value func(*kubecost.AssetProperties) string
+	}{
+		{"filterAccounts", func(p *kubecost.AssetProperties) string { return p.Account }},
+		{"filterCategories", func(p *kubecost.AssetProperties) string { return p.Category }},
+		{"filterClusters", func(p *kubecost.AssetProperties) string { return p.Cluster }},
+		{"filterNames", func(p *kubecost.AssetProperties) string { return p.Name }},
+		{"filterProjects", func(p *kubecost.AssetProperties) string { return p.Project }},
+		{"filterProviders", func(p *kubecost.AssetProperties) string { return p.Provider }},
+		{"filterProviderIDs", func(p *kubecost.AssetProperties) string { return p.ProviderID }},
+		{"filterServices", func(p *kubecost.AssetProperties) string { return p.Service }},
+	}
+
+	for _, pf := range propertyFilters {
+		raw := qp.GetList(pf.param, ",")
+		if len(raw) == 0 {
+			continue
+		}
+
+		value := pf.value
+		filterFuncs = append(filterFuncs, func(a kubecost.Asset) bool {
+			props := a.GetProperties()
+			if props == nil {
+				return false
+			}
+			return matchesAny(value(props), raw)
+		})
+	}
+
+	if raw := qp.GetList("filterTypes", ","); len(raw) > 0 {
+		types := map[kubecost.AssetType]bool{}
+		for _, rawFilterValue := range raw {
+			at, err := kubecost.ParseAssetType(rawFilterValue)
+			if err != nil {
+				log.Warnf("illegal filter for asset type: %s", rawFilterValue)
+				continue
+			}
+			types[at] = true
+		}
+
+		filterFuncs = append(filterFuncs, func(a kubecost.Asset) bool {
+			return types[a.Type()]
+		})
+	}
+
+	// filterLabels= accepts label:value filters, e.g. "app:cost-analyzer"
+	if raw := qp.GetList("filterLabels", ","); len(raw) > 0 {
+		type labelFilter struct {
+			name  string
+			value string
+		}
+
+		labelFilters := []labelFilter{}
+		for _, rawFilterValue := range raw {
+			split := strings.SplitN(strings.TrimSpace(rawFilterValue), ":", 2)
+			if len(split) != 2 {
+				log.Warnf("illegal filter for label: %s", rawFilterValue)
+				continue
+			}
+			labelFilters = append(labelFilters, labelFilter{
+				name:  strings.TrimSpace(split[0]),
+				value: strings.TrimSpace(split[1]),
+			})
+		}
+
+		filterFuncs = append(filterFuncs, func(a kubecost.Asset) bool {
+			labels := a.GetLabels()
+			for _, lf := range labelFilters {
+				if value, ok := labels[lf.name]; ok && matchesAny(value, []string{lf.value}) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+
+	return filterFuncs
+}
+
+// matchesAny returns true if the given value is equal to any of the raw filter
+// values, or starts with any wildcarded raw filter value.
+func matchesAny(value string, rawFilterValues []string) bool {
+	for _, rawFilterValue := range rawFilterValues {
+		filterValue, wildcard := parseWildcardEnd(strings.TrimSpace(rawFilterValue))
+		if wildcard && strings.HasPrefix(value, filterValue) {
+			return true
+		}
+		if !wildcard && value == filterValue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package assetfilterutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/util/mapper"
+)
+
+func TestAssetFilterFuncsFromParamsV1(t *testing.T) {
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	window := kubecost.NewClosedWindow(start, end)
+
+	node := kubecost.NewNode("node-one", "cluster-one", "id-one", start, end, window)
+	node.Properties.Provider = kubecost.GCPProvider
+	node.SetLabels(kubecost.AssetLabels{"app": "web"})
+
+	disk := kubecost.NewDisk("pv-one", "cluster-two", "id-two", start, end, window)
+	disk.Properties.Provider = kubecost.AWSProvider
+
+	lb := kubecost.NewLoadBalancer("default/ingress", "cluster-one", "id-three", start, end, window)
+
+	cases := []struct {
+		name           string
+		qp             map[string]string
+		shouldMatch    []kubecost.Asset
+		shouldNotMatch []kubecost.Asset
+	}{
+		{
+			name:        "no filters",
+			qp:          map[string]string{},
+			shouldMatch: []kubecost.Asset{node, disk, lb},
+		},
+		{
+			name: "single cluster",
+			qp: map[string]string{
+				"filterClusters": "cluster-one",
+			},
+			shouldMatch:    []kubecost.Asset{node, lb},
+			shouldNotMatch: []kubecost.Asset{disk},
+		},
+		{
+			name: "wildcard name",
+			qp: map[string]string{
+				"filterNames": "node*,pv*",
+			},
+			shouldMatch:    []kubecost.Asset{node, disk},
+			shouldNotMatch: []kubecost.Asset{lb},
+		},
+		{
+			name: "types",
+			qp: map[string]string{
+				"filterTypes": "disk,loadbalancer",
+			},
+			shouldMatch:    []kubecost.Asset{disk, lb},
+			shouldNotMatch: []kubecost.Asset{node},
+		},
+		{
+			name: "category",
+			qp: map[string]string{
+				"filterCategories": kubecost.StorageCategory,
+			},
+			shouldMatch:    []kubecost.Asset{disk},
+			shouldNotMatch: []kubecost.Asset{node, lb},
+		},
+		{
+			name: "provider",
+			qp: map[string]string{
+				"filterProviders": kubecost.AWSProvider,
+			},
+			shouldMatch:    []kubecost.Asset{disk},
+			shouldNotMatch: []kubecost.Asset{node, lb},
+		},
+		{
+			name: "label",
+			qp: map[string]string{
+				"filterLabels": "app:web",
+			},
+			shouldMatch:    []kubecost.Asset{node},
+			shouldNotMatch: []kubecost.Asset{disk, lb},
+		},
+		{
+			name: "cluster and type",
+			qp: map[string]string{
+				"filterClusters": "cluster-one",
+				"filterTypes":    "node",
+			},
+			shouldMatch:    []kubecost.Asset{node},
+			shouldNotMatch: []kubecost.Asset{disk, lb},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			qpMap := mapper.NewMap()
+			for k, v := range c.qp {
+				qpMap.Set(k, v)
+			}
+			filterFuncs := AssetFilterFuncsFromParamsV1(mapper.NewMapper(qpMap))
+
+			matches := func(a kubecost.Asset) bool {
+				for _, ff := range filterFuncs {
+					if !ff(a) {
+						return false
+					}
+				}
+				return true
+			}
+
+			for _, a := range c.shouldMatch {
+				if !matches(a) {
+					t.Errorf("should have matched: %s", a.GetProperties().Name)
+				}
+			}
+			for _, a := range c.shouldNotMatch {
+				if matches(a) {
+					t.Errorf("incorrectly matched: %s", a.GetProperties().Name)
+				}
+			}
+		})
+	}
+}
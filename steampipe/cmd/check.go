@@ -15,8 +15,11 @@ import (
 	"github.com/turbot/steampipe/pkg/constants"
 	"github.com/turbot/steampipe/pkg/contexthelpers"
 	"github.com/turbot/steampipe/pkg/control"
+	"github.com/turbot/steampipe/pkg/control/controldiff"
 	"github.com/turbot/steampipe/pkg/control/controldisplay"
 	"github.com/turbot/steampipe/pkg/control/controlexecute"
+	"github.com/turbot/steampipe/pkg/control/controlhistory"
+	"github.com/turbot/steampipe/pkg/control/controlnotify"
 	"github.com/turbot/steampipe/pkg/control/controlstatus"
 	"github.com/turbot/steampipe/pkg/display"
 	"github.com/turbot/steampipe/pkg/error_helpers"
@@ -58,12 +61,12 @@ You may specify one or more benchmarks or controls to run (separated by a space)
 		AddBoolFlag(constants.ArgHeader, true, "Include column headers for csv and table output").
 		AddBoolFlag(constants.ArgHelp, false, "Help for check", cmdconfig.FlagOptions.WithShortHand("h")).
 		AddStringFlag(constants.ArgSeparator, ",", "Separator string for csv output").
-		AddStringFlag(constants.ArgOutput, constants.OutputFormatText, "Output format: brief, csv, html, json, md, text, snapshot or none").
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatText, "Output format: brief, csv, html, json, md, text, snapshot, sarif, junit or none").
 		AddBoolFlag(constants.ArgTiming, false, "Turn on the timer which reports check time").
 		AddStringSliceFlag(constants.ArgSearchPath, nil, "Set a custom search_path for the steampipe user for a check session (comma-separated)").
 		AddStringSliceFlag(constants.ArgSearchPathPrefix, nil, "Set a prefix to the current search path for a check session (comma-separated)").
 		AddStringFlag(constants.ArgTheme, "dark", "Set the output theme for 'text' output: light, dark or plain").
-		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: csv, html, json, md, nunit3, sps (snapshot), asff").
+		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: csv, html, json, md, nunit3, sps (snapshot), snapshot_html, asff, sarif, junit").
 		AddBoolFlag(constants.ArgProgress, true, "Display control execution progress").
 		AddBoolFlag(constants.ArgDryRun, false, "Show which controls will be run without running them").
 		AddStringSliceFlag(constants.ArgTag, nil, "Filter controls based on their tag values ('--tag key=value')").
@@ -80,10 +83,22 @@ You may specify one or more benchmarks or controls to run (separated by a space)
 		AddBoolFlag(constants.ArgSnapshot, false, "Create snapshot in Steampipe Cloud with the default (workspace) visibility").
 		AddBoolFlag(constants.ArgShare, false, "Create snapshot in Steampipe Cloud with 'anyone_with_link' visibility").
 		AddStringArrayFlag(constants.ArgSnapshotTag, nil, "Specify tags to set on the snapshot").
-		AddStringFlag(constants.ArgSnapshotLocation, "", "The location to write snapshots - either a local file path or a Steampipe Cloud workspace").
-		AddStringFlag(constants.ArgSnapshotTitle, "", "The title to give a snapshot")
+		AddStringFlag(constants.ArgSnapshotLocation, "", "The location to write snapshots - either a local directory, an S3 url (s3://bucket/prefix) or a Steampipe Cloud workspace").
+		AddStringFlag(constants.ArgSnapshotS3Endpoint, "", "The endpoint of an S3 compatible service to write snapshots to, e.g. http://localhost:9000").
+		AddIntFlag(constants.ArgSnapshotRetainCount, 0, "The number of snapshots of each resource to keep in a directory or S3 snapshot location (0 keeps all)").
+		AddStringFlag(constants.ArgSnapshotRetainAge, "", "The maximum age of snapshots to keep in a directory or S3 snapshot location, e.g. 30d, 12h").
+		AddStringFlag(constants.ArgSnapshotTitle, "", "The title to give a snapshot").
+		AddStringFlag(constants.ArgCompareTo, "", "Compare the results with a previous run (a snapshot or json export) and report the differences").
+		AddStringSliceFlag(constants.ArgNotify, nil, "Send a notification of the results to the given notifiers (defined in the workspace profile)").
+		AddFloat64Flag(constants.ArgMinScore, 0, "Exit with a non-zero code if the compliance score of any benchmark is below this percentage (0-100)").
+		AddStringFlag(constants.ArgControlTimeout, "", "The maximum duration of each control run attempt, e.g. 30s or 5m (overrides the control and benchmark 'timeout')").
+		AddIntFlag(constants.ArgControlRetries, constants.DefaultControlRunRetries, "The number of times to retry a control which fails with a retryable error (overrides the control and benchmark 'retry')").
+		AddStringSliceFlag(constants.ArgControlRetryOn, controlexecute.DefaultRetryErrorClasses, fmt.Sprintf("The classes of error which cause a control to be retried: %s", strings.Join(controlexecute.RetryErrorClasses, ", "))).
+		AddBoolFlag(constants.ArgCheckHistory, false, fmt.Sprintf("Record the results in the local database history tables (%s.%s and %s.%s)", constants.InternalSchema, constants.InternalTableCheckRuns, constants.InternalSchema, constants.InternalTableCheckResults)).
+		AddStringFlag(constants.ArgHistoryRetention, constants.DefaultCheckHistoryRetention, "The maximum age of check runs to keep in the history tables, e.g. 90d, 12h (empty keeps all runs)")
 
 	cmd.AddCommand(getListSubCmd(listSubCmdOptions{parentCmd: cmd}))
+	cmd.AddCommand(getCheckDiffCmd())
 	return cmd
 }
 
@@ -118,6 +133,19 @@ func runCheckCmd(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	// if we are comparing with a previous run, load it now so we fail fast if it is invalid
+	var previousResults, currentResults *controldiff.ResultSet
+	if compareTo := viper.GetString(constants.ArgCompareTo); compareTo != "" {
+		var err error
+		previousResults, err = controldiff.LoadResultSet(compareTo)
+		error_helpers.FailOnError(err)
+		currentResults = controldiff.NewResultSet("current run")
+	}
+
+	// resolve the notifiers now so we fail fast if any are not defined
+	notifiers, err := controlnotify.GetNotifiers()
+	error_helpers.FailOnError(err)
+
 	// initialise
 	initData := control.NewInitData(ctx)
 	error_helpers.FailOnError(initData.Result.Error)
@@ -126,10 +154,17 @@ func runCheckCmd(cmd *cobra.Command, args []string) {
 	// if there is a usage warning we display it
 	initData.Result.DisplayMessages()
 
+	// if the results are being recorded, ensure the history tables exist
+	recordHistory := controlhistory.Enabled()
+	if recordHistory {
+		error_helpers.FailOnError(controlhistory.Init(ctx))
+	}
+
 	// pull out useful properties
 	w := initData.Workspace
 	client := initData.Client
 	failures := 0
+	belowMinScore := false
 	var durations []time.Duration
 
 	shouldShare := viper.GetBool(constants.ArgShare)
@@ -168,12 +203,61 @@ func runCheckCmd(cmd *cobra.Command, args []string) {
 			error_helpers.FailOnError(err)
 		}
 
+		// send notifications - a failure to notify is reported but does not fail the run
+		if len(notifiers) > 0 {
+			if err := controlnotify.Notify(ctx, executionTree, exportName, notifiers); err != nil {
+				error_helpers.ShowWarning(fmt.Sprintf("failed to send notifications: %s", err.Error()))
+			}
+		}
+
+		// a failure to record the results is reported but does not fail the run
+		if recordHistory && !utils.IsContextCancelled(ctx) {
+			if err := controlhistory.Record(ctx, client, executionTree, exportName); err != nil {
+				error_helpers.ShowWarning(err.Error())
+			}
+		}
+
+		if currentResults != nil {
+			currentResults.AddExecutionTree(executionTree)
+		}
+
+		if !meetsMinScore(executionTree) {
+			belowMinScore = true
+		}
+
 		durations = append(durations, executionTree.EndTime.Sub(executionTree.StartTime))
 	}
 
 	if shouldPrintTiming() {
 		printTiming(args, durations)
 	}
+
+	if previousResults != nil && !utils.IsContextCancelled(ctx) {
+		diff := controldiff.Diff(previousResults, currentResults)
+		if shouldPrintDiff() {
+			// blank line after renderer output
+			fmt.Println()
+			error_helpers.FailOnError(diff.Render(os.Stdout, constants.OutputFormatText))
+		}
+		if diff.HasRegressions() {
+			exitCode = constants.ExitCodeCheckRegressions
+		}
+	}
+
+	if belowMinScore && exitCode == constants.ExitCodeSuccessful {
+		exitCode = constants.ExitCodeCheckScoreBelowMinimum
+	}
+}
+
+// do the compliance scores of every benchmark of the execution tree meet the '--min-score' threshold
+// if no controls were scored (e.g. all results were skipped) the threshold is met
+func meetsMinScore(executionTree *controlexecute.ExecutionTree) bool {
+	minScore := viper.GetFloat64(constants.ArgMinScore)
+	score := executionTree.Root.LowestScore()
+	if minScore <= 0 || !score.Scored() {
+		return true
+	}
+	return score.Percentage >= minScore
 }
 
 // create the context for the check run - add a control status renderer
@@ -208,6 +292,30 @@ func validateCheckArgs(ctx context.Context, cmd *cobra.Command, args []string) b
 		return false
 	}
 
+	if minScore := viper.GetFloat64(constants.ArgMinScore); minScore < 0 || minScore > 100 {
+		error_helpers.ShowError(ctx, fmt.Errorf("'--%s' must be a percentage between 0 and 100", constants.ArgMinScore))
+		return false
+	}
+
+	if viper.IsSet(constants.ArgControlTimeout) {
+		if timeout, err := time.ParseDuration(viper.GetString(constants.ArgControlTimeout)); err != nil || timeout <= 0 {
+			error_helpers.ShowError(ctx, fmt.Errorf("'--%s' must be a positive duration, e.g. 30s or 5m", constants.ArgControlTimeout))
+			return false
+		}
+	}
+	if viper.GetInt(constants.ArgControlRetries) < 0 {
+		error_helpers.ShowError(ctx, fmt.Errorf("'--%s' must be zero or greater", constants.ArgControlRetries))
+		return false
+	}
+	if _, err := utils.ParseRetentionAge(viper.GetString(constants.ArgHistoryRetention)); err != nil {
+		error_helpers.ShowError(ctx, fmt.Errorf("invalid '--%s': %s", constants.ArgHistoryRetention, err.Error()))
+		return false
+	}
+	if err := controlexecute.ValidateRetryErrorClasses(viper.GetStringSlice(constants.ArgControlRetryOn)); err != nil {
+		error_helpers.ShowError(ctx, fmt.Errorf("'--%s' has an %s", constants.ArgControlRetryOn, err.Error()))
+		return false
+	}
+
 	// if both '--where' and '--tag' have been used, then it's an error
 	if viper.IsSet(constants.ArgWhere) && viper.IsSet(constants.ArgTag) {
 		error_helpers.ShowError(ctx, fmt.Errorf("only 1 of '--%s' and '--%s' may be set", constants.ArgWhere, constants.ArgTag))
@@ -236,6 +344,12 @@ func shouldPrintTiming() bool {
 		(outputFormat == constants.OutputFormatText || outputFormat == constants.OutputFormatBrief)
 }
 
+// only display the diff for human readable output formats - for other formats, the diff is reflected in the exit code
+func shouldPrintDiff() bool {
+	outputFormat := viper.GetString(constants.ArgOutput)
+	return outputFormat == constants.OutputFormatText || outputFormat == constants.OutputFormatBrief
+}
+
 func displayControlResults(ctx context.Context, executionTree *controlexecute.ExecutionTree, formatter controldisplay.Formatter) error {
 	reader, err := formatter.Format(ctx, executionTree)
 	if err != nil {
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/steampipe/pkg/cmdconfig"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/control/controldiff"
+	"github.com/turbot/steampipe/pkg/error_helpers"
+)
+
+func getCheckDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:              "diff <old> <new>",
+		TraverseChildren: true,
+		Args:             cobra.ExactArgs(2),
+		Run:              runCheckDiffCmd,
+		Short:            "Compare the results of two check runs",
+		Long: `Compare the results of two check runs.
+
+Each run may be either a snapshot (.sps) or a json check export. Results are matched
+by control, resource and dimensions, and are reported as newly failing, newly passing,
+status changed or disappeared.
+
+The exit code is only non-zero if there are newly failing (alarm or error) results.
+
+Examples:
+
+  # Compare last night's benchmark run with tonight's
+  steampipe check diff yesterday.sps today.sps
+
+  # Output the differences as markdown
+  steampipe check diff yesterday.json today.json --output md`,
+	}
+
+	cmdconfig.
+		OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for check diff", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatText, "Output format: text, json or md")
+
+	return cmd
+}
+
+func runCheckDiffCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	defer func() {
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	outputFormat := viper.GetString(constants.ArgOutput)
+	if !helpers.StringSliceContains(controldiff.OutputFormats, outputFormat) {
+		error_helpers.ShowError(ctx, fmt.Errorf("invalid output format '%s' - must be one of: text, json, md", outputFormat))
+		exitCode = constants.ExitCodeInsufficientOrWrongArguments
+		return
+	}
+
+	oldResults, err := controldiff.LoadResultSet(args[0])
+	error_helpers.FailOnError(err)
+	newResults, err := controldiff.LoadResultSet(args[1])
+	error_helpers.FailOnError(err)
+
+	diff := controldiff.Diff(oldResults, newResults)
+	error_helpers.FailOnError(diff.Render(os.Stdout, outputFormat))
+
+	if diff.HasRegressions() {
+		exitCode = constants.ExitCodeCheckRegressions
+	}
+}
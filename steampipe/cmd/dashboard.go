@@ -47,6 +47,12 @@ The current mod is the working directory, or the directory specified by the --mo
 		AddBoolFlag(constants.ArgModInstall, true, "Specify whether to install mod dependencies before running the dashboard").
 		AddStringFlag(constants.ArgDashboardListen, string(dashboardserver.ListenTypeLocal), "Accept connections from: local (localhost only) or network (open)").
 		AddIntFlag(constants.ArgDashboardPort, constants.DashboardServerDefaultPort, "Dashboard server port").
+		AddStringFlag(constants.ArgDashboardAuth, string(dashboardserver.AuthTypeNone), "Dashboard server authentication: none, token, basic or header (trusted reverse proxy)").
+		AddStringFlag(constants.ArgDashboardAuthToken, "", "The token required by token authentication").
+		AddStringFlag(constants.ArgDashboardAuthUsers, "", "The users file for basic authentication, with lines of the form <user>:<bcrypt hash>[:<groups>]").
+		AddStringFlag(constants.ArgDashboardAuthHeader, constants.DashboardAuthDefaultUserHeader, "The request header containing the user name for header authentication").
+		AddStringFlag(constants.ArgDashboardAuthGroups, constants.DashboardAuthDefaultGroupsHeader, "The request header containing the (comma-separated) user groups for header authentication").
+		AddStringFlag(constants.ArgDashboardAccess, "", "A file of access rules restricting the dashboards and benchmarks each user or group may view").
 		AddBoolFlag(constants.ArgBrowser, true, "Specify whether to launch the browser after starting the dashboard server").
 		AddStringSliceFlag(constants.ArgSearchPath, nil, "Set a custom search_path for the steampipe user for a dashboard session (comma-separated)").
 		AddStringSliceFlag(constants.ArgSearchPathPrefix, nil, "Set a prefix to the current search path for a dashboard session (comma-separated)").
@@ -60,13 +66,16 @@ The current mod is the working directory, or the directory specified by the --mo
 		AddStringFlag(constants.ArgOutput, constants.OutputFormatNone, "Select a console output format: none, snapshot").
 		AddBoolFlag(constants.ArgSnapshot, false, "Create snapshot in Steampipe Cloud with the default (workspace) visibility").
 		AddBoolFlag(constants.ArgShare, false, "Create snapshot in Steampipe Cloud with 'anyone_with_link' visibility").
-		AddStringFlag(constants.ArgSnapshotLocation, "", "The location to write snapshots - either a local file path or a Steampipe Cloud workspace").
+		AddStringFlag(constants.ArgSnapshotLocation, "", "The location to write snapshots - either a local directory, an S3 url (s3://bucket/prefix) or a Steampipe Cloud workspace").
+		AddStringFlag(constants.ArgSnapshotS3Endpoint, "", "The endpoint of an S3 compatible service to write snapshots to, e.g. http://localhost:9000").
+		AddIntFlag(constants.ArgSnapshotRetainCount, 0, "The number of snapshots of each resource to keep in a directory or S3 snapshot location (0 keeps all)").
+		AddStringFlag(constants.ArgSnapshotRetainAge, "", "The maximum age of snapshots to keep in a directory or S3 snapshot location, e.g. 30d, 12h").
 		AddStringFlag(constants.ArgSnapshotTitle, "", "The title to give a snapshot").
 		// NOTE: use StringArrayFlag for ArgDashboardInput, not StringSliceFlag
 		// Cobra will interpret values passed to a StringSliceFlag as CSV, where args passed to StringArrayFlag are not parsed and used raw
 		AddStringArrayFlag(constants.ArgDashboardInput, nil, "Specify the value of a dashboard input").
 		AddStringArrayFlag(constants.ArgSnapshotTag, nil, "Specify tags to set on the snapshot").
-		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported format: sps (snapshot)").
+		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: sps (snapshot), html (snapshot_html)").
 		// hidden flags that are used internally
 		AddBoolFlag(constants.ArgServiceMode, false, "Hidden flag to specify whether this is starting as a service", cmdconfig.FlagOptions.Hidden())
 
@@ -121,6 +130,12 @@ func runDashboardCmd(cmd *cobra.Command, args []string) {
 	serverListen := dashboardserver.ListenType(viper.GetString(constants.ArgDashboardListen))
 	error_helpers.FailOnError(serverListen.IsValid())
 
+	authType := dashboardserver.AuthType(viper.GetString(constants.ArgDashboardAuth))
+	error_helpers.FailOnError(authType.IsValid())
+	if serverListen == dashboardserver.ListenTypeNetwork && authType == dashboardserver.AuthTypeNone {
+		error_helpers.ShowWarning(fmt.Sprintf("the dashboard server is accepting network connections without authentication - use --%s to require authentication", constants.ArgDashboardAuth))
+	}
+
 	if err := utils.IsPortBindable(int(serverPort)); err != nil {
 		exitCode = constants.ExitCodeBindPortUnavailable
 		error_helpers.FailOnError(err)
@@ -257,7 +272,11 @@ func getInitData(ctx context.Context) *initialisation.InitData {
 }
 
 func dashboardExporters() []export.Exporter {
-	return []export.Exporter{&export.SnapshotExporter{}}
+	return []export.Exporter{
+		&export.SnapshotExporter{},
+		// the dashboard command has no other html format, so this is also the 'html' format
+		&export.SnapshotHTMLExporter{FormatAlias: constants.OutputFormatHTML},
+	}
 }
 
 func runSingleDashboard(ctx context.Context, targetName string, inputs map[string]interface{}) error {
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/steampipe/pkg/cmdconfig"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/control"
+	"github.com/turbot/steampipe/pkg/dashboard/dashboardserver"
+	"github.com/turbot/steampipe/pkg/error_helpers"
+	"github.com/turbot/steampipe/pkg/metrics"
+	"github.com/turbot/steampipe/pkg/statushooks"
+	"github.com/turbot/steampipe/pkg/utils"
+	"github.com/turbot/steampipe/pkg/workspace"
+)
+
+func exporterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:              "exporter [flags] [query/benchmark/control...]",
+		TraverseChildren: true,
+		Args:             cobra.ArbitraryArgs,
+		Run:              runExporterCmd,
+		Short:            "Expose query and control results as Prometheus metrics",
+		Long: `Run queries, benchmarks and controls on an interval and expose the results as Prometheus metrics.
+
+Numeric columns of query results are exported as gauges named 'steampipe_query_<column>', labelled with
+the query name and the values of the other columns. The number of control results with each status is
+exported for each benchmark and control.
+
+If no queries, benchmarks or controls are specified, the targets of the schedules defined in the current
+mod are run.
+
+Metrics are served on http://localhost:9195/metrics by default.`,
+	}
+
+	cmdconfig.
+		OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for exporter", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddStringFlag(constants.ArgMetricsListen, string(dashboardserver.ListenTypeLocal), "Accept connections from: local (localhost only) or network (open)").
+		AddIntFlag(constants.ArgMetricsPort, constants.MetricsServerDefaultPort, "Metrics server port").
+		AddStringFlag(constants.ArgMetricsInterval, constants.MetricsDefaultInterval, "The interval between runs, e.g. 30s, 5m").
+		AddBoolFlag(constants.ArgModInstall, true, "Specify whether to install mod dependencies before running").
+		AddIntFlag(constants.ArgDatabaseQueryTimeout, constants.DatabaseDefaultCheckQueryTimeout, "The query timeout").
+		AddIntFlag(constants.ArgMaxParallel, constants.DefaultMaxConnections, "The maximum number of concurrent database connections to open").
+		AddStringSliceFlag(constants.ArgVarFile, nil, "Specify an .spvar file containing variable values").
+		// NOTE: use StringArrayFlag for ArgVariable, not StringSliceFlag
+		// Cobra will interpret values passed to a StringSliceFlag as CSV,
+		// where args passed to StringArrayFlag are not parsed and used raw
+		AddStringArrayFlag(constants.ArgVariable, nil, "Specify the value of a variable").
+		AddBoolFlag(constants.ArgInput, true, "Enable interactive prompts").
+		// hidden flags used by the control initialisation
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatNone, "Output format", cmdconfig.FlagOptions.Hidden()).
+		AddStringFlag(constants.ArgTheme, "plain", "Output theme", cmdconfig.FlagOptions.Hidden()).
+		AddBoolFlag(constants.ArgProgress, false, "Display control execution progress", cmdconfig.FlagOptions.Hidden())
+
+	return cmd
+}
+
+func runExporterCmd(cmd *cobra.Command, args []string) {
+	utils.LogTime("runExporterCmd start")
+
+	ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	defer func() {
+		utils.LogTime("runExporterCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	// validate the server params before initialising
+	interval, err := time.ParseDuration(viper.GetString(constants.ArgMetricsInterval))
+	if err != nil {
+		exitCode = constants.ExitCodeInsufficientOrWrongArguments
+		error_helpers.FailOnErrorWithMessage(err, "invalid metrics interval")
+	}
+	serverPort := dashboardserver.ListenPort(viper.GetInt(constants.ArgMetricsPort))
+	error_helpers.FailOnError(serverPort.IsValid())
+	serverListen := dashboardserver.ListenType(viper.GetString(constants.ArgMetricsListen))
+	error_helpers.FailOnError(serverListen.IsValid())
+	if err := utils.IsPortBindable(int(serverPort)); err != nil {
+		exitCode = constants.ExitCodeBindPortUnavailable
+		error_helpers.FailOnError(err)
+	}
+
+	// initialise - this loads the workspace and creates the database client used for all runs
+	initData := control.NewInitData(ctx)
+	error_helpers.FailOnError(initData.Result.Error)
+	defer initData.Cleanup(ctx)
+	initData.Result.DisplayMessages()
+
+	targets := args
+	if len(targets) == 0 {
+		targets = scheduleTargets(initData.Workspace)
+		if len(targets) == 0 {
+			exitCode = constants.ExitCodeInsufficientOrWrongArguments
+			error_helpers.FailOnError(fmt.Errorf("no queries, benchmarks or controls specified, and no schedules are defined in the current mod"))
+		}
+	}
+	exporter, err := metrics.NewExporter(initData.Workspace, initData.Client, targets, interval)
+	error_helpers.FailOnError(err)
+
+	address := fmt.Sprintf(":%d", serverPort)
+	if serverListen == dashboardserver.ListenTypeLocal {
+		address = fmt.Sprintf("localhost:%d", serverPort)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter.Handler())
+	server := &http.Server{Addr: address, Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[WARN] metrics server failed: %s", err.Error())
+			cancel()
+		}
+	}()
+
+	fmt.Printf("Exporting metrics for %d %s every %s on http://%s/metrics\n", len(targets), utils.Pluralize("target", len(targets)), interval, address)
+	fmt.Println("Hit Ctrl+C to stop the exporter")
+
+	// disable status messages for the runs
+	exporter.Run(statushooks.DisableStatusHooks(ctx))
+
+	// shutdown the server - this must not happen in the cancelled context
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	server.Shutdown(shutdownCtx)
+}
+
+// return the distinct targets of the schedules defined in the workspace
+func scheduleTargets(w *workspace.Workspace) []string {
+	var res []string
+	for _, schedule := range w.GetResourceMaps().Schedules {
+		if !helpers.StringSliceContains(res, schedule.TargetName) {
+			res = append(res, schedule.TargetName)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
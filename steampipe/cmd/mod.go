@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -11,10 +14,15 @@ import (
 	"github.com/turbot/steampipe/pkg/constants"
 	"github.com/turbot/steampipe/pkg/error_helpers"
 	"github.com/turbot/steampipe/pkg/filepaths"
+	"github.com/turbot/steampipe/pkg/initialisation"
 	"github.com/turbot/steampipe/pkg/modinstaller"
+	"github.com/turbot/steampipe/pkg/modlint"
+	"github.com/turbot/steampipe/pkg/modtest"
+	"github.com/turbot/steampipe/pkg/schema"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/parse"
 	"github.com/turbot/steampipe/pkg/utils"
+	"github.com/turbot/steampipe/pkg/workspace"
 )
 
 // mod management commands
@@ -45,6 +53,15 @@ Examples:
     
     # Uninstall a mod
     steampipe mod uninstall github.com/turbot/steampipe-mod-aws-compliance
+
+    # Run the tests defined in the current mod
+    steampipe mod test
+
+    # Vendor the dependencies of the current mod, so they can be installed with no network access
+    steampipe mod vendor
+
+    # Check the current mod for common mistakes
+    steampipe mod lint
 	`,
 	}
 
@@ -53,6 +70,9 @@ Examples:
 	cmd.AddCommand(modUpdateCmd())
 	cmd.AddCommand(modListCmd())
 	cmd.AddCommand(modInitCmd())
+	cmd.AddCommand(modTestCmd())
+	cmd.AddCommand(modVendorCmd())
+	cmd.AddCommand(modLintCmd())
 	cmd.Flags().BoolP(constants.ArgHelp, "h", false, "Help for mod")
 
 	return cmd
@@ -199,6 +219,59 @@ func runModListCmd(cmd *cobra.Command, _ []string) {
 	fmt.Println(treeString)
 }
 
+// vendor
+func modVendorCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "vendor",
+		Run:   runModVendorCmd,
+		Short: "Copy all mod dependencies into the workspace",
+		Long: `Copy all mod dependencies into the workspace.
+
+Installs any missing dependencies, then copies every dependency version in the lock file into
+the .steampipe/vendor folder of the workspace. 'steampipe mod install' installs vendored mods in
+preference to cloning them from Git, so a workspace containing its vendor folder can be installed
+with no network access.`,
+	}
+
+	cmdconfig.OnCmd(cmd).AddBoolFlag(constants.ArgHelp, false, "Help for vendor", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func runModVendorCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runModVendorCmd")
+	defer func() {
+		utils.LogTime("cmd.runModVendorCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	// first ensure all dependencies are installed
+	opts := newInstallOpts(cmd)
+	_, err := modinstaller.InstallWorkspaceDependencies(opts)
+	error_helpers.FailOnError(err)
+
+	vendored, err := modinstaller.VendorWorkspaceDependencies(opts.WorkspacePath)
+	error_helpers.FailOnError(err)
+
+	if len(vendored) == 0 {
+		fmt.Println("No dependencies to vendor.")
+		return
+	}
+	var modNames []string
+	for fullName := range vendored.FlatMap() {
+		modNames = append(modNames, fullName)
+	}
+	sort.Strings(modNames)
+	fmt.Printf("\nVendored %d %s to %s:\n", len(modNames), utils.Pluralize("mod", len(modNames)), filepaths.WorkspaceVendorPath(opts.WorkspacePath))
+	for _, modName := range modNames {
+		fmt.Printf("  %s\n", modName)
+	}
+	fmt.Println()
+}
+
 // init
 func modInitCmd() *cobra.Command {
 	var cmd = &cobra.Command{
@@ -233,6 +306,235 @@ func runModInitCmd(cmd *cobra.Command, args []string) {
 	fmt.Printf("Created mod definition file '%s'\n", filepaths.ModFilePath(workspacePath))
 }
 
+// test
+func modTestCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "test [flags] [test...]",
+		Run:   runModTestCmd,
+		Short: "Run the tests defined in the current mod",
+		Long: `Run the tests defined in the current mod.
+
+Each test loads its fixture data into temporary tables, runs its target benchmark,
+control or query against the fixtures and compares the results with the expected
+statuses or rows. No plugins or cloud credentials are required.
+
+Examples:
+
+  # Run all tests in the mod
+  steampipe mod test
+
+  # Run a single test, writing JUnit output
+  steampipe mod test test.s3_bucket_versioning --output junit`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddStringFlag(constants.ArgOutput, modtest.OutputFormatText, "Output format: text or junit").
+		AddStringSliceFlag(constants.ArgVarFile, nil, "Specify an .spvar file containing variable values").
+		// NOTE: use StringArrayFlag for ArgVariable, not StringSliceFlag
+		// Cobra will interpret values passed to a StringSliceFlag as CSV,
+		// where args passed to StringArrayFlag are not parsed and used raw
+		AddStringArrayFlag(constants.ArgVariable, nil, "Specify the value of a variable").
+		AddIntFlag(constants.ArgDatabaseQueryTimeout, constants.DatabaseDefaultCheckQueryTimeout, "The query timeout").
+		AddIntFlag(constants.ArgMaxParallel, constants.DefaultMaxConnections, "The maximum number of concurrent database connections to open").
+		AddBoolFlag(constants.ArgModInstall, false, "Specify whether to install mod dependencies before running the tests").
+		AddBoolFlag(constants.ArgInput, true, "Enable interactive prompts").
+		AddBoolFlag(constants.ArgHelp, false, "Help for test", cmdconfig.FlagOptions.WithShortHand("h"))
+
+	return cmd
+}
+
+func runModTestCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runModTestCmd")
+	defer func() {
+		utils.LogTime("cmd.runModTestCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	outputFormat := viper.GetString(constants.ArgOutput)
+	if !helpers.StringSliceContains(modtest.OutputFormats, outputFormat) {
+		error_helpers.ShowError(ctx, fmt.Errorf("invalid output format '%s' - must be one of %s", outputFormat, strings.Join(modtest.OutputFormats, ", ")))
+		exitCode = constants.ExitCodeInsufficientOrWrongArguments
+		return
+	}
+
+	// the tests run against the fixture tables, so resolve unqualified table names in the fixture schema
+	viper.Set(constants.ArgSearchPath, []string{constants.ModTestSchema})
+
+	w, err := workspace.LoadWorkspacePromptingForVariables(ctx)
+	error_helpers.FailOnErrorWithMessage(err, "failed to load workspace")
+
+	tests, err := modtest.SelectTests(w, args)
+	if err != nil {
+		error_helpers.ShowError(ctx, err)
+		exitCode = constants.ExitCodeInsufficientOrWrongArguments
+		return
+	}
+	if len(tests) == 0 {
+		fmt.Println("No tests defined in the current mod")
+		return
+	}
+
+	initData := initialisation.NewInitData(w)
+	initData.SkipRequiredPluginsCheck = true
+	initData.Init(ctx, constants.InvokerCheck)
+	error_helpers.FailOnError(initData.Result.Error)
+	defer initData.Cleanup(ctx)
+	initData.Result.DisplayMessages()
+
+	results := modtest.NewRunner(w, initData.Client).Run(ctx, tests)
+	err = modtest.WriteResults(os.Stdout, outputFormat, w.Mod.ShortName, results)
+	error_helpers.FailOnError(err)
+
+	for _, res := range results {
+		if !res.Passed() {
+			exitCode = constants.ExitCodeModTestFailures
+			return
+		}
+	}
+}
+
+// lint
+func modLintCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "lint [flags]",
+		Args:  cobra.NoArgs,
+		Run:   runModLintCmd,
+		Short: "Check the current mod for common mistakes",
+		Long: fmt.Sprintf(`Check the current mod for common mistakes.
+
+Lint rules are selected by rule id or rule set. The rule sets are:
+
+  default      all rules which do not require a database connection
+  correctness  rules which find resources that will fail or behave unexpectedly
+  style        rules which find missing or duplicated metadata
+  all          all rules
+
+Rules:
+
+%s
+Examples:
+
+  # Lint the current mod using the default rules
+  steampipe mod lint
+
+  # Check that all tables referenced by queries exist, writing SARIF output
+  steampipe mod lint --rule default --rule unknown-table --output sarif
+
+  # Fix issues which can be fixed automatically, e.g. remove args which do not match query params
+  steampipe mod lint --fix`, modLintRulesHelp()),
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddStringFlag(constants.ArgOutput, modlint.OutputFormatText, "Output format: text, json or sarif").
+		AddStringSliceFlag(constants.ArgRule, []string{modlint.RuleSetDefault}, "The rules or rule sets to run").
+		AddStringSliceFlag(constants.ArgExcludeRule, nil, "The rules or rule sets to exclude").
+		AddBoolFlag(constants.ArgFix, false, "Fix issues which can be fixed automatically").
+		AddStringSliceFlag(constants.ArgVarFile, nil, "Specify an .spvar file containing variable values").
+		// NOTE: use StringArrayFlag for ArgVariable, not StringSliceFlag
+		// Cobra will interpret values passed to a StringSliceFlag as CSV,
+		// where args passed to StringArrayFlag are not parsed and used raw
+		AddStringArrayFlag(constants.ArgVariable, nil, "Specify the value of a variable").
+		AddBoolFlag(constants.ArgInput, true, "Enable interactive prompts").
+		AddBoolFlag(constants.ArgHelp, false, "Help for lint", cmdconfig.FlagOptions.WithShortHand("h"))
+
+	return cmd
+}
+
+// build the list of rules for the lint command help
+func modLintRulesHelp() string {
+	var sb strings.Builder
+	for _, rule := range modlint.Rules {
+		fmt.Fprintf(&sb, "  %-30s %s\n", rule.ID, rule.Description)
+	}
+	return sb.String()
+}
+
+func runModLintCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runModLintCmd")
+	defer func() {
+		utils.LogTime("cmd.runModLintCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	outputFormat := viper.GetString(constants.ArgOutput)
+	if !helpers.StringSliceContains(modlint.OutputFormats, outputFormat) {
+		error_helpers.ShowError(ctx, fmt.Errorf("invalid output format '%s' - must be one of %s", outputFormat, strings.Join(modlint.OutputFormats, ", ")))
+		exitCode = constants.ExitCodeInsufficientOrWrongArguments
+		return
+	}
+	rules, err := modlint.SelectRules(viper.GetStringSlice(constants.ArgRule), viper.GetStringSlice(constants.ArgExcludeRule))
+	if err != nil {
+		error_helpers.ShowError(ctx, err)
+		exitCode = constants.ExitCodeInsufficientOrWrongArguments
+		return
+	}
+
+	w, err := workspace.LoadWorkspacePromptingForVariables(ctx)
+	error_helpers.FailOnErrorWithMessage(err, "failed to load workspace")
+
+	// only connect to the database if a rule needs to check the schema
+	var dbSchema *schema.Metadata
+	for _, rule := range rules {
+		if rule.RequiresSchema {
+			dbSchema = getLintSchema(ctx, w)
+			break
+		}
+	}
+
+	linter := modlint.NewLinter(w.Mod, rules, dbSchema)
+	issues, err := linter.Lint()
+	error_helpers.FailOnError(err)
+
+	if viper.GetBool(constants.ArgFix) {
+		fixed, err := modlint.ApplyFixes(issues)
+		error_helpers.FailOnError(err)
+		if len(fixed) > 0 {
+			// write to stderr so the output remains valid json/sarif
+			fmt.Fprintf(os.Stderr, "Fixed %d %s\n", len(fixed), utils.Pluralize("issue", len(fixed)))
+			for _, issue := range fixed {
+				fmt.Fprintf(os.Stderr, "  %s\n", issue.Fix.Description)
+			}
+			// rerun the lint so the output has the remaining issues
+			w, err = workspace.LoadWorkspacePromptingForVariables(ctx)
+			error_helpers.FailOnErrorWithMessage(err, "failed to load workspace")
+			issues, err = modlint.NewLinter(w.Mod, rules, dbSchema).Lint()
+			error_helpers.FailOnError(err)
+		}
+	}
+
+	err = modlint.WriteIssues(os.Stdout, outputFormat, w.Path, rules, issues)
+	error_helpers.FailOnError(err)
+
+	for _, issue := range issues {
+		if issue.Severity == modlint.SeverityError {
+			exitCode = constants.ExitCodeModLintErrors
+			return
+		}
+	}
+}
+
+// connect to the database and return its schema
+func getLintSchema(ctx context.Context, w *workspace.Workspace) *schema.Metadata {
+	initData := initialisation.NewInitData(w)
+	initData.SkipRequiredPluginsCheck = true
+	initData.Init(ctx, constants.InvokerQuery)
+	error_helpers.FailOnError(initData.Result.Error)
+	defer initData.Cleanup(ctx)
+	initData.Result.DisplayMessages()
+
+	dbSchema, err := initData.Client.GetSchemaFromDB(ctx)
+	error_helpers.FailOnError(err)
+	return dbSchema
+}
+
 // helpers
 
 func newInstallOpts(cmd *cobra.Command, args ...string) *modinstaller.InstallOpts {
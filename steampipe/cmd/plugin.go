@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -50,10 +51,14 @@ Examples:
   steampipe plugin list
 
   # Uninstall a plugin
-  steampipe plugin uninstall aws`,
+  steampipe plugin uninstall aws
+
+  # Export plugins to a bundle, for installation with no network access
+  steampipe plugin export aws gcp --to plugins.tar.gz`,
 	}
 
 	cmd.AddCommand(pluginInstallCmd())
+	cmd.AddCommand(pluginExportCmd())
 	cmd.AddCommand(pluginListCmd())
 	cmd.AddCommand(pluginUninstallCmd())
 	cmd.AddCommand(pluginUpdateCmd())
@@ -82,15 +87,49 @@ Examples:
   steampipe plugin install aws
 
   # Install a specific plugin version
-  steampipe plugin install turbot/azure@0.1.0`,
+  steampipe plugin install turbot/azure@0.1.0
+
+  # Install a plugin from a bundle created by 'steampipe plugin export'
+  steampipe plugin install aws --from plugins.tar.gz`,
 	}
 
 	cmdconfig.
 		OnCmd(cmd).
+		AddStringFlag(constants.ArgFrom, "", "Install from a plugin bundle (an OCI image layout directory or tar file) rather than the registry").
 		AddBoolFlag(constants.ArgHelp, false, "Help for plugin install", cmdconfig.FlagOptions.WithShortHand("h"))
 	return cmd
 }
 
+// Export plugins to a bundle
+func pluginExportCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "export [flags] [registry/org/]name[@version]",
+		Args:  cobra.ArbitraryArgs,
+		Run:   runPluginExportCmd,
+		Short: "Export one or more plugins to a bundle",
+		Long: `Export one or more plugins to a bundle.
+
+Download plugins from the registry, for all platforms, into a bundle which can be installed
+with 'steampipe plugin install --from', with no network access. The bundle is an OCI image
+layout - either a directory, or a tar file if the destination ends with .tar, .tar.gz or .tgz.
+Plugins are added to an existing bundle directory.
+
+Examples:
+
+  # Export the aws plugin to a directory
+  steampipe plugin export aws --to ./mirror
+
+  # Export specific plugin versions to a gzipped tar file
+  steampipe plugin export aws@0.80.0 turbot/gcp --to plugins.tar.gz`,
+	}
+
+	cmdconfig.
+		OnCmd(cmd).
+		AddStringFlag(constants.ArgTo, "", "The bundle to export to - a directory, or a tar file (.tar, .tar.gz or .tgz)").
+		AddBoolFlag(constants.ArgHelp, false, "Help for plugin export", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
 // Update plugins
 func pluginUpdateCmd() *cobra.Command {
 	var cmd = &cobra.Command{
@@ -213,6 +252,15 @@ func runPluginInstallCmd(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	// if installing from a bundle, open it
+	var installOpts []ociinstaller.PluginInstallOption
+	if bundlePath := viper.GetString(constants.ArgFrom); bundlePath != "" {
+		bundleDir, cleanup, err := ociinstaller.OpenPluginBundle(bundlePath)
+		error_helpers.FailOnError(err)
+		defer cleanup()
+		installOpts = append(installOpts, ociinstaller.WithPluginBundle(bundleDir))
+	}
+
 	// a leading blank line - since we always output multiple lines
 	fmt.Println()
 
@@ -226,7 +274,7 @@ func runPluginInstallCmd(cmd *cobra.Command, args []string) {
 	for _, pluginName := range plugins {
 		installWaitGroup.Add(1)
 		bar := createProgressBar(pluginName, progressBars)
-		go doPluginInstall(ctx, bar, pluginName, installWaitGroup, dataChannel)
+		go doPluginInstall(ctx, bar, pluginName, installWaitGroup, dataChannel, installOpts...)
 	}
 	go func() {
 		installWaitGroup.Wait()
@@ -246,7 +294,7 @@ func runPluginInstallCmd(cmd *cobra.Command, args []string) {
 	fmt.Println()
 }
 
-func doPluginInstall(ctx context.Context, bar *uiprogress.Bar, pluginName string, wg *sync.WaitGroup, returnChannel chan *display.PluginInstallReport) {
+func doPluginInstall(ctx context.Context, bar *uiprogress.Bar, pluginName string, wg *sync.WaitGroup, returnChannel chan *display.PluginInstallReport, opts ...ociinstaller.PluginInstallOption) {
 	var report *display.PluginInstallReport
 
 	pluginAlreadyInstalled, _ := plugin.Exists(pluginName)
@@ -276,12 +324,76 @@ func doPluginInstall(ctx context.Context, bar *uiprogress.Bar, pluginName string
 				return helpers.Resize(pluginInstallSteps[b.Current()-1], 20)
 			}
 		})
-		report = installPlugin(ctx, pluginName, false, bar)
+		report = installPlugin(ctx, pluginName, false, bar, opts...)
 	}
 	returnChannel <- report
 	wg.Done()
 }
 
+func runPluginExportCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("runPluginExportCmd start")
+	defer func() {
+		utils.LogTime("runPluginExportCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	bundlePath := viper.GetString(constants.ArgTo)
+	if len(args) == 0 || bundlePath == "" {
+		fmt.Println()
+		error_helpers.ShowError(ctx, fmt.Errorf("you need to provide at least one plugin to export, and the bundle to export to with --%s", constants.ArgTo))
+		fmt.Println()
+		cmd.Help()
+		fmt.Println()
+		exitCode = constants.ExitCodeInsufficientOrWrongArguments
+		return
+	}
+
+	// tar bundles are built in a temp directory then written to the tar file
+	layoutDir := bundlePath
+	if ociinstaller.IsTarBundlePath(bundlePath) {
+		tmpDir, err := os.MkdirTemp("", "steampipe-plugin-bundle-*")
+		error_helpers.FailOnError(err)
+		defer os.RemoveAll(tmpDir)
+		layoutDir = tmpDir
+	}
+
+	statusSpinner := statushooks.NewStatusSpinner()
+	var exported []string
+	var errors []error
+	for _, pluginName := range args {
+		statusSpinner.UpdateSpinnerMessage(fmt.Sprintf("Exporting %s...", pluginName))
+		ref, err := ociinstaller.ExportPlugin(ctx, pluginName, layoutDir)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("failed to export %s: %s", pluginName, err.Error()))
+			continue
+		}
+		exported = append(exported, ref.DisplayImageRef())
+	}
+	if len(exported) > 0 && layoutDir != bundlePath {
+		statusSpinner.UpdateSpinnerMessage(fmt.Sprintf("Writing %s...", bundlePath))
+		err := ociinstaller.TarBundle(layoutDir, bundlePath)
+		statusSpinner.Done()
+		error_helpers.FailOnErrorWithMessage(err, "failed to write plugin bundle")
+	}
+	statusSpinner.Done()
+
+	if len(exported) > 0 {
+		fmt.Printf("\nExported %d %s to %s:\n", len(exported), utils.Pluralize("plugin", len(exported)), bundlePath)
+		for _, ref := range exported {
+			fmt.Printf("  %s\n", ref)
+		}
+		fmt.Println()
+	}
+	if len(errors) > 0 {
+		error_helpers.ShowError(ctx, error_helpers.CombineErrors(errors...))
+		exitCode = constants.ExitCodeUnknownErrorPanic
+	}
+}
+
 func runPluginUpdateCmd(cmd *cobra.Command, args []string) {
 	ctx := cmd.Context()
 	utils.LogTime("runPluginUpdateCmd start")
@@ -456,7 +568,7 @@ func createProgressBar(plugin string, parentProgressBars *uiprogress.Progress) *
 	return bar
 }
 
-func installPlugin(ctx context.Context, pluginName string, isUpdate bool, bar *uiprogress.Bar) *display.PluginInstallReport {
+func installPlugin(ctx context.Context, pluginName string, isUpdate bool, bar *uiprogress.Bar, opts ...ociinstaller.PluginInstallOption) *display.PluginInstallReport {
 	// start a channel for progress publications from plugin.Install
 	progress := make(chan struct{}, 5)
 	defer func() {
@@ -472,7 +584,7 @@ func installPlugin(ctx context.Context, pluginName string, isUpdate bool, bar *u
 		}
 	}()
 
-	image, err := plugin.Install(ctx, pluginName, progress)
+	image, err := plugin.Install(ctx, pluginName, progress, opts...)
 	if err != nil {
 		msg := ""
 		_, name, stream := ociinstaller.NewSteampipeImageRef(pluginName).GetOrgNameAndStream()
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -15,9 +16,12 @@ import (
 	"github.com/turbot/steampipe/pkg/cmdconfig"
 	"github.com/turbot/steampipe/pkg/connectionwatcher"
 	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/db/db_local"
 	"github.com/turbot/steampipe/pkg/error_helpers"
 	"github.com/turbot/steampipe/pkg/filepaths"
+	"github.com/turbot/steampipe/pkg/queryaudit"
 	"github.com/turbot/steampipe/pkg/steampipeconfig"
+	"github.com/turbot/steampipe/pkg/utils"
 	"github.com/turbot/steampipe/pluginmanager_service"
 )
 
@@ -66,10 +70,26 @@ func runPluginManagerCmd(cmd *cobra.Command, args []string) {
 		defer connectionWatcher.Close()
 	}
 
+	startQueryAudit(ctx)
+
 	log.Printf("[TRACE] about to serve")
 	pluginManager.Serve()
 }
 
+// if the service was started with the query audit log enabled, write the audit log while the plugin manager runs
+func startQueryAudit(ctx context.Context) {
+	state, err := db_local.GetState()
+	if err != nil || state == nil || !state.QueryAudit {
+		return
+	}
+	// the retention age is validated by the service start command
+	retention, _ := utils.ParseRetentionAge(state.QueryAuditRetention)
+
+	log.Printf("[INFO] starting query audit log")
+	syncer := queryaudit.NewSyncer(filepaths.EnsureLogDir(), filepaths.EnsureQueryAuditDir(), filepaths.QueryAuditStateFilePath(), retention)
+	go syncer.Run(ctx, constants.QueryAuditSyncInterval)
+}
+
 func shouldRunConnectionWatcher() bool {
 	// if EnvConnectionWatcher is set, overwrite the value in DefaultConnectionOptions
 	if envStr, ok := os.LookupEnv(constants.EnvConnectionWatcher); ok {
@@ -72,7 +72,7 @@ Examples:
 		AddBoolFlag(constants.ArgHelp, false, "Help for query", cmdconfig.FlagOptions.WithShortHand("h")).
 		AddBoolFlag(constants.ArgHeader, true, "Include column headers csv and table output").
 		AddStringFlag(constants.ArgSeparator, ",", "Separator string for csv output").
-		AddStringFlag(constants.ArgOutput, "table", "Output format: line, csv, json, table or snapshot").
+		AddStringFlag(constants.ArgOutput, "table", "Output format: line, csv, json, ndjson, md, html, parquet, table or snapshot").
 		AddBoolFlag(constants.ArgTiming, false, "Turn on the timer which reports query time").
 		AddBoolFlag(constants.ArgWatch, true, "Watch SQL files in the current workspace (works only in interactive mode)").
 		AddStringSliceFlag(constants.ArgSearchPath, nil, "Set a custom search_path for the steampipe user for a query session (comma-separated)").
@@ -88,8 +88,11 @@ Examples:
 		AddStringArrayFlag(constants.ArgSnapshotTag, nil, "Specify tags to set on the snapshot").
 		AddStringFlag(constants.ArgSnapshotTitle, "", "The title to give a snapshot").
 		AddIntFlag(constants.ArgDatabaseQueryTimeout, 0, "The query timeout").
-		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported format: sps (snapshot)").
-		AddStringFlag(constants.ArgSnapshotLocation, "", "The location to write snapshots - either a local file path or a Steampipe Cloud workspace").
+		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: sps (snapshot), csv, json, ndjson, md, html, parquet").
+		AddStringFlag(constants.ArgSnapshotLocation, "", "The location to write snapshots - either a local directory, an S3 url (s3://bucket/prefix) or a Steampipe Cloud workspace").
+		AddStringFlag(constants.ArgSnapshotS3Endpoint, "", "The endpoint of an S3 compatible service to write snapshots to, e.g. http://localhost:9000").
+		AddIntFlag(constants.ArgSnapshotRetainCount, 0, "The number of snapshots of each resource to keep in a directory or S3 snapshot location (0 keeps all)").
+		AddStringFlag(constants.ArgSnapshotRetainAge, "", "The maximum age of snapshots to keep in a directory or S3 snapshot location, e.g. 30d, 12h").
 		AddBoolFlag(constants.ArgProgress, true, "Display snapshot upload status")
 
 	cmd.AddCommand(getListSubCmd(listSubCmdOptions{parentCmd: cmd}))
@@ -156,13 +159,17 @@ func validateQueryArgs(ctx context.Context, args []string) error {
 	if interactiveMode && len(viper.GetStringSlice(constants.ArgExport)) > 0 {
 		return fmt.Errorf("cannot export query results in interactive mode")
 	}
+	// parquet is a binary format which holds a single result, so cannot be used for the results of an interactive session
+	if interactiveMode && viper.GetString(constants.ArgOutput) == constants.OutputFormatParquet {
+		return fmt.Errorf("cannot output parquet in interactive mode")
+	}
 	// if share or snapshot args are set, there must be a query specified
 	err := cmdconfig.ValidateSnapshotArgs(ctx)
 	if err != nil {
 		return err
 	}
 
-	validOutputFormats := []string{constants.OutputFormatLine, constants.OutputFormatCSV, constants.OutputFormatTable, constants.OutputFormatJSON, constants.OutputFormatSnapshot, constants.OutputFormatSnapshotShort, constants.OutputFormatNone}
+	validOutputFormats := append([]string{constants.OutputFormatLine, constants.OutputFormatTable, constants.OutputFormatSnapshot, constants.OutputFormatSnapshotShort, constants.OutputFormatNone}, display.ResultWriterFormats...)
 	output := viper.GetString(constants.ArgOutput)
 	if !helpers.StringSliceContains(validOutputFormats, output) {
 		return fmt.Errorf("invalid output format: '%s', must be one of [%s]", output, strings.Join(validOutputFormats, ", "))
@@ -228,9 +235,16 @@ func executeSnapshotQuery(initData *query.InitData, ctx context.Context) int {
 			error_helpers.FailOnErrorWithMessage(err, fmt.Sprintf("failed to publish snapshot to %s", viper.GetString(constants.ArgSnapshotLocation)))
 
 			// export the result if necessary
-			exportArgs := viper.GetStringSlice(constants.ArgExport)
-			err = initData.ExportManager.DoExport(ctx, snap.FileNameRoot, snap, exportArgs)
+			snapshotExports, resultExports := splitExportArgs(viper.GetStringSlice(constants.ArgExport))
+			err = initData.ExportManager.DoExport(ctx, snap.FileNameRoot, snap, snapshotExports)
 			error_helpers.FailOnErrorWithMessage(err, "failed to export snapshot")
+			// other formats export the query result - each export needs its own copy of the result to read
+			for _, exportArg := range resultExports {
+				result, err := snapshotToQueryResult(snap, queryProvider.Name())
+				error_helpers.FailOnErrorWithMessage(err, "failed to export query result")
+				err = initData.ExportManager.DoExport(ctx, snap.FileNameRoot, result, []string{exportArg})
+				error_helpers.FailOnErrorWithMessage(err, "failed to export query result")
+			}
 		}
 	}
 	return 0
@@ -301,18 +315,35 @@ func ensureQueryResource(name string, resolvedQuery *modconfig.ResolvedQuery, w
 }
 
 func snapshotRequired() bool {
-	SnapshotFormatNames := []string{constants.OutputFormatSnapshot, constants.OutputFormatSnapshotShort}
 	// if a snapshot exporter is specified return true
 	for _, e := range viper.GetStringSlice(constants.ArgExport) {
-		if helpers.StringSliceContains(SnapshotFormatNames, e) || path.Ext(e) == constants.SnapshotExtension {
+		if isSnapshotExport(e) {
 			return true
 		}
 	}
 	// if share/snapshot args are set or output is snapshot, return true
 	return viper.IsSet(constants.ArgShare) ||
 		viper.IsSet(constants.ArgSnapshot) ||
-		helpers.StringSliceContains(SnapshotFormatNames, viper.GetString(constants.ArgOutput))
+		helpers.StringSliceContains(snapshotFormatNames, viper.GetString(constants.ArgOutput))
+
+}
 
+var snapshotFormatNames = []string{constants.OutputFormatSnapshot, constants.OutputFormatSnapshotShort}
+
+func isSnapshotExport(e string) bool {
+	return helpers.StringSliceContains(snapshotFormatNames, e) || path.Ext(e) == constants.SnapshotExtension
+}
+
+// split the export args into snapshot exports and query result exports
+func splitExportArgs(exportArgs []string) (snapshotExports, resultExports []string) {
+	for _, e := range exportArgs {
+		if isSnapshotExport(e) {
+			snapshotExports = append(snapshotExports, e)
+		} else {
+			resultExports = append(resultExports, e)
+		}
+	}
+	return snapshotExports, resultExports
 }
 
 // getPipedStdinData reads the Standard Input and returns the available data as a string
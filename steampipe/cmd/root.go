@@ -336,6 +336,8 @@ func AddCommands() {
 		dashboardCmd(),
 		variableCmd(),
 		loginCmd(),
+		exporterCmd(),
+		snapshotCmd(),
 	)
 }
 
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	psutils "github.com/shirou/gopsutil/process"
@@ -19,6 +21,7 @@ import (
 	"github.com/turbot/steampipe/pkg/db/db_local"
 	"github.com/turbot/steampipe/pkg/display"
 	"github.com/turbot/steampipe/pkg/error_helpers"
+	"github.com/turbot/steampipe/pkg/scheduler"
 	"github.com/turbot/steampipe/pkg/statushooks"
 	"github.com/turbot/steampipe/pkg/utils"
 	"github.com/turbot/steampipe/pluginmanager"
@@ -39,6 +42,7 @@ connection from any Postgres compatible database client.`,
 	cmd.AddCommand(serviceStatusCmd())
 	cmd.AddCommand(serviceStopCmd())
 	cmd.AddCommand(serviceRestartCmd())
+	cmd.AddCommand(serviceSchedulerCmd())
 	cmd.Flags().BoolP(constants.ArgHelp, "h", false, "Help for service")
 	return cmd
 }
@@ -70,15 +74,27 @@ connection from any Postgres compatible database client.`,
 		AddBoolFlag(constants.ArgDashboard, false, "Run the dashboard webserver with the service").
 		AddStringFlag(constants.ArgDashboardListen, string(dashboardserver.ListenTypeNetwork), "Accept connections from: local (localhost only) or network (open) (dashboard)").
 		AddIntFlag(constants.ArgDashboardPort, constants.DashboardServerDefaultPort, "Report server port").
+		AddStringFlag(constants.ArgDashboardAuth, string(dashboardserver.AuthTypeNone), "Dashboard server authentication: none, token, basic or header (trusted reverse proxy)").
+		AddStringFlag(constants.ArgDashboardAuthToken, "", "The token required by token authentication (dashboard)").
+		AddStringFlag(constants.ArgDashboardAuthUsers, "", "The users file for basic authentication (dashboard)").
+		AddStringFlag(constants.ArgDashboardAuthHeader, constants.DashboardAuthDefaultUserHeader, "The request header containing the user name for header authentication (dashboard)").
+		AddStringFlag(constants.ArgDashboardAuthGroups, constants.DashboardAuthDefaultGroupsHeader, "The request header containing the user groups for header authentication (dashboard)").
+		AddStringFlag(constants.ArgDashboardAccess, "", "A file of access rules restricting the dashboards and benchmarks each user or group may view (dashboard)").
+		// scheduler
+		AddBoolFlag(constants.ArgScheduler, false, "Run the schedules defined in the current mod with the service").
+		// query audit log
+		AddBoolFlag(constants.ArgQueryAudit, false, "Write an audit log of every statement executed by the service").
+		AddStringFlag(constants.ArgQueryAuditRetention, constants.DefaultQueryAuditRetention, "The maximum age of query audit log files, e.g. 30d, 12h (empty keeps all files)").
+		AddBoolFlag(constants.ArgQueryAuditPlans, false, "Add the row count and search path of statements to the query audit log, using the Postgres auto_explain module (this runs every statement with EXPLAIN ANALYZE, which slows queries)").
 		// foreground enables the service to run in the foreground - till exit
 		AddBoolFlag(constants.ArgForeground, false, "Run the service in the foreground").
 
-		// flags relevant only if the --dashboard arg is used:
-		AddStringSliceFlag(constants.ArgVarFile, nil, "Specify an .spvar file containing variable values (only applies if '--dashboard' flag is also set)").
+		// flags relevant only if the --dashboard or --scheduler arg is used:
+		AddStringSliceFlag(constants.ArgVarFile, nil, "Specify an .spvar file containing variable values (only applies if '--dashboard' or '--scheduler' flag is also set)").
 		// NOTE: use StringArrayFlag for ArgVariable, not StringSliceFlag
 		// Cobra will interpret values passed to a StringSliceFlag as CSV,
 		// where args passed to StringArrayFlag are not parsed and used raw
-		AddStringArrayFlag(constants.ArgVariable, nil, "Specify the value of a variable (only applies if '--dashboard' flag is also set)").
+		AddStringArrayFlag(constants.ArgVariable, nil, "Specify the value of a variable (only applies if '--dashboard' or '--scheduler' flag is also set)").
 
 		// hidden flags for internal use
 		AddStringFlag(constants.ArgInvoker, string(constants.InvokerService), "Invoked by \"service\" or \"query\"", cmdconfig.FlagOptions.Hidden())
@@ -143,6 +159,32 @@ func serviceRestartCmd() *cobra.Command {
 	return cmd
 }
 
+// runs the workspace schedules - this is spawned by 'service start --scheduler'
+func serviceSchedulerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "scheduler",
+		Args:   cobra.NoArgs,
+		Run:    runServiceSchedulerCmd,
+		Hidden: true,
+		Short:  "Run the schedules defined in the current mod",
+	}
+
+	cmdconfig.
+		OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for service scheduler", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatNone, "Output format").
+		AddStringFlag(constants.ArgTheme, "plain", "Output theme").
+		AddBoolFlag(constants.ArgProgress, false, "Display control execution progress").
+		AddBoolFlag(constants.ArgInput, false, "Enable interactive prompts").
+		AddBoolFlag(constants.ArgModInstall, true, "Specify whether to install mod dependencies before each run").
+		AddIntFlag(constants.ArgDatabaseQueryTimeout, constants.DatabaseDefaultCheckQueryTimeout, "The query timeout").
+		AddIntFlag(constants.ArgMaxParallel, constants.DefaultMaxConnections, "The maximum number of concurrent database connections to open").
+		AddStringSliceFlag(constants.ArgVarFile, nil, "Specify an .spvar file containing variable values").
+		AddStringArrayFlag(constants.ArgVariable, nil, "Specify the value of a variable")
+
+	return cmd
+}
+
 func runServiceStartCmd(cmd *cobra.Command, args []string) {
 	ctx := cmd.Context()
 	utils.LogTime("runServiceStartCmd start")
@@ -173,7 +215,10 @@ func runServiceStartCmd(cmd *cobra.Command, args []string) {
 	invoker := constants.Invoker(cmdconfig.Viper().GetString(constants.ArgInvoker))
 	error_helpers.FailOnError(invoker.IsValid())
 
-	err := db_local.EnsureDBInstalled(ctx)
+	_, err := utils.ParseRetentionAge(viper.GetString(constants.ArgQueryAuditRetention))
+	error_helpers.FailOnErrorWithMessage(err, fmt.Sprintf("invalid '--%s'", constants.ArgQueryAuditRetention))
+
+	err = db_local.EnsureDBInstalled(ctx)
 	error_helpers.FailOnError(err)
 
 	// start db, refreshing connections
@@ -237,7 +282,26 @@ func runServiceStartCmd(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	printStatus(ctx, startResult.DbState, startResult.PluginManagerState, dashboardState, !servicesStarted)
+	var schedulerState *scheduler.SchedulerServiceState
+	if viper.GetBool(constants.ArgScheduler) {
+		schedulerState, err = scheduler.GetSchedulerServiceState()
+		if err != nil {
+			tryToStopServices(ctx)
+			error_helpers.ShowError(ctx, err)
+			return
+		}
+		if schedulerState == nil {
+			schedulerState, err = startScheduler(ctx, viper.GetString(constants.ArgModLocation))
+			if err != nil {
+				error_helpers.ShowError(ctx, err)
+				tryToStopServices(ctx)
+				return
+			}
+			servicesStarted = true
+		}
+	}
+
+	printStatus(ctx, startResult.DbState, startResult.PluginManagerState, dashboardState, schedulerState, !servicesStarted)
 
 	if viper.GetBool(constants.ArgForeground) {
 		runServiceInForeground(ctx, invoker)
@@ -253,6 +317,10 @@ func tryToStopServices(ctx context.Context) {
 	if err := dashboardserver.StopDashboardService(ctx); err != nil {
 		error_helpers.ShowError(ctx, err)
 	}
+	// stop the scheduler
+	if err := scheduler.StopSchedulerService(ctx); err != nil {
+		error_helpers.ShowError(ctx, err)
+	}
 }
 
 func startDashboardServer(ctx context.Context) (*dashboardserver.DashboardServiceState, error) {
@@ -262,6 +330,16 @@ func startDashboardServer(ctx context.Context) (*dashboardserver.DashboardServic
 	serverPort := dashboardserver.ListenPort(viper.GetInt(constants.ArgDashboardPort))
 	serverListen := dashboardserver.ListenType(viper.GetString(constants.ArgDashboardListen))
 
+	authType := dashboardserver.AuthType(viper.GetString(constants.ArgDashboardAuth))
+	if err := authType.IsValid(); err != nil {
+		return nil, err
+	}
+	// the service listens on the network by default - the dashboard server runs in the background,
+	// so warn here rather than in the dashboard command
+	if serverListen == dashboardserver.ListenTypeNetwork && authType == dashboardserver.AuthTypeNone {
+		error_helpers.ShowWarning(fmt.Sprintf("the dashboard server is accepting network connections without authentication - use --%s to require authentication, or --%s=%s to accept local connections only", constants.ArgDashboardAuth, constants.ArgDashboardListen, dashboardserver.ListenTypeLocal))
+	}
+
 	dashboardState, err = dashboardserver.GetDashboardServiceState()
 	if err != nil {
 		return nil, err
@@ -289,6 +367,67 @@ func startDashboardServer(ctx context.Context) (*dashboardserver.DashboardServic
 	return dashboardState, err
 }
 
+func startScheduler(ctx context.Context, modLocation string) (*scheduler.SchedulerServiceState, error) {
+	err := scheduler.RunForService(ctx, modLocation)
+	if err != nil {
+		return nil, err
+	}
+	// get the updated state
+	schedulerState, err := scheduler.GetSchedulerServiceState()
+	if err != nil {
+		error_helpers.ShowWarning(fmt.Sprintf("Started scheduler, but could not retrieve state: %v", err))
+	}
+	return schedulerState, err
+}
+
+func runServiceSchedulerCmd(cmd *cobra.Command, _ []string) {
+	ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var err error
+	utils.LogTime("runServiceSchedulerCmd start")
+	defer func() {
+		utils.LogTime("runServiceSchedulerCmd end")
+		if r := recover(); r != nil {
+			err = helpers.ToError(r)
+		}
+		if err != nil {
+			error_helpers.ShowError(ctx, err)
+			// save the error to the state file so that 'service start' can report it
+			if stateErr := scheduler.WriteServiceStateFile(&scheduler.SchedulerServiceState{
+				State: scheduler.ServiceStateError,
+				Error: err.Error(),
+				Pid:   os.Getpid(),
+			}); stateErr != nil {
+				// 'service start' will time out waiting for the state file - log the reason
+				log.Printf("[WARN] failed to write the scheduler state file: %s", stateErr.Error())
+			}
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	// disable all status messages
+	ctx = statushooks.DisableStatusHooks(ctx)
+
+	s, err := scheduler.NewScheduler(ctx)
+	if err != nil {
+		return
+	}
+
+	err = scheduler.WriteServiceStateFile(&scheduler.SchedulerServiceState{
+		State:       scheduler.ServiceStateRunning,
+		Pid:         os.Getpid(),
+		ModLocation: viper.GetString(constants.ArgModLocation),
+		Schedules:   s.ScheduleNames(),
+	})
+	if err != nil {
+		return
+	}
+
+	// run until we are signalled to stop
+	s.Run(ctx)
+}
+
 func runServiceInForeground(ctx context.Context, invoker constants.Invoker) {
 	fmt.Println("Hit Ctrl+C to stop the service")
 
@@ -315,6 +454,7 @@ func runServiceInForeground(ctx context.Context, invoker constants.Invoker) {
 		case <-sigIntChannel:
 			fmt.Print("\r")
 			dashboardserver.StopDashboardService(ctx)
+			scheduler.StopSchedulerService(ctx)
 			// if we have received this signal, then the user probably wants to shut down
 			// everything. Shutdowns MUST NOT happen in cancellable contexts
 			count, err := db_local.GetCountOfThirdPartyClients(context.Background())
@@ -371,6 +511,10 @@ func runServiceRestartCmd(cmd *cobra.Command, args []string) {
 	currentDashboardState, err := dashboardserver.GetDashboardServiceState()
 	error_helpers.FailOnError(err)
 
+	// and the current scheduler state - maybe nil
+	currentSchedulerState, err := scheduler.GetSchedulerServiceState()
+	error_helpers.FailOnError(err)
+
 	// stop db
 	stopStatus, err := db_local.StopServices(ctx, viper.GetBool(constants.ArgForce), constants.InvokerService)
 	error_helpers.FailOnErrorWithMessage(err, "could not stop current instance")
@@ -390,8 +534,16 @@ to force a restart.
 	err = dashboardserver.StopDashboardService(ctx)
 	error_helpers.FailOnErrorWithMessage(err, "could not stop dashboard service")
 
+	// stop the running scheduler
+	err = scheduler.StopSchedulerService(ctx)
+	error_helpers.FailOnErrorWithMessage(err, "could not stop scheduler")
+
 	// set the password in 'viper' so that it can be used by 'service start'
 	viper.Set(constants.ArgServicePassword, currentDbState.Password)
+	// and the query audit settings, so that the query audit log is still written by the restarted service
+	viper.Set(constants.ArgQueryAudit, currentDbState.QueryAudit)
+	viper.Set(constants.ArgQueryAuditRetention, currentDbState.QueryAuditRetention)
+	viper.Set(constants.ArgQueryAuditPlans, currentDbState.QueryAuditPlans)
 
 	// start db
 	dbStartResult := db_local.StartServices(cmd.Context(), currentDbState.Port, currentDbState.ListenType, currentDbState.Invoker)
@@ -415,7 +567,13 @@ to force a restart.
 		error_helpers.FailOnError(err)
 	}
 
-	printStatus(ctx, dbStartResult.DbState, dbStartResult.PluginManagerState, currentDashboardState, false)
+	// if the scheduler was running, start it
+	if currentSchedulerState != nil {
+		currentSchedulerState, err = startScheduler(ctx, currentSchedulerState.ModLocation)
+		error_helpers.FailOnError(err)
+	}
+
+	printStatus(ctx, dbStartResult.DbState, dbStartResult.PluginManagerState, currentDashboardState, currentSchedulerState, false)
 }
 
 func runServiceStatusCmd(cmd *cobra.Command, args []string) {
@@ -438,12 +596,13 @@ func runServiceStatusCmd(cmd *cobra.Command, args []string) {
 		dbState, dbStateErr := db_local.GetState()
 		pmState, pmStateErr := pluginmanager.LoadPluginManagerState()
 		dashboardState, dashboardStateErr := dashboardserver.GetDashboardServiceState()
+		schedulerState, _ := scheduler.GetSchedulerServiceState()
 
 		if dbStateErr != nil || pmStateErr != nil {
 			error_helpers.ShowError(ctx, composeStateError(dbStateErr, pmStateErr, dashboardStateErr))
 			return
 		}
-		printStatus(ctx, dbState, pmState, dashboardState, false)
+		printStatus(ctx, dbState, pmState, dashboardState, schedulerState, false)
 	}
 }
 
@@ -486,8 +645,9 @@ func runServiceStopCmd(cmd *cobra.Command, args []string) {
 	force := cmdconfig.Viper().GetBool(constants.ArgForce)
 	if force {
 		dashboardStopError := dashboardserver.StopDashboardService(ctx)
+		schedulerStopError := scheduler.StopSchedulerService(ctx)
 		status, dbStopError = db_local.StopServices(ctx, force, constants.InvokerService)
-		dbStopError = error_helpers.CombineErrors(dbStopError, dashboardStopError)
+		dbStopError = error_helpers.CombineErrors(dbStopError, dashboardStopError, schedulerStopError)
 		error_helpers.FailOnError(dbStopError)
 	} else {
 		dbState, dbStopError = db_local.GetState()
@@ -510,6 +670,10 @@ func runServiceStopCmd(cmd *cobra.Command, args []string) {
 			error_helpers.FailOnErrorWithMessage(err, "could not stop dashboard server")
 		}
 
+		// stop the scheduler before checking for connected clients, since it may have an active run
+		err = scheduler.StopSchedulerService(ctx)
+		error_helpers.FailOnErrorWithMessage(err, "could not stop scheduler")
+
 		var connectedClientCount int
 		// check if there are any connected clients to the service
 		connectedClientCount, err = db_local.GetCountOfThirdPartyClients(cmd.Context())
@@ -594,7 +758,7 @@ func getServiceProcessDetails(process *psutils.Process) (string, string, string,
 	return fmt.Sprintf("%d", process.Pid), installDir, port, listenType
 }
 
-func printStatus(ctx context.Context, dbState *db_local.RunningDBInstanceInfo, pmState *pluginmanager.PluginManagerState, dashboardState *dashboardserver.DashboardServiceState, alreadyRunning bool) {
+func printStatus(ctx context.Context, dbState *db_local.RunningDBInstanceInfo, pmState *pluginmanager.PluginManagerState, dashboardState *dashboardserver.DashboardServiceState, schedulerState *scheduler.SchedulerServiceState, alreadyRunning bool) {
 	if dbState == nil && !pmState.Running {
 		fmt.Println("Service is not running")
 		return
@@ -680,12 +844,25 @@ Dashboard:
 `, strings.Join(dashboardState.Listen, ", "), dashboardState.Port, browserUrl)
 	}
 
+	schedulerMsg := ""
+
+	if schedulerState != nil {
+		schedulerMsg = fmt.Sprintf(`
+Scheduler:
+
+  Mod location:  %v
+  Schedules:     %v
+  Run history:   %s.%s
+`, schedulerState.ModLocation, strings.Join(schedulerState.Schedules, ", "), constants.InternalSchema, constants.InternalTableScheduledRun)
+	}
+
 	if dbState.Invoker == constants.InvokerService {
 		statusMessage = fmt.Sprintf(
-			"%s%s%s%s",
+			"%s%s%s%s%s",
 			prefix,
 			postgresMsg,
 			dashboardMsg,
+			schedulerMsg,
 			suffix,
 		)
 	} else {
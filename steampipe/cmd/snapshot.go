@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/steampipe/pkg/cloud"
+	"github.com/turbot/steampipe/pkg/cmdconfig"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/display"
+	"github.com/turbot/steampipe/pkg/error_helpers"
+	"github.com/turbot/steampipe/pkg/export"
+	"github.com/turbot/steampipe/pkg/steampipeconfig"
+)
+
+// Snapshot management commands
+func snapshotCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "snapshot [command]",
+		Args:  cobra.NoArgs,
+		Short: "Steampipe snapshot management",
+		Long:  `Steampipe snapshot management.`,
+	}
+
+	cmd.AddCommand(snapshotListCmd())
+	cmd.Flags().BoolP(constants.ArgHelp, "h", false, "Help for snapshot")
+
+	return cmd
+}
+
+// List snapshots
+func snapshotListCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "list [name]",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runSnapshotListCmd,
+		Short: "List the snapshots stored in a snapshot location",
+		Long: `List the snapshots stored in a snapshot location.
+
+List the snapshots saved to a local directory or S3 snapshot location, most recent first. If a name
+is passed, only the snapshots of the dashboard, benchmark or query with that name are listed.
+
+Examples:
+
+  # List the snapshots in a local directory
+  steampipe snapshot list --snapshot-location ~/snapshots
+
+  # List the snapshots of a benchmark stored in a MinIO bucket
+  steampipe snapshot list aws_compliance.benchmark.cis_v150 --snapshot-location s3://snapshots/prod --snapshot-s3-endpoint http://localhost:9000
+`,
+	}
+
+	cmdconfig.
+		OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for snapshot list", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddStringFlag(constants.ArgSnapshotLocation, "", "The snapshot location to list - either a local directory or an S3 url (s3://bucket/prefix)").
+		AddStringFlag(constants.ArgSnapshotS3Endpoint, "", "The endpoint of an S3 compatible service, e.g. http://localhost:9000").
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatTable, "Select a console output format: table or json")
+
+	return cmd
+}
+
+func runSnapshotListCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	defer func() {
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	// validate output arg
+	output := viper.GetString(constants.ArgOutput)
+	if !helpers.StringSliceContains([]string{constants.OutputFormatTable, constants.OutputFormatJSON}, output) {
+		error_helpers.ShowError(ctx, fmt.Errorf("output flag must be either 'json' or 'table'"))
+		return
+	}
+
+	snapshotLocation := viper.GetString(constants.ArgSnapshotLocation)
+	if snapshotLocation == "" {
+		error_helpers.ShowError(ctx, fmt.Errorf("--%s must be set", constants.ArgSnapshotLocation))
+		exitCode = constants.ExitCodeInsufficientOrWrongArguments
+		return
+	}
+	if !export.IsS3Location(snapshotLocation) {
+		if steampipeconfig.IsCloudWorkspaceIdentifier(snapshotLocation) {
+			error_helpers.ShowError(ctx, fmt.Errorf("listing snapshots is only supported for directory and S3 snapshot locations"))
+			exitCode = constants.ExitCodeInsufficientOrWrongArguments
+			return
+		}
+		var err error
+		snapshotLocation, err = filehelpers.Tildefy(snapshotLocation)
+		error_helpers.FailOnError(err)
+		if !filehelpers.DirectoryExists(snapshotLocation) {
+			error_helpers.ShowError(ctx, fmt.Errorf("snapshot location %s does not exist", snapshotLocation))
+			exitCode = constants.ExitCodeInsufficientOrWrongArguments
+			return
+		}
+	}
+
+	store, err := cloud.NewSnapshotStore(snapshotLocation)
+	error_helpers.FailOnError(err)
+	snapshots, err := store.List(ctx)
+	error_helpers.FailOnErrorWithMessage(err, fmt.Sprintf("failed to list snapshots in %s", snapshotLocation))
+
+	// filter by name if one was passed
+	if len(args) == 1 {
+		var filtered []*export.StoredSnapshot
+		for _, s := range snapshots {
+			if s.Name == args[0] {
+				filtered = append(filtered, s)
+			}
+		}
+		snapshots = filtered
+	}
+
+	if output == constants.OutputFormatJSON {
+		display.ShowSnapshotListJson(snapshots)
+	} else {
+		display.ShowSnapshotListTable(snapshots)
+	}
+}
@@ -2,6 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -10,6 +13,7 @@ import (
 	"github.com/turbot/steampipe/pkg/constants"
 	"github.com/turbot/steampipe/pkg/display"
 	"github.com/turbot/steampipe/pkg/error_helpers"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/inputvars"
 	"github.com/turbot/steampipe/pkg/workspace"
 )
 
@@ -23,6 +27,7 @@ func variableCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(variableListCmd())
+	cmd.AddCommand(variableEncryptCmd())
 	cmd.Flags().BoolP(constants.ArgHelp, "h", false, "Help for variable")
 
 	return cmd
@@ -85,3 +90,63 @@ func runVariableListCmd(cmd *cobra.Command, args []string) {
 		display.ShowVarsListTable(vars)
 	}
 }
+
+// Encrypt a variable value
+func variableEncryptCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "encrypt <file>",
+		Args:  cobra.ExactArgs(1),
+		Run:   runVariableEncryptCmd,
+		Short: "Encrypt a variable value",
+		Long: `Encrypt a variable value.
+
+Read a value from stdin, encrypt it and write it to a file, which may be
+referenced in a variable value using 'encrypted://<file>'.
+
+The value is encrypted using the key in STEAMPIPE_SECRETS_KEY if set, otherwise
+using a key file in the Steampipe install directory, which is created if needed.
+
+Example:
+
+  # Encrypt a password and use it as the value of the 'db_password' variable
+  echo -n "my-password" | steampipe variable encrypt ~/.secrets/db_password
+  steampipe check all --var db_password=encrypted://~/.secrets/db_password
+
+`,
+	}
+
+	cmdconfig.
+		OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for variable encrypt", cmdconfig.FlagOptions.WithShortHand("h"))
+
+	return cmd
+}
+
+func runVariableEncryptCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	defer func() {
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	value, err := io.ReadAll(os.Stdin)
+	error_helpers.FailOnErrorWithMessage(err, "failed to read value")
+	if len(value) == 0 {
+		error_helpers.ShowError(ctx, fmt.Errorf("no value to encrypt - the value must be passed using stdin"))
+		exitCode = constants.ExitCodeInsufficientOrWrongArguments
+		return
+	}
+
+	encrypted, err := inputvars.EncryptSecret(value)
+	error_helpers.FailOnErrorWithMessage(err, "failed to encrypt value")
+
+	path, err := filepath.Abs(args[0])
+	error_helpers.FailOnError(err)
+	// the encrypted file is only readable by the current user
+	err = os.WriteFile(path, encrypted, 0600)
+	error_helpers.FailOnErrorWithMessage(err, "failed to write encrypted value")
+
+	fmt.Printf("Encrypted value written to %s - reference it in a variable value using 'encrypted://%s'\n", path, path)
+}
@@ -9,8 +9,8 @@ import (
 	"github.com/turbot/steampipe/pkg/dashboard/dashboardtypes"
 	"github.com/turbot/steampipe/pkg/export"
 	"github.com/turbot/steampipe/pkg/steampipeconfig"
+	"github.com/turbot/steampipe/pkg/utils"
 	"log"
-	"path"
 	"strings"
 )
 
@@ -22,7 +22,7 @@ func PublishSnapshot(ctx context.Context, snapshot *dashboardtypes.SteampipeSnap
 	}
 
 	// if snapshot location is a workspace handle, upload it
-	if steampipeconfig.IsCloudWorkspaceIdentifier(snapshotLocation) {
+	if !export.IsS3Location(snapshotLocation) && steampipeconfig.IsCloudWorkspaceIdentifier(snapshotLocation) {
 		url, err := uploadSnapshot(ctx, snapshot, share)
 		if err != nil {
 			return "", err
@@ -30,26 +30,55 @@ func PublishSnapshot(ctx context.Context, snapshot *dashboardtypes.SteampipeSnap
 		return fmt.Sprintf("\nSnapshot uploaded to %s\n", url), nil
 	}
 
-	// otherwise assume snapshot location is a file path
-	filePath, err := exportSnapshot(snapshot)
+	// otherwise snapshot location is an S3 url or a local directory
+	location, err := saveSnapshot(ctx, snapshot, snapshotLocation)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("\nSnapshot saved to %s\n", filePath), nil
+	return fmt.Sprintf("\nSnapshot saved to %s\n", location), nil
 }
 
-func exportSnapshot(snapshot *dashboardtypes.SteampipeSnapshot) (string, error) {
-	exporter := &export.SnapshotExporter{}
+// NewSnapshotStore returns the store for a snapshot location which is not a cloud workspace
+func NewSnapshotStore(snapshotLocation string) (export.SnapshotStore, error) {
+	return export.NewSnapshotStore(snapshotLocation, export.SnapshotStoreOptions{
+		S3Endpoint: viper.GetString(constants.ArgSnapshotS3Endpoint),
+	})
+}
 
-	fileName := export.GenerateDefaultExportFileName(snapshot.FileNameRoot, exporter.FileExtension())
-	dirName := viper.GetString(constants.ArgSnapshotLocation)
-	filePath := path.Join(dirName, fileName)
+// SnapshotRetentionPolicy returns the retention policy set by the snapshot-retain args
+func SnapshotRetentionPolicy() (export.RetentionPolicy, error) {
+	maxAge, err := utils.ParseRetentionAge(viper.GetString(constants.ArgSnapshotRetainAge))
+	if err != nil {
+		return export.RetentionPolicy{}, err
+	}
+	keepCount := viper.GetInt(constants.ArgSnapshotRetainCount)
+	if keepCount < 0 {
+		return export.RetentionPolicy{}, fmt.Errorf("--%s must not be negative", constants.ArgSnapshotRetainCount)
+	}
+	return export.RetentionPolicy{KeepCount: keepCount, MaxAge: maxAge}, nil
+}
+
+func saveSnapshot(ctx context.Context, snapshot *dashboardtypes.SteampipeSnapshot, snapshotLocation string) (string, error) {
+	store, err := NewSnapshotStore(snapshotLocation)
+	if err != nil {
+		return "", err
+	}
+	location, err := store.Save(ctx, snapshot)
+	if err != nil {
+		return "", err
+	}
 
-	err := exporter.Export(context.Background(), snapshot, filePath)
+	// now the snapshot is saved, remove any snapshots which are no longer retained
+	policy, err := SnapshotRetentionPolicy()
 	if err != nil {
 		return "", err
 	}
-	return filePath, nil
+	deleted, err := export.ApplyRetention(ctx, store, policy)
+	if err != nil {
+		return "", fmt.Errorf("snapshot saved to %s but failed to apply retention policy: %s", location, err.Error())
+	}
+	log.Printf("[TRACE] snapshot retention policy deleted %d snapshots", len(deleted))
+	return location, nil
 }
 
 func uploadSnapshot(ctx context.Context, snapshot *dashboardtypes.SteampipeSnapshot, share bool) (string, error) {
@@ -71,6 +71,16 @@ func (c *CmdBuilder) AddIntFlag(name string, defaultValue int, desc string, opts
 	return c
 }
 
+// AddFloat64Flag is a helper function to add a float flag to a command
+func (c *CmdBuilder) AddFloat64Flag(name string, defaultValue float64, desc string, opts ...flagOpt) *CmdBuilder {
+	c.cmd.Flags().Float64(name, defaultValue, desc)
+	c.bindings[name] = c.cmd.Flags().Lookup(name)
+	for _, o := range opts {
+		o(c.cmd, name, name)
+	}
+	return c
+}
+
 // AddBoolFlag ia s helper function to add a boolean flag to a command
 func (c *CmdBuilder) AddBoolFlag(name string, defaultValue bool, desc string, opts ...flagOpt) *CmdBuilder {
 	c.cmd.Flags().Bool(name, defaultValue, desc)
@@ -8,6 +8,7 @@ import (
 	"github.com/turbot/steampipe/pkg/cloud"
 	"github.com/turbot/steampipe/pkg/constants"
 	"github.com/turbot/steampipe/pkg/error_helpers"
+	"github.com/turbot/steampipe/pkg/export"
 	"github.com/turbot/steampipe/pkg/steampipeconfig"
 	"strings"
 )
@@ -34,8 +35,8 @@ func ValidateSnapshotArgs(ctx context.Context) error {
 	}
 
 	// if workspace-database or snapshot-location are a cloud workspace handle, cloud token must be set
-	requireCloudToken := steampipeconfig.IsCloudWorkspaceIdentifier(viper.GetString(constants.ArgWorkspaceDatabase)) ||
-		steampipeconfig.IsCloudWorkspaceIdentifier(viper.GetString(constants.ArgSnapshotLocation))
+	snapshotToCloud := isCloudSnapshotLocation(viper.GetString(constants.ArgSnapshotLocation))
+	requireCloudToken := steampipeconfig.IsCloudWorkspaceIdentifier(viper.GetString(constants.ArgWorkspaceDatabase)) || snapshotToCloud
 
 	// verify cloud token and workspace has been set
 	if requireCloudToken && token == "" {
@@ -47,9 +48,28 @@ func ValidateSnapshotArgs(ctx context.Context) error {
 		return fmt.Errorf("to share snapshots, cloud host must be set")
 	}
 
+	if err := validateSnapshotRetention(snapshotToCloud); err != nil {
+		return err
+	}
+
 	return validateSnapshotTags()
 }
 
+func isCloudSnapshotLocation(snapshotLocation string) bool {
+	return !export.IsS3Location(snapshotLocation) && steampipeconfig.IsCloudWorkspaceIdentifier(snapshotLocation)
+}
+
+func validateSnapshotRetention(snapshotToCloud bool) error {
+	policy, err := cloud.SnapshotRetentionPolicy()
+	if err != nil {
+		return err
+	}
+	if snapshotToCloud && !policy.Empty() {
+		return fmt.Errorf("--%s and --%s are only supported for directory and S3 snapshot locations", constants.ArgSnapshotRetainCount, constants.ArgSnapshotRetainAge)
+	}
+	return nil
+}
+
 func validateSnapshotLocation(ctx context.Context, cloudToken string) error {
 	snapshotLocation := viper.GetString(constants.ArgSnapshotLocation)
 
@@ -61,6 +81,11 @@ func validateSnapshotLocation(ctx context.Context, cloudToken string) error {
 		return setSnapshotLocationFromDefaultWorkspace(ctx, cloudToken)
 	}
 
+	// S3 locations are verified when the snapshot is saved
+	if export.IsS3Location(snapshotLocation) {
+		return nil
+	}
+
 	// if it is NOT a workspace handle, assume it is a local file location:
 	// tildefy it and ensure it exists
 	if !steampipeconfig.IsCloudWorkspaceIdentifier(snapshotLocation) {
@@ -106,20 +106,27 @@ func setDefaultsFromEnv() {
 
 	// a map of known environment variables to map to viper keys
 	envMappings := map[string]envMapping{
-		constants.EnvInstallDir:        {constants.ArgInstallDir, "string"},
-		constants.EnvWorkspaceChDir:    {constants.ArgModLocation, "string"},
-		constants.EnvModLocation:       {constants.ArgModLocation, "string"},
-		constants.EnvIntrospection:     {constants.ArgIntrospection, "string"},
-		constants.EnvTelemetry:         {constants.ArgTelemetry, "string"},
-		constants.EnvUpdateCheck:       {constants.ArgUpdateCheck, "bool"},
-		constants.EnvCloudHost:         {constants.ArgCloudHost, "string"},
-		constants.EnvCloudToken:        {constants.ArgCloudToken, "string"},
-		constants.EnvSnapshotLocation:  {constants.ArgSnapshotLocation, "string"},
-		constants.EnvWorkspaceDatabase: {constants.ArgWorkspaceDatabase, "string"},
-		constants.EnvServicePassword:   {constants.ArgServicePassword, "string"},
-		constants.EnvCheckDisplayWidth: {constants.ArgCheckDisplayWidth, "int"},
-		constants.EnvMaxParallel:       {constants.ArgMaxParallel, "int"},
-		constants.EnvQueryTimeout:      {constants.ArgDatabaseQueryTimeout, "int"},
+		constants.EnvInstallDir:            {constants.ArgInstallDir, "string"},
+		constants.EnvWorkspaceChDir:        {constants.ArgModLocation, "string"},
+		constants.EnvModLocation:           {constants.ArgModLocation, "string"},
+		constants.EnvIntrospection:         {constants.ArgIntrospection, "string"},
+		constants.EnvTelemetry:             {constants.ArgTelemetry, "string"},
+		constants.EnvUpdateCheck:           {constants.ArgUpdateCheck, "bool"},
+		constants.EnvCloudHost:             {constants.ArgCloudHost, "string"},
+		constants.EnvCloudToken:            {constants.ArgCloudToken, "string"},
+		constants.EnvSnapshotLocation:      {constants.ArgSnapshotLocation, "string"},
+		constants.EnvSnapshotS3Endpoint:    {constants.ArgSnapshotS3Endpoint, "string"},
+		constants.EnvWorkspaceDatabase:     {constants.ArgWorkspaceDatabase, "string"},
+		constants.EnvServicePassword:       {constants.ArgServicePassword, "string"},
+		constants.EnvCheckDisplayWidth:     {constants.ArgCheckDisplayWidth, "int"},
+		constants.EnvMaxParallel:           {constants.ArgMaxParallel, "int"},
+		constants.EnvQueryTimeout:          {constants.ArgDatabaseQueryTimeout, "int"},
+		constants.EnvCheckHistory:          {constants.ArgCheckHistory, "bool"},
+		constants.EnvCheckHistoryRetention: {constants.ArgHistoryRetention, "string"},
+		constants.EnvQueryAudit:            {constants.ArgQueryAudit, "bool"},
+		constants.EnvQueryAuditRetention:   {constants.ArgQueryAuditRetention, "string"},
+		constants.EnvQueryAuditPlans:       {constants.ArgQueryAuditPlans, "bool"},
+		constants.EnvDashboardAuthToken:    {constants.ArgDashboardAuthToken, "string"},
 	}
 
 	for k, v := range envMappings {
@@ -18,6 +18,13 @@ const (
 	ArgDashboard            = "dashboard"
 	ArgDashboardListen      = "dashboard-listen"
 	ArgDashboardPort        = "dashboard-port"
+	ArgDashboardAuth        = "dashboard-auth"
+	ArgDashboardAuthToken   = "dashboard-auth-token"
+	ArgDashboardAuthUsers   = "dashboard-auth-users"
+	ArgDashboardAuthHeader  = "dashboard-auth-header"
+	ArgDashboardAuthGroups  = "dashboard-auth-groups-header"
+	ArgDashboardAccess      = "dashboard-access"
+	ArgScheduler            = "scheduler"
 	ArgForeground           = "foreground"
 	ArgInvoker              = "invoker"
 	ArgUpdateCheck          = "update-check"
@@ -57,6 +64,28 @@ const (
 	ArgModLocation          = "mod-location"
 	ArgSnapshotLocation     = "snapshot-location"
 	ArgSnapshotTitle        = "snapshot-title"
+	ArgSnapshotS3Endpoint   = "snapshot-s3-endpoint"
+	ArgSnapshotRetainCount  = "snapshot-retain-count"
+	ArgSnapshotRetainAge    = "snapshot-retain-age"
+	ArgCompareTo            = "compare-to"
+	ArgNotify               = "notify"
+	ArgMetricsListen        = "metrics-listen"
+	ArgMetricsPort          = "metrics-port"
+	ArgMetricsInterval      = "metrics-interval"
+	ArgFrom                 = "from"
+	ArgTo                   = "to"
+	ArgRule                 = "rule"
+	ArgExcludeRule          = "exclude-rule"
+	ArgFix                  = "fix"
+	ArgMinScore             = "min-score"
+	ArgControlTimeout       = "control-timeout"
+	ArgControlRetries       = "control-retries"
+	ArgControlRetryOn       = "control-retry-on"
+	ArgCheckHistory         = "history"
+	ArgHistoryRetention     = "history-retention"
+	ArgQueryAudit           = "query-audit"
+	ArgQueryAuditRetention  = "query-audit-retention"
+	ArgQueryAuditPlans      = "query-audit-plans"
 )
 
 // metaquery mode arguments
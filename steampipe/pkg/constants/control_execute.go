@@ -3,7 +3,13 @@ package constants
 const (
 	// ControlQueryCancellationTimeoutSecs is maximum number of seconds to wait for control queries to finish cancelling
 	ControlQueryCancellationTimeoutSecs = 30
-	// MaxControlRunAttempts determines how many time should a cotnrol run should be retried
-	// in the case of a GRPC connectivity error
-	MaxControlRunAttempts = 2
+	// DefaultControlRunRetries determines how many times a control run should be retried
+	// if it fails with a retryable error and no retry count is set for the control
+	DefaultControlRunRetries = 1
+	// ControlRunRetryBackoffMs is the delay before the first retry of a control run - this doubles for each subsequent retry
+	ControlRunRetryBackoffMs = 500
+	// ControlRunMaxRetryBackoffSecs is the maximum delay between retries of a control run
+	ControlRunMaxRetryBackoffSecs = 30
+	// DefaultCheckHistoryRetention is the default maximum age of check runs kept in the history tables
+	DefaultCheckHistoryRetention = "90d"
 )
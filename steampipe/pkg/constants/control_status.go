@@ -6,4 +6,6 @@ const (
 	ControlSkip  = "skip"
 	ControlInfo  = "info"
 	ControlError = "error"
+	// ControlExempt is the status of an alarm which has been accepted by an exemption
+	ControlExempt = "exempt"
 )
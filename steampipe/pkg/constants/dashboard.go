@@ -9,6 +9,10 @@ import (
 const (
 	DashboardServerDefaultPort    = 9194
 	DashboardAssetsImageRefFormat = "us-docker.pkg.dev/steampipe/steampipe/assets:%s"
+
+	// the default request headers used to identify the user when the dashboard server is behind a trusted proxy
+	DashboardAuthDefaultUserHeader   = "X-Forwarded-User"
+	DashboardAuthDefaultGroupsHeader = "X-Forwarded-Groups"
 )
 
 var (
@@ -57,6 +57,30 @@ const (
 	CommandCacheClear                = "cache_clear"
 
 	CommandTableScanMetadata = "scan_metadata"
+
+	// InternalSchema is the schema used to store steampipe internal data, e.g. the scheduler run history
+	InternalSchema = "steampipe_internal"
+
+	InternalTableScheduledRun = "scheduled_run"
+	InternalTableCheckRuns    = "check_runs"
+	InternalTableCheckResults = "check_results"
+
+	// views of the check history, showing result counts for each check run
+	InternalViewBenchmarkTrend = "check_benchmark_trend"
+	InternalViewControlTrend   = "check_control_trend"
+	InternalViewResourceTrend  = "check_resource_trend"
+
+	// InternalTableQueryAudit is the foreign table which reads the query audit log
+	InternalTableQueryAudit = "query_audit"
+	// InternalViewQueryAuditLog shows the entries of the query audit log as typed columns
+	InternalViewQueryAuditLog = "query_audit_log"
+	// QueryAuditServer is the file_fdw foreign server used by the query audit table
+	QueryAuditServer = "steampipe_query_audit"
+	// DefaultQueryAuditRetention is the default maximum age of query audit log files
+	DefaultQueryAuditRetention = "30d"
+
+	// ModTestSchema is the scratch schema which 'steampipe mod test' loads fixture tables into
+	ModTestSchema = "steampipe_mod_test"
 )
 
 // Functions :: a list of SQLFunc objects that are installed in the db 'internal' schema startup
@@ -81,6 +105,8 @@ end;
 var ReservedConnectionNames = []string{
 	"public",
 	FunctionSchema,
+	InternalSchema,
+	ModTestSchema,
 }
 
 // introspection table names
@@ -4,6 +4,8 @@ import "time"
 
 var (
 	DashboardServiceStartTimeout = 30 * time.Second
+	SchedulerServiceStartTimeout = 30 * time.Second
 	DBConnectionTimeout          = 5 * time.Second
 	ServicePingInterval          = 50 * time.Millisecond
+	QueryAuditSyncInterval       = 5 * time.Second
 )
@@ -8,11 +8,14 @@ const (
 	EnvServicePassword = "STEAMPIPE_DATABASE_PASSWORD"
 	EnvMaxParallel     = "STEAMPIPE_MAX_PARALLEL"
 
-	EnvSnapshotLocation  = "STEAMPIPE_SNAPSHOT_LOCATION"
-	EnvWorkspaceDatabase = "STEAMPIPE_WORKSPACE_DATABASE"
-	EnvWorkspaceProfile  = "STEAMPIPE_WORKSPACE"
-	EnvCloudHost         = "STEAMPIPE_CLOUD_HOST"
-	EnvCloudToken        = "STEAMPIPE_CLOUD_TOKEN"
+	EnvSnapshotLocation   = "STEAMPIPE_SNAPSHOT_LOCATION"
+	EnvSnapshotS3Endpoint = "STEAMPIPE_SNAPSHOT_S3_ENDPOINT"
+	EnvWorkspaceDatabase  = "STEAMPIPE_WORKSPACE_DATABASE"
+	EnvWorkspaceProfile   = "STEAMPIPE_WORKSPACE"
+	EnvCloudHost          = "STEAMPIPE_CLOUD_HOST"
+	EnvCloudToken         = "STEAMPIPE_CLOUD_TOKEN"
+
+	EnvDashboardAuthToken = "STEAMPIPE_DASHBOARD_AUTH_TOKEN"
 
 	EnvCheckDisplayWidth = "STEAMPIPE_CHECK_DISPLAY_WIDTH"
 	EnvCacheEnabled      = "STEAMPIPE_CACHE"
@@ -20,6 +23,13 @@ const (
 	EnvCacheMaxSize      = "STEAMPIPE_CACHE_MAX_SIZE_MB"
 	EnvQueryTimeout      = "STEAMPIPE_QUERY_TIMEOUT"
 
+	EnvCheckHistory          = "STEAMPIPE_CHECK_HISTORY"
+	EnvCheckHistoryRetention = "STEAMPIPE_CHECK_HISTORY_RETENTION"
+
+	EnvQueryAudit          = "STEAMPIPE_QUERY_AUDIT"
+	EnvQueryAuditRetention = "STEAMPIPE_QUERY_AUDIT_RETENTION"
+	EnvQueryAuditPlans     = "STEAMPIPE_QUERY_AUDIT_PLANS"
+
 	EnvConnectionWatcher        = "STEAMPIPE_CONNECTION_WATCHER"
 	EnvWorkspaceChDir           = "STEAMPIPE_WORKSPACE_CHDIR"
 	EnvModLocation              = "STEAMPIPE_MOD_LOCATION"
@@ -28,6 +38,9 @@ const (
 	EnvWorkspaceProfileLocation = "STEAMPIPE_WORKSPACE_PROFILES_LOCATION"
 	EnvDiagnostics              = "STEAMPIPE_DIAGNOSTICS"
 
+	// EnvSecretsKey is the base64 encoded key used to decrypt 'encrypted://' variable values
+	EnvSecretsKey = "STEAMPIPE_SECRETS_KEY"
+
 	// EnvInputVarPrefix is the prefix for environment variables that represent values for input variables.
 	EnvInputVarPrefix = "SP_VAR_"
 )
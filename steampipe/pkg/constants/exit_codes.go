@@ -6,6 +6,10 @@ const (
 	ExitCodeInsufficientOrWrongArguments = 2
 	ExitCodeLoadingError                 = 3
 	ExitCodePluginListFailure            = 4
+	ExitCodeCheckRegressions             = 5
+	ExitCodeModTestFailures              = 6
+	ExitCodeModLintErrors                = 7
+	ExitCodeCheckScoreBelowMinimum       = 8
 	ExitCodeNoModFile                    = 15
 	ExitCodeBindPortUnavailable          = 31
 )
@@ -2,6 +2,7 @@ package constants
 
 // Constants for History
 const (
-	HistoryFile = "history.json" // File to store historical data
-	HistorySize = 500            // Number of historical records to store
+	HistoryFile      = "history.json"       // File to store the queries of the history - in the format read by older versions
+	QueryHistoryFile = "query_history.json" // File to store the history entries, with their timestamp and result
+	HistorySize      = 500                  // Number of historical records to store
 )
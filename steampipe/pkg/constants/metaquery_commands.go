@@ -21,6 +21,10 @@ const (
 	CmdSearchPathPrefix = ".search_path_prefix" // set search path prefix
 	CmdCache            = ".cache"              // cache control
 	CmdAutoComplete     = ".autocomplete"       // enable or disable auto complete
+	CmdExport           = ".export"             // export the last query result to a file
+	CmdEdit             = ".edit"               // edit the current query buffer in an external editor
+	CmdExplain          = ".explain"            // explain the last query
+	CmdHistory          = ".history"            // list or re-run queries from the history
 )
 
 // ArgFromMetaquery converts a metaquery of form '.header' into the config argument used to set the mode, i.e. 'header'
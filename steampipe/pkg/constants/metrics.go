@@ -0,0 +1,6 @@
+package constants
+
+const (
+	MetricsServerDefaultPort = 9195
+	MetricsDefaultInterval   = "60s"
+)
@@ -10,4 +10,9 @@ const (
 	OutputFormatBrief         = "brief"
 	OutputFormatSnapshot      = "snapshot"
 	OutputFormatSnapshotShort = "sps"
+	OutputFormatMarkdown      = "md"
+	OutputFormatNDJSON        = "ndjson"
+	OutputFormatHTML          = "html"
+	OutputFormatParquet       = "parquet"
+	OutputFormatSnapshotHTML  = "snapshot_html"
 )
@@ -17,6 +17,10 @@ const PostgresqlConfContent = `
 # First, use Steampipe's default settings for Postgres.
 include = 'steampipe.conf'
 
+# If the query audit log is enabled ('steampipe service start --query-audit'),
+# use the settings which log every statement.
+include_if_exists = 'query_audit.conf'
+
 # Second, allow users to customize Postgres settings with custom '.conf' files
 # created in the 'postgresql.conf.d' directory. Use with care, these settings
 # overwrite any 'steampipe.conf' settings above.
@@ -65,3 +69,37 @@ log_min_duration_statement=5000
 max_locks_per_transaction = 2048 
 
 `
+
+const QueryAuditConfContent = `
+# ----------------------------------------------
+# Steampipe's query audit Postgres configuration
+# ----------------------------------------------
+#
+# DO NOT EDIT THIS FILE!
+# It is written when Steampipe starts with the query audit log enabled, and
+# removed when it starts with the query audit log disabled.
+#
+
+# Write a CSV log alongside the database log. Steampipe converts the statement
+# and error entries of the CSV log into the JSONL query audit log.
+log_destination='stderr,csvlog'
+
+# Log every statement with its duration. Note that these entries will also
+# appear in the database log.
+log_min_duration_statement=0
+`
+
+// QueryAuditPlanConfContent is added to the query audit configuration if '--query-audit-plans' is set and the
+// database includes the auto_explain module
+const QueryAuditPlanConfContent = `
+# Log the plan of every statement with the number of rows it returned and the
+# search path it was run with. Steampipe adds these to the statement entries
+# of the query audit log. Every statement is run with EXPLAIN ANALYZE, which
+# adds overhead to each query.
+session_preload_libraries='auto_explain'
+auto_explain.log_min_duration=0
+auto_explain.log_analyze=on
+auto_explain.log_timing=off
+auto_explain.log_settings=on
+auto_explain.log_format=json
+`
@@ -0,0 +1,123 @@
+package controldiff
+
+import (
+	"sort"
+)
+
+// ResultChange is a change in the result for a single control/resource/dimension combination
+type ResultChange struct {
+	Control    string `json:"control"`
+	Title      string `json:"title,omitempty"`
+	Severity   string `json:"severity,omitempty"`
+	Resource   string `json:"resource"`
+	Dimensions string `json:"dimensions,omitempty"`
+	OldStatus  string `json:"old_status,omitempty"`
+	NewStatus  string `json:"new_status,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+// CheckDiff is the result of comparing two check runs
+type CheckDiff struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+	// results which are alarm/error, and were either passing or not present in the old run
+	NewlyFailing []*ResultChange `json:"newly_failing"`
+	// results which were alarm/error and are now ok/info/skip
+	NewlyPassing []*ResultChange `json:"newly_passing"`
+	// results which are present in the old run but not the new
+	Disappeared []*ResultChange `json:"disappeared"`
+	// all other status changes (including new results which are not failing)
+	StatusChanged []*ResultChange `json:"status_changed"`
+	// the number of results which are unchanged
+	UnchangedCount int `json:"unchanged_count"`
+}
+
+// HasRegressions returns whether any results are newly failing
+func (d *CheckDiff) HasRegressions() bool {
+	return len(d.NewlyFailing) > 0
+}
+
+// HasChanges returns whether there are any differences between the runs
+func (d *CheckDiff) HasChanges() bool {
+	return len(d.NewlyFailing)+len(d.NewlyPassing)+len(d.Disappeared)+len(d.StatusChanged) > 0
+}
+
+// Diff compares the results of 2 check runs
+func Diff(oldResults, newResults *ResultSet) *CheckDiff {
+	res := &CheckDiff{
+		Old:           oldResults.Source,
+		New:           newResults.Source,
+		NewlyFailing:  []*ResultChange{},
+		NewlyPassing:  []*ResultChange{},
+		Disappeared:   []*ResultChange{},
+		StatusChanged: []*ResultChange{},
+	}
+
+	oldByKey := oldResults.matchResults(newResults)
+	newByKey := newResults.matchResults(oldResults)
+	for key, newResult := range newByKey {
+		oldResult, existed := oldByKey[key]
+		change := newResultChange(oldResult, newResult)
+
+		switch {
+		case existed && oldResult.Status == newResult.Status:
+			res.UnchangedCount++
+		case newResult.IsFailing() && (!existed || !oldResult.IsFailing()):
+			res.NewlyFailing = append(res.NewlyFailing, change)
+		case existed && oldResult.IsFailing() && !newResult.IsFailing():
+			res.NewlyPassing = append(res.NewlyPassing, change)
+		default:
+			res.StatusChanged = append(res.StatusChanged, change)
+		}
+	}
+	for key, oldResult := range oldByKey {
+		if _, ok := newByKey[key]; !ok {
+			res.Disappeared = append(res.Disappeared, newResultChange(oldResult, nil))
+		}
+	}
+
+	for _, changes := range [][]*ResultChange{res.NewlyFailing, res.NewlyPassing, res.Disappeared, res.StatusChanged} {
+		sortChanges(changes)
+	}
+	return res
+}
+
+func newResultChange(oldResult, newResult *ControlResult) *ResultChange {
+	// take the control properties from the most recent result
+	source := newResult
+	if source == nil {
+		source = oldResult
+	}
+	res := &ResultChange{
+		Control:  source.Control,
+		Title:    source.Title,
+		Severity: source.Severity,
+		Resource: source.Resource,
+		Reason:   source.Reason,
+	}
+	for i, d := range source.Dimensions {
+		if i > 0 {
+			res.Dimensions += ", "
+		}
+		res.Dimensions += d.Key + "=" + d.Value
+	}
+	if oldResult != nil {
+		res.OldStatus = oldResult.Status
+	}
+	if newResult != nil {
+		res.NewStatus = newResult.Status
+	}
+	return res
+}
+
+func sortChanges(changes []*ResultChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Control != changes[j].Control {
+			return changes[i].Control < changes[j].Control
+		}
+		if changes[i].Resource != changes[j].Resource {
+			return changes[i].Resource < changes[j].Resource
+		}
+		return changes[i].Dimensions < changes[j].Dimensions
+	})
+}
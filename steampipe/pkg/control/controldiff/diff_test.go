@@ -0,0 +1,202 @@
+package controldiff
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/control/controlexecute"
+	"github.com/turbot/steampipe/pkg/query/queryresult"
+)
+
+const testJsonExport = `{
+	"group_id": "root_result_group",
+	"groups": [
+		{
+			"group_id": "benchmark.b1",
+			"groups": [],
+			"controls": [
+				{
+					"control_id": "control.c1",
+					"severity": "high",
+					"results": [
+						{"reason": "r1", "resource": "res1", "status": "ok", "dimensions": [{"key": "region", "value": "us-east-1"}]},
+						{"reason": "r2", "resource": "res2", "status": "alarm", "dimensions": []},
+						{"reason": "r3", "resource": "res3", "status": "ok", "dimensions": []},
+						{"reason": "r4", "resource": "res4", "status": "alarm", "dimensions": []}
+					]
+				}
+			]
+		}
+	],
+	"controls": null
+}`
+
+const testSnapshot = `{
+	"schema_version": "20220929",
+	"panels": {
+		"mod.benchmark.b1": {"name": "mod.benchmark.b1", "panel_type": "benchmark"},
+		"mod.control.c1": {
+			"name": "mod.control.c1",
+			"panel_type": "control",
+			"properties": {"severity": "high"},
+			"data": {
+				"columns": [],
+				"rows": [
+					{"reason": "r1", "resource": "res1", "status": "alarm", "region": "us-east-1"},
+					{"reason": "r2", "resource": "res2", "status": "ok"},
+					{"reason": "r4", "resource": "res4", "status": "error"},
+					{"reason": "r5", "resource": "res5", "status": "ok"}
+				]
+			}
+		}
+	}
+}`
+
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiffJsonExportWithSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	oldResults, err := LoadResultSet(writeTestFile(t, dir, "old.json", testJsonExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newResults, err := LoadResultSet(writeTestFile(t, dir, "new.sps", testSnapshot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oldResults.Len() != 4 || newResults.Len() != 4 {
+		t.Fatalf("expected 4 results in each run, got %d and %d", oldResults.Len(), newResults.Len())
+	}
+
+	diff := Diff(oldResults, newResults)
+
+	expected := map[string][]*ResultChange{
+		"newly failing":  {{Control: "mod.control.c1", Resource: "res1", OldStatus: "ok", NewStatus: "alarm"}},
+		"newly passing":  {{Control: "mod.control.c1", Resource: "res2", OldStatus: "alarm", NewStatus: "ok"}},
+		"status changed": {{Control: "mod.control.c1", Resource: "res4", OldStatus: "alarm", NewStatus: "error"}, {Control: "mod.control.c1", Resource: "res5", NewStatus: "ok"}},
+		"disappeared":    {{Control: "control.c1", Resource: "res3", OldStatus: "ok"}},
+	}
+	actual := map[string][]*ResultChange{
+		"newly failing":  diff.NewlyFailing,
+		"newly passing":  diff.NewlyPassing,
+		"status changed": diff.StatusChanged,
+		"disappeared":    diff.Disappeared,
+	}
+	for name, expectedChanges := range expected {
+		actualChanges := actual[name]
+		if len(actualChanges) != len(expectedChanges) {
+			t.Errorf("%s: expected %d changes, got %d", name, len(expectedChanges), len(actualChanges))
+			continue
+		}
+		for i, e := range expectedChanges {
+			a := actualChanges[i]
+			if a.Control != e.Control || a.Resource != e.Resource || a.OldStatus != e.OldStatus || a.NewStatus != e.NewStatus {
+				t.Errorf("%s: expected %+v, got %+v", name, e, a)
+			}
+		}
+	}
+	if !diff.HasRegressions() {
+		t.Error("expected diff to have regressions")
+	}
+}
+
+func TestDiffDimensionsAreMatched(t *testing.T) {
+	oldResults := NewResultSet("old")
+	newResults := NewResultSet("new")
+	for _, region := range []string{"us-east-1", "us-east-2"} {
+		oldResults.Add(&ControlResult{Control: "mod.control.c1", Resource: "res1", Status: "ok", Dimensions: []controlexecute.Dimension{{Key: "region", Value: region}}})
+	}
+	// the same resource in a different region is a different result
+	newResults.Add(&ControlResult{Control: "control.c1", Resource: "res1", Status: "ok", Dimensions: []controlexecute.Dimension{{Key: "region", Value: "us-east-1"}}})
+	newResults.Add(&ControlResult{Control: "control.c1", Resource: "res1", Status: "ok", Dimensions: []controlexecute.Dimension{{Key: "region", Value: "us-west-1"}}})
+
+	diff := Diff(oldResults, newResults)
+	if diff.UnchangedCount != 1 || len(diff.Disappeared) != 1 || len(diff.StatusChanged) != 1 {
+		t.Errorf("unexpected diff: %d unchanged, %d disappeared, %d status changed", diff.UnchangedCount, len(diff.Disappeared), len(diff.StatusChanged))
+	}
+	if diff.HasRegressions() {
+		t.Error("expected diff to have no regressions")
+	}
+}
+
+func TestDiffControlsOfDifferentMods(t *testing.T) {
+	newResultSet := func(source string, statuses map[string]string) *ResultSet {
+		res := NewResultSet(source)
+		for control, status := range statuses {
+			res.Add(&ControlResult{Control: control, Resource: "res1", Status: status})
+		}
+		return res
+	}
+
+	// controls with the same name in different mods are different controls
+	oldResults := newResultSet("old", map[string]string{"m1.control.c1": "ok", "m2.control.c1": "alarm"})
+	newResults := newResultSet("new", map[string]string{"m1.control.c1": "ok", "m2.control.c1": "alarm"})
+	diff := Diff(oldResults, newResults)
+	if diff.UnchangedCount != 2 || diff.HasChanges() {
+		t.Errorf("expected 2 unchanged results: %d unchanged, %d newly failing, %d newly passing", diff.UnchangedCount, len(diff.NewlyFailing), len(diff.NewlyPassing))
+	}
+
+	// if the alias of the root mod differs between the runs, its controls are matched on the unqualified name,
+	// while the controls of the dependency mod are still matched on the qualified name
+	newResults = newResultSet("new", map[string]string{"m1_renamed.control.c1": "alarm", "m2.control.c1": "alarm"})
+	diff = Diff(oldResults, newResults)
+	if diff.UnchangedCount != 1 || len(diff.NewlyFailing) != 1 || len(diff.Disappeared) != 0 {
+		t.Fatalf("unexpected diff: %d unchanged, %d newly failing, %d disappeared", diff.UnchangedCount, len(diff.NewlyFailing), len(diff.Disappeared))
+	}
+	if change := diff.NewlyFailing[0]; change.Control != "m1_renamed.control.c1" || change.OldStatus != "ok" {
+		t.Errorf("unexpected newly failing result: %+v", change)
+	}
+}
+
+func TestDiffExemptSnapshotWithExecutionTree(t *testing.T) {
+	rows := controlexecute.ResultRows{
+		{Reason: "r1", Resource: "res1", Status: constants.ControlOk, Dimensions: []controlexecute.Dimension{{Key: "region", Value: "us-east-1"}}},
+		{Reason: "r2", Resource: "res2", Status: constants.ControlExempt, ExemptionName: "exemption.e1", ExemptionReason: "accepted", Dimensions: []controlexecute.Dimension{{Key: "region", Value: "us-east-1"}}},
+	}
+	dimensionSchema := map[string]*queryresult.ColumnDef{"region": {Name: "region", DataType: "TEXT"}}
+
+	// write the rows to a snapshot in the same format as a check run
+	snapshot, err := json.Marshal(map[string]interface{}{
+		"schema_version": "20220929",
+		"panels": map[string]interface{}{
+			"mod.control.c1": map[string]interface{}{
+				"name":       "mod.control.c1",
+				"panel_type": "control",
+				"data":       rows.ToLeafData(dimensionSchema),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshotResults, err := LoadResultSet(writeTestFile(t, t.TempDir(), "old.sps", string(snapshot)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	liveResults := NewResultSet("live")
+	liveResults.AddExecutionTree(&controlexecute.ExecutionTree{
+		ControlRuns: []*controlexecute.ControlRun{{FullName: "mod.control.c1", Rows: rows}},
+	})
+
+	diff := Diff(snapshotResults, liveResults)
+	if diff.UnchangedCount != 2 || len(diff.Disappeared) != 0 || len(diff.StatusChanged) != 0 || diff.HasRegressions() {
+		t.Errorf("expected exempt results to match: %d unchanged, %d disappeared, %d status changed", diff.UnchangedCount, len(diff.Disappeared), len(diff.StatusChanged))
+	}
+}
+
+func TestLoadResultSetInvalidFormat(t *testing.T) {
+	path := writeTestFile(t, t.TempDir(), "results.json", `{"foo": "bar"}`)
+	if _, err := LoadResultSet(path); err == nil {
+		t.Error("expected error loading file in unrecognised format")
+	}
+}
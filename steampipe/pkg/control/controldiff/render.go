@@ -0,0 +1,126 @@
+package controldiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/turbot/steampipe/pkg/constants"
+)
+
+// supported output formats for a CheckDiff
+var OutputFormats = []string{constants.OutputFormatText, constants.OutputFormatJSON, constants.OutputFormatMarkdown}
+
+type diffSection struct {
+	title   string
+	color   func(interface{}) aurora.Value
+	changes []*ResultChange
+}
+
+func (d *CheckDiff) sections() []diffSection {
+	return []diffSection{
+		{"Newly failing", constants.BoldRed, d.NewlyFailing},
+		{"Newly passing", constants.BoldGreen, d.NewlyPassing},
+		{"Status changed", constants.BoldYellow, d.StatusChanged},
+		{"Disappeared", constants.Bold, d.Disappeared},
+	}
+}
+
+// Render writes the diff to the writer in the given output format
+func (d *CheckDiff) Render(w io.Writer, format string) error {
+	switch format {
+	case constants.OutputFormatText:
+		return d.renderText(w)
+	case constants.OutputFormatJSON:
+		return d.renderJson(w)
+	case constants.OutputFormatMarkdown:
+		return d.renderMarkdown(w)
+	}
+	return fmt.Errorf("invalid output format '%s' - must be one of: %s", format, strings.Join(OutputFormats, ", "))
+}
+
+func (d *CheckDiff) renderText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Comparing %s with %s\n\n", constants.Bold(d.Old), constants.Bold(d.New))
+	for _, s := range d.sections() {
+		if len(s.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s (%d)\n", s.color(s.title), len(s.changes))
+		for _, c := range s.changes {
+			fmt.Fprintf(&b, "  %s %s %s", statusTransition(c), c.Control, c.Resource)
+			if c.Dimensions != "" {
+				fmt.Fprintf(&b, " [%s]", c.Dimensions)
+			}
+			fmt.Fprintf(&b, "\n      %s\n", constants.Gray3(c.Reason))
+		}
+		b.WriteString("\n")
+	}
+	if !d.HasChanges() {
+		b.WriteString("No changes.\n\n")
+	}
+	fmt.Fprintf(&b, "%s: %d newly failing, %d newly passing, %d status changed, %d disappeared, %d unchanged\n",
+		constants.Bold("Summary"),
+		len(d.NewlyFailing),
+		len(d.NewlyPassing),
+		len(d.StatusChanged),
+		len(d.Disappeared),
+		d.UnchangedCount)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (d *CheckDiff) renderJson(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(d)
+}
+
+func (d *CheckDiff) renderMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Check diff\n\nComparing `%s` with `%s`\n\n", d.Old, d.New)
+	b.WriteString("| Newly failing | Newly passing | Status changed | Disappeared | Unchanged |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %d | %d |\n\n", len(d.NewlyFailing), len(d.NewlyPassing), len(d.StatusChanged), len(d.Disappeared), d.UnchangedCount)
+
+	for _, s := range d.sections() {
+		if len(s.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "## %s\n\n", s.title)
+		b.WriteString("| Control | Resource | Dimensions | Old status | New status | Reason |\n")
+		b.WriteString("|---|---|---|---|---|---|\n")
+		for _, c := range s.changes {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+				escapeMarkdownCell(c.Control),
+				escapeMarkdownCell(c.Resource),
+				escapeMarkdownCell(c.Dimensions),
+				c.OldStatus,
+				c.NewStatus,
+				escapeMarkdownCell(c.Reason))
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func statusTransition(c *ResultChange) string {
+	oldStatus := c.OldStatus
+	if oldStatus == "" {
+		oldStatus = "-"
+	}
+	newStatus := c.NewStatus
+	if newStatus == "" {
+		newStatus = "-"
+	}
+	return fmt.Sprintf("%-5s -> %-5s", oldStatus, newStatus)
+}
+
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,247 @@
+package controldiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/control/controlexecute"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+// ControlResult is a single control result row, along with the properties of the control which produced it
+type ControlResult struct {
+	Control    string                     `json:"control"`
+	Title      string                     `json:"title,omitempty"`
+	Severity   string                     `json:"severity,omitempty"`
+	Resource   string                     `json:"resource"`
+	Reason     string                     `json:"reason"`
+	Status     string                     `json:"status"`
+	Dimensions []controlexecute.Dimension `json:"dimensions,omitempty"`
+}
+
+// Key returns the identity of the result - the control, the resource and the dimension values
+func (r *ControlResult) Key() string {
+	return r.key(r.Control)
+}
+
+// key returns the identity of the result, using the given name for the control
+func (r *ControlResult) key(control string) string {
+	dimensions := make([]string, len(r.Dimensions))
+	for i, d := range r.Dimensions {
+		dimensions[i] = fmt.Sprintf("%s=%s", d.Key, d.Value)
+	}
+	sort.Strings(dimensions)
+	return strings.Join(append([]string{control, r.Resource}, dimensions...), "\x00")
+}
+
+// IsFailing returns whether the result has a status of alarm or error
+func (r *ControlResult) IsFailing() bool {
+	return isFailingStatus(r.Status)
+}
+
+func isFailingStatus(status string) bool {
+	return status == constants.ControlAlarm || status == constants.ControlError
+}
+
+// ResultSet is the flattened set of control results from a check run, keyed by ControlResult.Key
+type ResultSet struct {
+	// the file (or execution) the results were loaded from
+	Source  string
+	results map[string]*ControlResult
+	// the aliases of the mods of the controls of the results
+	mods map[string]bool
+}
+
+func NewResultSet(source string) *ResultSet {
+	return &ResultSet{
+		Source:  source,
+		results: make(map[string]*ControlResult),
+		mods:    make(map[string]bool),
+	}
+}
+
+// Add adds a result to the set
+// if the same control appears in more than one benchmark, its results will have the same key and be stored once
+func (s *ResultSet) Add(result *ControlResult) {
+	if mod := controlModAlias(result.Control); mod != "" {
+		s.mods[mod] = true
+	}
+	s.results[result.Key()] = result
+}
+
+// matchResults returns the results of the set keyed for matching against the results of the other set
+// a result is keyed on the fully qualified control name if the mod of the control also has results in the other set,
+// otherwise on the unqualified control name - this matches the controls of the root mod when its alias differs
+// between the runs, or when one run is a json export (which only qualifies the controls of dependency mods)
+func (s *ResultSet) matchResults(other *ResultSet) map[string]*ControlResult {
+	res := make(map[string]*ControlResult, len(s.results))
+	for _, result := range s.results {
+		control := result.Control
+		if !other.mods[controlModAlias(control)] {
+			control = modconfig.UnqualifiedResourceName(control)
+		}
+		res[result.key(control)] = result
+	}
+	return res
+}
+
+// controlModAlias returns the mod alias of a fully qualified control name, e.g. 'aws_compliance' for
+// 'aws_compliance.control.foo', or an empty string if the name is not qualified
+func controlModAlias(control string) string {
+	parts := strings.Split(control, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[0]
+}
+
+// AddExecutionTree adds all the result rows of the given execution tree to the set
+func (s *ResultSet) AddExecutionTree(tree *controlexecute.ExecutionTree) {
+	for _, run := range tree.ControlRuns {
+		for _, row := range run.Rows {
+			s.Add(&ControlResult{
+				Control:    run.FullName,
+				Title:      run.Title,
+				Severity:   run.Severity,
+				Resource:   row.Resource,
+				Reason:     row.Reason,
+				Status:     row.Status,
+				Dimensions: row.Dimensions,
+			})
+		}
+	}
+}
+
+func (s *ResultSet) Get(key string) (*ControlResult, bool) {
+	res, ok := s.results[key]
+	return res, ok
+}
+
+func (s *ResultSet) Len() int {
+	return len(s.results)
+}
+
+// LoadResultSet loads the results of a check run from either a snapshot (.sps) or a json check export
+func LoadResultSet(path string) (*ResultSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read check results from '%s': %s", path, err.Error())
+	}
+
+	// peek at the top level properties to determine which format this is
+	var header map[string]json.RawMessage
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse check results from '%s': %s", path, err.Error())
+	}
+
+	res := NewResultSet(path)
+	switch {
+	case header["panels"] != nil:
+		err = res.addSnapshot(data)
+	case header["group_id"] != nil:
+		err = res.addJsonExport(data)
+	default:
+		err = fmt.Errorf("unrecognised format - expected a snapshot (%s) or a json check export", constants.SnapshotExtension)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load check results from '%s': %s", filepath.Base(path), err.Error())
+	}
+	return res, nil
+}
+
+// snapshotPanel is the subset of the properties of a snapshot panel needed to extract control results
+type snapshotPanel struct {
+	Name       string `json:"name"`
+	Title      string `json:"title"`
+	PanelType  string `json:"panel_type"`
+	Properties struct {
+		Severity string `json:"severity"`
+	} `json:"properties"`
+	Data *struct {
+		Rows []map[string]interface{} `json:"rows"`
+	} `json:"data"`
+}
+
+func (s *ResultSet) addSnapshot(data []byte) error {
+	var snapshot struct {
+		Panels map[string]snapshotPanel `json:"panels"`
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+	for _, panel := range snapshot.Panels {
+		if panel.PanelType != modconfig.BlockTypeControl || panel.Data == nil {
+			continue
+		}
+		for _, row := range panel.Data.Rows {
+			result := &ControlResult{
+				Control:  panel.Name,
+				Title:    panel.Title,
+				Severity: panel.Properties.Severity,
+			}
+			// the standard columns are reason, resource, status and the exemption of exempt rows
+			// - everything else is a dimension
+			for k, v := range row {
+				switch k {
+				case "reason":
+					result.Reason = typehelpers.ToString(v)
+				case "resource":
+					result.Resource = typehelpers.ToString(v)
+				case "status":
+					result.Status = typehelpers.ToString(v)
+				case "exemption", "exemption_reason":
+					continue
+				default:
+					result.Dimensions = append(result.Dimensions, controlexecute.Dimension{Key: k, Value: typehelpers.ToString(v)})
+				}
+			}
+			s.Add(result)
+		}
+	}
+	return nil
+}
+
+// jsonExportGroup is the subset of the json check export format needed to extract control results
+type jsonExportGroup struct {
+	Groups   []jsonExportGroup `json:"groups"`
+	Controls []struct {
+		ControlId string                      `json:"control_id"`
+		Title     string                      `json:"title"`
+		Severity  string                      `json:"severity"`
+		Results   []*controlexecute.ResultRow `json:"results"`
+	} `json:"controls"`
+}
+
+func (s *ResultSet) addJsonExport(data []byte) error {
+	var root jsonExportGroup
+	if err := json.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	s.addJsonExportGroup(root)
+	return nil
+}
+
+func (s *ResultSet) addJsonExportGroup(group jsonExportGroup) {
+	for _, control := range group.Controls {
+		for _, row := range control.Results {
+			s.Add(&ControlResult{
+				Control:    control.ControlId,
+				Title:      control.Title,
+				Severity:   control.Severity,
+				Resource:   row.Resource,
+				Reason:     row.Reason,
+				Status:     row.Status,
+				Dimensions: row.Dimensions,
+			})
+		}
+	}
+	for _, child := range group.Groups {
+		s.addJsonExportGroup(child)
+	}
+}
@@ -31,20 +31,23 @@ type ControlColorSchemaDefinition struct {
 	CountGraphInfo       string
 	CountGraphOK         string
 	CountGraphSkip       string
+	CountGraphExempt     string
 	CountGraphBracket    string
 
 	// results
-	StatusAlarm string
-	StatusError string
-	StatusSkip  string
-	StatusInfo  string
-	StatusOK    string
-	StatusColon string
-	ReasonAlarm string
-	ReasonError string
-	ReasonSkip  string
-	ReasonInfo  string
-	ReasonOK    string
+	StatusAlarm  string
+	StatusError  string
+	StatusSkip   string
+	StatusInfo   string
+	StatusOK     string
+	StatusExempt string
+	StatusColon  string
+	ReasonAlarm  string
+	ReasonError  string
+	ReasonSkip   string
+	ReasonInfo   string
+	ReasonOK     string
+	ReasonExempt string
 
 	Spacer   string
 	Indent   string
@@ -67,18 +70,21 @@ type ControlColorScheme struct {
 	CountGraphInfo       colorFunc
 	CountGraphOK         colorFunc
 	CountGraphSkip       colorFunc
+	CountGraphExempt     colorFunc
 	CountGraphBracket    colorFunc
 	StatusAlarm          colorFunc
 	StatusError          colorFunc
 	StatusSkip           colorFunc
 	StatusInfo           colorFunc
 	StatusOK             colorFunc
+	StatusExempt         colorFunc
 	StatusColon          colorFunc
 	ReasonAlarm          colorFunc
 	ReasonError          colorFunc
 	ReasonSkip           colorFunc
 	ReasonInfo           colorFunc
 	ReasonOK             colorFunc
+	ReasonExempt         colorFunc
 	Spacer               colorFunc
 	Indent               colorFunc
 
@@ -143,25 +149,28 @@ func (c *ControlColorScheme) Initialise(def *ControlColorSchemaDefinition) error
 	}
 	// populate the color maps
 	c.ReasonColors = map[string]colorFunc{
-		constants.ControlAlarm: c.ReasonAlarm,
-		constants.ControlSkip:  c.ReasonSkip,
-		constants.ControlInfo:  c.ReasonInfo,
-		constants.ControlError: c.ReasonError,
-		constants.ControlOk:    c.ReasonOK,
+		constants.ControlAlarm:  c.ReasonAlarm,
+		constants.ControlSkip:   c.ReasonSkip,
+		constants.ControlInfo:   c.ReasonInfo,
+		constants.ControlError:  c.ReasonError,
+		constants.ControlOk:     c.ReasonOK,
+		constants.ControlExempt: c.ReasonExempt,
 	}
 	c.StatusColors = map[string]colorFunc{
-		constants.ControlAlarm: c.StatusAlarm,
-		constants.ControlSkip:  c.StatusSkip,
-		constants.ControlInfo:  c.StatusInfo,
-		constants.ControlError: c.StatusError,
-		constants.ControlOk:    c.StatusOK,
+		constants.ControlAlarm:  c.StatusAlarm,
+		constants.ControlSkip:   c.StatusSkip,
+		constants.ControlInfo:   c.StatusInfo,
+		constants.ControlError:  c.StatusError,
+		constants.ControlOk:     c.StatusOK,
+		constants.ControlExempt: c.StatusExempt,
 	}
 	c.GraphColors = map[string]colorFunc{
-		constants.ControlAlarm: c.CountGraphAlarm,
-		constants.ControlSkip:  c.CountGraphSkip,
-		constants.ControlInfo:  c.CountGraphInfo,
-		constants.ControlError: c.CountGraphError,
-		constants.ControlOk:    c.CountGraphOK,
+		constants.ControlAlarm:  c.CountGraphAlarm,
+		constants.ControlSkip:   c.CountGraphSkip,
+		constants.ControlInfo:   c.CountGraphInfo,
+		constants.ControlError:  c.CountGraphError,
+		constants.ControlOk:     c.CountGraphOK,
+		constants.ControlExempt: c.CountGraphExempt,
 	}
 
 	c.UseColor = def.UseColor
@@ -185,18 +194,21 @@ var ColorSchemes = map[string]*ControlColorSchemaDefinition{
 		CountGraphInfo:       "bright-cyan",
 		CountGraphOK:         "bright-green",
 		CountGraphSkip:       "gray3",
+		CountGraphExempt:     "bright-magenta",
 		CountGraphBracket:    "gray2",
 		StatusAlarm:          "bold-bright-red",
 		StatusError:          "bold-bright-red",
 		StatusSkip:           "gray3",
 		StatusInfo:           "bright-cyan",
 		StatusOK:             "bright-green",
+		StatusExempt:         "bright-magenta",
 		StatusColon:          "gray1",
 		ReasonAlarm:          "bright-red",
 		ReasonError:          "bright-red",
 		ReasonSkip:           "gray3",
 		ReasonInfo:           "bright-cyan",
 		ReasonOK:             "gray4",
+		ReasonExempt:         "bright-magenta",
 		Spacer:               "gray1",
 		Indent:               "gray1",
 		UseColor:             true,
@@ -217,18 +229,21 @@ var ColorSchemes = map[string]*ControlColorSchemaDefinition{
 		CountGraphInfo:       "bright-cyan",
 		CountGraphOK:         "bright-green",
 		CountGraphSkip:       "gray3",
+		CountGraphExempt:     "bright-magenta",
 		CountGraphBracket:    "gray4",
 		StatusAlarm:          "bold-bright-red",
 		StatusError:          "bold-bright-red",
 		StatusSkip:           "gray3",
 		StatusInfo:           "bright-cyan",
 		StatusOK:             "bright-green",
+		StatusExempt:         "bright-magenta",
 		StatusColon:          "gray5",
 		ReasonAlarm:          "bright-red",
 		ReasonError:          "bright-red",
 		ReasonSkip:           "gray3",
 		ReasonInfo:           "bright-cyan",
 		ReasonOK:             "gray2",
+		ReasonExempt:         "bright-magenta",
 		Spacer:               "gray5",
 		Indent:               "gray5",
 		UseColor:             true,
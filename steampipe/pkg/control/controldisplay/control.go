@@ -97,7 +97,7 @@ func (r ControlRenderer) Render() string {
 
 	// if the control is in error, render an error
 	if r.run.GetError() != nil {
-		errorRenderer := NewErrorRenderer(r.run.GetError(), r.width, r.parentIndent())
+		errorRenderer := NewErrorRenderer(r.run.GetError(), r.run.TimedOut, r.width, r.parentIndent())
 		controlStrings = append(controlStrings,
 			errorRenderer.Render(),
 			// newline after error
@@ -107,9 +107,14 @@ func (r ControlRenderer) Render() string {
 	// now render the results (if any)
 	var resultStrings []string
 	for _, row := range r.run.Rows {
+		reason := row.Reason
+		// for exempt rows, include the exemption in the reason
+		if row.Status == constants.ControlExempt {
+			reason = fmt.Sprintf("%s (%s: %s)", row.Reason, row.ExemptionName, row.ExemptionReason)
+		}
 		resultRenderer := NewResultRenderer(
 			row.Status,
-			row.Reason,
+			reason,
 			row.Dimensions,
 			r.colorGenerator,
 			r.width,
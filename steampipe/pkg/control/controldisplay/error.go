@@ -9,23 +9,29 @@ import (
 
 type ErrorRenderer struct {
 	error error
+	// the control timed out - rendered with a TIMEOUT status rather than ERROR
+	timedOut bool
 
 	// screen width
 	width  int
 	indent string
 }
 
-func NewErrorRenderer(err error, width int, indent string) *ErrorRenderer {
+func NewErrorRenderer(err error, timedOut bool, width int, indent string) *ErrorRenderer {
 	return &ErrorRenderer{
-		error:  err,
-		width:  width,
-		indent: indent,
+		error:    err,
+		timedOut: timedOut,
+		width:    width,
+		indent:   indent,
 	}
 }
 
 func (r ErrorRenderer) Render() string {
-	status := NewResultStatusRenderer(constants.ControlError)
-	statusString := status.Render()
+	statusString := NewResultStatusRenderer(constants.ControlError).Render()
+	if r.timedOut {
+		// timeouts use the error color, so the status is rendered here rather than by the status renderer
+		statusString = fmt.Sprintf("%s%s ", ControlColors.StatusError("TIMEOUT"), ControlColors.StatusColon(":"))
+	}
 	statusWidth := helpers.PrintableLength(statusString)
 	formattedIndent := fmt.Sprintf("%s", ControlColors.Indent(r.indent))
 	indentWidth := helpers.PrintableLength(formattedIndent)
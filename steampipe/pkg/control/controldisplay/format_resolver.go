@@ -26,6 +26,7 @@ func NewFormatResolver() (*FormatResolver, error) {
 		&NullFormatter{},
 		&TextFormatter{},
 		&SnapshotFormatter{},
+		&SnapshotHTMLFormatter{},
 	}
 
 	res := &FormatResolver{
@@ -0,0 +1,41 @@
+package controldisplay
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/control/controlexecute"
+	"github.com/turbot/steampipe/pkg/dashboard/dashboardassets"
+)
+
+// SnapshotHTMLFormatter formats the check results as a self-contained HTML page,
+// which renders the snapshot of the results using the dashboard UI
+type SnapshotHTMLFormatter struct {
+	FormatterBase
+}
+
+func (f *SnapshotHTMLFormatter) Format(ctx context.Context, tree *controlexecute.ExecutionTree) (io.Reader, error) {
+	snapshot, err := executionTreeToSnapshot(tree)
+	if err != nil {
+		return nil, err
+	}
+	snapshotStr, err := snapshot.AsStrippedJson(false)
+	if err != nil {
+		return nil, err
+	}
+	html, err := dashboardassets.SnapshotHTML(ctx, snapshotStr)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(html), nil
+}
+
+func (f *SnapshotHTMLFormatter) FileExtension() string {
+	return ".html"
+}
+
+func (f SnapshotHTMLFormatter) Name() string {
+	return constants.OutputFormatSnapshotHTML
+}
@@ -1,5 +1,22 @@
 package controldisplay
 
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/control/controlexecute"
+	"github.com/turbot/steampipe/pkg/control/controlstatus"
+	"github.com/turbot/steampipe/pkg/filepaths"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
 //
 //type resolveOutputTemplateTestCase struct {
 //	input    string // --export <val>
@@ -181,3 +198,264 @@ package controldisplay
 //func FormatEqual(l, r *OutputTemplate) bool {
 //	return (l.FormatFullName == r.FormatFullName)
 //}
+
+func newTemplateTestTree() *controlexecute.ExecutionTree {
+	title := "S3 buckets should be encrypted"
+	severity := "high"
+	control := &modconfig.Control{
+		ShortName: "s3_encrypted",
+		FullName:  "aws_test.control.s3_encrypted",
+		Title:     &title,
+		Severity:  &severity,
+		Tags:      map[string]string{"service": "AWS/S3", "cis": "true"},
+	}
+
+	root := &controlexecute.ResultGroup{
+		GroupId: controlexecute.RootResultGroupName,
+		Summary: controlexecute.NewGroupSummary(),
+	}
+	var runs []*controlexecute.ControlRun
+	// add the control to two benchmarks, to verify rules are de-duplicated
+	for _, name := range []string{"aws_test.benchmark.one", "aws_test.benchmark.two"} {
+		group := &controlexecute.ResultGroup{
+			GroupId: name,
+			Title:   "Benchmark <" + name + ">",
+			Summary: controlexecute.NewGroupSummary(),
+			Parent:  root,
+		}
+		run := &controlexecute.ControlRun{
+			ControlId: control.ShortName,
+			FullName:  control.FullName,
+			Title:     title,
+			Severity:  severity,
+			Tags:      control.Tags,
+			Control:   control,
+			Summary:   &controlstatus.StatusSummary{Ok: 1, Alarm: 1, Error: 1},
+			Group:     group,
+		}
+		run.Rows = controlexecute.ResultRows{
+			{Status: "ok", Resource: "arn:aws:s3:::ok", Reason: "ok is encrypted", Run: run, Control: control},
+			{Status: "alarm", Resource: "arn:aws:s3:::alarm", Reason: "alarm & \"not\" encrypted", Run: run, Control: control,
+				Dimensions: []controlexecute.Dimension{{Key: "region", Value: "us-east-1"}}},
+			{Status: "error", Resource: "arn:aws:s3:::error", Reason: "access denied", Run: run, Control: control},
+		}
+		group.ControlRuns = append(group.ControlRuns, run)
+		root.Groups = append(root.Groups, group)
+		runs = append(runs, run)
+	}
+
+	return &controlexecute.ExecutionTree{
+		Root:        root,
+		ControlRuns: runs,
+		StartTime:   time.Now().Add(-time.Minute),
+		EndTime:     time.Now(),
+	}
+}
+
+func renderTemplateForTest(t *testing.T, format string) []byte {
+	return renderTreeForTest(t, format, newTemplateTestTree())
+}
+
+// setTestSteampipeDir points the steampipe dir at a temporary directory for the duration of the test
+func setTestSteampipeDir(t *testing.T) {
+	prev := filepaths.SteampipeDir
+	filepaths.SteampipeDir = t.TempDir()
+	t.Cleanup(func() { filepaths.SteampipeDir = prev })
+}
+
+func renderTreeForTest(t *testing.T, format string, tree *controlexecute.ExecutionTree) []byte {
+	setTestSteampipeDir(t)
+	if err := EnsureTemplates(); err != nil {
+		t.Fatal(err)
+	}
+	resolver, err := NewFormatResolver()
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatter, err := resolver.GetFormatter(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := formatter.Format(context.Background(), tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestSarifTemplate(t *testing.T) {
+	output := renderTemplateForTest(t, "sarif")
+
+	var sarif struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						Id         string `json:"id"`
+						Properties struct {
+							Tags []string `json:"tags"`
+						} `json:"properties"`
+						DefaultConfiguration struct {
+							Level string `json:"level"`
+						} `json:"defaultConfiguration"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleId    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Message   struct {
+					Text string `json:"text"`
+				} `json:"message"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(output, &sarif); err != nil {
+		t.Fatalf("sarif output is not valid json: %v\n%s", err, output)
+	}
+	if sarif.Version != "2.1.0" || len(sarif.Runs) != 1 {
+		t.Fatalf("unexpected sarif document: %s", output)
+	}
+	run := sarif.Runs[0]
+	if len(run.Tool.Driver.Rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(run.Tool.Driver.Rules))
+	}
+	rule := run.Tool.Driver.Rules[0]
+	if rule.DefaultConfiguration.Level != "error" {
+		t.Errorf("expected rule level 'error' for high severity, got '%s'", rule.DefaultConfiguration.Level)
+	}
+	if !reflect.DeepEqual(rule.Properties.Tags, []string{"cis=true", "service=AWS/S3"}) {
+		t.Errorf("unexpected rule tags %v", rule.Properties.Tags)
+	}
+	// one alarm and one error row for each of the 2 control runs
+	if len(run.Results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(run.Results))
+	}
+	for _, r := range run.Results {
+		if r.RuleId != rule.Id || r.RuleIndex != 0 {
+			t.Errorf("result has unexpected rule reference %s/%d", r.RuleId, r.RuleIndex)
+		}
+	}
+	if run.Results[0].Message.Text != "alarm & \"not\" encrypted" {
+		t.Errorf("unexpected result message '%s'", run.Results[0].Message.Text)
+	}
+}
+
+func TestJUnitTemplate(t *testing.T) {
+	output := renderTemplateForTest(t, "junit")
+
+	var junit struct {
+		Suites []struct {
+			Name     string `xml:"name,attr"`
+			Tests    int    `xml:"tests,attr"`
+			Failures int    `xml:"failures,attr"`
+			Errors   int    `xml:"errors,attr"`
+			Cases    []struct {
+				Name    string    `xml:"name,attr"`
+				Failure *struct{} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(output, &junit); err != nil {
+		t.Fatalf("junit output is not valid xml: %v\n%s", err, output)
+	}
+	if len(junit.Suites) != 2 {
+		t.Fatalf("expected a suite per benchmark, got %d", len(junit.Suites))
+	}
+	suite := junit.Suites[0]
+	if suite.Name != "Benchmark <aws_test.benchmark.one>" {
+		t.Errorf("unexpected suite name '%s'", suite.Name)
+	}
+	if suite.Tests != 3 || suite.Failures != 1 || suite.Errors != 1 || len(suite.Cases) != 3 {
+		t.Errorf("unexpected suite counts: %+v", suite)
+	}
+	if suite.Cases[1].Name != "arn:aws:s3:::alarm" || suite.Cases[1].Failure == nil {
+		t.Errorf("expected alarm row to be rendered as a failure: %+v", suite.Cases[1])
+	}
+}
+
+func TestTemplatesIncludeExemptions(t *testing.T) {
+	tree := newTemplateTestTree()
+	// the json template expects a single top level group
+	tree.Root.Groups = tree.Root.Groups[:1]
+	tree.ControlRuns = tree.ControlRuns[:1]
+	for _, run := range tree.ControlRuns {
+		run.Tree = tree
+		run.RunStatus = controlstatus.ControlRunComplete
+		// the asff template only includes aws controls
+		run.Control.Tags["plugin"] = "aws"
+		run.Rows = append(run.Rows, &controlexecute.ResultRow{
+			Status:          constants.ControlExempt,
+			Resource:        "arn:aws:s3:::exempt",
+			Reason:          "exempt not encrypted",
+			ExemptionName:   "exemption.public_website",
+			ExemptionReason: "bucket hosts a public website",
+			Run:             run,
+			Control:         run.Control,
+		})
+		run.Summary.Exempt++
+	}
+
+	for _, format := range []string{"csv", "json", "md", "html", "nunit3", "sarif", "junit", "asff"} {
+		output := string(renderTreeForTest(t, format, tree))
+		if !strings.Contains(output, "exemption.public_website") {
+			t.Errorf("%s output does not include the exemption:\n%s", format, output)
+		}
+	}
+}
+
+func TestTemplatesIncludeScore(t *testing.T) {
+	tree := newTemplateTestTree()
+	// the json template expects a single top level group
+	tree.Root.Groups = tree.Root.Groups[:1]
+	tree.ControlRuns = tree.ControlRuns[:1]
+	score := &controlstatus.ComplianceScore{}
+	score.AddControl(&controlstatus.StatusSummary{Ok: 2, Alarm: 1}, 4)
+	for _, run := range tree.ControlRuns {
+		run.Tree = tree
+		run.RunStatus = controlstatus.ControlRunComplete
+		run.Group.Summary.Score = score
+	}
+	tree.Root.Summary.Score = score
+
+	for _, format := range []string{"csv", "json", "md", "html", "nunit3", "sarif", "junit"} {
+		output := string(renderTreeForTest(t, format, tree))
+		if !strings.Contains(output, "66.67") {
+			t.Errorf("%s output does not include the compliance score:\n%s", format, output)
+		}
+	}
+}
+
+func TestTemplatesIncludeTimeout(t *testing.T) {
+	tree := newTemplateTestTree()
+	// the json template expects a single top level group
+	tree.Root.Groups = tree.Root.Groups[:1]
+	tree.ControlRuns = tree.ControlRuns[:1]
+	for _, run := range tree.ControlRuns {
+		run.Tree = tree
+		run.RunStatus = controlstatus.ControlRunError
+		run.TimedOut = true
+		run.RunErrorString = "control timed out after 30s - showing 3 partial results"
+	}
+
+	// the timeout is shown as well as the error message, and the partial results are kept
+	expected := map[string][]string{
+		"csv":    {"timeout", "arn:aws:s3:::alarm"},
+		"nunit3": {"<key>steampipe:timed_out</key>\n     <value>true</value>", "s3_encrypted::1"},
+		"sarif":  {`"timed_out": true`, "arn:aws:s3:::alarm"},
+	}
+	for format, strs := range expected {
+		output := string(renderTreeForTest(t, format, tree))
+		for _, str := range strs {
+			if !strings.Contains(output, str) {
+				t.Errorf("%s output does not include '%s':\n%s", format, str, output)
+			}
+		}
+	}
+}
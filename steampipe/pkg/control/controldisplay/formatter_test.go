@@ -3,12 +3,10 @@ package controldisplay
 import (
 	"context"
 	"io"
-	"os"
 	"testing"
 
 	"github.com/turbot/steampipe/pkg/constants"
 	"github.com/turbot/steampipe/pkg/control/controlexecute"
-	"github.com/turbot/steampipe/pkg/filepaths"
 )
 
 // testFormatter is an implementation of the Formatter interface
@@ -76,15 +74,26 @@ var formatterTestCase = []testCase{
 			name:      "nunit3",
 		},
 	},
+	{
+		input: "sarif",
+		expected: testFormatter{
+			alias:     "",
+			extension: ".sarif",
+			name:      "sarif",
+		},
+	},
+	{
+		input: "junit.xml",
+		expected: testFormatter{
+			alias:     "junit.xml",
+			extension: ".junit.xml",
+			name:      "junit",
+		},
+	},
 }
 
 func TestFormatResolver(t *testing.T) {
-	tmpDir, err := os.MkdirTemp(os.TempDir(), "test")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer os.RemoveAll(tmpDir)
-	filepaths.SteampipeDir = tmpDir
+	setTestSteampipeDir(t)
 	if err := EnsureTemplates(); err != nil {
 		t.Fatal(err)
 	}
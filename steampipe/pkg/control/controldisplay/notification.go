@@ -0,0 +1,114 @@
+package controldisplay
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"text/template"
+
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/control/controlexecute"
+	"github.com/turbot/steampipe/pkg/control/controlstatus"
+	"github.com/turbot/steampipe/pkg/version"
+)
+
+//go:embed notification_templates/*
+var notificationTemplateFS embed.FS
+
+// the maximum number of alarm rows included in a notification
+const maxNotificationAlarms = 50
+
+// NotificationAlarm is an alarm row included in a notification
+type NotificationAlarm struct {
+	ControlName  string                     `json:"control_name"`
+	ControlTitle string                     `json:"control_title"`
+	Severity     string                     `json:"severity,omitempty"`
+	Resource     string                     `json:"resource"`
+	Reason       string                     `json:"reason"`
+	Dimensions   []controlexecute.Dimension `json:"dimensions"`
+}
+
+// NotificationRenderContext is the data passed to the notification templates
+type NotificationRenderContext struct {
+	Constants TemplateRenderConstants
+	// the name of the benchmark or control which was run
+	Target  string
+	Title   string
+	Summary controlstatus.StatusSummary
+	// the alarms which meet the notifier severity threshold
+	Alarms []NotificationAlarm
+	// the number of matching alarms not included in Alarms
+	TruncatedCount int
+	// the severity threshold of the notifier (empty if not set)
+	Severity string
+	Data     *controlexecute.ExecutionTree
+}
+
+// NewNotificationRenderContext builds the render context for a notification of the given execution tree,
+// including only alarms for which includeAlarm returns true
+func NewNotificationRenderContext(tree *controlexecute.ExecutionTree, target, severity string, includeAlarm func(severity string) bool) *NotificationRenderContext {
+	workingDirectory, _ := os.Getwd()
+	res := &NotificationRenderContext{
+		Constants: TemplateRenderConstants{
+			SteampipeVersion: version.SteampipeVersion.String(),
+			WorkingDir:       workingDirectory,
+		},
+		Target:   target,
+		Title:    tree.Root.Title,
+		Summary:  tree.Root.Summary.Status,
+		Alarms:   []NotificationAlarm{},
+		Severity: severity,
+		Data:     tree,
+	}
+	if res.Title == "" {
+		res.Title = target
+	}
+
+	for _, run := range tree.ControlRuns {
+		runSeverity := typehelpers.SafeString(run.Control.Severity)
+		if !includeAlarm(runSeverity) {
+			continue
+		}
+		for _, row := range run.Rows {
+			if row.Status != constants.ControlAlarm {
+				continue
+			}
+			if len(res.Alarms) == maxNotificationAlarms {
+				res.TruncatedCount++
+				continue
+			}
+			res.Alarms = append(res.Alarms, NotificationAlarm{
+				ControlName:  run.Control.Name(),
+				ControlTitle: run.Title,
+				Severity:     runSeverity,
+				Resource:     row.Resource,
+				Reason:       row.Reason,
+				Dimensions:   row.Dimensions,
+			})
+		}
+	}
+	return res
+}
+
+// AlarmCount returns the total number of alarms which meet the severity threshold
+func (c *NotificationRenderContext) AlarmCount() int {
+	return len(c.Alarms) + c.TruncatedCount
+}
+
+// RenderNotification renders the notification template for the given notifier type
+func RenderNotification(notifierType string, renderContext *NotificationRenderContext) (string, error) {
+	t, err := template.New("notification").
+		Funcs(templateFuncs(TemplateRenderContext{})).
+		ParseFS(notificationTemplateFS, fmt.Sprintf("notification_templates/%s.tmpl", notifierType))
+	if err != nil {
+		return "", fmt.Errorf("could not load notification template for '%s' - %v", notifierType, err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, "output", renderContext); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
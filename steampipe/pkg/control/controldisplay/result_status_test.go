@@ -34,3 +34,17 @@ func TestResultStatus(t *testing.T) {
 		}
 	}
 }
+
+func TestErrorRendererTimeout(t *testing.T) {
+	themeDef := ColorSchemes["plain"]
+	scheme, _ := NewControlColorScheme(themeDef)
+	ControlColors = scheme
+
+	err := fmt.Errorf("control timed out after 30s")
+	if output := NewErrorRenderer(err, false, 100, "").Render(); output != "ERROR: control timed out after 30s" {
+		t.Errorf("unexpected error rendering '%s'", output)
+	}
+	if output := NewErrorRenderer(err, true, 100, "").Render(); output != "TIMEOUT: control timed out after 30s" {
+		t.Errorf("unexpected timeout rendering '%s'", output)
+	}
+}
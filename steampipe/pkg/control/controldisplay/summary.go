@@ -48,6 +48,7 @@ func (r SummaryRenderer) Render() string {
 	infoStatusRow := NewSummaryStatusRowRenderer(r.resultTree, availableWidth, "info").Render()
 	alarmStatusRow := NewSummaryStatusRowRenderer(r.resultTree, availableWidth, "alarm").Render()
 	errorStatusRow := NewSummaryStatusRowRenderer(r.resultTree, availableWidth, "error").Render()
+	exemptStatusRow := NewSummaryStatusRowRenderer(r.resultTree, availableWidth, "exempt").Render()
 
 	titleLine := fmt.Sprintf("%s\n", ControlColors.GroupTitle("Summary"))
 
@@ -60,6 +61,7 @@ func (r SummaryRenderer) Render() string {
 		infoStatusRow,
 		alarmStatusRow,
 		errorStatusRow,
+		exemptStatusRow,
 	}
 	// if there is a severity block, add it
 	if len(severityRows) > 0 {
@@ -72,6 +74,10 @@ func (r SummaryRenderer) Render() string {
 		// summary row
 		summaryRow,
 	)
+	// add the compliance score, if any controls were scored
+	if scoreRow := NewSummaryScoreRowRenderer(r.resultTree, availableWidth).Render(); scoreRow != "" {
+		summaryLines = append(summaryLines, scoreRow)
+	}
 
 	return strings.Join(summaryLines, "\n")
 }
@@ -0,0 +1,41 @@
+package controldisplay
+
+import (
+	"fmt"
+
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/steampipe/pkg/control/controlexecute"
+)
+
+type SummaryScoreRowRenderer struct {
+	resultTree *controlexecute.ExecutionTree
+	width      int
+}
+
+func NewSummaryScoreRowRenderer(resultTree *controlexecute.ExecutionTree, width int) *SummaryScoreRowRenderer {
+	return &SummaryScoreRowRenderer{
+		resultTree: resultTree,
+		width:      width,
+	}
+}
+
+// Render returns the compliance score row - or an empty string if no controls were scored
+func (r *SummaryScoreRowRenderer) Render() string {
+	score := r.resultTree.Root.Summary.Score
+	if !score.Scored() {
+		return ""
+	}
+
+	head := fmt.Sprintf("%s ", ControlColors.GroupTitle("SCORE"))
+	value := fmt.Sprintf("%s", ControlColors.CountTotal(fmt.Sprintf("%.2f%%", score.Percentage)))
+
+	spaceWidth := r.width - (helpers.PrintableLength(head) + helpers.PrintableLength(value))
+	spacer := NewSpacerRenderer(spaceWidth)
+
+	return fmt.Sprintf(
+		"%s%s%s",
+		head,
+		spacer.Render(),
+		value,
+	)
+}
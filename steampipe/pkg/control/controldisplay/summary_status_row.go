@@ -41,6 +41,8 @@ func (r *SummaryStatusRowRenderer) Render() string {
 		count = r.resultTree.Root.Summary.Status.Alarm
 	case constants.ControlError:
 		count = r.resultTree.Root.Summary.Status.Error
+	case constants.ControlExempt:
+		count = r.resultTree.Root.Summary.Status.Exempt
 	default:
 		// we can safely panic here, since the status enum check should have been
 		// done by the executor. this is here for unit tests mostly
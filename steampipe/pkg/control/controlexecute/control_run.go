@@ -2,14 +2,16 @@ package controlexecute
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/spf13/viper"
 	typehelpers "github.com/turbot/go-kit/types"
-	"github.com/turbot/steampipe-plugin-sdk/v5/grpc"
 	"github.com/turbot/steampipe/pkg/constants"
 	"github.com/turbot/steampipe/pkg/control/controlstatus"
 	"github.com/turbot/steampipe/pkg/dashboard/dashboardtypes"
@@ -47,6 +49,9 @@ type ControlRun struct {
 	// result rows
 	Rows ResultRows `json:"-"`
 
+	// the active exemptions which apply to this control
+	exemptions []*modconfig.Exemption
+
 	// the results in snapshot format
 	Data *dashboardtypes.LeafData `json:"data"`
 
@@ -59,6 +64,8 @@ type ControlRun struct {
 	Group *ResultGroup `json:"-"`
 	// execution tree
 	Tree *ExecutionTree `json:"-"`
+	// did the control time out - any results received before the timeout are kept
+	TimedOut bool `json:"timed_out,omitempty"`
 	// save run error as string for JSON export
 	RunErrorString string `json:"error,omitempty"`
 	runError       error
@@ -67,7 +74,9 @@ type ControlRun struct {
 	rowMap      map[string]ResultRows
 	stateLock   sync.Mutex
 	doneChan    chan bool
-	attempts    int
+	// the timeout and retry settings
+	settings *controlRunSettings
+	attempts int
 }
 
 func NewControlRun(control *modconfig.Control, group *ResultGroup, executionTree *ExecutionTree) *ControlRun {
@@ -99,6 +108,21 @@ func NewControlRun(control *modconfig.Control, group *ResultGroup, executionTree
 		NodeType: modconfig.BlockTypeControl,
 		doneChan: make(chan bool, 1),
 	}
+	res.settings = newControlRunSettings(control, group)
+	res.exemptions = activeExemptionsForControl(control, executionTree.Workspace.GetResourceMaps(), time.Now())
+	return res
+}
+
+// return all exemptions which target the control (directly or via a parent benchmark) and have not expired
+func activeExemptionsForControl(control *modconfig.Control, resourceMaps *modconfig.ResourceMaps, now time.Time) []*modconfig.Exemption {
+	var res []*modconfig.Exemption
+	for _, exemption := range resourceMaps.Exemptions {
+		if !exemption.IsExpired(now) && exemption.AppliesToControl(control) {
+			res = append(res, exemption)
+		}
+	}
+	// sort by name so the exemption applied to a row is deterministic
+	sort.Slice(res, func(i, j int) bool { return res[i].Name() < res[j].Name() })
 	return res
 }
 
@@ -138,6 +162,11 @@ func (r *ControlRun) GetError() error {
 	return r.runError
 }
 
+// GetAttempts returns the number of times the control query was run
+func (r *ControlRun) GetAttempts() int {
+	return r.attempts
+}
+
 // IsSnapshotPanel implements SnapshotPanel
 func (*ControlRun) IsSnapshotPanel() {}
 
@@ -163,11 +192,7 @@ func (r *ControlRun) setError(ctx context.Context, err error) {
 	if err == nil {
 		return
 	}
-	if r.runError == context.DeadlineExceeded {
-		r.runError = fmt.Errorf("control execution timed out")
-	} else {
-		r.runError = error_helpers.TransformErrorToSteampipe(err)
-	}
+	r.runError = error_helpers.TransformErrorToSteampipe(err)
 	r.RunErrorString = r.runError.Error()
 	// update error count
 	r.Summary.Error++
@@ -226,6 +251,7 @@ func (r *ControlRun) execute(ctx context.Context, client db_common.Client) {
 		if len(r.Severity) != 0 {
 			r.Group.updateSeverityCounts(r.Severity, r.Summary)
 		}
+		r.Group.updateScore(r.Summary, controlstatus.ControlScoreWeight(r.Severity, r.Control.GetWeight()))
 		r.Duration = time.Since(startTime)
 		if r.Group != nil {
 			r.Group.addDuration(r.Duration)
@@ -277,38 +303,130 @@ func (r *ControlRun) execute(ctx context.Context, client db_common.Client) {
 		return
 	}
 
-	controlExecutionCtx := r.getControlQueryContext(ctx)
+	for {
+		timedOut, err := r.executeAttempt(ctx, client, dbSession, resolvedQuery)
+		r.attempts++
+		// if the run was finished elsewhere, there is nothing more to do
+		if r.Finished() {
+			return
+		}
+		if err == nil {
+			r.setComplete(ctx)
+			return
+		}
+		if !r.shouldRetry(ctx, err, timedOut) {
+			r.setAttemptError(ctx, err, timedOut)
+			return
+		}
+		// clear the results of the failed attempt and wait before retrying
+		r.resetResults()
+		if err := sleepWithContext(ctx, retryBackoff(r.attempts)); err != nil {
+			r.setError(ctx, err)
+			return
+		}
+	}
+}
+
+// execute the control query and read the results, applying the control timeout (if any)
+// returns whether the attempt timed out, and the error which caused the attempt to fail
+func (r *ControlRun) executeAttempt(ctx context.Context, client db_common.Client, dbSession *db_common.DatabaseSession, resolvedQuery *modconfig.ResolvedQuery) (bool, error) {
+	attemptCtx := ctx
+	if r.settings.timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, r.settings.timeout)
+		defer cancel()
+	}
+	controlExecutionCtx := r.getControlQueryContext(attemptCtx)
 
 	// execute the control query
 	// NOTE no need to pass an OnComplete callback - we are already closing our session after waiting for results
-	log.Printf("[TRACE] execute start for, %s\n", control.Name())
+	log.Printf("[TRACE] execute start for, %s (attempt %d)\n", r.Control.Name(), r.attempts+1)
 	queryResult, err := client.ExecuteInSession(controlExecutionCtx, dbSession, nil, resolvedQuery.ExecuteSQL, resolvedQuery.Args...)
-	log.Printf("[TRACE] execute finish for, %s\n", control.Name())
+	log.Printf("[TRACE] execute finish for, %s\n", r.Control.Name())
+	if err != nil {
+		return isDeadlineExceeded(attemptCtx, err), err
+	}
+
+	r.queryResult = queryResult
 
+	// now wait for control completion
+	log.Printf("[TRACE] wait result for, %s\n", r.Control.Name())
+	err = r.waitForResults(attemptCtx)
+	log.Printf("[TRACE] finish result for, %s\n", r.Control.Name())
 	if err != nil {
-		r.attempts++
+		timedOut := isDeadlineExceeded(attemptCtx, err)
+		// wait for the query to stop streaming before the session is reused or closed
+		r.drainResults(ctx)
+		return timedOut, err
+	}
+	return false, nil
+}
 
-		// is this an rpc EOF error - meaning that the plugin somehow crashed
-		if grpc.IsGRPCConnectivityError(err) {
-			if r.attempts < constants.MaxControlRunAttempts {
-				log.Printf("[TRACE] control %s query failed with plugin connectivity error %s - retrying...", r.Control.Name(), err)
-				// recurse into this function to retry using the original context - which Execute will use to create it's own timeout context
-				r.execute(ctx, client)
+// should the control be retried after the given error
+func (r *ControlRun) shouldRetry(ctx context.Context, err error, timedOut bool) bool {
+	if utils.IsContextCancelled(ctx) || r.attempts > r.settings.retries {
+		return false
+	}
+	errorClass := errorClass(err, timedOut)
+	if errorClass == "" || !r.settings.retryOn[errorClass] {
+		return false
+	}
+	log.Printf("[TRACE] control %s failed with %s error %s - retrying (attempt %d of %d)", r.Control.Name(), errorClass, err, r.attempts+1, r.settings.retries+1)
+	return true
+}
+
+// set the error of the final attempt, keeping any results which were received before the error
+func (r *ControlRun) setAttemptError(ctx context.Context, err error, timedOut bool) {
+	r.createdOrderedResultRows()
+	r.setData()
+	if timedOut {
+		r.TimedOut = true
+		err = r.timeoutError()
+	}
+	r.setError(ctx, err)
+}
+
+func (r *ControlRun) timeoutError() error {
+	// the query timeout applies as well as the control timeout, so whichever is shorter is the one which expired
+	timeout := r.settings.timeout
+	if queryTimeout := time.Duration(viper.GetInt(constants.ArgDatabaseQueryTimeout)) * time.Second; queryTimeout > 0 && (timeout == 0 || queryTimeout < timeout) {
+		timeout = queryTimeout
+	}
+	msg := fmt.Sprintf("control timed out after %s", timeout)
+	if r.attempts > 1 {
+		msg = fmt.Sprintf("%s (%d attempts)", msg, r.attempts)
+	}
+	if resultCount := r.Summary.TotalCount(); resultCount > 0 {
+		msg = fmt.Sprintf("%s - showing %d partial %s", msg, resultCount, utils.Pluralize("result", resultCount))
+	}
+	return errors.New(msg)
+}
+
+// clear the results of a failed attempt
+func (r *ControlRun) resetResults() {
+	r.stateLock.Lock()
+	defer r.stateLock.Unlock()
+	r.rowMap = make(map[string]ResultRows)
+	r.Rows = nil
+	r.Summary = &controlstatus.StatusSummary{}
+	r.queryResult = nil
+}
+
+// read and discard any remaining results of the query
+func (r *ControlRun) drainResults(ctx context.Context) {
+	if r.queryResult == nil {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case row, ok := <-*r.queryResult.RowChan:
+			if !ok || row == nil {
 				return
-			} else {
-				log.Printf("[TRACE] control %s query failed again with plugin connectivity error %s - NOT retrying...", r.Control.Name(), err)
 			}
 		}
-		r.setError(ctx, err)
-		return
 	}
-
-	r.queryResult = queryResult
-
-	// now wait for control completion
-	log.Printf("[TRACE] wait result for, %s\n", control.Name())
-	r.waitForResults(ctx)
-	log.Printf("[TRACE] finish result for, %s\n", control.Name())
 }
 
 // try to acquire a database session - retry up to 4 times if there is an error
@@ -342,48 +460,49 @@ func (r *ControlRun) resolveControlQuery(control *modconfig.Control) (*modconfig
 	return resolvedQuery, nil
 }
 
-func (r *ControlRun) waitForResults(ctx context.Context) {
-	defer func() {
-		dimensionsSchema := r.getDimensionSchema()
-		// convert the data to snapshot format
-		r.Data = r.Rows.ToLeafData(dimensionsSchema)
-	}()
-
+// read the query results into result rows
+// returns an error if the query fails, or if the context is cancelled or times out before all results are read
+func (r *ControlRun) waitForResults(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
-			r.setError(ctx, ctx.Err())
-			return
+			return ctx.Err()
 		case row := <-*r.queryResult.RowChan:
 			// nil row means control run is complete
 			if row == nil {
-				// nil row means we are done
-				r.setRunStatus(ctx, controlstatus.ControlRunComplete)
-				r.createdOrderedResultRows()
-				return
+				// the query stops streaming rows (without an error) if its context is done
+				return ctx.Err()
 			}
 			// if the row is in error then we terminate the run
 			if row.Error != nil {
-				// set error status and summary
-				r.setError(ctx, row.Error)
-				// update the result group status with our status - this will be passed all the way up the execution tree
-				r.Group.updateSummary(r.Summary)
-				return
+				return row.Error
 			}
 
 			// so all is ok - create another result row
 			result, err := NewResultRow(r, row, r.queryResult.Cols)
 			if err != nil {
-				r.setError(ctx, err)
-				return
+				return err
 			}
 			r.addResultRow(result)
 		case <-r.doneChan:
-			return
+			return nil
 		}
 	}
 }
 
+// the control completed successfully - populate the ordered results and set the status
+func (r *ControlRun) setComplete(ctx context.Context) {
+	r.createdOrderedResultRows()
+	r.setData()
+	r.setRunStatus(ctx, controlstatus.ControlRunComplete)
+}
+
+// convert the results to snapshot format
+func (r *ControlRun) setData() {
+	dimensionsSchema := r.getDimensionSchema()
+	r.Data = r.Rows.ToLeafData(dimensionsSchema)
+}
+
 func (r *ControlRun) getDimensionSchema() map[string]*queryresult.ColumnDef {
 	var dimensionsSchema = make(map[string]*queryresult.ColumnDef)
 
@@ -407,6 +526,13 @@ func (r *ControlRun) getDimensionSchema() map[string]*queryresult.ColumnDef {
 
 // add the result row to our results and update the summary with the row status
 func (r *ControlRun) addResultRow(row *ResultRow) {
+	// if this is an alarm, check whether it has been exempted
+	if row.Status == constants.ControlAlarm {
+		if exemption := r.exemptionForRow(row); exemption != nil {
+			row.applyExemption(exemption)
+		}
+	}
+
 	// update results
 	r.rowMap[row.Status] = append(r.rowMap[row.Status], row)
 
@@ -422,12 +548,28 @@ func (r *ControlRun) addResultRow(row *ResultRow) {
 		r.Summary.Info++
 	case constants.ControlError:
 		r.Summary.Error++
+	case constants.ControlExempt:
+		r.Summary.Exempt++
+	}
+}
+
+// return the first active exemption which matches the row, or nil if there is none
+func (r *ControlRun) exemptionForRow(row *ResultRow) *modconfig.Exemption {
+	if len(r.exemptions) == 0 {
+		return nil
+	}
+	dimensions := row.dimensionMap()
+	for _, exemption := range r.exemptions {
+		if exemption.MatchesResult(row.Resource, dimensions) {
+			return exemption
+		}
 	}
+	return nil
 }
 
 // populate ordered list of rows
 func (r *ControlRun) createdOrderedResultRows() {
-	statusOrder := []string{constants.ControlError, constants.ControlAlarm, constants.ControlInfo, constants.ControlOk, constants.ControlSkip}
+	statusOrder := []string{constants.ControlError, constants.ControlAlarm, constants.ControlExempt, constants.ControlInfo, constants.ControlOk, constants.ControlSkip}
 	for _, status := range statusOrder {
 		r.Rows = append(r.Rows, r.rowMap[status]...)
 	}
@@ -0,0 +1,169 @@
+package controlexecute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+// the classes of error which may cause a control run to be retried
+const (
+	// the plugin crashed or could not be reached
+	RetryOnConnectivity = "connectivity"
+	// the API called by the plugin is throttling requests
+	RetryOnThrottling = "throttling"
+	// the control timed out
+	RetryOnTimeout = "timeout"
+)
+
+// RetryErrorClasses is the list of all error classes which may be retried
+var RetryErrorClasses = []string{RetryOnConnectivity, RetryOnThrottling, RetryOnTimeout}
+
+// DefaultRetryErrorClasses is the list of error classes which are retried if '--control-retry-on' is not set
+var DefaultRetryErrorClasses = []string{RetryOnConnectivity, RetryOnThrottling}
+
+// error messages which indicate an API is throttling requests
+var throttlingErrorMessages = []string{
+	"throttl",
+	"rate exceeded",
+	"rate limit",
+	"ratelimit",
+	"too many requests",
+	"requestlimitexceeded",
+	"slowdown",
+	"slow down",
+}
+
+// the timeout and retry settings for a control run
+type controlRunSettings struct {
+	// the maximum duration of a single attempt - zero means the query timeout applies
+	timeout time.Duration
+	// the number of times to retry the control if it fails with a retryable error
+	retries int
+	// the error classes which are retried
+	retryOn map[string]bool
+}
+
+// resolve the timeout and retry settings of a control run
+// the '--control-timeout' and '--control-retries' args take precedence over the control properties,
+// which take precedence over the properties of the closest ancestor benchmark which sets them
+func newControlRunSettings(control *modconfig.Control, group *ResultGroup) *controlRunSettings {
+	res := &controlRunSettings{
+		timeout: control.GetTimeout(),
+		retries: constants.DefaultControlRunRetries,
+		retryOn: make(map[string]bool),
+	}
+	retry := control.Retry
+	for g := group; g != nil && (res.timeout == 0 || retry == nil); g = g.Parent {
+		benchmark, ok := g.GroupItem.(*modconfig.Benchmark)
+		if !ok {
+			continue
+		}
+		if res.timeout == 0 {
+			res.timeout = benchmark.GetTimeout()
+		}
+		if retry == nil {
+			retry = benchmark.Retry
+		}
+	}
+	if retry != nil {
+		res.retries = *retry
+	}
+
+	// command line overrides (these are validated by the check command)
+	if viper.IsSet(constants.ArgControlTimeout) {
+		if timeout, err := time.ParseDuration(viper.GetString(constants.ArgControlTimeout)); err == nil {
+			res.timeout = timeout
+		}
+	}
+	if viper.IsSet(constants.ArgControlRetries) {
+		res.retries = viper.GetInt(constants.ArgControlRetries)
+	}
+	retryOn := DefaultRetryErrorClasses
+	if viper.IsSet(constants.ArgControlRetryOn) {
+		retryOn = viper.GetStringSlice(constants.ArgControlRetryOn)
+	}
+	for _, errorClass := range retryOn {
+		res.retryOn[errorClass] = true
+	}
+	return res
+}
+
+// ValidateRetryErrorClasses returns an error if any of the given error classes is not recognised
+func ValidateRetryErrorClasses(errorClasses []string) error {
+	for _, errorClass := range errorClasses {
+		if !isRetryErrorClass(errorClass) {
+			return fmt.Errorf("invalid error class '%s' - must be one of %s", errorClass, strings.Join(RetryErrorClasses, ", "))
+		}
+	}
+	return nil
+}
+
+func isRetryErrorClass(errorClass string) bool {
+	for _, c := range RetryErrorClasses {
+		if c == errorClass {
+			return true
+		}
+	}
+	return false
+}
+
+// return the class of a control run error, or an empty string if the error is not retryable
+func errorClass(err error, timedOut bool) string {
+	switch {
+	case timedOut:
+		return RetryOnTimeout
+	case grpc.IsGRPCConnectivityError(err):
+		return RetryOnConnectivity
+	case isThrottlingError(err):
+		return RetryOnThrottling
+	}
+	return ""
+}
+
+func isThrottlingError(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, m := range throttlingErrorMessages {
+		if strings.Contains(message, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// return the delay before the given retry (starting from 1) - the delay doubles with each retry, up to a maximum
+func retryBackoff(retry int) time.Duration {
+	maxBackoff := constants.ControlRunMaxRetryBackoffSecs * time.Second
+	backoff := constants.ControlRunRetryBackoffMs * time.Millisecond
+	for i := 1; i < retry && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+// wait for the given duration, returning an error if the context is cancelled first
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// is the error the result of a context deadline being exceeded
+func isDeadlineExceeded(ctx context.Context, err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
@@ -0,0 +1,100 @@
+package controlexecute
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+func TestNewControlRunSettings(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(i int) *int { return &i }
+
+	parent := &ResultGroup{GroupItem: &modconfig.Benchmark{Timeout: str("5m"), Retry: num(3)}}
+	child := &ResultGroup{GroupItem: &modconfig.Benchmark{Timeout: str("1m")}, Parent: parent}
+
+	testCases := map[string]struct {
+		control         *modconfig.Control
+		group           *ResultGroup
+		args            map[string]any
+		expectedTimeout time.Duration
+		expectedRetries int
+	}{
+		"defaults": {
+			control:         &modconfig.Control{},
+			group:           &ResultGroup{},
+			expectedRetries: constants.DefaultControlRunRetries,
+		},
+		"control": {
+			control:         &modconfig.Control{Timeout: str("30s"), Retry: num(0)},
+			group:           child,
+			expectedTimeout: 30 * time.Second,
+			expectedRetries: 0,
+		},
+		"inherited from closest benchmark": {
+			control:         &modconfig.Control{},
+			group:           child,
+			expectedTimeout: time.Minute,
+			expectedRetries: 3,
+		},
+		"args override control": {
+			control:         &modconfig.Control{Timeout: str("30s"), Retry: num(0)},
+			group:           child,
+			args:            map[string]any{constants.ArgControlTimeout: "10s", constants.ArgControlRetries: 2},
+			expectedTimeout: 10 * time.Second,
+			expectedRetries: 2,
+		},
+	}
+
+	for name, tc := range testCases {
+		viper.Reset()
+		for k, v := range tc.args {
+			viper.Set(k, v)
+		}
+		settings := newControlRunSettings(tc.control, tc.group)
+		if settings.timeout != tc.expectedTimeout {
+			t.Errorf("%s: expected timeout %s, got %s", name, tc.expectedTimeout, settings.timeout)
+		}
+		if settings.retries != tc.expectedRetries {
+			t.Errorf("%s: expected %d retries, got %d", name, tc.expectedRetries, settings.retries)
+		}
+		if !settings.retryOn[RetryOnThrottling] || settings.retryOn[RetryOnTimeout] {
+			t.Errorf("%s: expected default retry error classes, got %v", name, settings.retryOn)
+		}
+	}
+	viper.Reset()
+}
+
+func TestErrorClass(t *testing.T) {
+	testCases := []struct {
+		err      error
+		timedOut bool
+		expected string
+	}{
+		{fmt.Errorf("ThrottlingException: Rate exceeded"), false, RetryOnThrottling},
+		{fmt.Errorf("googleapi: Error 429: Too Many Requests"), false, RetryOnThrottling},
+		{fmt.Errorf("context deadline exceeded"), true, RetryOnTimeout},
+		{fmt.Errorf("AccessDenied: not authorized"), false, ""},
+	}
+	for _, tc := range testCases {
+		if actual := errorClass(tc.err, tc.timedOut); actual != tc.expected {
+			t.Errorf("error '%s': expected class '%s', got '%s'", tc.err, tc.expected, actual)
+		}
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	expected := []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second}
+	for i, e := range expected {
+		if actual := retryBackoff(i + 1); actual != e {
+			t.Errorf("retry %d: expected backoff %s, got %s", i+1, e, actual)
+		}
+	}
+	if actual := retryBackoff(100); actual != constants.ControlRunMaxRetryBackoffSecs*time.Second {
+		t.Errorf("expected backoff to be limited to %ds, got %s", constants.ControlRunMaxRetryBackoffSecs, actual)
+	}
+}
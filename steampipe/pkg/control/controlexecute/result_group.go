@@ -56,6 +56,8 @@ type ResultGroup struct {
 type GroupSummary struct {
 	Status   controlstatus.StatusSummary            `json:"status"`
 	Severity map[string]controlstatus.StatusSummary `json:"-"`
+	// the compliance score of the group - nil if no controls in the group have been scored
+	Score *controlstatus.ComplianceScore `json:"score,omitempty"`
 }
 
 func NewGroupSummary() *GroupSummary {
@@ -268,6 +270,7 @@ func (r *ResultGroup) updateSummary(summary *controlstatus.StatusSummary) {
 	r.Summary.Status.Info += summary.Info
 	r.Summary.Status.Ok += summary.Ok
 	r.Summary.Status.Error += summary.Error
+	r.Summary.Status.Exempt += summary.Exempt
 
 	if r.Parent != nil {
 		r.Parent.updateSummary(summary)
@@ -287,6 +290,7 @@ func (r *ResultGroup) updateSeverityCounts(severity string, summary *controlstat
 	val.Info += summary.Info
 	val.Ok += summary.Ok
 	val.Skip += summary.Skip
+	val.Exempt += summary.Exempt
 
 	r.Summary.Severity[severity] = val
 	if r.Parent != nil {
@@ -294,6 +298,40 @@ func (r *ResultGroup) updateSeverityCounts(severity string, summary *controlstat
 	}
 }
 
+func (r *ResultGroup) updateScore(summary *controlstatus.StatusSummary, weight float64) {
+	r.updateLock.Lock()
+	defer r.updateLock.Unlock()
+
+	score := r.Summary.Score
+	if score == nil {
+		score = &controlstatus.ComplianceScore{}
+	}
+	score.AddControl(summary, weight)
+	if score.Scored() {
+		r.Summary.Score = score
+	}
+	if r.Parent != nil {
+		r.Parent.updateScore(summary, weight)
+	}
+}
+
+// LowestScore returns the lowest compliance score of the group and its descendant groups (i.e. benchmarks),
+// or nil if no controls were scored
+func (r *ResultGroup) LowestScore() *controlstatus.ComplianceScore {
+	var lowest *controlstatus.ComplianceScore
+	addScore := func(score *controlstatus.ComplianceScore) {
+		if score.Scored() && (lowest == nil || score.Percentage < lowest.Percentage) {
+			lowest = score
+		}
+	}
+
+	addScore(r.Summary.Score)
+	for _, child := range r.Groups {
+		addScore(child.LowestScore())
+	}
+	return lowest
+}
+
 func (r *ResultGroup) execute(ctx context.Context, client db_common.Client, parallelismLock *semaphore.Weighted) {
 	log.Printf("[TRACE] begin ResultGroup.Execute: %s\n", r.GroupId)
 	defer log.Printf("[TRACE] end ResultGroup.Execute: %s\n", r.GroupId)
@@ -0,0 +1,45 @@
+package controlexecute
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/control/controlstatus"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+func TestResultGroupLowestScore(t *testing.T) {
+	newRun := func(ok, alarm int) *ControlRun {
+		return &ControlRun{
+			Control:  &modconfig.Control{},
+			Severity: "low",
+			Summary:  &controlstatus.StatusSummary{Ok: ok, Alarm: alarm},
+		}
+	}
+	newGroup := func(runs ...*ControlRun) *ResultGroup {
+		group := &ResultGroup{ControlRuns: runs, Summary: &GroupSummary{}, updateLock: new(sync.Mutex)}
+		for _, run := range runs {
+			group.updateScore(run.Summary, controlstatus.ControlScoreWeight(run.Severity, run.Control.GetWeight()))
+		}
+		return group
+	}
+
+	// the child benchmark scores 75% although one of its controls scores 0%
+	child := newGroup(newRun(1, 0), newRun(1, 0), newRun(1, 0), newRun(0, 1))
+	root := newGroup(newRun(2, 0), newRun(2, 0))
+	root.Groups = []*ResultGroup{child}
+
+	lowest := root.LowestScore()
+	if !lowest.Scored() || lowest.Percentage != 75 {
+		t.Errorf("expected lowest score 75, got %+v", lowest)
+	}
+	if lowest := child.LowestScore(); lowest.Percentage != 75 {
+		t.Errorf("expected lowest child score 75, got %+v", lowest)
+	}
+
+	// controls with no scored results are ignored
+	skipped := newGroup(&ControlRun{Control: &modconfig.Control{}, Summary: &controlstatus.StatusSummary{Skip: 1}})
+	if lowest := skipped.LowestScore(); lowest.Scored() {
+		t.Errorf("expected no score, got %+v", lowest)
+	}
+}
@@ -27,6 +27,9 @@ func (r ResultRows) ToLeafData(dimensionSchema map[string]*queryresult.ColumnDef
 	for _, d := range dimensionSchema {
 		res.Columns = append(res.Columns, d)
 	}
+	if r.hasExemptions() {
+		res.Columns = append(res.Columns, &queryresult.ColumnDef{Name: "exemption", DataType: "TEXT"}, &queryresult.ColumnDef{Name: "exemption_reason", DataType: "TEXT"})
+	}
 	for i, row := range r {
 		res.Rows[i] = map[string]interface{}{
 			"reason":   row.Reason,
@@ -37,18 +40,35 @@ func (r ResultRows) ToLeafData(dimensionSchema map[string]*queryresult.ColumnDef
 		for _, d := range row.Dimensions {
 			res.Rows[i][d.Key] = d.Value
 		}
+		if row.ExemptionName != "" {
+			res.Rows[i]["exemption"] = row.ExemptionName
+			res.Rows[i]["exemption_reason"] = row.ExemptionReason
+		}
 	}
 	return res
 }
 
+func (r ResultRows) hasExemptions() bool {
+	for _, row := range r {
+		if row.ExemptionName != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // ResultRow is the result of a control execution for a single resource
 type ResultRow struct {
 	// reason for the status
 	Reason string `json:"reason" csv:"reason"`
 	// resource name
 	Resource string `json:"resource" csv:"resource"`
-	// status of the row (ok, info, alarm, error, skip)
+	// status of the row (ok, info, alarm, error, skip, exempt)
 	Status string `json:"status" csv:"status"`
+	// name, reason and expiry of the exemption which was applied to this row (exempt rows only)
+	ExemptionName    string `json:"exemption,omitempty" csv:"exemption"`
+	ExemptionReason  string `json:"exemption_reason,omitempty" csv:"exemption_reason"`
+	ExemptionExpires string `json:"exemption_expires,omitempty" csv:"exemption_expires"`
 	// dimensions for this row
 	Dimensions []Dimension `json:"dimensions"`
 	// parent control run
@@ -116,6 +136,23 @@ func NewResultRow(run *ControlRun, row *queryresult.RowResult, cols []*queryresu
 	return res, nil
 }
 
+// applyExemption marks an alarm row as exempt
+func (r *ResultRow) applyExemption(exemption *modconfig.Exemption) {
+	r.Status = constants.ControlExempt
+	r.ExemptionName = exemption.GetUnqualifiedName()
+	r.ExemptionReason = exemption.GetReason()
+	r.ExemptionExpires = typehelpers.SafeString(exemption.Expires)
+}
+
+// dimensionMap returns the dimensions of the row as a map of key to value
+func (r *ResultRow) dimensionMap() map[string]string {
+	res := make(map[string]string, len(r.Dimensions))
+	for _, d := range r.Dimensions {
+		res[d.Key] = d.Value
+	}
+	return res
+}
+
 func IsValidControlStatus(status string) bool {
 	return helpers.StringSliceContains([]string{constants.ControlOk, constants.ControlAlarm, constants.ControlInfo, constants.ControlError, constants.ControlSkip}, status)
 }
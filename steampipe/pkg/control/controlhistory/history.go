@@ -0,0 +1,136 @@
+package controlhistory
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/control/controlexecute"
+	"github.com/turbot/steampipe/pkg/db/db_common"
+	"github.com/turbot/steampipe/pkg/db/db_local"
+	"github.com/turbot/steampipe/pkg/utils"
+)
+
+// Enabled returns whether check results should be recorded in the history store
+func Enabled() bool {
+	return viper.GetBool(constants.ArgCheckHistory)
+}
+
+// Init verifies the history store can be used and creates the history tables if they do not exist
+// the history store is in the local database, so cannot be used with a remote workspace database
+func Init(ctx context.Context) error {
+	if viper.GetString(constants.ArgConnectionString) != "" {
+		return fmt.Errorf("'--%s' is only supported for the local database", constants.ArgCheckHistory)
+	}
+	return db_local.EnsureInternalTables(ctx)
+}
+
+// Record adds the results of the execution tree to the history store using a session of the given client,
+// then deletes any runs older than the retention period
+func Record(ctx context.Context, client db_common.Client, tree *controlexecute.ExecutionTree, target string) error {
+	sessionResult := client.AcquireSession(ctx)
+	if sessionResult.Error != nil {
+		return fmt.Errorf("failed to record check results: %s", sessionResult.Error.Error())
+	}
+	defer func() {
+		sessionResult.Session.Close(utils.IsContextCancelled(ctx))
+	}()
+	conn := sessionResult.Session.Connection.Conn()
+
+	run, results := buildRecords(tree, target)
+	runId, err := db_local.InsertCheckRun(ctx, conn, run, results)
+	if err != nil {
+		return fmt.Errorf("failed to record check results: %s", err.Error())
+	}
+	log.Printf("[TRACE] recorded %d results for check run %d", len(results), runId)
+
+	// the retention age is validated by the check command
+	maxAge, _ := utils.ParseRetentionAge(viper.GetString(constants.ArgHistoryRetention))
+	if maxAge == 0 {
+		return nil
+	}
+	deleted, err := db_local.DeleteCheckRunsBefore(ctx, conn, time.Now().Add(-maxAge))
+	if err != nil {
+		return fmt.Errorf("failed to delete expired check results: %s", err.Error())
+	}
+	log.Printf("[TRACE] deleted %d expired check runs", deleted)
+	return nil
+}
+
+// build the check run record and the result records from the execution tree
+func buildRecords(tree *controlexecute.ExecutionTree, target string) (*db_local.CheckRunRecord, []*db_local.CheckResultRecord) {
+	run := &db_local.CheckRunRecord{
+		Target:       target,
+		ModName:      tree.Workspace.Mod.ShortName,
+		StartedAt:    tree.StartTime,
+		CompletedAt:  tree.EndTime,
+		Summary:      tree.Root.Summary.Status,
+		Scores:       make(map[string]float64),
+		ControlCount: len(tree.ControlRuns),
+	}
+	if score := tree.Root.Summary.Score; score.Scored() {
+		run.Score = &score.Percentage
+	}
+
+	var results []*db_local.CheckResultRecord
+	var addGroup func(group *controlexecute.ResultGroup, path []string)
+	addGroup = func(group *controlexecute.ResultGroup, path []string) {
+		// the root result group is synthetic - it is not included in the benchmark path
+		if group.GroupId != controlexecute.RootResultGroupName {
+			path = append(append([]string{}, path...), group.GroupId)
+			if score := group.Summary.Score; score.Scored() {
+				run.Scores[group.GroupId] = score.Percentage
+			}
+		}
+		for _, controlRun := range group.ControlRuns {
+			results = append(results, controlRunRecords(controlRun, path)...)
+		}
+		for _, child := range group.Groups {
+			addGroup(child, path)
+		}
+	}
+	addGroup(tree.Root, nil)
+	return run, results
+}
+
+// build the result records for a control run
+// if the control run failed, an error result is included, as well as any results received before the failure
+func controlRunRecords(controlRun *controlexecute.ControlRun, path []string) []*db_local.CheckResultRecord {
+	newRecord := func() *db_local.CheckResultRecord {
+		record := &db_local.CheckResultRecord{
+			BenchmarkPath: path,
+			Control:       controlRun.FullName,
+			ControlTitle:  controlRun.Title,
+			Severity:      controlRun.Severity,
+		}
+		if len(path) > 0 {
+			record.Benchmark = path[len(path)-1]
+		}
+		return record
+	}
+
+	var res []*db_local.CheckResultRecord
+	if controlRun.RunErrorString != "" {
+		record := newRecord()
+		record.Status = constants.ControlError
+		record.Reason = controlRun.RunErrorString
+		res = append(res, record)
+	}
+	for _, row := range controlRun.Rows {
+		record := newRecord()
+		record.Status = row.Status
+		record.Reason = row.Reason
+		record.Resource = row.Resource
+		if len(row.Dimensions) > 0 {
+			record.Dimensions = make(map[string]string, len(row.Dimensions))
+			for _, d := range row.Dimensions {
+				record.Dimensions[d.Key] = d.Value
+			}
+		}
+		res = append(res, record)
+	}
+	return res
+}
@@ -0,0 +1,76 @@
+package controlhistory
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/control/controlexecute"
+	"github.com/turbot/steampipe/pkg/control/controlstatus"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"github.com/turbot/steampipe/pkg/workspace"
+)
+
+func TestBuildRecords(t *testing.T) {
+	passing := &controlexecute.ControlRun{
+		FullName: "m.control.passing",
+		Title:    "Passing",
+		Severity: "high",
+		Rows: controlexecute.ResultRows{
+			{Status: constants.ControlOk, Reason: "ok", Resource: "arn:1", Dimensions: []controlexecute.Dimension{{Key: "region", Value: "us-east-1"}}},
+			{Status: constants.ControlAlarm, Reason: "alarm", Resource: "arn:2"},
+		},
+	}
+	failed := &controlexecute.ControlRun{
+		FullName:       "m.control.failed",
+		RunErrorString: "control timed out after 10s",
+	}
+
+	child := &controlexecute.ResultGroup{
+		GroupId:     "m.benchmark.child",
+		Summary:     &controlexecute.GroupSummary{Score: &controlstatus.ComplianceScore{Percentage: 50, TotalWeight: 4, PassedWeight: 2}},
+		ControlRuns: []*controlexecute.ControlRun{passing},
+	}
+	parent := &controlexecute.ResultGroup{
+		GroupId:     "m.benchmark.parent",
+		Summary:     &controlexecute.GroupSummary{},
+		ControlRuns: []*controlexecute.ControlRun{failed},
+		Groups:      []*controlexecute.ResultGroup{child},
+	}
+	root := &controlexecute.ResultGroup{
+		GroupId: controlexecute.RootResultGroupName,
+		Summary: &controlexecute.GroupSummary{Status: controlstatus.StatusSummary{Ok: 1, Alarm: 1, Error: 1}},
+		Groups:  []*controlexecute.ResultGroup{parent},
+	}
+	tree := &controlexecute.ExecutionTree{
+		Root:        root,
+		ControlRuns: []*controlexecute.ControlRun{failed, passing},
+		Workspace:   &workspace.Workspace{Mod: &modconfig.Mod{ShortName: "m"}},
+	}
+
+	run, results := buildRecords(tree, "m.benchmark.parent")
+
+	if run.ModName != "m" || run.ControlCount != 2 || run.Score != nil {
+		t.Errorf("unexpected run record: %+v", run)
+	}
+	if !reflect.DeepEqual(run.Scores, map[string]float64{"m.benchmark.child": 50}) {
+		t.Errorf("unexpected benchmark scores: %v", run.Scores)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	// the failed control has a single error result
+	if r := results[0]; r.Status != constants.ControlError || r.Reason != failed.RunErrorString || r.Benchmark != "m.benchmark.parent" || r.Resource != "" {
+		t.Errorf("unexpected error result: %+v", r)
+	}
+	expectedPath := []string{"m.benchmark.parent", "m.benchmark.child"}
+	for _, r := range results[1:] {
+		if r.Benchmark != "m.benchmark.child" || !reflect.DeepEqual(r.BenchmarkPath, expectedPath) || r.Severity != "high" {
+			t.Errorf("unexpected result: %+v", r)
+		}
+	}
+	if !reflect.DeepEqual(results[1].Dimensions, map[string]string{"region": "us-east-1"}) || results[2].Dimensions != nil {
+		t.Errorf("unexpected dimensions: %v, %v", results[1].Dimensions, results[2].Dimensions)
+	}
+}
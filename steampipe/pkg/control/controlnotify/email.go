@@ -0,0 +1,54 @@
+package controlnotify
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe/pkg/control/controldisplay"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+func sendEmail(notifier *modconfig.Notifier, renderContext *controldisplay.NotificationRenderContext, message string) error {
+	subject := typehelpers.SafeString(notifier.Subject)
+	if subject == "" {
+		subject = fmt.Sprintf("Steampipe check: %s - %d alarms", renderContext.Title, renderContext.AlarmCount())
+	}
+	// the subject may contain the title of the benchmark - remove any line breaks, which would start a new header
+	subject = strings.Join(strings.Fields(subject), " ")
+	from := typehelpers.SafeString(notifier.From)
+	// an address with a line break could add headers or recipients to the email
+	for _, address := range append([]string{from}, notifier.To...) {
+		if strings.ContainsAny(address, "\r\n") {
+			return fmt.Errorf("invalid email address %q - addresses must not contain line breaks", address)
+		}
+	}
+
+	var auth smtp.Auth
+	if notifier.SmtpUsername != nil {
+		host, _, err := net.SplitHostPort(notifier.SmtpAddress())
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", *notifier.SmtpUsername, typehelpers.SafeString(notifier.SmtpPassword), host)
+	}
+
+	return smtp.SendMail(notifier.SmtpAddress(), auth, from, notifier.To, buildEmail(from, notifier.To, subject, message))
+}
+
+func buildEmail(from string, to []string, subject, body string) []byte {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("From: %s\r\n", from))
+	b.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(to, ", ")))
+	b.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
+	b.WriteString(fmt.Sprintf("Date: %s\r\n", time.Now().Format(time.RFC1123Z)))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	// normalise line endings
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
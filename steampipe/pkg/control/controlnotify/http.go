@@ -0,0 +1,86 @@
+package controlnotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe/pkg/control/controldisplay"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+const httpTimeout = 30 * time.Second
+
+// slack incoming webhook payload
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// teams incoming webhook payload (legacy message card format)
+type teamsMessage struct {
+	Type       string `json:"@type"`
+	Context    string `json:"@context"`
+	Summary    string `json:"summary"`
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+	ThemeColor string `json:"themeColor"`
+}
+
+func sendSlack(ctx context.Context, notifier *modconfig.Notifier, message string) error {
+	body, err := json.Marshal(slackMessage{Text: message})
+	if err != nil {
+		return err
+	}
+	return post(ctx, notifier, body)
+}
+
+func sendTeams(ctx context.Context, notifier *modconfig.Notifier, renderContext *controldisplay.NotificationRenderContext, message string) error {
+	title := fmt.Sprintf("Steampipe check: %s", renderContext.Title)
+	body, err := json.Marshal(teamsMessage{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		Summary:    title,
+		Title:      title,
+		Text:       message,
+		ThemeColor: "d62728",
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, notifier, body)
+}
+
+func sendWebhook(ctx context.Context, notifier *modconfig.Notifier, message string) error {
+	return post(ctx, notifier, []byte(message))
+}
+
+func post(ctx context.Context, notifier *modconfig.Notifier, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, httpTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, typehelpers.SafeString(notifier.Url), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range notifier.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("request failed with status %s: %s", resp.Status, string(respBody))
+	}
+	return nil
+}
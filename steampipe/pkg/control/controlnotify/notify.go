@@ -0,0 +1,82 @@
+package controlnotify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/viper"
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/control/controldisplay"
+	"github.com/turbot/steampipe/pkg/control/controlexecute"
+	"github.com/turbot/steampipe/pkg/error_helpers"
+	"github.com/turbot/steampipe/pkg/steampipeconfig"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+// GetNotifiers returns the notifiers specified by the --notify arg, from the active workspace profile
+func GetNotifiers() ([]*modconfig.Notifier, error) {
+	names := viper.GetStringSlice(constants.ArgNotify)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	profile := steampipeconfig.GlobalWorkspaceProfile
+	var res []*modconfig.Notifier
+	var missing []string
+	for _, name := range names {
+		var notifier *modconfig.Notifier
+		var ok bool
+		if profile != nil {
+			notifier, ok = profile.GetNotifier(name)
+		}
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		res = append(res, notifier)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("notifier not found in workspace profile: %s", strings.Join(missing, ", "))
+	}
+	return res, nil
+}
+
+// Notify sends a notification of the execution results to each notifier
+// A notifier is only sent a notification if there are alarms which meet its severity threshold
+func Notify(ctx context.Context, tree *controlexecute.ExecutionTree, target string, notifiers []*modconfig.Notifier) error {
+	var errors []error
+	for _, notifier := range notifiers {
+		if err := notify(ctx, tree, target, notifier); err != nil {
+			errors = append(errors, fmt.Errorf("notifier '%s': %s", notifier.Name, err.Error()))
+		}
+	}
+	return error_helpers.CombineErrors(errors...)
+}
+
+func notify(ctx context.Context, tree *controlexecute.ExecutionTree, target string, notifier *modconfig.Notifier) error {
+	renderContext := controldisplay.NewNotificationRenderContext(tree, target, typehelpers.SafeString(notifier.Severity), notifier.MeetsSeverityThreshold)
+	if renderContext.AlarmCount() == 0 {
+		log.Printf("[TRACE] no alarms meet the threshold for notifier '%s' - not sending", notifier.Name)
+		return nil
+	}
+
+	message, err := controldisplay.RenderNotification(notifier.Type, renderContext)
+	if err != nil {
+		return err
+	}
+
+	switch notifier.Type {
+	case modconfig.NotifierTypeSlack:
+		return sendSlack(ctx, notifier, message)
+	case modconfig.NotifierTypeTeams:
+		return sendTeams(ctx, notifier, renderContext, message)
+	case modconfig.NotifierTypeWebhook:
+		return sendWebhook(ctx, notifier, message)
+	case modconfig.NotifierTypeEmail:
+		return sendEmail(notifier, renderContext, message)
+	}
+	return fmt.Errorf("unsupported notifier type '%s'", notifier.Type)
+}
@@ -0,0 +1,292 @@
+package controlnotify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/control/controlexecute"
+	"github.com/turbot/steampipe/pkg/control/controlstatus"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+func newNotifyTestTree() *controlexecute.ExecutionTree {
+	root := &controlexecute.ResultGroup{
+		GroupId: controlexecute.RootResultGroupName,
+		Title:   "Test Benchmark",
+		Summary: &controlexecute.GroupSummary{Status: controlstatus.StatusSummary{Ok: 1, Alarm: 2}},
+	}
+	var runs []*controlexecute.ControlRun
+	for _, c := range []struct{ name, severity, resource string }{
+		{"s3_encrypted", "high", "arn:aws:s3:::high"},
+		{"s3_tagged", "low", "arn:aws:s3:::low"},
+	} {
+		title := c.name + " title"
+		severity := c.severity
+		control := &modconfig.Control{
+			ShortName: c.name,
+			FullName:  "aws_test.control." + c.name,
+			Title:     &title,
+			Severity:  &severity,
+		}
+		run := &controlexecute.ControlRun{
+			Title:   title,
+			Control: control,
+			Summary: &controlstatus.StatusSummary{Alarm: 1},
+		}
+		run.Rows = controlexecute.ResultRows{
+			{Status: "alarm", Resource: c.resource, Reason: c.resource + " alarm", Run: run, Control: control},
+			{Status: "ok", Resource: c.resource + "-ok", Reason: "ok", Run: run, Control: control},
+		}
+		runs = append(runs, run)
+	}
+	return &controlexecute.ExecutionTree{
+		Root:        root,
+		ControlRuns: runs,
+		StartTime:   time.Now().Add(-time.Minute),
+		EndTime:     time.Now(),
+	}
+}
+
+// start an http server which records the bodies of requests it receives
+func newRecordingServer() (*httptest.Server, *[]string) {
+	var bodies []string
+	var lock sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lock.Lock()
+		bodies = append(bodies, string(body))
+		lock.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	return server, &bodies
+}
+
+func TestNotifyWebhook(t *testing.T) {
+	server, bodies := newRecordingServer()
+	defer server.Close()
+
+	severity := "high"
+	notifier := &modconfig.Notifier{Name: "hook", Type: modconfig.NotifierTypeWebhook, Url: &server.URL, Severity: &severity}
+	if err := Notify(context.Background(), newNotifyTestTree(), "aws_test.benchmark.test", []*modconfig.Notifier{notifier}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*bodies) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(*bodies))
+	}
+
+	var payload struct {
+		Target     string `json:"target"`
+		AlarmCount int    `json:"alarm_count"`
+		Alarms     []struct {
+			Resource string `json:"resource"`
+		} `json:"alarms"`
+	}
+	if err := json.Unmarshal([]byte((*bodies)[0]), &payload); err != nil {
+		t.Fatalf("webhook payload is not valid json: %s\n%s", err, (*bodies)[0])
+	}
+	if payload.Target != "aws_test.benchmark.test" || payload.AlarmCount != 1 || len(payload.Alarms) != 1 || payload.Alarms[0].Resource != "arn:aws:s3:::high" {
+		t.Errorf("unexpected webhook payload: %s", (*bodies)[0])
+	}
+}
+
+func TestNotifyBelowThreshold(t *testing.T) {
+	server, bodies := newRecordingServer()
+	defer server.Close()
+
+	severity := "critical"
+	notifier := &modconfig.Notifier{Name: "slack", Type: modconfig.NotifierTypeSlack, Url: &server.URL, Severity: &severity}
+	if err := Notify(context.Background(), newNotifyTestTree(), "aws_test.benchmark.test", []*modconfig.Notifier{notifier}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*bodies) != 0 {
+		t.Errorf("expected no notification, got %v", *bodies)
+	}
+}
+
+func TestNotifyChat(t *testing.T) {
+	server, bodies := newRecordingServer()
+	defer server.Close()
+
+	notifiers := []*modconfig.Notifier{
+		{Name: "slack", Type: modconfig.NotifierTypeSlack, Url: &server.URL},
+		{Name: "teams", Type: modconfig.NotifierTypeTeams, Url: &server.URL},
+	}
+	if err := Notify(context.Background(), newNotifyTestTree(), "aws_test.benchmark.test", notifiers); err != nil {
+		t.Fatal(err)
+	}
+	if len(*bodies) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(*bodies))
+	}
+	for i, expectedKey := range []string{"text", "@type"} {
+		var payload map[string]any
+		if err := json.Unmarshal([]byte((*bodies)[i]), &payload); err != nil {
+			t.Fatalf("%s payload is not valid json: %s", notifiers[i].Name, err)
+		}
+		if _, ok := payload[expectedKey]; !ok {
+			t.Errorf("%s payload does not contain '%s': %s", notifiers[i].Name, expectedKey, (*bodies)[i])
+		}
+		// no threshold - both alarms should be included
+		for _, resource := range []string{"arn:aws:s3:::high", "arn:aws:s3:::low"} {
+			if !strings.Contains((*bodies)[i], resource) {
+				t.Errorf("%s payload does not contain alarm for %s", notifiers[i].Name, resource)
+			}
+		}
+	}
+}
+
+func TestNotifyHttpError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	notifier := &modconfig.Notifier{Name: "slack", Type: modconfig.NotifierTypeSlack, Url: &server.URL}
+	if err := Notify(context.Background(), newNotifyTestTree(), "aws_test.benchmark.test", []*modconfig.Notifier{notifier}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// a minimal SMTP server which accepts a single message
+func startTestSmtpServer(t *testing.T) (string, int, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := make(chan string, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		write := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		write("220 localhost ESMTP")
+		var data strings.Builder
+		inData := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					messages <- data.String()
+					write("250 OK")
+					continue
+				}
+				data.WriteString(line)
+				continue
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				write("250 localhost")
+			case cmd == "DATA":
+				inData = true
+				write("354 go ahead")
+			case cmd == "QUIT":
+				write("221 bye")
+				return
+			default:
+				write("250 OK")
+			}
+		}
+	}()
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, messages
+}
+
+func TestNotifyEmail(t *testing.T) {
+	host, port, messages := startTestSmtpServer(t)
+
+	from := "steampipe@example.com"
+	severity := "low"
+	notifier := &modconfig.Notifier{
+		Name:     "email",
+		Type:     modconfig.NotifierTypeEmail,
+		Severity: &severity,
+		SmtpHost: &host,
+		SmtpPort: &port,
+		From:     &from,
+		To:       []string{"security@example.com"},
+	}
+	if err := Notify(context.Background(), newNotifyTestTree(), "aws_test.benchmark.test", []*modconfig.Notifier{notifier}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case message := <-messages:
+		for _, expected := range []string{"To: security@example.com", "Subject: Steampipe check: Test Benchmark - 2 alarms", "arn:aws:s3:::high", "arn:aws:s3:::low"} {
+			if !strings.Contains(message, expected) {
+				t.Errorf("email does not contain '%s':\n%s", expected, message)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for email")
+	}
+}
+
+func TestNotifyEmailAddressLineBreaks(t *testing.T) {
+	tree := newNotifyTestTree()
+	from := "steampipe@example.com"
+	host := "127.0.0.1"
+	port := 1
+	notifier := &modconfig.Notifier{
+		Name:     "email",
+		Type:     modconfig.NotifierTypeEmail,
+		SmtpHost: &host,
+		SmtpPort: &port,
+		From:     &from,
+		To:       []string{"security@example.com\r\nBcc: attacker@example.com"},
+	}
+
+	// addresses containing line breaks are rejected before connecting to the server
+	err := Notify(context.Background(), tree, "aws_test.benchmark.test", []*modconfig.Notifier{notifier})
+	if err == nil || !strings.Contains(err.Error(), "line breaks") {
+		t.Errorf("expected an invalid address error, got %v", err)
+	}
+}
+
+func TestNotifyEmailSubjectLineBreaks(t *testing.T) {
+	host, port, messages := startTestSmtpServer(t)
+
+	tree := newNotifyTestTree()
+	tree.Root.Title = "Test\r\nBcc: attacker@example.com"
+	from := "steampipe@example.com"
+	notifier := &modconfig.Notifier{
+		Name:     "email",
+		Type:     modconfig.NotifierTypeEmail,
+		SmtpHost: &host,
+		SmtpPort: &port,
+		From:     &from,
+		To:       []string{"security@example.com"},
+	}
+	if err := Notify(context.Background(), tree, "aws_test.benchmark.test", []*modconfig.Notifier{notifier}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case message := <-messages:
+		if !strings.Contains(message, "Subject: Steampipe check: Test Bcc: attacker@example.com - 2 alarms\r\n") {
+			t.Errorf("email subject contains a line break:\n%s", message)
+		}
+		// the title may contain line breaks in the body, but not in the headers
+		headers, _, _ := strings.Cut(message, "\r\n\r\n")
+		if strings.Contains(headers, "\r\nBcc:") {
+			t.Errorf("email contains an injected header:\n%s", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for email")
+	}
+}
@@ -0,0 +1,53 @@
+package controlstatus
+
+import "math"
+
+// the weight of each control severity in the compliance score
+// controls with no (or an unknown) severity have the same weight as low severity controls
+var severityWeights = map[string]float64{
+	"none":     1,
+	"low":      1,
+	"medium":   2,
+	"high":     4,
+	"critical": 8,
+}
+
+// ComplianceScore is the percentage of passing control results, weighted by control severity and weight
+//
+// each control contributes the proportion of its ok results (out of its ok, alarm and error results),
+// multiplied by the weight of its severity and the control weight
+// info, skip and exempt results are not scored
+type ComplianceScore struct {
+	// the score as a percentage, from 0 to 100
+	Percentage float64 `json:"percentage"`
+	// the total weight of the scored controls
+	TotalWeight float64 `json:"total_weight"`
+	// the weighted sum of the passing proportion of each control
+	PassedWeight float64 `json:"passed_weight"`
+}
+
+// ControlScoreWeight returns the weight of a control in the compliance score
+func ControlScoreWeight(severity string, controlWeight int) float64 {
+	severityWeight, ok := severityWeights[severity]
+	if !ok {
+		severityWeight = 1
+	}
+	return severityWeight * float64(controlWeight)
+}
+
+// AddControl adds the results of a control to the score
+// controls with no ok, alarm or error results, or a weight of zero, are not scored
+func (s *ComplianceScore) AddControl(summary *StatusSummary, weight float64) {
+	scored := summary.Ok + summary.Alarm + summary.Error
+	if scored == 0 || weight == 0 {
+		return
+	}
+	s.TotalWeight += weight
+	s.PassedWeight += weight * float64(summary.Ok) / float64(scored)
+	s.Percentage = math.Round(s.PassedWeight/s.TotalWeight*10000) / 100
+}
+
+// Scored returns whether any controls have been added to the score
+func (s *ComplianceScore) Scored() bool {
+	return s != nil && s.TotalWeight > 0
+}
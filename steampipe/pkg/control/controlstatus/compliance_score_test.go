@@ -0,0 +1,25 @@
+package controlstatus
+
+import "testing"
+
+func TestComplianceScore(t *testing.T) {
+	score := &ComplianceScore{}
+	if score.Scored() {
+		t.Fatal("expected an empty score not to be scored")
+	}
+
+	// a critical control with all results passing
+	score.AddControl(&StatusSummary{Ok: 3}, ControlScoreWeight("critical", 1))
+	// a low severity control with a weight of 2, half passing - info, skip and exempt results are ignored
+	score.AddControl(&StatusSummary{Ok: 1, Alarm: 1, Info: 5, Skip: 2, Exempt: 1}, ControlScoreWeight("low", 2))
+	// a control with no severity which errored
+	score.AddControl(&StatusSummary{Error: 1}, ControlScoreWeight("", 1))
+	// controls with no scored results or a weight of zero are not scored
+	score.AddControl(&StatusSummary{Skip: 1}, ControlScoreWeight("high", 1))
+	score.AddControl(&StatusSummary{Alarm: 1}, ControlScoreWeight("high", 0))
+
+	// (8*1 + 2*0.5 + 1*0) / (8 + 2 + 1)
+	if !score.Scored() || score.TotalWeight != 11 || score.Percentage != 81.82 {
+		t.Errorf("unexpected score %+v", score)
+	}
+}
@@ -16,6 +16,8 @@ type StatusSummary struct {
 	Info  int `json:"info"`
 	Skip  int `json:"skip"`
 	Error int `json:"error"`
+	// alarms which have been accepted by an exemption
+	Exempt int `json:"exempt"`
 }
 
 func (s *StatusSummary) PassedCount() int {
@@ -27,7 +29,7 @@ func (s *StatusSummary) FailedCount() int {
 }
 
 func (s *StatusSummary) TotalCount() int {
-	return s.Alarm + s.Ok + s.Info + s.Skip + s.Error
+	return s.Alarm + s.Ok + s.Info + s.Skip + s.Error + s.Exempt
 }
 
 func (s *StatusSummary) Merge(summary *StatusSummary) {
@@ -36,4 +38,5 @@ func (s *StatusSummary) Merge(summary *StatusSummary) {
 	s.Info += summary.Info
 	s.Skip += summary.Skip
 	s.Error += summary.Error
+	s.Exempt += summary.Exempt
 }
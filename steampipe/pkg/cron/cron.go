@@ -0,0 +1,225 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// descriptors are the supported shorthand expressions
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// the maximum period Next will search for a matching time
+const maxSearchPeriod = 5 * 366 * 24 * time.Hour
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = field{"minute", 0, 59, nil}
+	hourField   = field{"hour", 0, 23, nil}
+	domField    = field{"day of month", 1, 31, nil}
+	monthField  = field{"month", 1, 12, monthNames}
+	// allow 7 as an alias for sunday
+	dowField = field{"day of week", 0, 7, dayNames}
+)
+
+// Expression is a parsed 5 field cron expression: minute hour day-of-month month day-of-week
+type Expression struct {
+	source  string
+	minutes map[int]bool
+	hours   map[int]bool
+	dom     map[int]bool
+	months  map[int]bool
+	dow     map[int]bool
+	// were the day fields restricted (i.e. not including every day, as '*' does)
+	domRestricted bool
+	dowRestricted bool
+}
+
+// Parse parses a standard 5 field cron expression, or one of the descriptors @yearly, @monthly, @weekly, @daily, @hourly
+func Parse(expr string) (*Expression, error) {
+	source := strings.TrimSpace(expr)
+	if descriptor, ok := descriptors[strings.ToLower(source)]; ok {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression '%s': expected 5 fields, got %d", source, len(fields))
+	}
+
+	res := &Expression{source: source}
+	var err error
+	if res.minutes, err = parseField(fields[0], minuteField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression '%s': %s", source, err.Error())
+	}
+	if res.hours, err = parseField(fields[1], hourField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression '%s': %s", source, err.Error())
+	}
+	if res.dom, err = parseField(fields[2], domField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression '%s': %s", source, err.Error())
+	}
+	if res.months, err = parseField(fields[3], monthField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression '%s': %s", source, err.Error())
+	}
+	if res.dow, err = parseField(fields[4], dowField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression '%s': %s", source, err.Error())
+	}
+	// 7 is an alias for sunday
+	if res.dow[7] {
+		res.dow[0] = true
+	}
+	res.domRestricted = isRestricted(res.dom, domField.min, domField.max)
+	// 7 is an alias, so check sunday to saturday
+	res.dowRestricted = isRestricted(res.dow, 0, 6)
+	return res, nil
+}
+
+// String returns the source expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Next returns the first time after t which matches the expression
+// If there is no matching time within 5 years (e.g. '0 0 30 2 *'), the zero time is returned
+func (e *Expression) Next(t time.Time) time.Time {
+	// start from the next whole minute
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearchPeriod)
+
+	for t.Before(limit) {
+		if !e.months[int(t.Month())] {
+			// move to the start of the next month
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !e.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !e.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !e.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// if both day of month and day of week are restricted, a time matches if EITHER field matches
+// (this is the standard cron behaviour)
+func (e *Expression) dayMatches(t time.Time) bool {
+	domMatch := e.dom[t.Day()]
+	dowMatch := e.dow[int(t.Weekday())]
+	if e.domRestricted && e.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// a field is restricted unless it includes every value from min to max - e.g. '*', '*/1' or '1-31'
+func isRestricted(values map[int]bool, min, max int) bool {
+	for i := min; i <= max; i++ {
+		if !values[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// parse a single field, which is a comma separated list of '*', values, ranges and steps, e.g. '1,5-10,*/15'
+func parseField(value string, f field) (map[int]bool, error) {
+	res := make(map[int]bool)
+	for _, part := range strings.Split(value, ",") {
+		if err := parsePart(part, f, res); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func parsePart(part string, f field, res map[int]bool) error {
+	rangePart, step := part, 1
+	if idx := strings.Index(part, "/"); idx != -1 {
+		rangePart = part[:idx]
+		var err error
+		step, err = strconv.Atoi(part[idx+1:])
+		if err != nil || step < 1 {
+			return fmt.Errorf("invalid step in %s field '%s'", f.name, part)
+		}
+	}
+
+	var start, end int
+	switch {
+	case rangePart == "*":
+		start, end = f.min, f.max
+	case strings.Contains(rangePart, "-"):
+		bounds := strings.SplitN(rangePart, "-", 2)
+		var err error
+		if start, err = parseValue(bounds[0], f); err != nil {
+			return err
+		}
+		if end, err = parseValue(bounds[1], f); err != nil {
+			return err
+		}
+		if start > end {
+			return fmt.Errorf("invalid range in %s field '%s'", f.name, part)
+		}
+	default:
+		var err error
+		if start, err = parseValue(rangePart, f); err != nil {
+			return err
+		}
+		end = start
+		// a step applied to a single value means 'from value to max'
+		if step > 1 {
+			end = f.max
+		}
+	}
+
+	for i := start; i <= end; i += step {
+		res[i] = true
+	}
+	return nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	if v, ok := f.names[strings.ToLower(value)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s '%s'", f.name, value)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s '%d' out of range (%d-%d)", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
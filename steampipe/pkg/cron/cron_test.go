@@ -0,0 +1,112 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+type nextTest struct {
+	expr     string
+	from     string
+	expected string
+}
+
+var testCasesNext = map[string]nextTest{
+	"every minute": {
+		expr:     "* * * * *",
+		from:     "2022-12-01T10:15:30Z",
+		expected: "2022-12-01T10:16:00Z",
+	},
+	"every 15 minutes": {
+		expr:     "*/15 * * * *",
+		from:     "2022-12-01T10:15:00Z",
+		expected: "2022-12-01T10:30:00Z",
+	},
+	"daily at 2am": {
+		expr:     "0 2 * * *",
+		from:     "2022-12-01T10:15:00Z",
+		expected: "2022-12-02T02:00:00Z",
+	},
+	"daily descriptor": {
+		expr:     "@daily",
+		from:     "2022-12-31T23:59:00Z",
+		expected: "2023-01-01T00:00:00Z",
+	},
+	"weekdays at 9": {
+		expr:     "0 9 * * mon-fri",
+		from:     "2022-12-02T10:00:00Z", // friday
+		expected: "2022-12-05T09:00:00Z", // monday
+	},
+	"sunday as 7": {
+		expr:     "30 6 * * 7",
+		from:     "2022-12-01T00:00:00Z",
+		expected: "2022-12-04T06:30:00Z",
+	},
+	"first of month": {
+		expr:     "0 0 1 * *",
+		from:     "2022-12-01T00:00:00Z",
+		expected: "2023-01-01T00:00:00Z",
+	},
+	"day of month or day of week": {
+		expr:     "0 0 15 * sun",
+		from:     "2022-12-05T00:00:00Z",
+		expected: "2022-12-11T00:00:00Z",
+	},
+	"month names and lists": {
+		expr:     "0 12 1 jan,jul *",
+		from:     "2022-02-01T00:00:00Z",
+		expected: "2022-07-01T12:00:00Z",
+	},
+	"leap day": {
+		expr:     "0 0 29 2 *",
+		from:     "2022-03-01T00:00:00Z",
+		expected: "2024-02-29T00:00:00Z",
+	},
+	"day of month step covering every day is unrestricted": {
+		expr:     "0 0 */1 * MON",
+		from:     "2022-03-01T00:00:00Z",
+		expected: "2022-03-07T00:00:00Z",
+	},
+	"day of month range covering every day is unrestricted": {
+		expr:     "0 0 1-31 * mon",
+		from:     "2022-03-01T00:00:00Z",
+		expected: "2022-03-07T00:00:00Z",
+	},
+	"day of week range covering every day is unrestricted": {
+		expr:     "0 0 15 * 0-7",
+		from:     "2022-03-01T00:00:00Z",
+		expected: "2022-03-15T00:00:00Z",
+	},
+	"impossible date": {
+		expr:     "0 0 30 2 *",
+		from:     "2022-03-01T00:00:00Z",
+		expected: "",
+	},
+}
+
+func TestNext(t *testing.T) {
+	for name, test := range testCasesNext {
+		expr, err := Parse(test.expr)
+		if err != nil {
+			t.Errorf("Test: '%s'' FAILED : %s", name, err.Error())
+			continue
+		}
+		from, _ := time.Parse(time.RFC3339, test.from)
+		next := expr.Next(from)
+		var actual string
+		if !next.IsZero() {
+			actual = next.Format(time.RFC3339)
+		}
+		if actual != test.expected {
+			t.Errorf("Test: '%s'' FAILED : expected %s, got %s", name, test.expected, actual)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "* * * foo *", "@every_minute"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("expected error parsing '%s'", expr)
+		}
+	}
+}
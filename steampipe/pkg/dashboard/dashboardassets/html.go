@@ -0,0 +1,213 @@
+package dashboardassets
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/turbot/steampipe/pkg/filepaths"
+)
+
+// the global variable the dashboard UI reads an embedded snapshot from
+const embeddedSnapshotVariable = "__STEAMPIPE_SNAPSHOT__"
+
+var (
+	scriptTagRegex = regexp.MustCompile(`<script[^>]*\ssrc="([^"]+)"[^>]*>\s*</script>`)
+	linkTagRegex   = regexp.MustCompile(`<link[^>]*>`)
+	hrefRegex      = regexp.MustCompile(`\shref="([^"]+)"`)
+	relRegex       = regexp.MustCompile(`\srel="([^"]+)"`)
+	cssURLRegex    = regexp.MustCompile(`url\(\s*['"]?([^'")]+?)['"]?\s*\)`)
+)
+
+// SnapshotHTML returns a self-contained HTML page which renders the snapshot using the dashboard UI
+// All scripts, styles and media are inlined, so the page can be viewed without network access
+func SnapshotHTML(ctx context.Context, snapshotJSON []byte) ([]byte, error) {
+	// ensure the dashboard assets are installed
+	if err := Ensure(ctx); err != nil {
+		return nil, err
+	}
+	return bundleSnapshotHTML(filepaths.EnsureDashboardAssetsDir(), snapshotJSON)
+}
+
+func bundleSnapshotHTML(assetsDir string, snapshotJSON []byte) ([]byte, error) {
+	indexHTML, err := os.ReadFile(filepath.Join(assetsDir, "index.html"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dashboard assets: %s", err.Error())
+	}
+	page := string(indexHTML)
+
+	// remove the script tags - the scripts are inlined at the end of the body, as inline scripts cannot be deferred
+	var scripts []string
+	page = scriptTagRegex.ReplaceAllStringFunc(page, func(tag string) string {
+		scripts = append(scripts, scriptTagRegex.FindStringSubmatch(tag)[1])
+		return ""
+	})
+	if len(scripts) == 0 {
+		return nil, fmt.Errorf("dashboard assets index.html does not reference any scripts")
+	}
+
+	// inline stylesheets and icons - remove any other links (e.g. the manifest) as they cannot be loaded offline
+	var stylesheets []string
+	var linkErr error
+	page = linkTagRegex.ReplaceAllStringFunc(page, func(tag string) string {
+		href := hrefRegex.FindStringSubmatch(tag)
+		rel := relRegex.FindStringSubmatch(tag)
+		if href == nil || rel == nil || isExternalURL(href[1]) {
+			return tag
+		}
+		assetPath := resolveAssetPath(assetsDir, "", href[1])
+		switch {
+		case rel[1] == "stylesheet":
+			stylesheets = append(stylesheets, href[1])
+			css, err := inlineCSS(assetsDir, assetPath)
+			if err != nil {
+				linkErr = err
+				return ""
+			}
+			return "<style>" + css + "</style>"
+		case strings.Contains(rel[1], "icon") && rel[1] != "apple-touch-icon":
+			dataURI, err := fileAsDataURI(assetPath)
+			if err != nil {
+				return ""
+			}
+			return strings.Replace(tag, href[0], fmt.Sprintf(` href="%s"`, dataURI), 1)
+		}
+		return ""
+	})
+	if linkErr != nil {
+		return nil, linkErr
+	}
+
+	// inline the lazily loaded stylesheet chunks - the chunk loader does not load a stylesheet
+	// if there is a style tag whose data-href is the stylesheet url
+	styleChunks, err := lazyChunks(assetsDir, filepath.Join("static", "css", "*.css"), stylesheets)
+	if err != nil {
+		return nil, err
+	}
+	var head strings.Builder
+	for _, chunk := range styleChunks {
+		css, err := inlineCSS(assetsDir, resolveAssetPath(assetsDir, "", chunk))
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&head, "<style data-href=\"%s\">%s</style>\n", chunk, css)
+	}
+	headEnd := strings.Index(page, "</head>")
+	if headEnd == -1 {
+		return nil, fmt.Errorf("dashboard assets index.html has no head")
+	}
+	page = page[:headEnd] + head.String() + page[headEnd:]
+
+	// build the inline scripts - the snapshot, then any lazily loaded chunks, so they are registered
+	// before the entry point scripts run, then the entry point scripts
+	var body strings.Builder
+	// '<' can only occur within json strings, so escaping it ensures the snapshot cannot close the script tag
+	snapshotJSON = bytes.ReplaceAll(snapshotJSON, []byte("<"), []byte(`\u003c`))
+	fmt.Fprintf(&body, "<script>window.%s=%s;</script>\n", embeddedSnapshotVariable, snapshotJSON)
+	chunks, err := lazyChunks(assetsDir, filepath.Join("static", "js", "*.js"), scripts)
+	if err != nil {
+		return nil, err
+	}
+	for _, script := range append(chunks, scripts...) {
+		if isExternalURL(script) {
+			continue
+		}
+		content, err := os.ReadFile(resolveAssetPath(assetsDir, "", script))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dashboard assets: %s", err.Error())
+		}
+		fmt.Fprintf(&body, "<script>%s</script>\n", escapeScript(string(content)))
+	}
+
+	bodyEnd := strings.LastIndex(page, "</body>")
+	if bodyEnd == -1 {
+		return nil, fmt.Errorf("dashboard assets index.html has no body")
+	}
+	return []byte(page[:bodyEnd] + body.String() + page[bodyEnd:]), nil
+}
+
+// lazyChunks returns the paths (relative to the assets dir) of the assets matching the pattern which are not
+// referenced by index.html, i.e. those loaded on demand
+func lazyChunks(assetsDir, pattern string, entryRefs []string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(assetsDir, pattern))
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, f := range files {
+		rel := "/" + filepath.ToSlash(strings.TrimPrefix(f, assetsDir+string(filepath.Separator)))
+		isEntry := false
+		for _, ref := range entryRefs {
+			if "/"+strings.TrimPrefix(strings.TrimPrefix(ref, "."), "/") == rel {
+				isEntry = true
+			}
+		}
+		if !isEntry {
+			res = append(res, rel)
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// replace the url() references in a stylesheet with data URIs
+func inlineCSS(assetsDir, cssPath string) (string, error) {
+	css, err := os.ReadFile(cssPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read dashboard assets: %s", err.Error())
+	}
+	res := cssURLRegex.ReplaceAllStringFunc(string(css), func(ref string) string {
+		url := cssURLRegex.FindStringSubmatch(ref)[1]
+		if isExternalURL(url) || strings.HasPrefix(url, "data:") || strings.HasPrefix(url, "#") {
+			return ref
+		}
+		dataURI, err := fileAsDataURI(resolveAssetPath(assetsDir, filepath.Dir(cssPath), url))
+		if err != nil {
+			// leave unresolvable references - the browser will ignore them
+			return ref
+		}
+		return fmt.Sprintf(`url("%s")`, dataURI)
+	})
+	// a stylesheet must not close the style tag
+	return strings.ReplaceAll(res, "</style", `<\/style`), nil
+}
+
+// resolve an asset reference - absolute references are relative to the assets dir, others to the given dir
+func resolveAssetPath(assetsDir, dir, ref string) string {
+	// remove any query string or fragment
+	if idx := strings.IndexAny(ref, "?#"); idx != -1 {
+		ref = ref[:idx]
+	}
+	if strings.HasPrefix(ref, "/") || dir == "" {
+		return filepath.Join(assetsDir, filepath.FromSlash(ref))
+	}
+	return filepath.Join(dir, filepath.FromSlash(ref))
+}
+
+func fileAsDataURI(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(content)), nil
+}
+
+func isExternalURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "//")
+}
+
+// a script must not close the script tag
+func escapeScript(script string) string {
+	return strings.ReplaceAll(script, "</script", `<\/script`)
+}
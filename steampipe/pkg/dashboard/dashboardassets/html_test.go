@@ -0,0 +1,76 @@
+package dashboardassets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeAsset(t *testing.T, assetsDir, name, content string) {
+	path := filepath.Join(assetsDir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBundleSnapshotHTML(t *testing.T) {
+	assetsDir := t.TempDir()
+	writeAsset(t, assetsDir, "index.html", `<!doctype html><html lang="en"><head><meta charset="utf-8"/>`+
+		`<link rel="icon" href="/favicon.svg" type="image/svg+xml"/><link rel="manifest" href="/manifest.json"/>`+
+		`<title>Dashboards | Steampipe</title><script defer="defer" src="/static/js/main.abc.js"></script>`+
+		`<link href="/static/css/main.abc.css" rel="stylesheet"></head><body><div id="root"></div></body></html>`)
+	writeAsset(t, assetsDir, "favicon.svg", "<svg></svg>")
+	writeAsset(t, assetsDir, "manifest.json", "{}")
+	writeAsset(t, assetsDir, "static/js/main.abc.js", `console.log("main</script>")`)
+	writeAsset(t, assetsDir, "static/js/123.def.chunk.js", `console.log("chunk")`)
+	writeAsset(t, assetsDir, "static/css/main.abc.css", `.logo{background:url(../media/logo.png)}`)
+	writeAsset(t, assetsDir, "static/css/456.ghi.chunk.css", `.chart{background:url(../media/logo.png)}`)
+	writeAsset(t, assetsDir, "static/media/logo.png", "png")
+
+	res, err := bundleSnapshotHTML(assetsDir, []byte(`{"title":"</script><b>"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(res)
+
+	// no references to local files should remain
+	for _, ref := range []string{` src="/`, ` href="/`, "manifest", "../media/logo.png"} {
+		if strings.Contains(html, ref) {
+			t.Errorf("expected no reference '%s' in bundled html", ref)
+		}
+	}
+	// the snapshot, chunk and main scripts are inlined at the end of the body, in that order
+	snapshotIdx := strings.Index(html, `window.__STEAMPIPE_SNAPSHOT__={"title":"\u003c/script>\u003cb>"};`)
+	chunkIdx := strings.Index(html, `console.log("chunk")`)
+	mainIdx := strings.Index(html, `console.log("main<\/script>")`)
+	bodyIdx := strings.Index(html, `<div id="root"></div>`)
+	if snapshotIdx == -1 || chunkIdx == -1 || mainIdx == -1 || !(bodyIdx < snapshotIdx && snapshotIdx < chunkIdx && chunkIdx < mainIdx) {
+		t.Errorf("scripts not inlined as expected:\n%s", html)
+	}
+	// the lazily loaded stylesheet chunk is inlined in the head, with the data-href the chunk loader looks for
+	chunkStyle := `<style data-href="/static/css/456.ghi.chunk.css">.chart{background:url("data:image/png;base64,cG5n")}</style>`
+	if chunkStyleIdx := strings.Index(html, chunkStyle); chunkStyleIdx == -1 || chunkStyleIdx > strings.Index(html, "</head>") {
+		t.Errorf("expected stylesheet chunk to be inlined in the head:\n%s", html)
+	}
+	if strings.Contains(html, `data-href="/static/css/main.abc.css"`) {
+		t.Error("expected the entry stylesheet not to be inlined as a chunk")
+	}
+	for _, expected := range []string{
+		`<style>.logo{background:url("data:image/png;base64,cG5n")}</style>`,
+		`href="data:image/svg+xml;base64,`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("expected bundled html to contain '%s'", expected)
+		}
+	}
+}
+
+func TestBundleSnapshotHTMLMissingAssets(t *testing.T) {
+	if _, err := bundleSnapshotHTML(t.TempDir(), []byte("{}")); err == nil {
+		t.Error("expected error bundling html with no assets")
+	}
+}
@@ -0,0 +1,99 @@
+package dashboardserver
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// AccessPolicy restricts the dashboards, benchmarks and snapshots which each user may view
+// it is loaded from a file of 'access' blocks:
+//
+//	access "auditors" {
+//	  users     = ["alice"]
+//	  groups    = ["auditors"]
+//	  resources = ["aws_compliance.benchmark.*"]
+//	}
+//
+// a user may view a resource if any rule which applies to the user (by name or group) has a resource pattern
+// matching the resource name - users to which no rule applies may not view anything
+type AccessPolicy struct {
+	Rules []*AccessRule `hcl:"access,block"`
+}
+
+type AccessRule struct {
+	Name   string   `hcl:"name,label"`
+	Users  []string `hcl:"users,optional"`
+	Groups []string `hcl:"groups,optional"`
+	// glob patterns matched against the resource names, e.g. 'aws_compliance.benchmark.cis_*'
+	Resources []string `hcl:"resources"`
+}
+
+// LoadAccessPolicy loads the access policy from the given file
+func LoadAccessPolicy(filePath string) (*AccessPolicy, error) {
+	file, diags := hclparse.NewParser().ParseHCLFile(filePath)
+	if diags.HasErrors() {
+		return nil, plugin.DiagsToError("failed to load dashboard access policy", diags)
+	}
+	policy := &AccessPolicy{}
+	if diags := gohcl.DecodeBody(file.Body, nil, policy); diags.HasErrors() {
+		return nil, plugin.DiagsToError("failed to load dashboard access policy", diags)
+	}
+	for _, rule := range policy.Rules {
+		if len(rule.Users)+len(rule.Groups) == 0 {
+			return nil, fmt.Errorf("dashboard access rule '%s' must specify 'users' or 'groups'", rule.Name)
+		}
+		for _, pattern := range rule.Resources {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("dashboard access rule '%s' has invalid resource pattern '%s'", rule.Name, pattern)
+			}
+		}
+	}
+	return policy, nil
+}
+
+// Allowed returns whether the user may view the resource
+// a nil policy allows all access
+func (p *AccessPolicy) Allowed(user *User, resourceName string) bool {
+	if p == nil {
+		return true
+	}
+	if user == nil {
+		return false
+	}
+	for _, rule := range p.Rules {
+		if rule.appliesTo(user) && rule.matches(resourceName) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *AccessRule) appliesTo(user *User) bool {
+	if helpers.StringSliceContains(r.Users, user.Name) {
+		return true
+	}
+	for _, group := range user.Groups {
+		if helpers.StringSliceContains(r.Groups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *AccessRule) matches(resourceName string) bool {
+	for _, pattern := range r.Resources {
+		// '*' on its own matches everything, including snapshot paths
+		if pattern == "*" {
+			return true
+		}
+		if match, _ := path.Match(pattern, resourceName); match {
+			return true
+		}
+	}
+	return false
+}
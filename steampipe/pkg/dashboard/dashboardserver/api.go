@@ -17,7 +17,10 @@ import (
 	"gopkg.in/olahol/melody.v1"
 )
 
-func startAPIAsync(ctx context.Context, webSocket *melody.Melody) chan struct{} {
+// the gin context and websocket session key of the authenticated user
+const userKey = "user"
+
+func startAPIAsync(ctx context.Context, webSocket *melody.Melody, authenticator Authenticator) chan struct{} {
 	doneChan := make(chan struct{})
 
 	go func() {
@@ -25,13 +28,22 @@ func startAPIAsync(ctx context.Context, webSocket *melody.Melody) chan struct{}
 		router := gin.New()
 		// only add the Recovery middleware
 		router.Use(gin.Recovery())
+		// authenticate all requests, including static assets, before they are served
+		if authenticator != nil {
+			router.Use(authMiddleware(authenticator))
+		}
 
 		assetsDirectory := filepaths.EnsureDashboardAssetsDir()
 
 		router.Use(static.Serve("/", static.LocalFile(assetsDirectory, true)))
 
 		router.GET("/ws", func(c *gin.Context) {
-			webSocket.HandleRequest(c.Writer, c.Request)
+			// store the user in the session, so the user's access can be checked for each message
+			var keys map[string]interface{}
+			if user, ok := c.Get(userKey); ok {
+				keys = map[string]interface{}{userKey: user}
+			}
+			webSocket.HandleRequestWithKeys(c.Writer, c.Request, keys)
 		})
 
 		router.NoRoute(func(c *gin.Context) {
@@ -80,3 +92,17 @@ func startAPIAsync(ctx context.Context, webSocket *melody.Melody) chan struct{}
 
 	return doneChan
 }
+
+func authMiddleware(authenticator Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := authenticator.Authenticate(c.Writer, c.Request)
+		if user == nil {
+			log.Printf("[TRACE] unauthenticated request for %s", c.Request.URL.Path)
+			authenticator.Challenge(c.Writer)
+			c.Abort()
+			return
+		}
+		c.Set(userKey, user)
+		c.Next()
+	}
+}
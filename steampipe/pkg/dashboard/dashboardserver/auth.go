@@ -0,0 +1,271 @@
+package dashboardserver
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/steampipe/pkg/constants"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type AuthType string
+
+const (
+	AuthTypeNone   AuthType = "none"
+	AuthTypeToken  AuthType = "token"
+	AuthTypeBasic  AuthType = "basic"
+	AuthTypeHeader AuthType = "header"
+)
+
+// IsValid is a validator for AuthType known values
+func (a AuthType) IsValid() error {
+	switch a {
+	case AuthTypeNone, AuthTypeToken, AuthTypeBasic, AuthTypeHeader:
+		return nil
+	}
+	return fmt.Errorf("invalid auth type. Must be one of '%v', '%v', '%v' or '%v'", AuthTypeNone, AuthTypeToken, AuthTypeBasic, AuthTypeHeader)
+}
+
+// the name of the cookie used to store the token, so the token only needs to be passed in the url once
+const tokenCookieName = "steampipe_dashboard_token"
+
+// TokenUserName is the user name of sessions authenticated with a static token
+const TokenUserName = "token"
+
+// User is an authenticated dashboard server user
+type User struct {
+	Name   string
+	Groups []string
+}
+
+// Authenticator authenticates requests to the dashboard server
+type Authenticator interface {
+	// Authenticate returns the user making the request, or nil if the request is not authenticated
+	Authenticate(w http.ResponseWriter, r *http.Request) *User
+	// Challenge writes the response to an unauthenticated request
+	Challenge(w http.ResponseWriter)
+}
+
+// NewAuthenticator creates the Authenticator for the auth type set in viper
+// it returns nil if authentication is disabled
+func NewAuthenticator() (Authenticator, error) {
+	authType := AuthType(viper.GetString(constants.ArgDashboardAuth))
+	if err := authType.IsValid(); err != nil {
+		return nil, err
+	}
+
+	switch authType {
+	case AuthTypeToken:
+		token := viper.GetString(constants.ArgDashboardAuthToken)
+		if token == "" {
+			return nil, fmt.Errorf("--%s must be set (or %s) when using token authentication", constants.ArgDashboardAuthToken, constants.EnvDashboardAuthToken)
+		}
+		return &tokenAuthenticator{token: []byte(token)}, nil
+	case AuthTypeBasic:
+		usersFile := viper.GetString(constants.ArgDashboardAuthUsers)
+		if usersFile == "" {
+			return nil, fmt.Errorf("--%s must be set when using basic authentication", constants.ArgDashboardAuthUsers)
+		}
+		return newBasicAuthenticator(usersFile)
+	case AuthTypeHeader:
+		return &headerAuthenticator{
+			userHeader:   viper.GetString(constants.ArgDashboardAuthHeader),
+			groupsHeader: viper.GetString(constants.ArgDashboardAuthGroups),
+		}, nil
+	}
+	return nil, nil
+}
+
+// tokenAuthenticator authenticates requests which provide a static token, either as a bearer token,
+// a 'token' query parameter (e.g. when first opening the dashboard in a browser) or the token cookie
+type tokenAuthenticator struct {
+	token []byte
+}
+
+func (a *tokenAuthenticator) Authenticate(w http.ResponseWriter, r *http.Request) *User {
+	if authHeader := r.Header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") && a.matches(strings.TrimPrefix(authHeader, "Bearer ")) {
+		return &User{Name: TokenUserName}
+	}
+	if cookie, err := r.Cookie(tokenCookieName); err == nil && a.matches(cookie.Value) {
+		return &User{Name: TokenUserName}
+	}
+	if token := r.URL.Query().Get("token"); token != "" && a.matches(token) {
+		// store the token in a cookie so subsequent requests (including the websocket) are authenticated
+		http.SetCookie(w, &http.Cookie{
+			Name:     tokenCookieName,
+			Value:    token,
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteStrictMode,
+		})
+		return &User{Name: TokenUserName}
+	}
+	return nil
+}
+
+func (a *tokenAuthenticator) matches(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), a.token) == 1
+}
+
+func (a *tokenAuthenticator) Challenge(w http.ResponseWriter) {
+	http.Error(w, "a valid token must be provided", http.StatusUnauthorized)
+}
+
+type basicUser struct {
+	passwordHash []byte
+	groups       []string
+}
+
+// basicAuthenticator authenticates requests using HTTP basic auth, verifying the password against
+// the bcrypt hashes in a users file
+type basicAuthenticator struct {
+	users map[string]*basicUser
+	// bcrypt is deliberately slow - cache the digest of verified credentials, as the browser sends them on every request
+	verified     map[string][32]byte
+	verifiedLock sync.Mutex
+}
+
+func newBasicAuthenticator(usersFile string) (*basicAuthenticator, error) {
+	users, err := loadUsersFile(usersFile)
+	if err != nil {
+		return nil, err
+	}
+	return &basicAuthenticator{
+		users:    users,
+		verified: make(map[string][32]byte),
+	}, nil
+}
+
+func (a *basicAuthenticator) Authenticate(_ http.ResponseWriter, r *http.Request) *User {
+	name, password, ok := r.BasicAuth()
+	if !ok {
+		return nil
+	}
+	user, ok := a.users[name]
+	if !ok {
+		return nil
+	}
+
+	digest := sha256.Sum256([]byte(password))
+	a.verifiedLock.Lock()
+	verified, ok := a.verified[name]
+	a.verifiedLock.Unlock()
+	if !ok || subtle.ConstantTimeCompare(verified[:], digest[:]) != 1 {
+		if bcrypt.CompareHashAndPassword(user.passwordHash, []byte(password)) != nil {
+			return nil
+		}
+		a.verifiedLock.Lock()
+		a.verified[name] = digest
+		a.verifiedLock.Unlock()
+	}
+	return &User{Name: name, Groups: user.groups}
+}
+
+func (a *basicAuthenticator) Challenge(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="Steampipe Dashboard", charset="UTF-8"`)
+	http.Error(w, "authentication required", http.StatusUnauthorized)
+}
+
+// loadUsersFile loads a users file - each line has the format
+//
+//	<user>:<bcrypt password hash>[:<group>,<group>...]
+//
+// blank lines and lines starting with '#' are ignored
+func loadUsersFile(path string) (map[string]*basicUser, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read users file: %s", err.Error())
+	}
+
+	users := make(map[string]*basicUser)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Split(line, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return nil, fmt.Errorf("%s line %d: expected '<user>:<password hash>[:<groups>]'", path, lineNumber)
+		}
+		if _, err := bcrypt.Cost([]byte(parts[1])); err != nil {
+			return nil, fmt.Errorf("%s line %d: password hash for user '%s' is not a valid bcrypt hash", path, lineNumber, parts[0])
+		}
+		if _, ok := users[parts[0]]; ok {
+			return nil, fmt.Errorf("%s line %d: duplicate user '%s'", path, lineNumber, parts[0])
+		}
+		user := &basicUser{passwordHash: []byte(parts[1])}
+		if len(parts) == 3 {
+			user.groups = splitGroups(parts[2])
+		}
+		users[parts[0]] = user
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("users file %s does not contain any users", path)
+	}
+	return users, nil
+}
+
+// headerAuthenticator trusts the user (and optionally groups) set in request headers by an authenticating
+// reverse proxy - it must only be used when the dashboard server is not reachable other than through the proxy
+type headerAuthenticator struct {
+	userHeader   string
+	groupsHeader string
+}
+
+func (a *headerAuthenticator) Authenticate(_ http.ResponseWriter, r *http.Request) *User {
+	name := strings.TrimSpace(r.Header.Get(a.userHeader))
+	if name == "" {
+		return nil
+	}
+	user := &User{Name: name}
+	if a.groupsHeader != "" {
+		user.Groups = splitGroups(r.Header.Get(a.groupsHeader))
+	}
+	return user
+}
+
+func (a *headerAuthenticator) Challenge(w http.ResponseWriter) {
+	http.Error(w, fmt.Sprintf("request does not contain the '%s' header", a.userHeader), http.StatusUnauthorized)
+}
+
+func splitGroups(groups string) []string {
+	var res []string
+	for _, group := range strings.Split(groups, ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			res = append(res, group)
+		}
+	}
+	return res
+}
+
+// checkSameOrigin returns whether the origin of a websocket upgrade request (if any) is the dashboard server.
+// Browsers send the credentials of the dashboard server (cookies and basic auth) with a websocket request
+// made from any page, so when auth is enabled, requests from pages on other sites must be refused.
+// The forwarded host is used if set by a reverse proxy - browsers do not allow pages to set it
+func checkSameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host := r.Host
+	if forwardedHost := r.Header.Get("X-Forwarded-Host"); forwardedHost != "" {
+		host = forwardedHost
+	}
+	return strings.EqualFold(u.Host, host)
+}
@@ -0,0 +1,173 @@
+package dashboardserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestTokenAuthenticator(t *testing.T) {
+	a := &tokenAuthenticator{token: []byte("secret")}
+
+	// the token in the query string is accepted and stored in a cookie
+	w := httptest.NewRecorder()
+	if user := a.Authenticate(w, httptest.NewRequest(http.MethodGet, "/?token=secret", nil)); user == nil {
+		t.Fatal("expected query string token to be accepted")
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != tokenCookieName {
+		t.Fatalf("expected token cookie to be set, got %v", cookies)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	r.AddCookie(cookies[0])
+	if user := a.Authenticate(httptest.NewRecorder(), r); user == nil || user.Name != TokenUserName {
+		t.Errorf("expected token cookie to be accepted, got %v", user)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	if user := a.Authenticate(httptest.NewRecorder(), r); user == nil {
+		t.Error("expected bearer token to be accepted")
+	}
+	r.Header.Set("Authorization", "Bearer wrong")
+	if user := a.Authenticate(httptest.NewRecorder(), r); user != nil {
+		t.Error("expected invalid bearer token to be rejected")
+	}
+}
+
+func TestBasicAuthenticator(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	usersFile := filepath.Join(t.TempDir(), "users")
+	content := "# dashboard users\nalice:" + string(hash) + ":auditors, admins\n\nbob:" + string(hash) + "\n"
+	if err := os.WriteFile(usersFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	a, err := newBasicAuthenticator(usersFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		user, password string
+		authenticated  bool
+	}{
+		{"alice", "password", true},
+		// the second request uses the cached verification
+		{"alice", "password", true},
+		{"alice", "wrong", false},
+		{"carol", "password", false},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.SetBasicAuth(test.user, test.password)
+		user := a.Authenticate(httptest.NewRecorder(), r)
+		if (user != nil) != test.authenticated {
+			t.Errorf("%s/%s: expected authenticated %v", test.user, test.password, test.authenticated)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.SetBasicAuth("alice", "password")
+	if user := a.Authenticate(httptest.NewRecorder(), r); len(user.Groups) != 2 || user.Groups[1] != "admins" {
+		t.Errorf("expected groups [auditors admins], got %v", user.Groups)
+	}
+
+	if err := os.WriteFile(usersFile, []byte("alice:notahash\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newBasicAuthenticator(usersFile); err == nil {
+		t.Error("expected error loading users file with invalid hash")
+	}
+}
+
+func TestHeaderAuthenticator(t *testing.T) {
+	a := &headerAuthenticator{userHeader: "X-Forwarded-User", groupsHeader: "X-Forwarded-Groups"}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if user := a.Authenticate(httptest.NewRecorder(), r); user != nil {
+		t.Error("expected request without user header to be rejected")
+	}
+	r.Header.Set("X-Forwarded-User", "alice")
+	r.Header.Set("X-Forwarded-Groups", "auditors,admins")
+	if user := a.Authenticate(httptest.NewRecorder(), r); user == nil || user.Name != "alice" || len(user.Groups) != 2 {
+		t.Errorf("unexpected user %v", user)
+	}
+}
+
+func TestAccessPolicy(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "access.hcl")
+	content := `
+access "auditors" {
+  groups    = ["auditors"]
+  resources = ["aws_compliance.benchmark.cis_*"]
+}
+
+access "admin" {
+  users     = ["admin"]
+  resources = ["*"]
+}
+`
+	if err := os.WriteFile(policyFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := LoadAccessPolicy(policyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auditor := &User{Name: "alice", Groups: []string{"auditors"}}
+	admin := &User{Name: "admin"}
+	other := &User{Name: "bob", Groups: []string{"developers"}}
+	for _, test := range []struct {
+		user     *User
+		resource string
+		allowed  bool
+	}{
+		{auditor, "aws_compliance.benchmark.cis_v150", true},
+		{auditor, "aws_compliance.benchmark.pci_v321", false},
+		{auditor, "aws_insights.dashboard.s3_bucket_detail", false},
+		{admin, "aws_insights.dashboard.s3_bucket_detail", true},
+		{other, "aws_compliance.benchmark.cis_v150", false},
+		{nil, "aws_compliance.benchmark.cis_v150", false},
+	} {
+		if allowed := policy.Allowed(test.user, test.resource); allowed != test.allowed {
+			t.Errorf("%v %s: expected allowed %v", test.user, test.resource, test.allowed)
+		}
+	}
+
+	var noPolicy *AccessPolicy
+	if !noPolicy.Allowed(nil, "aws_compliance.benchmark.cis_v150") {
+		t.Error("expected nil policy to allow all access")
+	}
+}
+
+func TestCheckSameOrigin(t *testing.T) {
+	for _, test := range []struct {
+		name, host, origin, forwardedHost string
+		allowed                           bool
+	}{
+		{name: "no origin", host: "localhost:9194", allowed: true},
+		{name: "same origin", host: "localhost:9194", origin: "http://localhost:9194", allowed: true},
+		{name: "other site", host: "localhost:9194", origin: "https://evil.example.com"},
+		{name: "other port", host: "localhost:9194", origin: "http://localhost:8080"},
+		{name: "forwarded host", host: "steampipe:9194", origin: "https://dashboards.example.com", forwardedHost: "dashboards.example.com", allowed: true},
+		{name: "invalid origin", host: "localhost:9194", origin: "://"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+		r.Host = test.host
+		if test.origin != "" {
+			r.Header.Set("Origin", test.origin)
+		}
+		if test.forwardedHost != "" {
+			r.Header.Set("X-Forwarded-Host", test.forwardedHost)
+		}
+		if allowed := checkSameOrigin(r); allowed != test.allowed {
+			t.Errorf("%s: expected allowed %v, got %v", test.name, test.allowed, allowed)
+		}
+	}
+}
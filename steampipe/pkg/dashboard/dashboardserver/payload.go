@@ -77,13 +77,19 @@ func addBenchmarkChildren(benchmark *modconfig.Benchmark, recordTrunk bool, trun
 	return children
 }
 
-func buildAvailableDashboardsPayload(workspaceResources *modconfig.ResourceMaps) ([]byte, error) {
+// buildAvailableDashboardsPayload builds the payload of the dashboards, benchmarks and snapshots which pass the filter
+func buildAvailableDashboardsPayload(workspaceResources *modconfig.ResourceMaps, filter func(string) bool) ([]byte, error) {
 
 	payload := AvailableDashboardsPayload{
 		Action:     "available_dashboards",
 		Dashboards: make(map[string]ModAvailableDashboard),
 		Benchmarks: make(map[string]ModAvailableBenchmark),
-		Snapshots:  workspaceResources.Snapshots,
+		Snapshots:  make(map[string]string),
+	}
+	for name, snapshotPath := range workspaceResources.Snapshots {
+		if filter(name) {
+			payload.Snapshots[name] = snapshotPath
+		}
 	}
 
 	// if workspace resources has a mod, populate dashboards and benchmarks
@@ -92,6 +98,9 @@ func buildAvailableDashboardsPayload(workspaceResources *modconfig.ResourceMaps)
 
 		// iterate over the dashboards for the top level mod - this will include the dashboards from dependency mods
 		for _, dashboard := range workspaceResources.Mod.ResourceMaps.Dashboards {
+			if !filter(dashboard.FullName) {
+				continue
+			}
 			mod := dashboard.Mod
 			// add this dashboard
 			payload.Dashboards[dashboard.FullName] = ModAvailableDashboard{
@@ -105,7 +114,7 @@ func buildAvailableDashboardsPayload(workspaceResources *modconfig.ResourceMaps)
 
 		benchmarkTrunks := make(map[string][][]string)
 		for _, benchmark := range workspaceResources.Mod.ResourceMaps.Benchmarks {
-			if benchmark.IsAnonymous() {
+			if benchmark.IsAnonymous() || !filter(benchmark.FullName) {
 				continue
 			}
 
@@ -156,6 +165,14 @@ func buildWorkspaceErrorPayload(e *dashboardevents.WorkspaceError) ([]byte, erro
 	return json.Marshal(payload)
 }
 
+func buildAccessDeniedPayload(resourceName string) ([]byte, error) {
+	payload := ExecutionErrorPayload{
+		Action: "execution_error",
+		Error:  fmt.Sprintf("you do not have permission to view %s", resourceName),
+	}
+	return json.Marshal(payload)
+}
+
 func buildControlCompletePayload(event *dashboardevents.ControlComplete) ([]byte, error) {
 	payload := ControlEventPayload{
 		Action:      "control_complete",
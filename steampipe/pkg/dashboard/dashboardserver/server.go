@@ -10,8 +10,10 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/spf13/viper"
 	"github.com/turbot/go-kit/helpers"
 	typeHelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe/pkg/constants"
 	"github.com/turbot/steampipe/pkg/dashboard/dashboardevents"
 	"github.com/turbot/steampipe/pkg/dashboard/dashboardexecute"
 	"github.com/turbot/steampipe/pkg/db/db_common"
@@ -28,14 +30,25 @@ type Server struct {
 	dashboardClients map[string]*DashboardClientInfo
 	webSocket        *melody.Melody
 	workspace        *workspace.Workspace
+	authenticator    Authenticator
+	accessPolicy     *AccessPolicy
 }
 
 func NewServer(ctx context.Context, dbClient db_common.Client, w *workspace.Workspace) (*Server, error) {
 	initLogSink()
 
+	authenticator, accessPolicy, err := newAuth()
+	if err != nil {
+		return nil, err
+	}
+
 	OutputWait(ctx, "Starting Dashboard Server")
 
 	webSocket := melody.New()
+	if authenticator != nil {
+		// prevent cross-site websocket hijacking of authenticated sessions
+		webSocket.Upgrader.CheckOrigin = checkSameOrigin
+	}
 
 	var dashboardClients = make(map[string]*DashboardClientInfo)
 
@@ -48,10 +61,12 @@ func NewServer(ctx context.Context, dbClient db_common.Client, w *workspace.Work
 		dashboardClients: dashboardClients,
 		webSocket:        webSocket,
 		workspace:        w,
+		authenticator:    authenticator,
+		accessPolicy:     accessPolicy,
 	}
 
 	w.RegisterDashboardEventHandler(server.HandleDashboardEvent)
-	err := w.SetupWatcher(ctx, dbClient, func(c context.Context, e error) {})
+	err = w.SetupWatcher(ctx, dbClient, func(c context.Context, e error) {})
 	OutputMessage(ctx, "Workspace loaded")
 
 	return server, err
@@ -61,7 +76,27 @@ func NewServer(ctx context.Context, dbClient db_common.Client, w *workspace.Work
 // it returns a channel which is signalled when the API server terminates
 func (s *Server) Start() chan struct{} {
 	s.initAsync(s.context)
-	return startAPIAsync(s.context, s.webSocket)
+	return startAPIAsync(s.context, s.webSocket, s.authenticator)
+}
+
+// create the authenticator and access policy from the auth args
+func newAuth() (Authenticator, *AccessPolicy, error) {
+	authenticator, err := NewAuthenticator()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var accessPolicy *AccessPolicy
+	if accessPolicyPath := viper.GetString(constants.ArgDashboardAccess); accessPolicyPath != "" {
+		if authenticator == nil {
+			return nil, nil, fmt.Errorf("--%s requires --%s to be set", constants.ArgDashboardAccess, constants.ArgDashboardAuth)
+		}
+		accessPolicy, err = LoadAccessPolicy(accessPolicyPath)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return authenticator, accessPolicy, nil
 }
 
 // Shutdown stops the API server
@@ -210,11 +245,9 @@ func (s *Server) HandleDashboardEvent(event dashboardevents.DashboardEvent) {
 		// If) any deleted/new/changed dashboards, emit an available dashboards message to clients
 		if len(deletedDashboards) != 0 || len(newDashboards) != 0 || len(changedDashboards) != 0 || len(changedBenchmarks) != 0 {
 			OutputMessage(s.context, "Available Dashboards updated")
-			payload, payloadError = buildAvailableDashboardsPayload(s.workspace.GetResourceMaps())
-			if payloadError != nil {
+			if payloadError = s.broadcastAvailableDashboards(); payloadError != nil {
 				return
 			}
-			_ = s.webSocket.Broadcast(payload)
 		}
 
 		var dashboardssBeingWatched []string
@@ -355,16 +388,22 @@ func (s *Server) handleMessageFunc(ctx context.Context) func(session *melody.Ses
 			}
 			_ = session.Write(payload)
 		case "get_available_dashboards":
-			payload, err := buildAvailableDashboardsPayload(s.workspace.GetResourceMaps())
+			payload, err := buildAvailableDashboardsPayload(s.workspace.GetResourceMaps(), s.accessFilter(session))
 			if err != nil {
 				panic(fmt.Errorf("error building payload for get_available_dashboards: %v", err))
 			}
 			_ = session.Write(payload)
 		case "select_dashboard":
+			if !s.checkAccess(session, request.Payload.Dashboard.FullName) {
+				return
+			}
 			s.setDashboardForSession(sessionId, request.Payload.Dashboard.FullName, request.Payload.InputValues)
 			_ = dashboardexecute.Executor.ExecuteDashboard(ctx, sessionId, request.Payload.Dashboard.FullName, request.Payload.InputValues, s.workspace, s.dbClient)
 		case "select_snapshot":
 			snapshotName := request.Payload.Dashboard.FullName
+			if !s.checkAccess(session, snapshotName) {
+				return
+			}
 			s.setDashboardForSession(sessionId, snapshotName, request.Payload.InputValues)
 			snap, err := dashboardexecute.Executor.LoadSnapshot(ctx, sessionId, snapshotName, s.workspace)
 			// TACTICAL- handle with error message
@@ -386,6 +425,49 @@ func (s *Server) handleMessageFunc(ctx context.Context) func(session *melody.Ses
 	}
 }
 
+// send the available dashboards to all sessions - if there is an access policy,
+// each session is sent only the dashboards its user may view
+func (s *Server) broadcastAvailableDashboards() error {
+	if s.accessPolicy == nil {
+		payload, err := buildAvailableDashboardsPayload(s.workspace.GetResourceMaps(), func(string) bool { return true })
+		if err != nil {
+			return err
+		}
+		return s.webSocket.Broadcast(payload)
+	}
+
+	for sessionId, clientInfo := range s.getDashboardClients() {
+		payload, err := buildAvailableDashboardsPayload(s.workspace.GetResourceMaps(), s.accessFilter(clientInfo.Session))
+		if err != nil {
+			return err
+		}
+		s.writePayloadToSession(sessionId, payload)
+	}
+	return nil
+}
+
+// checkAccess returns whether the session user may view the resource - if not, an error is sent to the session
+func (s *Server) checkAccess(session *melody.Session, resourceName string) bool {
+	user := getSessionUser(session)
+	if s.accessPolicy.Allowed(user, resourceName) {
+		return true
+	}
+	log.Printf("[WARN] user '%s' is not permitted to view %s", user.Name, resourceName)
+	payload, err := buildAccessDeniedPayload(resourceName)
+	if err == nil {
+		_ = session.Write(payload)
+	}
+	return false
+}
+
+// accessFilter returns a function which returns whether the session user may view a resource
+func (s *Server) accessFilter(session *melody.Session) func(string) bool {
+	user := getSessionUser(session)
+	return func(resourceName string) bool {
+		return s.accessPolicy.Allowed(user, resourceName)
+	}
+}
+
 func (s *Server) clearSession(ctx context.Context, session *melody.Session) {
 	if strings.ToUpper(os.Getenv("DEBUG")) == "TRUE" {
 		return
@@ -403,6 +485,7 @@ func (s *Server) addSession(session *melody.Session) {
 
 	clientSession := &DashboardClientInfo{
 		Session: session,
+		User:    getSessionUser(session),
 	}
 
 	s.addDashboardClient(sessionId, clientSession)
@@ -420,6 +503,14 @@ func (s *Server) getSessionId(session *melody.Session) string {
 	return fmt.Sprintf("%p", session)
 }
 
+// getSessionUser returns the authenticated user of the session, or nil if authentication is disabled
+func getSessionUser(session *melody.Session) *User {
+	if user, ok := session.Get(userKey); ok {
+		return user.(*User)
+	}
+	return nil
+}
+
 // functions providing locked access to member properties
 
 func (s *Server) setDashboardForSession(sessionId string, dashboardName string, inputs map[string]interface{}) *DashboardClientInfo {
@@ -149,11 +149,22 @@ func RunForService(ctx context.Context, serverListen ListenType, serverPort List
 	for _, varFile := range viper.GetStringSlice(constants.ArgVarFile) {
 		args = append(args, fmt.Sprintf("--%s=%s", constants.ArgVarFile, varFile))
 	}
+
+	// auth args
+	for _, authArg := range []string{constants.ArgDashboardAuth, constants.ArgDashboardAuthUsers, constants.ArgDashboardAuthHeader, constants.ArgDashboardAuthGroups, constants.ArgDashboardAccess} {
+		if value := viper.GetString(authArg); value != "" {
+			args = append(args, fmt.Sprintf("--%s=%s", authArg, value))
+		}
+	}
 	cmd := exec.Command(
 		self,
 		args...,
 	)
 	cmd.Env = os.Environ()
+	// pass the token in the environment, so it is not visible in the process list
+	if token := viper.GetString(constants.ArgDashboardAuthToken); token != "" {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", constants.EnvDashboardAuthToken, token))
+	}
 
 	// set group pgid attributes on the command to ensure the process is not shutdown when its parent terminates
 	cmd.SysProcAttr = &syscall.SysProcAttr{
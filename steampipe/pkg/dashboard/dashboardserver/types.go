@@ -95,7 +95,9 @@ type InputValuesClearedPayload struct {
 }
 
 type DashboardClientInfo struct {
-	Session         *melody.Session
+	Session *melody.Session
+	// the authenticated user - nil if authentication is disabled
+	User            *User
 	Dashboard       *string
 	DashboardInputs map[string]interface{}
 }
@@ -112,9 +112,9 @@ func (c *DbClient) ExecuteInSession(ctx context.Context, session *db_common.Data
 	}
 	startTime := time.Now()
 	// get a context with a timeout for the query to execute within
-	// we don't use the cancelFn from this timeout context, since usage will lead to 'pgx'
-	// prematurely closing the database connection that this query executed in
-	ctxExecute := c.getExecuteContext(ctx)
+	// the cancelFn from this timeout context must only be called once the query is complete and its rows are read,
+	// since calling it earlier will lead to 'pgx' prematurely closing the database connection that this query executed in
+	ctxExecute, cancelExecute := c.getExecuteContext(ctx)
 
 	var tx *sql.Tx
 
@@ -131,6 +131,7 @@ func (c *DbClient) ExecuteInSession(ctx context.Context, session *db_common.Data
 			if onComplete != nil {
 				onComplete()
 			}
+			cancelExecute()
 		}
 	}()
 
@@ -162,23 +163,23 @@ func (c *DbClient) ExecuteInSession(ctx context.Context, session *db_common.Data
 		if onComplete != nil {
 			onComplete()
 		}
+		// the rows are read, so the timeout context can be released
+		cancelExecute()
 	}()
 
 	return result, nil
 }
 
-func (c *DbClient) getExecuteContext(ctx context.Context) context.Context {
+func (c *DbClient) getExecuteContext(ctx context.Context) (context.Context, context.CancelFunc) {
 	queryTimeout := time.Duration(viper.GetInt(constants.ArgDatabaseQueryTimeout)) * time.Second
 	// if timeout is zero, do not set a timeout
 	if queryTimeout == 0 {
-		return ctx
+		return ctx, func() {}
 	}
 	// create a context with a deadline
+	// if the caller has set an earlier deadline (e.g. a control timeout), that deadline still applies
 	shouldBeDoneBy := time.Now().Add(queryTimeout)
-	// we don't use this cancel fn because, pgx prematurely cancels the PG connection when this cancel gets called in 'defer'
-	newCtx, _ := context.WithDeadline(ctx, shouldBeDoneBy)
-
-	return newCtx
+	return context.WithDeadline(ctx, shouldBeDoneBy)
 }
 
 func (c *DbClient) getQueryTiming(ctx context.Context, startTime time.Time, session *db_common.DatabaseSession, resultChannel chan *queryresult.TimingResult) {
@@ -384,7 +385,7 @@ func populateRow(columnValues []interface{}, cols []*queryresult.ColumnDef) ([]i
 func isStreamingOutput() bool {
 	outputFormat := viper.GetString(constants.ArgOutput)
 
-	return helpers.StringSliceContains([]string{constants.OutputFormatCSV, constants.OutputFormatLine}, outputFormat)
+	return helpers.StringSliceContains([]string{constants.OutputFormatCSV, constants.OutputFormatLine, constants.OutputFormatNDJSON, constants.OutputFormatMarkdown, constants.OutputFormatHTML}, outputFormat)
 }
 
 func humanizeRowCount(count int) string {
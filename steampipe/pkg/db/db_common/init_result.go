@@ -41,9 +41,9 @@ func (r *InitResult) DisplayMessages() {
 			error_helpers.ShowWarning(w)
 		}
 	}
-	// do not display message in json or csv output mode
+	// do not display message in machine readable output modes
 	output := viper.Get(constants.ArgOutput)
-	if output == constants.OutputFormatJSON || output == constants.OutputFormatCSV {
+	if output == constants.OutputFormatJSON || output == constants.OutputFormatCSV || output == constants.OutputFormatNDJSON || output == constants.OutputFormatParquet {
 		return
 	}
 	for _, w := range r.Warnings {
@@ -112,7 +112,8 @@ func getTableInsertSql(workspaceResources *modconfig.ResourceMaps) string {
 		}
 	}
 	for _, variable := range workspaceResources.Variables {
-		insertSql = append(insertSql, getTableInsertSqlForResource(variable, constants.IntrospectionTableVariable))
+		// the values of sensitive variables are masked
+		insertSql = append(insertSql, getTableInsertSqlForResource(variable.Masked(), constants.IntrospectionTableVariable))
 	}
 	for _, dashboard := range workspaceResources.Dashboards {
 		insertSql = append(insertSql, getTableInsertSqlForResource(dashboard, constants.IntrospectionTableDashboard))
@@ -0,0 +1,194 @@
+package db_local
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/utils"
+)
+
+// CheckRunRecord is a row of the check run history table
+type CheckRunRecord struct {
+	Target      string
+	ModName     string
+	StartedAt   time.Time
+	CompletedAt time.Time
+	// the status summary of the run
+	Summary any
+	// the compliance score of the run - nil if no controls were scored
+	Score *float64
+	// the compliance score of each benchmark, keyed by benchmark name
+	Scores       map[string]float64
+	ControlCount int
+}
+
+// CheckResultRecord is a row of the check result history table
+type CheckResultRecord struct {
+	// the parent benchmark of the control (empty if a control was run directly)
+	Benchmark string
+	// the ancestor benchmarks of the control, starting with the top level benchmark
+	BenchmarkPath []string
+	Control       string
+	ControlTitle  string
+	Severity      string
+	Status        string
+	Reason        string
+	Resource      string
+	Dimensions    map[string]string
+}
+
+// the statuses counted by the check history trend views
+var checkHistoryStatuses = []string{
+	constants.ControlOk,
+	constants.ControlAlarm,
+	constants.ControlInfo,
+	constants.ControlSkip,
+	constants.ControlError,
+	constants.ControlExempt,
+}
+
+// InsertCheckRun adds a check run and its results to the check history tables using the given connection
+// of the database client, returning the id of the run
+func InsertCheckRun(ctx context.Context, conn *pgx.Conn, run *CheckRunRecord, results []*CheckResultRecord) (int64, error) {
+	utils.LogTime("db.InsertCheckRun start")
+	defer utils.LogTime("db.InsertCheckRun end")
+
+	summary, err := json.Marshal(run.Summary)
+	if err != nil {
+		return 0, err
+	}
+	scores, err := json.Marshal(run.Scores)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// rollback is a no-op if the transaction has been committed
+	defer tx.Rollback(ctx)
+
+	query := fmt.Sprintf(`insert into %s.%s
+	(target, mod_name, started_at, completed_at, summary, score, scores, control_count)
+	values ($1, $2, $3, $4, $5::jsonb, $6, $7::jsonb, $8)
+	returning id`,
+		constants.InternalSchema, constants.InternalTableCheckRuns)
+	var runId int64
+	err = tx.QueryRow(ctx, query,
+		run.Target,
+		run.ModName,
+		run.StartedAt,
+		run.CompletedAt,
+		string(summary),
+		run.Score,
+		string(scores),
+		run.ControlCount).Scan(&runId)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := make([][]any, len(results))
+	for i, result := range results {
+		var dimensions any
+		if len(result.Dimensions) > 0 {
+			dimensionsJSON, err := json.Marshal(result.Dimensions)
+			if err != nil {
+				return 0, err
+			}
+			dimensions = string(dimensionsJSON)
+		}
+		rows[i] = []any{
+			runId,
+			nullIfEmpty(result.Benchmark),
+			result.BenchmarkPath,
+			result.Control,
+			nullIfEmpty(result.ControlTitle),
+			nullIfEmpty(result.Severity),
+			result.Status,
+			nullIfEmpty(result.Reason),
+			nullIfEmpty(result.Resource),
+			dimensions,
+		}
+	}
+	columns := []string{"run_id", "benchmark", "benchmark_path", "control", "control_title", "severity", "status", "reason", "resource", "dimensions"}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{constants.InternalSchema, constants.InternalTableCheckResults}, columns, pgx.CopyFromRows(rows)); err != nil {
+		return 0, err
+	}
+
+	return runId, tx.Commit(ctx)
+}
+
+// DeleteCheckRunsBefore deletes the check runs (and their results) which started before the given time
+// using the given connection of the database client, returning the number of runs deleted
+func DeleteCheckRunsBefore(ctx context.Context, conn *pgx.Conn, before time.Time) (int64, error) {
+	query := fmt.Sprintf(`delete from %s.%s where started_at < $1`, constants.InternalSchema, constants.InternalTableCheckRuns)
+	res, err := conn.Exec(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+// the views which show the trend of check results over time, for each benchmark, control and resource
+func checkHistoryViews() []string {
+	runs := fmt.Sprintf("%s.%s", constants.InternalSchema, constants.InternalTableCheckRuns)
+	results := fmt.Sprintf("%s.%s", constants.InternalSchema, constants.InternalTableCheckResults)
+
+	var counts []string
+	for _, status := range checkHistoryStatuses {
+		counts = append(counts, fmt.Sprintf("count(*) filter (where res.status = '%s') as %s", status, status))
+	}
+	statusCounts := strings.Join(append(counts, "count(*) as total"), ",\n\t")
+
+	return []string{
+		fmt.Sprintf(`create or replace view %s.%s as
+select
+	r.id as run_id,
+	r.started_at,
+	r.target,
+	b.benchmark,
+	(r.scores ->> b.benchmark)::numeric as score,
+	%s
+from %s r
+	join %s res on res.run_id = r.id
+	cross join lateral unnest(res.benchmark_path) as b(benchmark)
+group by r.id, b.benchmark;`, constants.InternalSchema, constants.InternalViewBenchmarkTrend, statusCounts, runs, results),
+		fmt.Sprintf(`create or replace view %s.%s as
+select
+	r.id as run_id,
+	r.started_at,
+	r.target,
+	res.control,
+	res.control_title,
+	res.severity,
+	%s
+from %s r
+	join %s res on res.run_id = r.id
+group by r.id, res.control, res.control_title, res.severity;`, constants.InternalSchema, constants.InternalViewControlTrend, statusCounts, runs, results),
+		fmt.Sprintf(`create or replace view %s.%s as
+select
+	r.id as run_id,
+	r.started_at,
+	r.target,
+	res.resource,
+	res.control,
+	res.status,
+	res.reason
+from %s r
+	join %s res on res.run_id = r.id
+where res.resource is not null;`, constants.InternalSchema, constants.InternalViewResourceTrend, runs, results),
+	}
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
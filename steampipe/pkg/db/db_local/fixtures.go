@@ -0,0 +1,87 @@
+package db_local
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/db/db_common"
+	"github.com/turbot/steampipe/pkg/utils"
+)
+
+// FixtureTable is a table of test data, loaded by 'steampipe mod test'
+type FixtureTable struct {
+	Name    string
+	Columns []FixtureColumn
+	// the row values, in postgres text format - nil values are null
+	Rows [][]*string
+}
+
+type FixtureColumn struct {
+	Name string
+	// the postgres data type
+	Type string
+}
+
+// CreateFixtureSchema (re)creates the given schema containing the fixture tables
+// the tables are readable by all steampipe users
+func CreateFixtureSchema(ctx context.Context, schema string, tables []*FixtureTable) error {
+	utils.LogTime("db.CreateFixtureSchema start")
+	defer utils.LogTime("db.CreateFixtureSchema end")
+
+	rootClient, err := createLocalDbClient(ctx, &CreateDbOptions{Username: constants.DatabaseSuperUser})
+	if err != nil {
+		return err
+	}
+	defer rootClient.Close(ctx)
+
+	escapedSchema := db_common.PgEscapeName(schema)
+	statements := []string{
+		fmt.Sprintf(`drop schema if exists %s cascade;`, escapedSchema),
+		fmt.Sprintf(`create schema %s;`, escapedSchema),
+		fmt.Sprintf(`grant usage on schema %s to %s;`, escapedSchema, constants.DatabaseUsersRole),
+	}
+	for _, statement := range statements {
+		if _, err := rootClient.Exec(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	for _, table := range tables {
+		tableName := fmt.Sprintf("%s.%s", escapedSchema, db_common.PgEscapeName(table.Name))
+		columnDefs := make([]string, len(table.Columns))
+		// pass all values as text and cast them in the database
+		params := make([]string, len(table.Columns))
+		for i, c := range table.Columns {
+			columnDefs[i] = fmt.Sprintf("%s %s", db_common.PgEscapeName(c.Name), c.Type)
+			params[i] = fmt.Sprintf("$%d::text::%s", i+1, c.Type)
+		}
+		if _, err := rootClient.Exec(ctx, fmt.Sprintf(`create table %s (%s);`, tableName, strings.Join(columnDefs, ", "))); err != nil {
+			return fmt.Errorf("failed to create fixture table '%s': %s", table.Name, err.Error())
+		}
+
+		insert := fmt.Sprintf(`insert into %s values (%s);`, tableName, strings.Join(params, ", "))
+		for rowIdx, row := range table.Rows {
+			args := make([]any, len(row))
+			for i, v := range row {
+				// pass nil (rather than a nil *string) for nulls
+				if v != nil {
+					args[i] = *v
+				}
+			}
+			if _, err := rootClient.Exec(ctx, insert, args...); err != nil {
+				return fmt.Errorf("failed to load row %d of fixture table '%s': %s", rowIdx+1, table.Name, err.Error())
+			}
+		}
+	}
+
+	_, err = rootClient.Exec(ctx, fmt.Sprintf(`grant select on all tables in schema %s to %s;`, escapedSchema, constants.DatabaseUsersRole))
+	return err
+}
+
+// DropFixtureSchema drops the given fixture schema, if it exists
+func DropFixtureSchema(ctx context.Context, schema string) error {
+	_, err := executeSqlAsRoot(ctx, fmt.Sprintf(`drop schema if exists %s cascade;`, db_common.PgEscapeName(schema)))
+	return err
+}
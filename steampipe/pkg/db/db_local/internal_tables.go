@@ -0,0 +1,131 @@
+package db_local
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/utils"
+)
+
+// ScheduledRunRecord is a row of the scheduled run history table
+type ScheduledRunRecord struct {
+	Schedule     string
+	Target       string
+	Status       string
+	ErrorMessage string
+	// the control status summary (benchmark and control targets only)
+	Summary     any
+	Exports     []string
+	RowCount    int
+	StartedAt   time.Time
+	CompletedAt time.Time
+}
+
+// EnsureInternalTables creates the steampipe_internal schema and the tables it contains, if they do not exist
+// the tables are readable by all steampipe users
+func EnsureInternalTables(ctx context.Context) error {
+	utils.LogTime("db.EnsureInternalTables start")
+	defer utils.LogTime("db.EnsureInternalTables end")
+
+	queries := []string{
+		fmt.Sprintf(`create schema if not exists %s;`, constants.InternalSchema),
+		fmt.Sprintf(`create table if not exists %s.%s (
+	id bigserial primary key,
+	schedule_name text not null,
+	target text not null,
+	status text not null,
+	error_message text,
+	summary jsonb,
+	exports jsonb,
+	row_count integer,
+	started_at timestamptz not null,
+	completed_at timestamptz not null
+);`, constants.InternalSchema, constants.InternalTableScheduledRun),
+		fmt.Sprintf(`create table if not exists %s.%s (
+	id bigserial primary key,
+	target text not null,
+	mod_name text not null,
+	started_at timestamptz not null,
+	completed_at timestamptz not null,
+	summary jsonb,
+	score numeric,
+	scores jsonb,
+	control_count integer
+);`, constants.InternalSchema, constants.InternalTableCheckRuns),
+		fmt.Sprintf(`create index if not exists %[2]s_started_at_idx on %[1]s.%[2]s (started_at);`, constants.InternalSchema, constants.InternalTableCheckRuns),
+		fmt.Sprintf(`create table if not exists %s.%s (
+	run_id bigint not null references %s.%s (id) on delete cascade,
+	benchmark text,
+	benchmark_path text[],
+	control text not null,
+	control_title text,
+	severity text,
+	status text not null,
+	reason text,
+	resource text,
+	dimensions jsonb
+);`, constants.InternalSchema, constants.InternalTableCheckResults, constants.InternalSchema, constants.InternalTableCheckRuns),
+		fmt.Sprintf(`create index if not exists %[2]s_run_id_idx on %[1]s.%[2]s (run_id);`, constants.InternalSchema, constants.InternalTableCheckResults),
+		fmt.Sprintf(`create index if not exists %[2]s_control_idx on %[1]s.%[2]s (control);`, constants.InternalSchema, constants.InternalTableCheckResults),
+		fmt.Sprintf(`create index if not exists %[2]s_resource_idx on %[1]s.%[2]s (resource);`, constants.InternalSchema, constants.InternalTableCheckResults),
+	}
+	queries = append(queries, checkHistoryViews()...)
+	queries = append(queries,
+		fmt.Sprintf(`grant usage on schema %s to %s;`, constants.InternalSchema, constants.DatabaseUsersRole),
+	)
+	// grant select on each table rather than all tables in the schema - the query audit table is only readable by root
+	for _, table := range []string{
+		constants.InternalTableScheduledRun,
+		constants.InternalTableCheckRuns,
+		constants.InternalTableCheckResults,
+		constants.InternalViewBenchmarkTrend,
+		constants.InternalViewControlTrend,
+		constants.InternalViewResourceTrend,
+	} {
+		queries = append(queries, fmt.Sprintf(`grant select on %s.%s to %s;`, constants.InternalSchema, table, constants.DatabaseUsersRole))
+	}
+	// the check history is written by 'steampipe check' using the connection of its database client
+	queries = append(queries,
+		fmt.Sprintf(`grant insert, delete on %[1]s.%[2]s, %[1]s.%[3]s to %[4]s;`, constants.InternalSchema, constants.InternalTableCheckRuns, constants.InternalTableCheckResults, constants.DatabaseUser),
+		fmt.Sprintf(`grant usage on sequence %s.%s_id_seq to %s;`, constants.InternalSchema, constants.InternalTableCheckRuns, constants.DatabaseUser),
+	)
+	_, err := executeSqlAsRoot(ctx, queries...)
+	return err
+}
+
+// InsertScheduledRun adds a record to the scheduled run history table
+func InsertScheduledRun(ctx context.Context, record *ScheduledRunRecord) error {
+	summary, err := json.Marshal(record.Summary)
+	if err != nil {
+		return err
+	}
+	exports, err := json.Marshal(record.Exports)
+	if err != nil {
+		return err
+	}
+
+	rootClient, err := createLocalDbClient(ctx, &CreateDbOptions{Username: constants.DatabaseSuperUser})
+	if err != nil {
+		return err
+	}
+	defer rootClient.Close(ctx)
+
+	query := fmt.Sprintf(`insert into %s.%s
+	(schedule_name, target, status, error_message, summary, exports, row_count, started_at, completed_at)
+	values ($1, $2, $3, nullif($4, ''), $5::jsonb, $6::jsonb, $7, $8, $9)`,
+		constants.InternalSchema, constants.InternalTableScheduledRun)
+	_, err = rootClient.Exec(ctx, query,
+		record.Schedule,
+		record.Target,
+		record.Status,
+		record.ErrorMessage,
+		string(summary),
+		string(exports),
+		record.RowCount,
+		record.StartedAt,
+		record.CompletedAt)
+	return err
+}
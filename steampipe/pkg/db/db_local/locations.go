@@ -101,6 +101,10 @@ func getFDWBinaryLocation() string {
 	return filepath.Join(getDatabaseLibDirectory(), "postgresql", "steampipe_postgres_fdw.so")
 }
 
+func getAutoExplainLibraryLocation() string {
+	return filepath.Join(getDatabaseLibDirectory(), "postgresql", "auto_explain.so")
+}
+
 func getFDWSQLAndControlLocation() (string, string) {
 	base := filepath.Join(getDatabaseLocation(), "share", "postgresql", "extension")
 	sqlLocation := filepath.Join(base, "steampipe_postgres_fdw--1.0.sql")
@@ -128,6 +132,10 @@ func getSteampipeConfLocation() string {
 	return filepath.Join(getDataLocation(), "steampipe.conf")
 }
 
+func getQueryAuditConfLocation() string {
+	return filepath.Join(getDataLocation(), "query_audit.conf")
+}
+
 func getLegacyPasswordFileLocation() string {
 	return filepath.Join(getDatabaseLocation(), ".passwd")
 }
@@ -23,7 +23,8 @@ func TrimLogs() {
 		}
 
 		fileName := fi.Name()
-		if filepath.Ext(fileName) != ".log" {
+		// the CSV logs are written if the query audit log is enabled
+		if ext := filepath.Ext(fileName); ext != ".log" && ext != ".csv" {
 			continue
 		}
 
@@ -0,0 +1,89 @@
+package db_local
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/viper"
+	"github.com/turbot/go-kit/files"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/db/db_common"
+	"github.com/turbot/steampipe/pkg/error_helpers"
+	"github.com/turbot/steampipe/pkg/filepaths"
+)
+
+// the query audit log is written when the service is started with '--query-audit'
+func queryAuditEnabled() bool {
+	return viper.GetBool(constants.ArgQueryAudit)
+}
+
+// write the postgres config which logs every statement if the query audit log is enabled, otherwise remove it
+func writeQueryAuditConf() error {
+	if !queryAuditEnabled() {
+		err := os.Remove(getQueryAuditConfLocation())
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	content := constants.QueryAuditConfContent
+	// the search path and row count of statements are only logged by the auto_explain module - this analyzes every
+	// statement, which slows queries, so it is only loaded if '--query-audit-plans' is set
+	// (and if it is included in the database installation, as connections fail if it is missing)
+	if viper.GetBool(constants.ArgQueryAuditPlans) {
+		if files.FileExists(getAutoExplainLibraryLocation()) {
+			content += constants.QueryAuditPlanConfContent
+		} else {
+			error_helpers.ShowWarning(fmt.Sprintf("'--%s' is ignored as the database installation does not include the auto_explain module", constants.ArgQueryAuditPlans))
+		}
+	}
+	return os.WriteFile(getQueryAuditConfLocation(), []byte(content), 0600)
+}
+
+// ensure the query audit foreign table exists - this reads the audit log files using file_fdw - and the view which shows
+// its entries as typed columns
+// the audit log contains the statements of all users, so these are only readable by root - no privileges are granted
+// to other users (they are created again each time the service starts, so none granted previously remain)
+func ensureQueryAuditTable(ctx context.Context, rootClient *pgx.Conn) error {
+	// escape the audit directory for the shell command which reads the audit log files
+	auditDir := strings.ReplaceAll(filepaths.EnsureQueryAuditDir(), `'`, `'\''`)
+	// the file_fdw program is run by the shell - ignore the error if there are no audit log files yet
+	program := fmt.Sprintf(`cat '%s'/query-audit-*.jsonl 2>/dev/null || true`, auditDir)
+
+	queries := []string{
+		`create extension if not exists file_fdw`,
+		fmt.Sprintf(`create server if not exists %s foreign data wrapper file_fdw`, constants.QueryAuditServer),
+		fmt.Sprintf(`create schema if not exists %s`, constants.InternalSchema),
+		fmt.Sprintf(`drop view if exists %s.%s`, constants.InternalSchema, constants.InternalViewQueryAuditLog),
+		fmt.Sprintf(`drop foreign table if exists %s.%s`, constants.InternalSchema, constants.InternalTableQueryAudit),
+		// each line of an audit log file is read as a single jsonb value - the delimiter and quote characters
+		// are control characters which cannot appear in the JSON
+		fmt.Sprintf(`create foreign table %s.%s (entry jsonb) server %s options (program %s, format 'csv', delimiter e'\x01', quote e'\x02')`,
+			constants.InternalSchema, constants.InternalTableQueryAudit, constants.QueryAuditServer, db_common.PgEscapeString(program)),
+		fmt.Sprintf(`create view %s.%s as
+select
+  (entry->>'time')::timestamptz as time,
+  entry->>'client_address' as client_address,
+  entry->>'user' as "user",
+  entry->>'database' as database,
+  entry->>'application' as application,
+  entry->>'session_id' as session_id,
+  entry->>'command' as command,
+  entry->>'statement' as statement,
+  (entry->>'duration_ms')::float8 as duration_ms,
+  entry->>'error' as error,
+  entry->>'sql_state' as sql_state,
+  entry->>'search_path' as search_path,
+  (entry->>'rows')::bigint as rows
+from %s.%s`, constants.InternalSchema, constants.InternalViewQueryAuditLog, constants.InternalSchema, constants.InternalTableQueryAudit),
+	}
+	for _, query := range queries {
+		if _, err := rootClient.Exec(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package db_local
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/filepaths"
+)
+
+func TestWriteQueryAuditConf(t *testing.T) {
+	steampipeDir := filepaths.SteampipeDir
+	t.Cleanup(func() {
+		filepaths.SteampipeDir = steampipeDir
+		viper.Set(constants.ArgQueryAudit, nil)
+		viper.Set(constants.ArgQueryAuditPlans, nil)
+	})
+	filepaths.SteampipeDir = t.TempDir()
+
+	testCases := map[string]struct {
+		plans         bool
+		autoExplain   bool
+		expectPlanLog bool
+	}{
+		"plans not enabled":      {plans: false, autoExplain: true, expectPlanLog: false},
+		"plans enabled":          {plans: true, autoExplain: true, expectPlanLog: true},
+		"auto_explain not found": {plans: true, autoExplain: false, expectPlanLog: false},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			libLocation := getAutoExplainLibraryLocation()
+			os.Remove(libLocation)
+			if tc.autoExplain {
+				if err := os.MkdirAll(filepath.Dir(libLocation), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(libLocation, nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			viper.Set(constants.ArgQueryAudit, true)
+			viper.Set(constants.ArgQueryAuditPlans, tc.plans)
+
+			if err := writeQueryAuditConf(); err != nil {
+				t.Fatal(err)
+			}
+			content, err := os.ReadFile(getQueryAuditConfLocation())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(content), "log_min_duration_statement=0") {
+				t.Errorf("expected the statement log configuration, got:\n%s", content)
+			}
+			if got := strings.Contains(string(content), "auto_explain"); got != tc.expectPlanLog {
+				t.Errorf("expected auto_explain configuration %v, got:\n%s", tc.expectPlanLog, content)
+			}
+		})
+	}
+
+	// the configuration is removed when the query audit log is not enabled
+	viper.Set(constants.ArgQueryAudit, false)
+	if err := writeQueryAuditConf(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(getQueryAuditConfLocation()); !os.IsNotExist(err) {
+		t.Errorf("expected the query audit configuration to be removed")
+	}
+}
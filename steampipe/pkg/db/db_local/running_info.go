@@ -26,6 +26,11 @@ type RunningDBInstanceInfo struct {
 	User          string            `json:"user"`
 	Database      string            `json:"database"`
 	StructVersion int64             `json:"struct_version"`
+
+	// is the query audit log enabled, the maximum age of the audit log files, and are statement plans logged
+	QueryAudit          bool   `json:"query_audit,omitempty"`
+	QueryAuditRetention string `json:"query_audit_retention,omitempty"`
+	QueryAuditPlans     bool   `json:"query_audit_plans,omitempty"`
 }
 
 func newRunningDBInstanceInfo(cmd *exec.Cmd, port int, databaseName string, password string, listen StartListenType, invoker constants.Invoker) *RunningDBInstanceInfo {
@@ -183,6 +183,12 @@ func startDB(ctx context.Context, port int, listen StartListenType, invoker cons
 	// create a RunningInfo with empty database name
 	// we need this to connect to the service using 'root', required retrieve the name of the installed database
 	res.DbState = newRunningDBInstanceInfo(postgresCmd, port, "", password, listen, invoker)
+	// the plugin manager writes the query audit log, using the settings in the running info
+	if queryAuditEnabled() {
+		res.DbState.QueryAudit = true
+		res.DbState.QueryAuditRetention = viper.GetString(constants.ArgQueryAuditRetention)
+		res.DbState.QueryAuditPlans = viper.GetBool(constants.ArgQueryAuditPlans)
+	}
 	err = res.DbState.Save()
 	if err != nil {
 		return res.SetError(err)
@@ -251,6 +257,14 @@ func ensureService(ctx context.Context, databaseName string) error {
 	if err != nil {
 		return err
 	}
+
+	// if the query audit log is enabled, ensure the table and view which read it exist
+	// the audit log is still written if this fails, so just warn
+	if queryAuditEnabled() {
+		if err := ensureQueryAuditTable(ctx, rootClient); err != nil {
+			error_helpers.ShowWarning(fmt.Sprintf("failed to create the %s.%s table: %s", constants.InternalSchema, constants.InternalTableQueryAudit, err.Error()))
+		}
+	}
 	return nil
 }
 
@@ -337,6 +351,12 @@ func writePGConf(ctx context.Context) error {
 		return err
 	}
 
+	// write (or remove) the query audit settings
+	err = writeQueryAuditConf()
+	if err != nil {
+		return err
+	}
+
 	// create the postgresql.conf.d location, don't fail if it errors
 	err = os.MkdirAll(getPostgresqlConfDLocation(), 0700)
 	if err != nil {
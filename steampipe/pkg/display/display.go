@@ -3,8 +3,6 @@ package display
 import (
 	"bytes"
 	"context"
-	"encoding/csv"
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -70,6 +68,10 @@ func ShowOutput(ctx context.Context, result *queryresult.Result, opts ...Display
 		displayLine(ctx, result)
 	case constants.OutputFormatTable:
 		displayTable(ctx, result)
+	case constants.OutputFormatNDJSON, constants.OutputFormatMarkdown, constants.OutputFormatHTML, constants.OutputFormatParquet:
+		if err := WriteResult(ctx, cmdconfig.Viper().GetString(constants.ArgOutput), os.Stdout, result); err != nil {
+			error_helpers.ShowError(ctx, err)
+		}
 	}
 
 	if options.timing {
@@ -240,57 +242,14 @@ func getTerminalColumnsRequiredForString(str string) int {
 }
 
 func displayJSON(ctx context.Context, result *queryresult.Result) {
-	var jsonOutput []map[string]interface{}
-
-	// define function to add each row to the JSON output
-	rowFunc := func(row []interface{}, result *queryresult.Result) {
-		record := map[string]interface{}{}
-		for idx, col := range result.Cols {
-			value, _ := ParseJSONOutputColumnValue(row[idx], col)
-			record[col.Name] = value
-		}
-		jsonOutput = append(jsonOutput, record)
-	}
-
-	// call this function for each row
-	if err := iterateResults(result, rowFunc); err != nil {
+	if err := writeJSON(os.Stdout, result); err != nil {
 		error_helpers.ShowError(ctx, err)
-		return
-	}
-	// display the JSON
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", " ")
-	encoder.SetEscapeHTML(false)
-	if err := encoder.Encode(jsonOutput); err != nil {
-		fmt.Print("Error displaying result as JSON", err)
-		return
 	}
 }
 
 func displayCSV(ctx context.Context, result *queryresult.Result) {
-	csvWriter := csv.NewWriter(os.Stdout)
-	csvWriter.Comma = []rune(cmdconfig.Viper().GetString(constants.ArgSeparator))[0]
-
-	if cmdconfig.Viper().GetBool(constants.ArgHeader) {
-		_ = csvWriter.Write(ColumnNames(result.Cols))
-	}
-
-	// print the data as it comes
-	// define function display each csv row
-	rowFunc := func(row []interface{}, result *queryresult.Result) {
-		rowAsString, _ := ColumnValuesAsString(row, result.Cols)
-		_ = csvWriter.Write(rowAsString)
-	}
-
-	// call this function for each row
-	if err := iterateResults(result, rowFunc); err != nil {
+	if err := writeCSV(os.Stdout, result); err != nil {
 		error_helpers.ShowError(ctx, err)
-		return
-	}
-
-	csvWriter.Flush()
-	if csvWriter.Error() != nil {
-		error_helpers.ShowErrorWithMessage(ctx, csvWriter.Error(), "unable to print csv")
 	}
 }
 
@@ -0,0 +1,350 @@
+package display
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/cmdconfig"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/query/queryresult"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/marshal"
+	"github.com/xitongsys/parquet-go/parquet"
+	parquetwriter "github.com/xitongsys/parquet-go/writer"
+)
+
+// ResultWriterFormats is the list of output formats which may be written to an arbitrary writer,
+// and so may be used for both output and export
+var ResultWriterFormats = []string{
+	constants.OutputFormatCSV,
+	constants.OutputFormatJSON,
+	constants.OutputFormatNDJSON,
+	constants.OutputFormatMarkdown,
+	constants.OutputFormatHTML,
+	constants.OutputFormatParquet,
+}
+
+// WriteResult writes the result to w using the given format
+// All formats other than json and parquet are written as rows are received
+func WriteResult(_ context.Context, format string, w io.Writer, result *queryresult.Result) error {
+	switch format {
+	case constants.OutputFormatCSV:
+		return writeCSV(w, result)
+	case constants.OutputFormatJSON:
+		return writeJSON(w, result)
+	case constants.OutputFormatNDJSON:
+		return writeNDJSON(w, result)
+	case constants.OutputFormatMarkdown:
+		return writeMarkdown(w, result)
+	case constants.OutputFormatHTML:
+		return writeHTML(w, result)
+	case constants.OutputFormatParquet:
+		return writeParquet(w, result)
+	}
+	return fmt.Errorf("unsupported result format '%s'", format)
+}
+
+// iterate the results, stopping at the first error returned by the row function
+func iterateResultsWithError(result *queryresult.Result, rowFunc func(row []interface{}) error) error {
+	var rowErr error
+	err := iterateResults(result, func(row []interface{}, _ *queryresult.Result) {
+		if rowErr == nil {
+			rowErr = rowFunc(row)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return rowErr
+}
+
+func writeJSON(w io.Writer, result *queryresult.Result) error {
+	var jsonOutput []map[string]interface{}
+
+	// add each row to the JSON output
+	err := iterateResultsWithError(result, func(row []interface{}) error {
+		record := map[string]interface{}{}
+		for idx, col := range result.Cols {
+			value, _ := ParseJSONOutputColumnValue(row[idx], col)
+			record[col.Name] = value
+		}
+		jsonOutput = append(jsonOutput, record)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", " ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(jsonOutput); err != nil {
+		return fmt.Errorf("error displaying result as JSON: %s", err.Error())
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, result *queryresult.Result) error {
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Comma = []rune(cmdconfig.Viper().GetString(constants.ArgSeparator))[0]
+
+	if cmdconfig.Viper().GetBool(constants.ArgHeader) {
+		_ = csvWriter.Write(ColumnNames(result.Cols))
+	}
+
+	// write each row as it comes
+	err := iterateResultsWithError(result, func(row []interface{}) error {
+		rowAsString, _ := ColumnValuesAsString(row, result.Cols)
+		return csvWriter.Write(rowAsString)
+	})
+	if err != nil {
+		return err
+	}
+
+	csvWriter.Flush()
+	if csvWriter.Error() != nil {
+		return fmt.Errorf("unable to print csv: %s", csvWriter.Error().Error())
+	}
+	return nil
+}
+
+func writeNDJSON(w io.Writer, result *queryresult.Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	return iterateResultsWithError(result, func(row []interface{}) error {
+		record := map[string]interface{}{}
+		for idx, col := range result.Cols {
+			value, _ := ParseJSONOutputColumnValue(row[idx], col)
+			record[col.Name] = value
+		}
+		return encoder.Encode(record)
+	})
+}
+
+func writeMarkdown(w io.Writer, result *queryresult.Result) error {
+	names := ColumnNames(result.Cols)
+	separators := make([]string, len(names))
+	for i, name := range names {
+		names[i] = escapeMarkdownCell(name)
+		separators[i] = "---"
+	}
+	if _, err := fmt.Fprintf(w, "| %s |\n| %s |\n", strings.Join(names, " | "), strings.Join(separators, " | ")); err != nil {
+		return err
+	}
+
+	return iterateResultsWithError(result, func(row []interface{}) error {
+		rowAsString, _ := ColumnValuesAsString(row, result.Cols)
+		for i, val := range rowAsString {
+			rowAsString[i] = escapeMarkdownCell(val)
+		}
+		_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(rowAsString, " | "))
+		return err
+	})
+}
+
+func escapeMarkdownCell(val string) string {
+	val = strings.ReplaceAll(val, "|", `\|`)
+	val = strings.ReplaceAll(val, "\r\n", "<br>")
+	return strings.ReplaceAll(val, "\n", "<br>")
+}
+
+func writeHTML(w io.Writer, result *queryresult.Result) error {
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Steampipe query results</title>\n</head>\n<body>\n<table>\n<thead>\n<tr>")
+	for _, name := range ColumnNames(result.Cols) {
+		sb.WriteString(fmt.Sprintf("<th>%s</th>", html.EscapeString(name)))
+	}
+	sb.WriteString("</tr>\n</thead>\n<tbody>\n")
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return err
+	}
+
+	err := iterateResultsWithError(result, func(row []interface{}) error {
+		var sb strings.Builder
+		rowAsString, _ := ColumnValuesAsString(row, result.Cols)
+		sb.WriteString("<tr>")
+		for _, val := range rowAsString {
+			sb.WriteString(fmt.Sprintf("<td>%s</td>", html.EscapeString(val)))
+		}
+		sb.WriteString("</tr>\n")
+		_, err := io.WriteString(w, sb.String())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "</tbody>\n</table>\n</body>\n</html>\n")
+	return err
+}
+
+func writeParquet(w io.Writer, result *queryresult.Result) error {
+	schema := parquetSchema(result.Cols)
+	writer, err := parquetwriter.NewParquetWriterFromWriter(w, schema, 1)
+	if err != nil {
+		return err
+	}
+	// rows are written as a slice of values in column order, rather than as structs
+	writer.MarshalFunc = marshal.MarshalCSV
+
+	err = iterateResultsWithError(result, func(row []interface{}) error {
+		values := make([]interface{}, len(row))
+		for i, val := range row {
+			// the first schema element is the root
+			v, err := parquetValue(val, result.Cols[i], schema[i+1])
+			if err != nil {
+				return err
+			}
+			values[i] = v
+		}
+		return writer.Write(values)
+	})
+	if err != nil {
+		return err
+	}
+	return writer.WriteStop()
+}
+
+// build the parquet schema for the columns - a root element followed by an optional element for each column
+// column names which are not unique within parquet (which is case insensitive for the first character,
+// and replaces characters other than letters, digits and underscores) are given a numeric suffix
+func parquetSchema(cols []*queryresult.ColumnDef) []*parquet.SchemaElement {
+	root := parquet.NewSchemaElement()
+	root.Name = "schema"
+	numChildren := int32(len(cols))
+	root.NumChildren = &numChildren
+	root.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED)
+
+	res := []*parquet.SchemaElement{root}
+	names := map[string]bool{}
+	for _, col := range cols {
+		element := parquetColumn(col)
+		name := element.Name
+		for i := 2; names[common.StringToVariableName(name)]; i++ {
+			name = fmt.Sprintf("%s_%d", element.Name, i)
+		}
+		names[common.StringToVariableName(name)] = true
+		element.Name = name
+		res = append(res, element)
+	}
+	return res
+}
+
+// map the postgres column type to a parquet schema element
+func parquetColumn(col *queryresult.ColumnDef) *parquet.SchemaElement {
+	res := parquet.NewSchemaElement()
+	res.Name = col.Name
+	res.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL)
+
+	var convertedType *parquet.ConvertedType
+	switch col.DataType {
+	case "BOOL":
+		res.Type = parquet.TypePtr(parquet.Type_BOOLEAN)
+	case "INT2", "INT4":
+		res.Type = parquet.TypePtr(parquet.Type_INT32)
+	case "INT8":
+		res.Type = parquet.TypePtr(parquet.Type_INT64)
+	case "FLOAT4", "FLOAT8", "NUMERIC":
+		res.Type = parquet.TypePtr(parquet.Type_DOUBLE)
+	case "TIMESTAMP", "TIMESTAMPTZ":
+		res.Type = parquet.TypePtr(parquet.Type_INT64)
+		convertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MICROS)
+	case "DATE":
+		res.Type = parquet.TypePtr(parquet.Type_INT32)
+		convertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_DATE)
+	case "JSON", "JSONB":
+		res.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+		convertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_JSON)
+	default:
+		res.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+		convertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)
+	}
+	res.ConvertedType = convertedType
+	return res
+}
+
+// convert the column value into the go type required by the parquet schema element
+func parquetValue(val interface{}, col *queryresult.ColumnDef, element *parquet.SchemaElement) (interface{}, error) {
+	if val == nil {
+		return nil, nil
+	}
+	invalid := fmt.Errorf("cannot convert value %v (%T) of column '%s' to parquet %s", val, val, col.Name, element.GetType())
+
+	if element.IsSetConvertedType() {
+		switch element.GetConvertedType() {
+		case parquet.ConvertedType_TIMESTAMP_MICROS:
+			t, ok := val.(time.Time)
+			if !ok {
+				return nil, invalid
+			}
+			return t.UnixMicro(), nil
+		case parquet.ConvertedType_DATE:
+			t, ok := val.(time.Time)
+			if !ok {
+				return nil, invalid
+			}
+			return int32(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400), nil
+		case parquet.ConvertedType_JSON:
+			b, err := json.Marshal(val)
+			if err != nil {
+				return nil, err
+			}
+			return string(b), nil
+		case parquet.ConvertedType_UTF8:
+			return ColumnValueAsString(val, col)
+		}
+	}
+
+	switch element.GetType() {
+	case parquet.Type_BOOLEAN:
+		b, ok := val.(bool)
+		if !ok {
+			return nil, invalid
+		}
+		return b, nil
+	case parquet.Type_INT32:
+		i, ok := toInt64(val)
+		if !ok {
+			return nil, invalid
+		}
+		return int32(i), nil
+	case parquet.Type_INT64:
+		i, ok := toInt64(val)
+		if !ok {
+			return nil, invalid
+		}
+		return i, nil
+	case parquet.Type_DOUBLE:
+		switch f := val.(type) {
+		case float64:
+			return f, nil
+		case float32:
+			return float64(f), nil
+		}
+		if i, ok := toInt64(val); ok {
+			return float64(i), nil
+		}
+	}
+	return nil, invalid
+}
+
+func toInt64(val interface{}) (int64, bool) {
+	switch i := val.(type) {
+	case int:
+		return int64(i), true
+	case int16:
+		return int64(i), true
+	case int32:
+		return int64(i), true
+	case int64:
+		return i, true
+	}
+	return 0, false
+}
@@ -0,0 +1,173 @@
+package display
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/query/queryresult"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+func testResult() *queryresult.Result {
+	cols := []*queryresult.ColumnDef{
+		{Name: "name", DataType: "TEXT"},
+		{Name: "count", DataType: "INT8"},
+		{Name: "tags", DataType: "JSONB"},
+	}
+	rows := [][]interface{}{
+		{"a|b", int64(1), map[string]interface{}{"k": "v"}},
+		{"multi\nline", nil, nil},
+	}
+	return queryresult.NewResultFromRows(cols, rows)
+}
+
+func TestWriteResult(t *testing.T) {
+	testCases := map[string]string{
+		constants.OutputFormatNDJSON: `{"count":1,"name":"a|b","tags":{"k":"v"}}
+{"count":null,"name":"multi\nline","tags":null}
+`,
+		constants.OutputFormatMarkdown: `| name | count | tags |
+| --- | --- | --- |
+| a\|b | 1 | {"k":"v"} |
+| multi<br>line | <null> | <null> |
+`,
+	}
+	for format, expected := range testCases {
+		var buf bytes.Buffer
+		if err := WriteResult(context.Background(), format, &buf, testResult()); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		if buf.String() != expected {
+			t.Errorf("%s: expected\n%s\ngot\n%s", format, expected, buf.String())
+		}
+	}
+}
+
+func TestWriteResultHTMLEscapes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResult(context.Background(), constants.OutputFormatHTML, &buf, testResult()); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("<tr><td>multi\nline</td><td>&lt;null&gt;</td><td>&lt;null&gt;</td></tr>")) {
+		t.Errorf("unexpected html output:\n%s", buf.String())
+	}
+}
+
+func TestParquetValue(t *testing.T) {
+	ts := time.Date(2022, 12, 2, 10, 30, 0, 0, time.UTC)
+	testCases := []struct {
+		dataType string
+		value    interface{}
+		expected any
+	}{
+		{"INT2", int16(3), int32(3)},
+		{"INT8", int64(3), int64(3)},
+		{"FLOAT8", 1.5, 1.5},
+		{"NUMERIC", 2.5, 2.5},
+		{"BOOL", true, true},
+		{"TIMESTAMPTZ", ts, ts.UnixMicro()},
+		{"DATE", ts, int32(19328)},
+		{"TEXT", "foo", "foo"},
+	}
+	for _, tc := range testCases {
+		col := &queryresult.ColumnDef{Name: "c", DataType: tc.dataType}
+		res, err := parquetValue(tc.value, col, parquetColumn(col))
+		if err != nil {
+			t.Errorf("%s: %s", tc.dataType, err)
+			continue
+		}
+		if res != tc.expected {
+			t.Errorf("%s: expected %v (%T), got %v (%T)", tc.dataType, tc.expected, tc.expected, res, res)
+		}
+	}
+
+	col := &queryresult.ColumnDef{Name: "c", DataType: "JSONB"}
+	if parquetColumn(col).GetConvertedType() != parquet.ConvertedType_JSON {
+		t.Errorf("expected JSONB to map to a JSON column")
+	}
+	if _, err := parquetValue("not a time", &queryresult.ColumnDef{Name: "c", DataType: "TIMESTAMP"}, parquetColumn(&queryresult.ColumnDef{DataType: "TIMESTAMP"})); err == nil {
+		t.Errorf("expected error converting string to timestamp")
+	}
+}
+
+func TestWriteResultParquet(t *testing.T) {
+	cols := []*queryresult.ColumnDef{
+		{Name: "name", DataType: "TEXT"},
+		{Name: "count", DataType: "INT8"},
+		{Name: "tags", DataType: "JSONB"},
+		// postgres allows duplicate column names
+		{Name: "Count", DataType: "BOOL"},
+	}
+	rows := [][]interface{}{
+		{"a|b", int64(1), map[string]interface{}{"k": "v"}, true},
+		{"multi\nline", nil, nil, false},
+	}
+	var buf bytes.Buffer
+	if err := WriteResult(context.Background(), constants.OutputFormatParquet, &buf, queryresult.NewResultFromRows(cols, rows)); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := buffer.NewBufferFile(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := reader.NewParquetColumnReader(file, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.ReadStop()
+	if r.GetNumRows() != 2 {
+		t.Fatalf("expected 2 rows, got %d", r.GetNumRows())
+	}
+
+	// the reader renames the schema elements, so check the original names
+	var names []string
+	for _, info := range r.SchemaHandler.Infos[1:] {
+		names = append(names, info.ExName)
+	}
+	if expected := []string{"name", "count", "tags", "Count_2"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected columns %v, got %v", expected, names)
+	}
+
+	expected := [][]interface{}{
+		{"a|b", "multi\nline"},
+		{int64(1), nil},
+		{`{"k":"v"}`, nil},
+		{true, false},
+	}
+	for i := range cols {
+		values, _, _, err := r.ReadColumnByIndex(int64(i), 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(values, expected[i]) {
+			t.Errorf("column %d: expected %v, got %v", i, expected[i], values)
+		}
+	}
+}
+
+func TestWriteResultCapturedError(t *testing.T) {
+	source := queryresult.NewResult(testResult().Cols)
+	go func() {
+		source.StreamRow([]interface{}{"a", int64(1), nil})
+		source.StreamError(errors.New("canceling statement due to statement timeout"))
+		source.Close()
+	}()
+	result, capture := queryresult.CaptureResult(source)
+	for range *result.RowChan {
+	}
+
+	// the captured error must be returned when the captured result is written, e.g. exported
+	var buf bytes.Buffer
+	err := WriteResult(context.Background(), constants.OutputFormatNDJSON, &buf, capture.NewResult())
+	if err == nil || err.Error() != "canceling statement due to statement timeout" {
+		t.Errorf("expected the captured error, got %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/error_helpers"
+	"github.com/turbot/steampipe/pkg/export"
+)
+
+func ShowSnapshotListJson(snapshots []*export.StoredSnapshot) {
+	// ensure an empty list is output as [] rather than null
+	if snapshots == nil {
+		snapshots = []*export.StoredSnapshot{}
+	}
+	jsonOutput, err := json.MarshalIndent(snapshots, "", "  ")
+	error_helpers.FailOnErrorWithMessage(err, "failed to marshal snapshots to JSON")
+
+	fmt.Println(string(jsonOutput))
+}
+
+func ShowSnapshotListTable(snapshots []*export.StoredSnapshot) {
+	headers := []string{"name", "timestamp", "size", "location"}
+	var rows = make([][]string, len(snapshots))
+	for i, s := range snapshots {
+		rows[i] = []string{s.Name, s.Timestamp.Format(time.RFC3339), fmt.Sprintf("%d", s.Size), s.Location}
+	}
+	ShowWrappedTable(headers, rows, &ShowWrappedTableOptions{AutoMerge: false})
+}
@@ -8,17 +8,24 @@ import (
 	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
 )
 
+// ShowVarsListJson displays the variables as JSON - the values of sensitive variables are masked
 func ShowVarsListJson(vars []*modconfig.Variable) {
-	jsonOutput, err := json.MarshalIndent(vars, "", "  ")
+	masked := make([]*modconfig.Variable, len(vars))
+	for i, v := range vars {
+		masked[i] = v.Masked()
+	}
+	jsonOutput, err := json.MarshalIndent(masked, "", "  ")
 	error_helpers.FailOnErrorWithMessage(err, "failed to marshal variables to JSON")
 
 	fmt.Println(string(jsonOutput))
 }
 
+// ShowVarsListTable displays the variables as a table - the values of sensitive variables are masked
 func ShowVarsListTable(vars []*modconfig.Variable) {
 	headers := []string{"mod_name", "name", "description", "value", "value_default", "type"}
 	var rows = make([][]string, len(vars))
 	for i, v := range vars {
+		v = v.Masked()
 		rows[i] = []string{v.ModName, v.ShortName, v.Description, fmt.Sprintf("%v", v.ValueGo), fmt.Sprintf("%v", v.DefaultGo), v.TypeString}
 	}
 	ShowWrappedTable(headers, rows, &ShowWrappedTableOptions{AutoMerge: false})
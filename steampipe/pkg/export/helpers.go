@@ -8,9 +8,7 @@ import (
 )
 
 func GenerateDefaultExportFileName(executionName, fileExtension string) string {
-	now := time.Now()
-	timeFormatted := fmt.Sprintf("%d%02d%02dT%02d%02d%02d", now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second())
-	return fmt.Sprintf("%s.%s%s", executionName, timeFormatted, fileExtension)
+	return fmt.Sprintf("%s.%s%s", executionName, time.Now().Format(snapshotTimestampFormat), fileExtension)
 }
 
 func Write(filePath string, exportData io.Reader) error {
@@ -0,0 +1,47 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/dashboard/dashboardassets"
+	"github.com/turbot/steampipe/pkg/dashboard/dashboardtypes"
+)
+
+// SnapshotHTMLExporter exports a snapshot as a self-contained HTML page, which renders the snapshot
+// using the dashboard UI without requiring a dashboard server or network access
+type SnapshotHTMLExporter struct {
+	// an optional alias for the format - used to make this the 'html' format for commands which have no other html format
+	FormatAlias string
+}
+
+func (e *SnapshotHTMLExporter) Export(ctx context.Context, input ExportSourceData, filePath string) error {
+	snapshot, ok := input.(*dashboardtypes.SteampipeSnapshot)
+	if !ok {
+		return fmt.Errorf("SnapshotHTMLExporter input must be *dashboardtypes.SteampipeSnapshot")
+	}
+	snapshotBytes, err := snapshot.AsStrippedJson(false)
+	if err != nil {
+		return err
+	}
+	html, err := dashboardassets.SnapshotHTML(ctx, snapshotBytes)
+	if err != nil {
+		return err
+	}
+
+	return Write(filePath, bytes.NewReader(html))
+}
+
+func (e *SnapshotHTMLExporter) FileExtension() string {
+	return ".html"
+}
+
+func (e *SnapshotHTMLExporter) Name() string {
+	return constants.OutputFormatSnapshotHTML
+}
+
+func (e *SnapshotHTMLExporter) Alias() string {
+	return e.FormatAlias
+}
@@ -0,0 +1,151 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/dashboard/dashboardtypes"
+)
+
+// the format of the timestamp in snapshot file names
+const snapshotTimestampFormat = "20060102T150405"
+
+var snapshotFileNameRegex = regexp.MustCompile(`^(.+)\.(\d{8}T\d{6})` + regexp.QuoteMeta(constants.SnapshotExtension) + `$`)
+
+// StoredSnapshot is a snapshot saved in a SnapshotStore
+type StoredSnapshot struct {
+	// the file name root of the snapshot, i.e. the name of the dashboard, benchmark or query
+	Name      string    `json:"name"`
+	Timestamp time.Time `json:"timestamp"`
+	// the file path or url of the snapshot
+	Location string `json:"location"`
+	Size     int64  `json:"size"`
+}
+
+// SnapshotStore is a location snapshots are saved to, e.g. a local directory or an S3 bucket
+//
+// snapshots are stored using a timestamped layout:
+//
+//	<root>/<yyyy>/<mm>/<dd>/<name>.<yyyymmddThhmmss>.sps
+type SnapshotStore interface {
+	// Save saves the snapshot and returns its location
+	Save(ctx context.Context, snapshot *dashboardtypes.SteampipeSnapshot) (string, error)
+	// List returns the snapshots in the store, most recent first
+	List(ctx context.Context) ([]*StoredSnapshot, error)
+	Delete(ctx context.Context, snapshot *StoredSnapshot) error
+}
+
+type SnapshotStoreOptions struct {
+	// the endpoint of an S3 compatible service, e.g. http://localhost:9000 - if not set, AWS S3 is used
+	S3Endpoint string
+}
+
+// NewSnapshotStore returns the store for the given location - either an 's3://bucket/prefix' url or a local directory
+func NewSnapshotStore(location string, opts SnapshotStoreOptions) (SnapshotStore, error) {
+	if IsS3Location(location) {
+		return newS3SnapshotStore(location, opts)
+	}
+	return newDirSnapshotStore(location), nil
+}
+
+// the path of a snapshot relative to the root of the store - the timestamp is in UTC
+func snapshotPath(name string, timestamp time.Time) string {
+	timestamp = timestamp.UTC()
+	fileName := fmt.Sprintf("%s.%s%s", name, timestamp.Format(snapshotTimestampFormat), constants.SnapshotExtension)
+	return path.Join(timestamp.Format("2006"), timestamp.Format("01"), timestamp.Format("02"), fileName)
+}
+
+// parse the name and timestamp from a snapshot file name, in the given location
+// returns false if this is not a snapshot file
+func parseSnapshotFileName(fileName string, loc *time.Location) (string, time.Time, bool) {
+	match := snapshotFileNameRegex.FindStringSubmatch(fileName)
+	if match == nil {
+		return "", time.Time{}, false
+	}
+	timestamp, err := time.ParseInLocation(snapshotTimestampFormat, match[2], loc)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return match[1], timestamp, true
+}
+
+// parse the name and timestamp from the path of a snapshot relative to the root of the store
+// returns false if this is not a snapshot saved in the store, i.e. it is not in the directory for its date
+// or directly in the root directory (where snapshots were saved, using the local time, before the timestamped layout)
+func parseSnapshotPath(relPath string) (string, time.Time, bool) {
+	dir, fileName := path.Split(relPath)
+	if dir == "" {
+		return parseSnapshotFileName(fileName, time.Local)
+	}
+	name, timestamp, ok := parseSnapshotFileName(fileName, time.UTC)
+	if !ok || relPath != snapshotPath(name, timestamp) {
+		return "", time.Time{}, false
+	}
+	return name, timestamp, true
+}
+
+// the depth of the directories of the timestamped layout below the root of the store
+const snapshotDirDepth = 3
+
+// sort snapshots most recent first
+func sortStoredSnapshots(snapshots []*StoredSnapshot) {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if !snapshots[i].Timestamp.Equal(snapshots[j].Timestamp) {
+			return snapshots[i].Timestamp.After(snapshots[j].Timestamp)
+		}
+		return snapshots[i].Location > snapshots[j].Location
+	})
+}
+
+// RetentionPolicy determines which snapshots are kept in a store
+// the policy is applied separately to the snapshots of each dashboard, benchmark or query
+type RetentionPolicy struct {
+	// the number of snapshots to keep - zero keeps all
+	KeepCount int
+	// the maximum age of snapshots - zero keeps all
+	MaxAge time.Duration
+}
+
+func (p RetentionPolicy) Empty() bool {
+	return p.KeepCount == 0 && p.MaxAge == 0
+}
+
+// ApplyRetention deletes the snapshots in the store which are not retained by the policy and returns them
+func ApplyRetention(ctx context.Context, store SnapshotStore, policy RetentionPolicy) ([]*StoredSnapshot, error) {
+	if policy.Empty() {
+		return nil, nil
+	}
+	snapshots, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expired := expiredSnapshots(snapshots, policy, time.Now())
+	for _, snapshot := range expired {
+		if err := store.Delete(ctx, snapshot); err != nil {
+			return nil, err
+		}
+	}
+	return expired, nil
+}
+
+// expiredSnapshots returns the snapshots which are not retained by the policy
+// snapshots must be sorted most recent first
+func expiredSnapshots(snapshots []*StoredSnapshot, policy RetentionPolicy, now time.Time) []*StoredSnapshot {
+	var res []*StoredSnapshot
+	keptCount := make(map[string]int)
+	for _, snapshot := range snapshots {
+		tooMany := policy.KeepCount > 0 && keptCount[snapshot.Name] >= policy.KeepCount
+		tooOld := policy.MaxAge > 0 && now.Sub(snapshot.Timestamp) > policy.MaxAge
+		if tooMany || tooOld {
+			res = append(res, snapshot)
+			continue
+		}
+		keptCount[snapshot.Name]++
+	}
+	return res
+}
@@ -0,0 +1,95 @@
+package export
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/dashboard/dashboardtypes"
+)
+
+// dirSnapshotStore stores snapshots in a local directory
+type dirSnapshotStore struct {
+	root string
+}
+
+func newDirSnapshotStore(root string) *dirSnapshotStore {
+	return &dirSnapshotStore{root: root}
+}
+
+func (s *dirSnapshotStore) Save(ctx context.Context, snapshot *dashboardtypes.SteampipeSnapshot) (string, error) {
+	filePath := filepath.Join(s.root, filepath.FromSlash(snapshotPath(snapshot.FileNameRoot, time.Now())))
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return "", err
+	}
+	exporter := &SnapshotExporter{}
+	if err := exporter.Export(ctx, snapshot, filePath); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
+
+// List returns the snapshots saved in the timestamped layout under the root directory - this includes snapshots
+// saved directly in the root directory, as they were before the timestamped layout was introduced
+// snapshots saved anywhere else below the root directory are not included
+func (s *dirSnapshotStore) List(ctx context.Context) ([]*StoredSnapshot, error) {
+	var res []*StoredSnapshot
+	err := filepath.WalkDir(s.root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		relPath, err := filepath.Rel(s.root, filePath)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if d.IsDir() {
+			// do not descend below the directories of the timestamped layout
+			if relPath != "." && strings.Count(relPath, "/") >= snapshotDirDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name, timestamp, ok := parseSnapshotPath(relPath)
+		if !ok {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		res = append(res, &StoredSnapshot{
+			Name:      name,
+			Timestamp: timestamp,
+			Location:  filePath,
+			Size:      info.Size(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortStoredSnapshots(res)
+	return res, nil
+}
+
+// Delete removes the snapshot file, and any of its parent directories which are left empty
+func (s *dirSnapshotStore) Delete(_ context.Context, snapshot *StoredSnapshot) error {
+	if err := os.Remove(snapshot.Location); err != nil {
+		return err
+	}
+	root := filepath.Clean(s.root)
+	for dir := filepath.Dir(snapshot.Location); dir != root && len(dir) > len(root); dir = filepath.Dir(dir) {
+		// Remove fails if the directory is not empty
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
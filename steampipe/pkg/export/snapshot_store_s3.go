@@ -0,0 +1,135 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/turbot/steampipe/pkg/dashboard/dashboardtypes"
+)
+
+const s3Scheme = "s3://"
+
+// the region used if none is configured - S3 compatible services generally ignore the region
+const defaultS3Region = "us-east-1"
+
+// IsS3Location returns whether the snapshot location is an S3 url
+func IsS3Location(location string) bool {
+	return strings.HasPrefix(location, s3Scheme)
+}
+
+// s3SnapshotStore stores snapshots in an S3 bucket (or any S3 compatible service, e.g. MinIO)
+// credentials and region are resolved using the standard AWS environment variables and config files
+type s3SnapshotStore struct {
+	client *s3.S3
+	bucket string
+	prefix string
+}
+
+func newS3SnapshotStore(location string, opts SnapshotStoreOptions) (*s3SnapshotStore, error) {
+	bucket, prefix, err := parseS3Location(location)
+	if err != nil {
+		return nil, err
+	}
+
+	config := aws.Config{}
+	if opts.S3Endpoint != "" {
+		config.Endpoint = aws.String(opts.S3Endpoint)
+		// S3 compatible services generally do not support virtual hosted buckets
+		config.S3ForcePathStyle = aws.Bool(true)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            config,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 session: %s", err.Error())
+	}
+	if aws.StringValue(sess.Config.Region) == "" {
+		sess.Config.Region = aws.String(defaultS3Region)
+	}
+
+	return &s3SnapshotStore{
+		client: s3.New(sess),
+		bucket: bucket,
+		prefix: prefix,
+	}, nil
+}
+
+// parseS3Location parses an 's3://bucket/prefix' url into the bucket and prefix (which may be empty)
+func parseS3Location(location string) (string, string, error) {
+	u, err := url.Parse(location)
+	if err != nil || u.Host == "" {
+		return "", "", fmt.Errorf("invalid S3 snapshot location '%s' - expected s3://<bucket>[/<prefix>]", location)
+	}
+	return u.Host, strings.Trim(u.Path, "/"), nil
+}
+
+func (s *s3SnapshotStore) Save(ctx context.Context, snapshot *dashboardtypes.SteampipeSnapshot) (string, error) {
+	snapshotBytes, err := snapshot.AsStrippedJson(false)
+	if err != nil {
+		return "", err
+	}
+	key := path.Join(s.prefix, snapshotPath(snapshot.FileNameRoot, time.Now()))
+	_, err = s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(append(snapshotBytes, '\n')),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return "", err
+	}
+	return s.url(key), nil
+}
+
+func (s *s3SnapshotStore) List(ctx context.Context) ([]*StoredSnapshot, error) {
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(s.bucket)}
+	if s.prefix != "" {
+		input.Prefix = aws.String(s.prefix + "/")
+	}
+
+	var res []*StoredSnapshot
+	err := s.client.ListObjectsV2PagesWithContext(ctx, input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			key := aws.StringValue(object.Key)
+			// only include the snapshots saved by the store - not those saved elsewhere below the prefix
+			name, timestamp, ok := parseSnapshotPath(strings.TrimPrefix(key, aws.StringValue(input.Prefix)))
+			if !ok {
+				continue
+			}
+			res = append(res, &StoredSnapshot{
+				Name:      name,
+				Timestamp: timestamp,
+				Location:  s.url(key),
+				Size:      aws.Int64Value(object.Size),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortStoredSnapshots(res)
+	return res, nil
+}
+
+func (s *s3SnapshotStore) Delete(ctx context.Context, snapshot *StoredSnapshot) error {
+	key := strings.TrimPrefix(snapshot.Location, s.url(""))
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func (s *s3SnapshotStore) url(key string) string {
+	return fmt.Sprintf("%s%s/%s", s3Scheme, s.bucket, key)
+}
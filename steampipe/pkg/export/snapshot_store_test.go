@@ -0,0 +1,157 @@
+package export
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/dashboard/dashboardtypes"
+)
+
+func TestParseSnapshotPath(t *testing.T) {
+	// snapshot paths use UTC, whatever the location of the timestamp
+	timestamp := time.Date(2022, 11, 3, 14, 5, 6, 0, time.FixedZone("UTC+10", 10*60*60))
+	relPath := snapshotPath("aws_compliance.benchmark.cis_v150", timestamp)
+	if relPath != "2022/11/03/aws_compliance.benchmark.cis_v150.20221103T040506.sps" {
+		t.Errorf("unexpected snapshot path %s", relPath)
+	}
+	name, parsedTimestamp, ok := parseSnapshotPath(relPath)
+	if !ok || name != "aws_compliance.benchmark.cis_v150" || !parsedTimestamp.Equal(timestamp) {
+		t.Errorf("failed to parse snapshot path: got %s %v %v", name, parsedTimestamp, ok)
+	}
+
+	// snapshots saved in the root directory before the timestamped layout use the local time
+	name, parsedTimestamp, ok = parseSnapshotPath("dashboard.foo.20221103T140506.sps")
+	if !ok || name != "dashboard.foo" || !parsedTimestamp.Equal(time.Date(2022, 11, 3, 14, 5, 6, 0, time.Local)) {
+		t.Errorf("failed to parse legacy snapshot path: got %s %v %v", name, parsedTimestamp, ok)
+	}
+
+	for _, relPath := range []string{
+		"dashboard.foo.sps",
+		"dashboard.foo.20221103T140506.json",
+		"notes.txt",
+		// not in the directory for its date
+		"2022/11/04/dashboard.foo.20221103T140506.sps",
+		"archive/dashboard.foo.20221103T140506.sps",
+		"2022/11/03/archive/dashboard.foo.20221103T140506.sps",
+	} {
+		if _, _, ok := parseSnapshotPath(relPath); ok {
+			t.Errorf("%s should not be parsed as a snapshot path", relPath)
+		}
+	}
+}
+
+func TestExpiredSnapshots(t *testing.T) {
+	now := time.Date(2022, 11, 30, 12, 0, 0, 0, time.UTC)
+	snapshot := func(name string, age time.Duration) *StoredSnapshot {
+		return &StoredSnapshot{Name: name, Timestamp: now.Add(-age), Location: name + age.String()}
+	}
+	a1, a2, a3 := snapshot("a", time.Hour), snapshot("a", 24*time.Hour), snapshot("a", 72*time.Hour)
+	b1, b2 := snapshot("b", 2*time.Hour), snapshot("b", 96*time.Hour)
+	snapshots := []*StoredSnapshot{a1, b1, a2, a3, b2}
+	sortStoredSnapshots(snapshots)
+
+	testCases := map[string]struct {
+		policy   RetentionPolicy
+		expected []*StoredSnapshot
+	}{
+		"empty":         {RetentionPolicy{}, nil},
+		"keep count":    {RetentionPolicy{KeepCount: 1}, []*StoredSnapshot{a2, a3, b2}},
+		"max age":       {RetentionPolicy{MaxAge: 48 * time.Hour}, []*StoredSnapshot{a3, b2}},
+		"count and age": {RetentionPolicy{KeepCount: 2, MaxAge: 80 * time.Hour}, []*StoredSnapshot{a3, b2}},
+	}
+	for name, tc := range testCases {
+		got := expiredSnapshots(snapshots, tc.policy, now)
+		if len(got) != len(tc.expected) {
+			t.Errorf("%s: expected %d expired snapshots, got %d", name, len(tc.expected), len(got))
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.expected[i] {
+				t.Errorf("%s: expected %s to be expired, got %s", name, tc.expected[i].Location, got[i].Location)
+			}
+		}
+	}
+}
+
+func TestParseS3Location(t *testing.T) {
+	testCases := map[string][2]string{
+		"s3://bucket":                {"bucket", ""},
+		"s3://bucket/":               {"bucket", ""},
+		"s3://bucket/prefix":         {"bucket", "prefix"},
+		"s3://bucket/nested/prefix/": {"bucket", "nested/prefix"},
+	}
+	for location, expected := range testCases {
+		bucket, prefix, err := parseS3Location(location)
+		if err != nil || bucket != expected[0] || prefix != expected[1] {
+			t.Errorf("parsing '%s': expected %v, got %s %s (%v)", location, expected, bucket, prefix, err)
+		}
+	}
+	if _, _, err := parseS3Location("s3:///prefix"); err == nil {
+		t.Errorf("expected error parsing location with no bucket")
+	}
+}
+
+func TestDirSnapshotStore(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := newDirSnapshotStore(root)
+
+	location, err := store.Save(ctx, &dashboardtypes.SteampipeSnapshot{FileNameRoot: "dashboard.foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// add older snapshots, including one saved before the timestamped layout was introduced
+	old := time.Now().Add(-72 * time.Hour)
+	olderPath := filepath.Join(root, filepath.FromSlash(snapshotPath("dashboard.foo", old)))
+	legacyPath := filepath.Join(root, "dashboard.foo.20200101T000000.sps")
+	for _, p := range []string{olderPath, legacyPath} {
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// files which are not snapshots, or snapshots which were not saved by the store, are ignored
+	for _, p := range []string{
+		filepath.Join(root, "notes.txt"),
+		filepath.Join(root, "archive", "dashboard.foo.20200101T000000.sps"),
+		filepath.Join(root, "2020", "01", "01", "archive", "dashboard.foo.20200101T000000.sps"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshots, err := store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 3 || snapshots[0].Location != location || snapshots[1].Location != olderPath || snapshots[2].Location != legacyPath {
+		t.Fatalf("unexpected snapshots listed: %v", snapshots)
+	}
+
+	deleted, err := ApplyRetention(ctx, store, RetentionPolicy{MaxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 2 {
+		t.Fatalf("expected 2 snapshots to be deleted, got %d", len(deleted))
+	}
+	if _, err := os.Stat(location); err != nil {
+		t.Errorf("retained snapshot was deleted: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "archive", "dashboard.foo.20200101T000000.sps")); err != nil {
+		t.Errorf("snapshot which was not saved by the store was deleted: %s", err)
+	}
+	// the empty directories of the deleted snapshot should be removed
+	if _, err := os.Stat(filepath.Dir(olderPath)); !os.IsNotExist(err) {
+		t.Errorf("empty snapshot directory was not removed")
+	}
+}
@@ -19,9 +19,12 @@ const (
 	databaseRunningInfoFileName  = "steampipe.json"
 	pluginManagerStateFileName   = "plugin_manager.json"
 	dashboardServerStateFileName = "dashboard_service.json"
+	schedulerStateFileName       = "scheduler_service.json"
 	stateFileName                = "update_check.json"
 	legacyStateFileName          = "update-check.json"
 	notificationsFileName        = "notifications.json"
+	secretsKeyFileName           = "secrets.key"
+	queryAuditStateFileName      = "query_audit.json"
 )
 
 var SteampipeDir string
@@ -147,6 +150,22 @@ func ReportAssetsVersionFilePath() string {
 	return filepath.Join(EnsureDashboardAssetsDir(), versionFileName)
 }
 
+// SecretsKeyFilePath returns the path of the key file used to encrypt and decrypt variable values
+func SecretsKeyFilePath() string {
+	return filepath.Join(EnsureInternalDir(), secretsKeyFileName)
+}
+
+// EnsureQueryAuditDir returns the directory containing the query audit log files, creating it if needed
+func EnsureQueryAuditDir() string {
+	return ensureSteampipeSubDir(filepath.Join("logs", "audit"))
+}
+
+// QueryAuditStateFilePath returns the path of the file recording how much of each database CSV log
+// has been written to the query audit log
+func QueryAuditStateFilePath() string {
+	return filepath.Join(EnsureInternalDir(), queryAuditStateFileName)
+}
+
 func RunningInfoFilePath() string {
 	return filepath.Join(EnsureInternalDir(), databaseRunningInfoFileName)
 }
@@ -159,6 +178,10 @@ func DashboardServiceStateFilePath() string {
 	return filepath.Join(EnsureInternalDir(), dashboardServerStateFileName)
 }
 
+func SchedulerServiceStateFilePath() string {
+	return filepath.Join(EnsureInternalDir(), schedulerStateFileName)
+}
+
 func StateFileName() string {
 	return stateFileName
 }
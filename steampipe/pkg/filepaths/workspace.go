@@ -9,6 +9,7 @@ import (
 const (
 	WorkspaceDataDir        = ".steampipe"
 	WorkspaceModDir         = "mods"
+	WorkspaceVendorDir      = "vendor"
 	WorkspaceConfigFileName = "workspace.spc"
 	WorkspaceIgnoreFile     = ".steampipeignore"
 	ModFileName             = "mod.sp"
@@ -20,6 +21,12 @@ func WorkspaceModPath(workspacePath string) string {
 	return path.Join(workspacePath, WorkspaceDataDir, WorkspaceModDir)
 }
 
+// WorkspaceVendorPath returns the folder mod dependencies are vendored to - mods are installed from here
+// in preference to Git, allowing 'mod install' to run with no network access
+func WorkspaceVendorPath(workspacePath string) string {
+	return path.Join(workspacePath, WorkspaceDataDir, WorkspaceVendorDir)
+}
+
 func WorkspaceLockPath(workspacePath string) string {
 	return path.Join(workspacePath, WorkspaceLockFileName)
 }
@@ -30,6 +30,10 @@ type InitData struct {
 
 	ShutdownTelemetry func()
 	ExportManager     *export.Manager
+
+	// if set, do not check the plugins required by the mod are installed
+	// (used by 'mod test', which runs against fixture data rather than plugin tables)
+	SkipRequiredPluginsCheck bool
 }
 
 func NewErrorInitData(err error) *InitData {
@@ -98,10 +102,12 @@ func (i *InitData) Init(ctx context.Context, invoker constants.Invoker) (res *In
 	i.Workspace.CloudMetadata = cloudMetadata
 
 	// check if the required plugins are installed
-	err = i.Workspace.CheckRequiredPluginsInstalled()
-	if err != nil {
-		i.Result.Error = err
-		return
+	if !i.SkipRequiredPluginsCheck {
+		err = i.Workspace.CheckRequiredPluginsInstalled()
+		if err != nil {
+			i.Result.Error = err
+			return
+		}
 	}
 
 	//validate steampipe version
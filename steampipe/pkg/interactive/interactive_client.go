@@ -29,6 +29,7 @@ import (
 	"github.com/turbot/steampipe/pkg/query/queryresult"
 	"github.com/turbot/steampipe/pkg/schema"
 	"github.com/turbot/steampipe/pkg/statushooks"
+	"github.com/turbot/steampipe/pkg/steampipeconfig"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
 	"github.com/turbot/steampipe/pkg/utils"
 	"github.com/turbot/steampipe/pkg/version"
@@ -65,6 +66,35 @@ type InteractiveClient struct {
 	highlighter    *Highlighter
 	hidePrompt     bool
 	suggestions    []prompt.Suggest
+	// the last query executed, and a capture of its result - used by the .export, .edit and .explain metaqueries
+	lastQuery  *modconfig.ResolvedQuery
+	lastResult *queryresult.ResultCapture
+	// the history entry for the query being executed - may be nil
+	currentHistoryEntry *queryhistory.HistoryEntry
+	// the state of the active Ctrl-R history search - nil if there is no search in progress
+	historySearch *historySearch
+}
+
+// historySearch is the state of a reverse incremental search of the query history
+type historySearch struct {
+	// the text being searched for
+	text string
+	// the index of the current match in the history
+	index int
+	// the text shown in the prompt - the query of the current match
+	match string
+	// the prompt text when the search started - restored if the search is aborted
+	original string
+	// was there no match for the text
+	failed bool
+}
+
+// prefix returns the prompt prefix to show while the search is in progress
+func (s *historySearch) prefix() string {
+	if s.failed {
+		return fmt.Sprintf("(failed reverse-i-search)`%s': ", s.text)
+	}
+	return fmt.Sprintf("(reverse-i-search)`%s': ", s.text)
 }
 
 func getHighlighter(theme string) *Highlighter {
@@ -271,6 +301,9 @@ func (c *InteractiveClient) runInteractivePrompt(ctx context.Context) (ret utils
 			if c.hidePrompt {
 				prefix = ""
 			}
+			if c.historySearch != nil {
+				prefix = c.historySearch.prefix()
+			}
 			return
 		}),
 		prompt.OptionFormatter(c.highlighter.Highlight),
@@ -281,7 +314,29 @@ func (c *InteractiveClient) runInteractivePrompt(ctx context.Context) (ret utils
 		// Known Key Bindings
 		prompt.OptionAddKeyBind(prompt.KeyBind{
 			Key: prompt.ControlC,
-			Fn:  func(b *prompt.Buffer) { c.breakMultilinePrompt(b) },
+			Fn: func(b *prompt.Buffer) {
+				c.historySearch = nil
+				c.breakMultilinePrompt(b)
+			},
+		}),
+		// Ctrl-R starts a reverse incremental search of the history - or moves on to the next older match
+		prompt.OptionAddKeyBind(prompt.KeyBind{
+			Key: prompt.ControlR,
+			Fn:  func(b *prompt.Buffer) { c.reverseSearchHistory(b) },
+		}),
+		// Ctrl-G aborts the search, restoring the text from before the search
+		prompt.OptionAddKeyBind(prompt.KeyBind{
+			Key: prompt.ControlG,
+			Fn:  func(b *prompt.Buffer) { c.abortHistorySearch(b) },
+		}),
+		// typed text is added to the search text, and the match updated
+		prompt.OptionAddKeyBind(prompt.KeyBind{
+			Key: prompt.NotDefined,
+			Fn:  func(b *prompt.Buffer) { c.updateHistorySearch(b) },
+		}),
+		prompt.OptionAddKeyBind(prompt.KeyBind{
+			Key: prompt.Backspace,
+			Fn:  func(b *prompt.Buffer) { c.deleteHistorySearchChar(b) },
 		}),
 		prompt.OptionAddKeyBind(prompt.KeyBind{
 			Key: prompt.ControlD,
@@ -308,8 +363,16 @@ func (c *InteractiveClient) runInteractivePrompt(ctx context.Context) (ret utils
 				if len(b.Text()) == 0 {
 					c.autocompleteOnEmpty = false
 				}
+				c.endHistorySearch(b)
 			},
 		}),
+		// moving the cursor ends the search, leaving the match in the prompt to be edited
+		prompt.OptionAddKeyBind(
+			prompt.KeyBind{Key: prompt.Left, Fn: c.endHistorySearch},
+			prompt.KeyBind{Key: prompt.Right, Fn: c.endHistorySearch},
+			prompt.KeyBind{Key: prompt.Home, Fn: c.endHistorySearch},
+			prompt.KeyBind{Key: prompt.End, Fn: c.endHistorySearch},
+		),
 		prompt.OptionAddKeyBind(prompt.KeyBind{
 			Key: prompt.ShiftLeft,
 			Fn:  prompt.GoLeftChar,
@@ -377,6 +440,102 @@ func (c *InteractiveClient) breakMultilinePrompt(buffer *prompt.Buffer) {
 	c.interactiveBuffer = []string{}
 }
 
+// reverseSearchHistory starts a reverse incremental search of the history
+// if a search is in progress, it moves on to the next older match of the search text
+func (c *InteractiveClient) reverseSearchHistory(b *prompt.Buffer) {
+	if c.historySearch == nil {
+		c.historySearch = &historySearch{
+			index:    len(c.interactiveQueryHistory.Entries()),
+			match:    b.Text(),
+			original: b.Text(),
+		}
+		// typed text is read from the end of the prompt, so move the cursor there
+		c.setPromptText(b, b.Text())
+		return
+	}
+	if c.historySearch.text == "" {
+		return
+	}
+	c.searchHistory(b, c.historySearch.index)
+}
+
+// updateHistorySearch moves text typed into the prompt to the search text, and updates the match
+// the current match is kept if it still matches
+func (c *InteractiveClient) updateHistorySearch(b *prompt.Buffer) {
+	if c.historySearch == nil {
+		return
+	}
+	// the cursor is at the end of the match, so the typed text follows it
+	// if the prompt text does not start with the match, it has been changed outside the search (e.g. by history navigation)
+	if !strings.HasPrefix(b.Text(), c.historySearch.match) || b.Document().TextAfterCursor() != "" {
+		c.historySearch = nil
+		return
+	}
+	c.historySearch.text += strings.TrimPrefix(b.Text(), c.historySearch.match)
+	c.searchHistory(b, c.historySearch.index+1)
+}
+
+// deleteHistorySearchChar removes the last character of the search text and searches again from the most recent entry
+func (c *InteractiveClient) deleteHistorySearchChar(b *prompt.Buffer) {
+	if c.historySearch == nil {
+		return
+	}
+	// backspace has deleted the last character of the match
+	match := []rune(c.historySearch.match)
+	if len(match) > 0 {
+		match = match[:len(match)-1]
+	}
+	if b.Text() != string(match) {
+		c.historySearch = nil
+		return
+	}
+	text := []rune(c.historySearch.text)
+	if len(text) > 0 {
+		c.historySearch.text = string(text[:len(text)-1])
+	}
+	if c.historySearch.text == "" {
+		c.historySearch.failed = false
+		c.setPromptText(b, c.historySearch.match)
+		return
+	}
+	c.searchHistory(b, len(c.interactiveQueryHistory.Entries()))
+}
+
+// searchHistory shows the most recent history entry before index 'before' which contains the search text
+// if there is no such entry, the search is marked as failed and the current match is left in the prompt
+func (c *InteractiveClient) searchHistory(b *prompt.Buffer, before int) {
+	entry, idx := c.interactiveQueryHistory.Search(c.historySearch.text, before)
+	c.historySearch.failed = entry == nil
+	if entry != nil {
+		c.historySearch.index = idx
+		c.historySearch.match = entry.Query
+	}
+	c.setPromptText(b, c.historySearch.match)
+}
+
+// endHistorySearch ends the search, leaving the match in the prompt
+func (c *InteractiveClient) endHistorySearch(*prompt.Buffer) {
+	c.historySearch = nil
+}
+
+// abortHistorySearch ends the search, restoring the prompt text from before the search
+func (c *InteractiveClient) abortHistorySearch(b *prompt.Buffer) {
+	if c.historySearch == nil {
+		return
+	}
+	original := c.historySearch.original
+	c.setPromptText(b, original)
+	c.historySearch = nil
+}
+
+// setPromptText replaces the prompt text, leaving the cursor at the end
+func (c *InteractiveClient) setPromptText(b *prompt.Buffer, text string) {
+	d := b.Document()
+	b.DeleteBeforeCursor(len([]rune(d.TextBeforeCursor())))
+	b.Delete(len([]rune(d.TextAfterCursor())))
+	b.InsertText(text, false, true)
+}
+
 func (c *InteractiveClient) executor(ctx context.Context, line string) {
 	// take an execution lock, so that errors and warnings don't show up while
 	// we are underway
@@ -386,6 +545,9 @@ func (c *InteractiveClient) executor(ctx context.Context, line string) {
 	// set afterClose to restart - is we are exiting the metaquery will set this to AfterPromptCloseExit
 	c.afterClose = AfterPromptCloseRestart
 
+	// any history search is complete
+	c.historySearch = nil
+
 	line = strings.TrimSpace(line)
 
 	resolvedQuery := c.getQuery(ctx, line)
@@ -401,8 +563,11 @@ func (c *InteractiveClient) executor(ctx context.Context, line string) {
 	// create a  context for the execution of the query
 	queryCtx := c.createQueryContext(ctx)
 
+	t := time.Now()
+	var rowCount *int
+	var err error
 	if metaquery.IsMetaQuery(resolvedQuery.ExecuteSQL) {
-		if err := c.executeMetaquery(queryCtx, resolvedQuery.ExecuteSQL); err != nil {
+		if err = c.executeMetaquery(queryCtx, resolvedQuery.ExecuteSQL); err != nil {
 			error_helpers.ShowError(ctx, err)
 		}
 		// cancel the context
@@ -410,23 +575,64 @@ func (c *InteractiveClient) executor(ctx context.Context, line string) {
 
 	} else {
 		// otherwise execute query
-		t := time.Now()
-		result, err := c.client().Execute(queryCtx, resolvedQuery.ExecuteSQL, resolvedQuery.Args...)
-		if err != nil {
-			error_helpers.ShowError(ctx, error_helpers.HandleCancelError(err))
-			// if timing flag is enabled, show the time taken for the query to fail
-			if cmdconfig.Viper().GetBool(constants.ArgTiming) {
-				display.DisplayErrorTiming(t)
-			}
-		} else {
-			c.resultsStreamer.StreamResult(result)
-		}
+		rowCount, err = c.executeQuery(queryCtx, resolvedQuery)
+	}
+
+	// record the outcome in the history
+	if c.currentHistoryEntry != nil {
+		c.currentHistoryEntry.SetResult(time.Since(t), rowCount, err)
 	}
 
 	// restart the prompt
 	c.restartInteractiveSession()
 }
 
+// executeQuery executes the query and streams the result to be displayed
+// the query and its result are stored for use by metaqueries
+// it returns the number of rows returned and the error, if any (the error will already have been displayed)
+func (c *InteractiveClient) executeQuery(ctx context.Context, resolvedQuery *modconfig.ResolvedQuery) (*int, error) {
+	c.lastQuery = resolvedQuery
+	c.lastResult = nil
+
+	t := time.Now()
+	result, err := c.client().Execute(ctx, resolvedQuery.ExecuteSQL, resolvedQuery.Args...)
+	if err != nil {
+		err = error_helpers.HandleCancelError(err)
+		error_helpers.ShowError(ctx, err)
+		// if timing flag is enabled, show the time taken for the query to fail
+		if cmdconfig.Viper().GetBool(constants.ArgTiming) {
+			display.DisplayErrorTiming(t)
+		}
+		return nil, err
+	}
+	// capture the rows as they are displayed
+	result, c.lastResult = queryresult.CaptureResult(result)
+	c.resultsStreamer.StreamResult(result)
+
+	// drain any rows the display did not read, so the capture is complete
+	for range *result.RowChan {
+	}
+	if c.lastResult.Error != nil {
+		return nil, c.lastResult.Error
+	}
+	rowCount := len(c.lastResult.Rows)
+	return &rowCount, nil
+}
+
+// runQuery runs a query string, or metaquery, as if it had been entered at the prompt
+func (c *InteractiveClient) runQuery(ctx context.Context, queryString string) error {
+	if metaquery.IsMetaQuery(queryString) {
+		return c.executeMetaquery(ctx, queryString)
+	}
+	resolvedQuery, _, err := c.workspace().ResolveQueryAndArgsFromSQLString(queryString)
+	if err != nil {
+		return err
+	}
+	// the error (if any) will already have been displayed, so just return nil
+	c.executeQuery(ctx, resolvedQuery)
+	return nil
+}
+
 func (c *InteractiveClient) getQuery(ctx context.Context, line string) *modconfig.ResolvedQuery {
 	// if it's an empty line, then we don't need to do anything
 	if line == "" {
@@ -435,10 +641,14 @@ func (c *InteractiveClient) getQuery(ctx context.Context, line string) *modconfi
 
 	// store the history (the raw line which was entered)
 	historyEntry := line
+	c.currentHistoryEntry = nil
 	defer func() {
 		if len(historyEntry) > 0 {
 			// we want to store even if we fail to resolve a query
-			c.interactiveQueryHistory.Push(historyEntry)
+			c.currentHistoryEntry = c.interactiveQueryHistory.Push(historyEntry)
+			if c.currentHistoryEntry != nil && steampipeconfig.GlobalWorkspaceProfile != nil {
+				c.currentHistoryEntry.Workspace = steampipeconfig.GlobalWorkspaceProfile.ProfileName
+			}
 		}
 
 	}()
@@ -467,6 +677,11 @@ func (c *InteractiveClient) getQuery(ctx context.Context, line string) *modconfi
 		}
 	}
 
+	// .edit operates on the current buffer, so run it without adding it to the buffer
+	if metaquery.IsMetaQuery(line) && strings.Fields(line)[0] == constants.CmdEdit {
+		return &modconfig.ResolvedQuery{ExecuteSQL: line, RawSQL: line}
+	}
+
 	// push the current line into the buffer
 	c.interactiveBuffer = append(c.interactiveBuffer, line)
 
@@ -542,6 +757,20 @@ func (c *InteractiveClient) executeMetaquery(ctx context.Context, query string)
 		Connections: client.ConnectionMap(),
 		Prompt:      c.interactivePrompt,
 		ClosePrompt: func() { c.afterClose = AfterPromptCloseExit },
+		Buffer:      c.interactiveBuffer,
+		ClearBuffer: func() { c.interactiveBuffer = nil },
+		LastQuery:   c.lastQuery,
+		LastResult: func() *queryresult.Result {
+			if c.lastResult == nil {
+				return nil
+			}
+			return c.lastResult.NewResult()
+		},
+		ExecuteQuery: func(ctx context.Context, resolvedQuery *modconfig.ResolvedQuery) {
+			c.executeQuery(ctx, resolvedQuery)
+		},
+		History:  c.interactiveQueryHistory,
+		RunQuery: c.runQuery,
 	})
 }
 
@@ -0,0 +1,73 @@
+package interactive
+
+import (
+	"testing"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/turbot/steampipe/pkg/filepaths"
+	"github.com/turbot/steampipe/pkg/query/queryhistory"
+)
+
+func TestReverseSearchHistory(t *testing.T) {
+	prev := filepaths.SteampipeDir
+	filepaths.SteampipeDir = t.TempDir()
+	t.Cleanup(func() { filepaths.SteampipeDir = prev })
+
+	history, err := queryhistory.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []string{"select * from aws_s3_bucket", "select name from aws_iam_user", "select 1"} {
+		history.Push(q)
+	}
+	c := &InteractiveClient{interactiveQueryHistory: history}
+	b := prompt.NewBuffer()
+	b.InsertText("sel", false, true)
+
+	// typeText simulates typing into the prompt - go-prompt inserts the text, then calls the key binding
+	typeText := func(text string) {
+		b.InsertText(text, false, true)
+		c.updateHistorySearch(b)
+	}
+	expect := func(prefix, text string) {
+		t.Helper()
+		if got := c.historySearch.prefix(); got != prefix {
+			t.Errorf("expected prefix %q, got %q", prefix, got)
+		}
+		if b.Text() != text {
+			t.Errorf("expected prompt text %q, got %q", text, b.Text())
+		}
+	}
+
+	c.reverseSearchHistory(b)
+	expect("(reverse-i-search)`': ", "sel")
+
+	// the match is updated as each character is typed
+	typeText("a")
+	expect("(reverse-i-search)`a': ", "select name from aws_iam_user")
+	typeText("ws_s")
+	expect("(reverse-i-search)`aws_s': ", "select * from aws_s3_bucket")
+	typeText("x")
+	expect("(failed reverse-i-search)`aws_sx': ", "select * from aws_s3_bucket")
+
+	// backspace removes the last character of the search text, and searches from the most recent entry
+	b.DeleteBeforeCursor(1)
+	c.deleteHistorySearchChar(b)
+	b.DeleteBeforeCursor(1)
+	c.deleteHistorySearchChar(b)
+	b.DeleteBeforeCursor(1)
+	c.deleteHistorySearchChar(b)
+	expect("(reverse-i-search)`aws': ", "select name from aws_iam_user")
+
+	// Ctrl-R moves on to the next older match
+	c.reverseSearchHistory(b)
+	expect("(reverse-i-search)`aws': ", "select * from aws_s3_bucket")
+	c.reverseSearchHistory(b)
+	expect("(failed reverse-i-search)`aws': ", "select * from aws_s3_bucket")
+
+	// Ctrl-G aborts the search
+	c.abortHistorySearch(b)
+	if c.historySearch != nil || b.Text() != "sel" {
+		t.Errorf("expected the search to be aborted, restoring %q, got %q", "sel", b.Text())
+	}
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	runSuccessDesc = prometheus.NewDesc(
+		"steampipe_exporter_run_success",
+		"Whether the last run of the target succeeded (1) or failed (0)",
+		[]string{"target"}, nil,
+	)
+	runDurationDesc = prometheus.NewDesc(
+		"steampipe_exporter_run_duration_seconds",
+		"The duration of the last run of the target",
+		[]string{"target"}, nil,
+	)
+	runTimestampDesc = prometheus.NewDesc(
+		"steampipe_exporter_run_timestamp_seconds",
+		"The time the last run of the target completed, as a unix timestamp",
+		[]string{"target"}, nil,
+	)
+)
+
+// Collector is a prometheus collector which reports the metrics produced by the latest run of each target
+// As the metrics depend on the query results, the collector is unchecked, i.e. it does not describe its metrics up front
+type Collector struct {
+	mut     sync.RWMutex
+	targets map[string]*targetMetrics
+}
+
+type targetMetrics struct {
+	// the metrics from the last successful run
+	results []prometheus.Metric
+	// the outcome of the last run
+	success  bool
+	duration time.Duration
+	runTime  time.Time
+}
+
+func NewCollector() *Collector {
+	return &Collector{targets: make(map[string]*targetMetrics)}
+}
+
+// SetResults records the outcome of a run of the target - if the run succeeded, the result metrics replace
+// those of the previous run, otherwise the previous result metrics continue to be reported
+func (c *Collector) SetResults(target string, results []prometheus.Metric, duration time.Duration, err error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	t, ok := c.targets[target]
+	if !ok {
+		t = &targetMetrics{}
+		c.targets[target] = t
+	}
+	t.success = err == nil
+	t.duration = duration
+	t.runTime = time.Now()
+	if err == nil {
+		t.results = results
+	}
+}
+
+// Describe implements prometheus.Collector
+// NOTE: no descriptors are sent, making this an unchecked collector
+func (c *Collector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	// report targets in a consistent order
+	names := make([]string, 0, len(c.targets))
+	for name := range c.targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t := c.targets[name]
+		success := 0.0
+		if t.success {
+			success = 1
+		}
+		ch <- prometheus.MustNewConstMetric(runSuccessDesc, prometheus.GaugeValue, success, name)
+		ch <- prometheus.MustNewConstMetric(runDurationDesc, prometheus.GaugeValue, t.duration.Seconds(), name)
+		ch <- prometheus.MustNewConstMetric(runTimestampDesc, prometheus.GaugeValue, float64(t.runTime.Unix()), name)
+		for _, m := range t.results {
+			ch <- m
+		}
+	}
+}
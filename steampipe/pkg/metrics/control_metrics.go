@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/control/controlexecute"
+	"github.com/turbot/steampipe/pkg/control/controlstatus"
+)
+
+// ControlMetrics converts the results of a control run into gauges of the count of results with each status,
+// for each benchmark and control in the execution tree
+func ControlMetrics(tree *controlexecute.ExecutionTree) []prometheus.Metric {
+	set := newMetricSet()
+	addGroupMetrics(set, tree.Root)
+	for _, run := range tree.ControlRuns {
+		if run.Summary == nil {
+			continue
+		}
+		addStatusMetrics(set, "steampipe_control_status_count", "The number of control results with the given status", "control", run.Control.Name(), run.Summary)
+	}
+	return set.metrics
+}
+
+func addGroupMetrics(set *metricSet, group *controlexecute.ResultGroup) {
+	if group.GroupId != controlexecute.RootResultGroupName && group.Summary != nil {
+		addStatusMetrics(set, "steampipe_benchmark_status_count", "The number of control results in the benchmark with the given status", "benchmark", group.GroupId, &group.Summary.Status)
+	}
+	for _, child := range group.Groups {
+		addGroupMetrics(set, child)
+	}
+}
+
+func addStatusMetrics(set *metricSet, name, help, label, value string, summary *controlstatus.StatusSummary) {
+	counts := []struct {
+		status string
+		count  int
+	}{
+		{constants.ControlAlarm, summary.Alarm},
+		{constants.ControlOk, summary.Ok},
+		{constants.ControlInfo, summary.Info},
+		{constants.ControlSkip, summary.Skip},
+		{constants.ControlError, summary.Error},
+		{constants.ControlExempt, summary.Exempt},
+	}
+	for _, c := range counts {
+		set.addGauge(name, help, float64(c.count), []string{label, "status"}, []string{value, c.status})
+	}
+}
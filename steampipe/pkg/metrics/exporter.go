@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/turbot/steampipe/pkg/control/controlexecute"
+	"github.com/turbot/steampipe/pkg/db/db_common"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"github.com/turbot/steampipe/pkg/workspace"
+)
+
+// Exporter runs queries, benchmarks and controls on an interval, and exposes the results as prometheus metrics
+type Exporter struct {
+	Targets  []string
+	Interval time.Duration
+
+	workspace *workspace.Workspace
+	client    db_common.Client
+	collector *Collector
+	registry  *prometheus.Registry
+}
+
+// NewExporter returns an Exporter for the given targets, which must be queries, benchmarks or controls in the workspace
+func NewExporter(w *workspace.Workspace, client db_common.Client, targets []string, interval time.Duration) (*Exporter, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no queries, benchmarks or controls specified")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid interval %s - the interval must be greater than zero", interval)
+	}
+	for _, target := range targets {
+		if err := validateTarget(w, target); err != nil {
+			return nil, err
+		}
+	}
+
+	e := &Exporter{
+		Targets:   targets,
+		Interval:  interval,
+		workspace: w,
+		client:    client,
+		collector: NewCollector(),
+		registry:  prometheus.NewRegistry(),
+	}
+	if err := e.registry.Register(e.collector); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func validateTarget(w *workspace.Workspace, target string) error {
+	parsedName, err := modconfig.ParseResourceName(target)
+	if err != nil {
+		return err
+	}
+	switch parsedName.ItemType {
+	case modconfig.BlockTypeQuery, modconfig.BlockTypeBenchmark, modconfig.BlockTypeControl:
+	default:
+		return fmt.Errorf("invalid target '%s' - target must be a query, benchmark or control", target)
+	}
+	if _, ok := modconfig.GetResource(w, parsedName); !ok {
+		return fmt.Errorf("%s '%s' not found in workspace", parsedName.ItemType, target)
+	}
+	return nil
+}
+
+// Handler returns the http handler which serves the metrics
+func (e *Exporter) Handler() http.Handler {
+	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{})
+}
+
+// Run runs all targets immediately, then every Interval, until the context is cancelled
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.Interval)
+	defer ticker.Stop()
+
+	for {
+		for _, target := range e.Targets {
+			if ctx.Err() != nil {
+				return
+			}
+			e.runTarget(ctx, target)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Exporter) runTarget(ctx context.Context, target string) {
+	log.Printf("[TRACE] exporter running %s", target)
+	start := time.Now()
+
+	parsedName, _ := modconfig.ParseResourceName(target)
+	var results []prometheus.Metric
+	var err error
+	if parsedName.ItemType == modconfig.BlockTypeQuery {
+		results, err = e.runQuery(ctx, target)
+	} else {
+		results, err = e.runControls(ctx, target)
+	}
+	if err != nil {
+		log.Printf("[WARN] exporter failed to run %s: %s", target, err.Error())
+	}
+	e.collector.SetResults(target, results, time.Since(start), err)
+}
+
+func (e *Exporter) runQuery(ctx context.Context, queryName string) ([]prometheus.Metric, error) {
+	resolvedQuery, _, err := e.workspace.ResolveQueryAndArgsFromSQLString(queryName)
+	if err != nil {
+		return nil, err
+	}
+	result, err := e.client.ExecuteSync(ctx, resolvedQuery.ExecuteSQL, resolvedQuery.Args...)
+	if err != nil {
+		return nil, err
+	}
+	return QueryMetrics(queryName, result), nil
+}
+
+func (e *Exporter) runControls(ctx context.Context, target string) ([]prometheus.Metric, error) {
+	executionTree, err := controlexecute.NewExecutionTree(ctx, e.workspace, e.client, target, "")
+	if err != nil {
+		return nil, err
+	}
+	executionTree.Execute(ctx)
+	return ControlMetrics(executionTree), nil
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// sanitiseName converts a column name into a valid prometheus metric or label name
+func sanitiseName(name string) string {
+	res := invalidNameChars.ReplaceAllString(strings.ToLower(name), "_")
+	if res == "" || (res[0] >= '0' && res[0] <= '9') {
+		res = "_" + res
+	}
+	// names beginning '__' are reserved for internal use
+	if strings.HasPrefix(res, "__") {
+		res = "x" + res
+	}
+	return res
+}
+
+// metricSet builds a list of gauges, skipping any with the same name and label values as a gauge already added
+// (prometheus rejects a scrape containing duplicate metrics)
+type metricSet struct {
+	metrics []prometheus.Metric
+	descs   map[string]*prometheus.Desc
+	seen    map[string]bool
+}
+
+func newMetricSet() *metricSet {
+	return &metricSet{
+		descs: make(map[string]*prometheus.Desc),
+		seen:  make(map[string]bool),
+	}
+}
+
+func (s *metricSet) addGauge(name, help string, value float64, labelNames, labelValues []string) {
+	key := name + "\xff" + strings.Join(labelNames, "\xff") + "\xff\xff" + strings.Join(labelValues, "\xff")
+	if s.seen[key] {
+		log.Printf("[WARN] skipping duplicate metric %s %v", name, labelValues)
+		return
+	}
+	s.seen[key] = true
+
+	descKey := name + "\xff" + strings.Join(labelNames, "\xff")
+	desc, ok := s.descs[descKey]
+	if !ok {
+		desc = prometheus.NewDesc(name, help, labelNames, nil)
+		s.descs[descKey] = desc
+	}
+	metric, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, value, labelValues...)
+	if err != nil {
+		log.Printf("[WARN] failed to create metric %s: %s", name, err.Error())
+		return
+	}
+	s.metrics = append(s.metrics, metric)
+}
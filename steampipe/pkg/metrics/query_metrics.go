@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/steampipe/pkg/display"
+	"github.com/turbot/steampipe/pkg/query/queryresult"
+)
+
+const queryLabel = "query"
+
+// QueryMetrics converts a query result into gauges
+// a gauge named 'steampipe_query_<column>' is created for each numeric column of each row, labelled with
+// the query name and the values of the non-numeric columns
+func QueryMetrics(queryName string, result *queryresult.SyncQueryResult) []prometheus.Metric {
+	set := newMetricSet()
+	set.addGauge("steampipe_query_rows", "The number of rows returned by the query", float64(len(result.Rows)), []string{queryLabel}, []string{queryName})
+
+	// split the columns into values and labels
+	var valueCols, labelCols []int
+	labelNames := []string{queryLabel}
+	for i, col := range result.Cols {
+		if isNumericColumn(col) {
+			valueCols = append(valueCols, i)
+			continue
+		}
+		labelCols = append(labelCols, i)
+		labelNames = append(labelNames, uniqueLabelName(col.Name, labelNames))
+	}
+
+	for _, r := range result.Rows {
+		row, ok := r.(*queryresult.RowResult)
+		if !ok || row.Error != nil {
+			continue
+		}
+		labelValues := []string{queryName}
+		for _, i := range labelCols {
+			labelValues = append(labelValues, labelValue(row.Data[i], result.Cols[i]))
+		}
+		for _, i := range valueCols {
+			value, ok := toFloat64(row.Data[i])
+			if !ok {
+				// null (or unsupported) values are not exported
+				continue
+			}
+			col := result.Cols[i]
+			name := "steampipe_query_" + sanitiseName(col.Name)
+			help := fmt.Sprintf("The value of the '%s' column of the query result", col.Name)
+			set.addGauge(name, help, value, labelNames, labelValues)
+		}
+	}
+	return set.metrics
+}
+
+func isNumericColumn(col *queryresult.ColumnDef) bool {
+	switch col.DataType {
+	case "INT2", "INT4", "INT8", "FLOAT4", "FLOAT8", "NUMERIC":
+		return true
+	}
+	return false
+}
+
+func toFloat64(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case int:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func labelValue(val interface{}, col *queryresult.ColumnDef) string {
+	if val == nil {
+		return ""
+	}
+	res, err := display.ColumnValueAsString(val, col)
+	if err != nil {
+		return fmt.Sprintf("%v", val)
+	}
+	return res
+}
+
+// return a valid label name for the column which does not clash with the existing label names
+// following prometheus convention, a column which clashes with the query label is prefixed 'exported_'
+func uniqueLabelName(columnName string, existing []string) string {
+	name := sanitiseName(columnName)
+	if name == queryLabel {
+		name = "exported_" + name
+	}
+	res := name
+	for i := 2; helpers.StringSliceContains(existing, res); i++ {
+		res = fmt.Sprintf("%s_%d", name, i)
+	}
+	return res
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/turbot/steampipe/pkg/query/queryresult"
+)
+
+// gather the metrics from a collector, returning them as '<name>{<labels>} <value>' strings
+func gather(t *testing.T, c *Collector) []string {
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(c); err != nil {
+		t.Fatal(err)
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res []string
+	for _, family := range families {
+		if strings.HasPrefix(family.GetName(), "steampipe_exporter_") {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			var labels []string
+			for _, l := range m.GetLabel() {
+				labels = append(labels, fmt.Sprintf("%s=%q", l.GetName(), l.GetValue()))
+			}
+			res = append(res, fmt.Sprintf("%s{%s} %v", family.GetName(), strings.Join(labels, ","), m.GetGauge().GetValue()))
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func TestQueryMetrics(t *testing.T) {
+	result := &queryresult.SyncQueryResult{
+		Cols: []*queryresult.ColumnDef{
+			{Name: "region", DataType: "TEXT"},
+			{Name: "Bucket Count", DataType: "INT8"},
+			{Name: "size", DataType: "FLOAT8"},
+			{Name: "query", DataType: "TEXT"},
+		},
+		Rows: []interface{}{
+			&queryresult.RowResult{Data: []interface{}{"us-east-1", int64(3), 1.5, "a"}},
+			&queryresult.RowResult{Data: []interface{}{"eu-west-2", int64(1), nil, "b"}},
+			// duplicate labels are skipped
+			&queryresult.RowResult{Data: []interface{}{"eu-west-2", int64(2), nil, "b"}},
+		},
+	}
+
+	c := NewCollector()
+	c.SetResults("query.buckets", QueryMetrics("query.buckets", result), time.Second, nil)
+
+	expected := []string{
+		`steampipe_query_bucket_count{exported_query="a",query="query.buckets",region="us-east-1"} 3`,
+		`steampipe_query_bucket_count{exported_query="b",query="query.buckets",region="eu-west-2"} 1`,
+		`steampipe_query_rows{query="query.buckets"} 3`,
+		`steampipe_query_size{exported_query="a",query="query.buckets",region="us-east-1"} 1.5`,
+	}
+	sort.Strings(expected)
+	if actual := gather(t, c); strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+
+	// a failed run keeps the previous results
+	c.SetResults("query.buckets", nil, time.Second, fmt.Errorf("failed"))
+	if actual := gather(t, c); len(actual) != len(expected) {
+		t.Errorf("expected previous results to be retained after a failed run, got %v", actual)
+	}
+}
+
+func TestSanitiseName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"count":        "count",
+		"Total Cost $": "total_cost__",
+		"2xx":          "_2xx",
+		"__name":       "x__name",
+	} {
+		if actual := sanitiseName(name); actual != expected {
+			t.Errorf("%s: expected %s, got %s", name, expected, actual)
+		}
+	}
+}
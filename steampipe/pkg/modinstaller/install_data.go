@@ -17,6 +17,8 @@ type InstallData struct {
 
 	// ALL the available versions for each dependency mod(we populate this in a lazy fashion)
 	allAvailable versionmap.VersionListMap
+	// the versions of each mod in the workspace vendor folder - these are used in preference to Git
+	vendored versionmap.VersionListMap
 
 	// list of dependencies installed by recent install operation
 	Installed versionmap.DependencyVersionMap
@@ -35,6 +37,7 @@ func NewInstallData(workspaceLock *versionmap.WorkspaceLock, workspaceMod *modco
 		WorkspaceMod: workspaceMod,
 		NewLock:      versionmap.EmptyWorkspaceLock(workspaceLock),
 		allAvailable: make(versionmap.VersionListMap),
+		vendored:     make(versionmap.VersionListMap),
 		Installed:    make(versionmap.DependencyVersionMap),
 		Upgraded:     make(versionmap.DependencyVersionMap),
 		Downgraded:   make(versionmap.DependencyVersionMap),
@@ -85,6 +88,10 @@ func (d *InstallData) getAvailableModVersions(modName string, includePrerelease
 	if ok {
 		return availableVersions, nil
 	}
+	// if the mod has been vendored, only the vendored versions are available
+	if vendoredVersions, ok := d.vendored[modName]; ok {
+		return vendoredVersions, nil
+	}
 	// so we have not cached this yet - retrieve from Git
 	var err error
 	availableVersions, err = getTagVersionsFromGit(getGitUrl(modName), includePrerelease)
@@ -60,6 +60,10 @@ func NewModInstaller(opts *InstallOpts) (*ModInstaller, error) {
 
 	// create install data
 	i.installData = NewInstallData(workspaceLock, workspaceMod)
+	i.installData.vendored, err = loadVendoredModVersions(filepaths.WorkspaceVendorPath(i.workspacePath))
+	if err != nil {
+		return nil, err
+	}
 
 	// parse args to get the required mod versions
 	requiredMods, err := i.GetRequiredModVersionsFromArgs(opts.ModArgs)
@@ -362,7 +366,13 @@ func (i *ModInstaller) install(dependency *ResolvedModRef, parent *modconfig.Mod
 	// if the target path exists, use the exiting file
 	// if it does not exist (the usual case), install it
 	if _, err := os.Stat(tempDestPath); os.IsNotExist(err) {
-		if err := i.installFromGit(dependency, tempDestPath); err != nil {
+		// install from the vendor folder if the mod has been vendored, otherwise from Git
+		vendorPath := filepath.Join(filepaths.WorkspaceVendorPath(i.workspacePath), fullName)
+		if _, err := os.Stat(vendorPath); err == nil {
+			if err := copy.Copy(vendorPath, tempDestPath); err != nil {
+				return nil, err
+			}
+		} else if err := i.installFromGit(dependency, tempDestPath); err != nil {
 			return nil, err
 		}
 	}
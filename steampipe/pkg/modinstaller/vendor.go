@@ -0,0 +1,91 @@
+package modinstaller
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/otiai10/copy"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/steampipe/pkg/filepaths"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/versionmap"
+)
+
+// VendorWorkspaceDependencies copies all dependencies in the workspace lock file into the workspace vendor folder
+// mods are installed from the vendor folder in preference to Git, so 'mod install' can run with no network access
+// it returns the vendored mod versions
+func VendorWorkspaceDependencies(workspacePath string) (versionmap.VersionListMap, error) {
+	workspaceLock, err := versionmap.LoadWorkspaceLock(workspacePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(workspaceLock.MissingVersions) > 0 {
+		return nil, fmt.Errorf("not all dependencies are installed - run 'steampipe mod install' before vendoring")
+	}
+
+	// build the list of locked mod versions - a version may be a dependency of multiple parents
+	vendored := make(versionmap.VersionListMap)
+	flatVendored := make(map[string]bool)
+	for _, deps := range workspaceLock.InstallCache {
+		for name, resolvedConstraint := range deps {
+			fullName := modconfig.ModVersionFullName(name, resolvedConstraint.Version)
+			if !flatVendored[fullName] {
+				flatVendored[fullName] = true
+				vendored.Add(name, resolvedConstraint.Version)
+			}
+		}
+	}
+
+	// recreate the vendor folder, so it does not contain versions which are no longer required
+	vendorPath := filepaths.WorkspaceVendorPath(workspacePath)
+	if err := os.RemoveAll(vendorPath); err != nil {
+		return nil, err
+	}
+	if len(vendored) == 0 {
+		return vendored, nil
+	}
+
+	for fullName := range flatVendored {
+		sourcePath := filepath.Join(workspaceLock.ModInstallationPath, fullName)
+		destPath := filepath.Join(vendorPath, fullName)
+		err := copy.Copy(sourcePath, destPath, copy.Options{
+			// the Git metadata is not required to install the mod
+			Skip: func(srcinfo os.FileInfo, _, _ string) (bool, error) {
+				return srcinfo.IsDir() && srcinfo.Name() == ".git", nil
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to vendor %s: %s", fullName, err.Error())
+		}
+	}
+	return vendored, nil
+}
+
+// loadVendoredModVersions returns the versions of each mod in the vendor folder
+func loadVendoredModVersions(vendorPath string) (versionmap.VersionListMap, error) {
+	res := make(versionmap.VersionListMap)
+	if !filehelpers.DirectoryExists(vendorPath) {
+		return res, nil
+	}
+	modFiles, err := filehelpers.ListFiles(vendorPath, &filehelpers.ListOptions{
+		Flags:   filehelpers.FilesRecursive,
+		Include: []string{"**/mod.sp"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, modfilePath := range modFiles {
+		modFullName, err := filepath.Rel(vendorPath, filepath.Dir(modfilePath))
+		if err != nil {
+			continue
+		}
+		modName, version, err := modconfig.ParseModFullName(filepath.ToSlash(modFullName))
+		if err != nil {
+			// this is not a vendored mod folder - it is probably a child folder of a mod
+			continue
+		}
+		res.Add(modName, version)
+	}
+	return res, nil
+}
@@ -0,0 +1,80 @@
+package modinstaller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/filepaths"
+)
+
+func TestVendorWorkspaceDependencies(t *testing.T) {
+	workspacePath := t.TempDir()
+	modsPath := filepaths.WorkspaceModPath(workspacePath)
+	lock := `{
+  "local": {
+    "github.com/turbot/steampipe-mod-a": {"name": "github.com/turbot/steampipe-mod-a", "version": "1.2.0", "constraint": "*"}
+  },
+  "github.com/turbot/steampipe-mod-a@v1.2": {
+    "github.com/turbot/steampipe-mod-b": {"name": "github.com/turbot/steampipe-mod-b", "version": "0.3.0", "constraint": "^0.3"}
+  }
+}`
+	writeTestFile(t, filepaths.WorkspaceLockPath(workspacePath), lock)
+	writeTestFile(t, filepath.Join(modsPath, "github.com/turbot/steampipe-mod-a@v1.2", "mod.sp"), `mod "a" {}`)
+	writeTestFile(t, filepath.Join(modsPath, "github.com/turbot/steampipe-mod-a@v1.2", ".git", "HEAD"), "ref")
+	writeTestFile(t, filepath.Join(modsPath, "github.com/turbot/steampipe-mod-b@v0.3", "mod.sp"), `mod "b" {}`)
+	// a mod which is installed but not in the lock file should not be vendored
+	writeTestFile(t, filepath.Join(modsPath, "github.com/turbot/steampipe-mod-c@v1.0", "mod.sp"), `mod "c" {}`)
+	// a previously vendored mod which is no longer required should be removed
+	vendorPath := filepaths.WorkspaceVendorPath(workspacePath)
+	writeTestFile(t, filepath.Join(vendorPath, "github.com/turbot/steampipe-mod-a@v1.1", "mod.sp"), `mod "a" {}`)
+
+	vendored, err := VendorWorkspaceDependencies(workspacePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vendored) != 2 {
+		t.Fatalf("expected 2 vendored mods, got %d", len(vendored))
+	}
+
+	// the vendored versions are available to the installer
+	available, err := loadVendoredModVersions(vendorPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"github.com/turbot/steampipe-mod-a": "1.2.0",
+		"github.com/turbot/steampipe-mod-b": "0.3.0",
+	}
+	if len(available) != len(expected) {
+		t.Fatalf("expected %d mods to be available, got %v", len(expected), available)
+	}
+	for name, version := range expected {
+		if versions := available[name]; len(versions) != 1 || versions[0].String() != version {
+			t.Errorf("expected %s version %s to be available, got %v", name, version, versions)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(vendorPath, "github.com/turbot/steampipe-mod-a@v1.2", ".git")); !os.IsNotExist(err) {
+		t.Errorf("git metadata should not be vendored")
+	}
+}
+
+func TestVendorWorkspaceDependenciesMissing(t *testing.T) {
+	workspacePath := t.TempDir()
+	lock := `{"local": {"github.com/turbot/steampipe-mod-a": {"name": "github.com/turbot/steampipe-mod-a", "version": "1.2.0", "constraint": "*"}}}`
+	writeTestFile(t, filepaths.WorkspaceLockPath(workspacePath), lock)
+
+	if _, err := VendorWorkspaceDependencies(workspacePath); err == nil {
+		t.Errorf("expected an error vendoring dependencies which are not installed")
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,115 @@
+package modlint
+
+import (
+	"os"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Fix is an automatic fix for an issue
+type Fix struct {
+	Description string
+	Edits       []*Edit
+}
+
+// Edit replaces the bytes [Start, End) of a file
+type Edit struct {
+	Filename    string
+	Start       int
+	End         int
+	Replacement string
+}
+
+func (e *Edit) overlaps(other *Edit) bool {
+	return e.Filename == other.Filename && e.Start < other.End && other.Start < e.End
+}
+
+// ApplyFixes applies the fixes of the given issues to the mod files and returns the issues which were fixed
+// if the edits of two fixes overlap, only the first is applied - lint should be run again to fix the remaining issues
+func ApplyFixes(issues []*Issue) ([]*Issue, error) {
+	var fixed []*Issue
+	var edits []*Edit
+	for _, issue := range issues {
+		if issue.Fix == nil || overlapsAny(issue.Fix.Edits, edits) {
+			continue
+		}
+		edits = append(edits, issue.Fix.Edits...)
+		fixed = append(fixed, issue)
+	}
+
+	editsByFile := map[string][]*Edit{}
+	for _, edit := range edits {
+		editsByFile[edit.Filename] = append(editsByFile[edit.Filename], edit)
+	}
+	for filename, fileEdits := range editsByFile {
+		if err := applyEdits(filename, fileEdits); err != nil {
+			return nil, err
+		}
+	}
+	return fixed, nil
+}
+
+func overlapsAny(edits []*Edit, existing []*Edit) bool {
+	for _, e := range edits {
+		for _, other := range existing {
+			if e.overlaps(other) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func applyEdits(filename string, edits []*Edit) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	// apply the edits from the end of the file, so the offsets of the remaining edits are unchanged
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Start > edits[j].Start
+	})
+	for _, edit := range edits {
+		data = append(data[:edit.Start], append([]byte(edit.Replacement), data[edit.End:]...)...)
+	}
+	return os.WriteFile(filename, data, info.Mode())
+}
+
+// build an edit which deletes an item of an object or list literal, including its trailing comma
+func deleteItemEdit(l *Linter, r hcl.Range) *Edit {
+	src := l.parser.Sources()[r.Filename]
+	start, end := r.Start.Byte, skipSpace(src, r.End.Byte)
+	if end < len(src) && src[end] == ',' {
+		end = skipSpace(src, end+1)
+	} else {
+		end = r.End.Byte
+	}
+	// if the item is on its own line, remove the line
+	if lineStart := skipSpaceBackwards(src, start); lineStart == 0 || src[lineStart-1] == '\n' {
+		if lineEnd := skipSpace(src, end); lineEnd < len(src) && src[lineEnd] == '\n' {
+			start, end = lineStart, lineEnd+1
+		}
+	}
+	return &Edit{Filename: r.Filename, Start: start, End: end}
+}
+
+// return the offset of the first non space or tab character at or after pos
+func skipSpace(src []byte, pos int) int {
+	for pos < len(src) && (src[pos] == ' ' || src[pos] == '\t' || src[pos] == '\r') {
+		pos++
+	}
+	return pos
+}
+
+// return the offset after the last non space or tab character before pos
+func skipSpaceBackwards(src []byte, pos int) int {
+	for pos > 0 && (src[pos-1] == ' ' || src[pos-1] == '\t') {
+		pos--
+	}
+	return pos
+}
@@ -0,0 +1,177 @@
+package modlint
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/turbot/steampipe/pkg/filepaths"
+	"github.com/turbot/steampipe/pkg/schema"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in a mod by a lint rule
+type Issue struct {
+	RuleID   string
+	Severity Severity
+	// the name of the resource the issue was found in
+	Resource string
+	Message  string
+	Range    hcl.Range
+	// the fix for the issue - nil if the issue cannot be fixed automatically
+	Fix *Fix
+}
+
+// Linter runs lint rules against the resources of a mod
+// only resources defined in the mod itself are linted - resources of mod dependencies are ignored
+type Linter struct {
+	mod   *modconfig.Mod
+	rules []*Rule
+	// the schema of the connected database - only required by rules which check table names
+	schema *schema.Metadata
+	parser *hclparse.Parser
+}
+
+func NewLinter(mod *modconfig.Mod, rules []*Rule, schema *schema.Metadata) *Linter {
+	return &Linter{
+		mod:    mod,
+		rules:  rules,
+		schema: schema,
+		parser: hclparse.NewParser(),
+	}
+}
+
+// Lint runs the rules and returns the issues found, ordered by file position
+func (l *Linter) Lint() ([]*Issue, error) {
+	var res []*Issue
+	for _, rule := range l.rules {
+		if rule.RequiresSchema && l.schema == nil {
+			return nil, fmt.Errorf("rule '%s' requires a database connection", rule.ID)
+		}
+		issues, err := rule.check(l)
+		if err != nil {
+			return nil, fmt.Errorf("rule '%s' failed: %s", rule.ID, err.Error())
+		}
+		for _, issue := range issues {
+			issue.RuleID = rule.ID
+			issue.Severity = rule.Severity
+		}
+		res = append(res, issues...)
+	}
+	sortIssues(res)
+	return res, nil
+}
+
+// return the resources defined in the mod, excluding anonymous resources, sorted by file position
+func (l *Linter) resources() []modconfig.HclResource {
+	var res []modconfig.HclResource
+	resourceFunc := func(item modconfig.HclResource) (bool, error) {
+		if l.isLocal(item.GetDeclRange()) && !isAnonymous(item) {
+			res = append(res, item)
+		}
+		// continue walking
+		return true, nil
+	}
+	l.mod.WalkResources(resourceFunc)
+
+	sort.Slice(res, func(i, j int) bool {
+		return rangeLess(*res[i].GetDeclRange(), *res[j].GetDeclRange())
+	})
+	return res
+}
+
+// the hcl files which define the mod and its resources
+func (l *Linter) files() []string {
+	fileMap := map[string]struct{}{}
+	if l.isLocal(l.mod.GetDeclRange()) {
+		fileMap[l.mod.GetDeclRange().Filename] = struct{}{}
+	}
+	for _, r := range l.resources() {
+		if filename := r.GetDeclRange().Filename; filepath.Ext(filename) == ".sp" {
+			fileMap[filename] = struct{}{}
+		}
+	}
+	res := make([]string, 0, len(fileMap))
+	for filename := range fileMap {
+		res = append(res, filename)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// is the range in a file of the mod itself (rather than a dependency mod)
+func (l *Linter) isLocal(r *hcl.Range) bool {
+	if r == nil || r.Filename == "" {
+		return false
+	}
+	relPath, err := filepath.Rel(l.mod.ModPath, r.Filename)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return false
+	}
+	return !strings.HasPrefix(relPath, filepaths.WorkspaceDataDir+string(filepath.Separator))
+}
+
+// parse the given hcl file - the parser caches the result
+func (l *Linter) parseFile(filename string) (*hclsyntax.Body, error) {
+	file, diags := l.parser.ParseHCLFile(filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return file.Body.(*hclsyntax.Body), nil
+}
+
+// find the block whose definition is at the given range
+func (l *Linter) findBlock(declRange *hcl.Range) (*hclsyntax.Block, error) {
+	body, err := l.parseFile(declRange.Filename)
+	if err != nil {
+		return nil, err
+	}
+	if block := findBlockInBody(body, declRange); block != nil {
+		return block, nil
+	}
+	return nil, fmt.Errorf("no block found at %s", declRange.String())
+}
+
+func findBlockInBody(body *hclsyntax.Body, declRange *hcl.Range) *hclsyntax.Block {
+	for _, block := range body.Blocks {
+		if block.DefRange().Start.Byte == declRange.Start.Byte {
+			return block
+		}
+		if child := findBlockInBody(block.Body, declRange); child != nil {
+			return child
+		}
+	}
+	return nil
+}
+
+func isAnonymous(item modconfig.HclResource) bool {
+	resourceWithMetadata, ok := item.(modconfig.ResourceWithMetadata)
+	return ok && resourceWithMetadata.IsAnonymous()
+}
+
+func sortIssues(issues []*Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Range.Filename != issues[j].Range.Filename || issues[i].Range.Start.Byte != issues[j].Range.Start.Byte {
+			return rangeLess(issues[i].Range, issues[j].Range)
+		}
+		return issues[i].RuleID < issues[j].RuleID
+	})
+}
+
+func rangeLess(a, b hcl.Range) bool {
+	if a.Filename != b.Filename {
+		return a.Filename < b.Filename
+	}
+	return a.Start.Byte < b.Start.Byte
+}
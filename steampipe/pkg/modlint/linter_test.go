@@ -0,0 +1,184 @@
+package modlint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/schema"
+	"github.com/turbot/steampipe/pkg/workspace"
+)
+
+const testModData = `mod "lint_test" {
+  title = "Lint test"
+}
+
+variable "used" {
+  type    = string
+  default = "us-east-1"
+}
+
+variable "unused" {
+  type    = string
+  default = "unused"
+}
+
+query "buckets" {
+  sql = "select name from aws_s3_bucket where region = $1"
+
+  param "region" {
+    default = var.used
+  }
+}
+
+control "bucket_named_args" {
+  title         = "Buckets"
+  severity      = "low"
+  documentation = "docs"
+  query         = query.buckets
+  args = {
+    region = "us-east-2"
+    bogus  = "value"
+  }
+}
+
+control "bucket_duplicate_title" {
+  title = "Buckets"
+  sql   = "select 'ok' as status, arn as resource, 'ok' as reason from aws_s3_bukcet"
+}
+
+benchmark "empty" {
+  title    = "Empty"
+  children = []
+}
+`
+
+func loadTestMod(t *testing.T) (*workspace.Workspace, string) {
+	modPath := t.TempDir()
+	modFile := filepath.Join(modPath, "mod.sp")
+	if err := os.WriteFile(modFile, []byte(testModData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := workspace.Load(context.Background(), modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w, modFile
+}
+
+func issueSummaries(issues []*Issue) []string {
+	var res []string
+	for _, issue := range issues {
+		res = append(res, issue.RuleID+": "+issue.Message)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func TestLint(t *testing.T) {
+	w, _ := loadTestMod(t)
+	rules, err := SelectRules([]string{RuleSetAll}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbSchema := &schema.Metadata{
+		Schemas: map[string]map[string]schema.TableSchema{
+			"aws": {"aws_s3_bucket": {}},
+		},
+	}
+	issues, err := NewLinter(w.Mod, rules, dbSchema).Lint()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"arg-param-mismatch: control 'control.bucket_named_args' has arg 'bogus' which does not match any param",
+		"control-missing-documentation: control 'control.bucket_duplicate_title' has no documentation",
+		"control-missing-severity: control 'control.bucket_duplicate_title' has no severity",
+		"duplicate-title: control 'control.bucket_duplicate_title' has the same title as 'control.bucket_named_args': 'Buckets'",
+		"empty-benchmark: benchmark 'benchmark.empty' has no children",
+		"unknown-table: control 'control.bucket_duplicate_title' references table 'aws_s3_bukcet' which does not exist",
+		"unused-variable: variable 'unused' is not used",
+	}
+	actual := issueSummaries(issues)
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected issues:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func TestLintRequiresSchema(t *testing.T) {
+	w, _ := loadTestMod(t)
+	rules, err := SelectRules([]string{"unknown-table"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewLinter(w.Mod, rules, nil).Lint(); err == nil {
+		t.Error("expected an error linting with no schema")
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	w, modFile := loadTestMod(t)
+	rules, err := SelectRules([]string{"unused-variable", "arg-param-mismatch"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues, err := NewLinter(w.Mod, rules, nil).Lint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixed, err := ApplyFixes(issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixed) != 1 {
+		t.Fatalf("expected 1 fixed issue, got %d", len(fixed))
+	}
+
+	// unused variables are not fixed - only the bogus arg is removed
+	data, err := os.ReadFile(modFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Replace(testModData, `    bogus  = "value"
+`, "", 1)
+	if string(data) != expected {
+		t.Errorf("unexpected fixed mod file:\n%s", string(data))
+	}
+
+	// the fixed mod should load and only have the unused variable issue remaining
+	w, err = workspace.Load(context.Background(), w.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues, err = NewLinter(w.Mod, rules, nil).Lint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedIssues := []string{"unused-variable: variable 'unused' is not used"}
+	if actual := issueSummaries(issues); strings.Join(actual, "\n") != strings.Join(expectedIssues, "\n") {
+		t.Errorf("expected issues after fixing:\n%s\ngot:\n%s", strings.Join(expectedIssues, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func TestSelectRules(t *testing.T) {
+	rules, err := SelectRules([]string{RuleSetDefault, "unknown-table"}, []string{RuleSetStyle})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, rule := range rules {
+		ids = append(ids, rule.ID)
+	}
+	expected := "unused-variable,unknown-table,arg-param-mismatch,empty-benchmark"
+	if strings.Join(ids, ",") != expected {
+		t.Errorf("expected rules %s, got %s", expected, strings.Join(ids, ","))
+	}
+
+	if _, err := SelectRules([]string{"not-a-rule"}, nil); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+}
@@ -0,0 +1,220 @@
+package modlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/utils"
+	"github.com/turbot/steampipe/pkg/version"
+)
+
+const (
+	OutputFormatText  = "text"
+	OutputFormatJSON  = "json"
+	OutputFormatSARIF = "sarif"
+)
+
+var OutputFormats = []string{OutputFormatText, OutputFormatJSON, OutputFormatSARIF}
+
+// WriteIssues writes the lint issues in the given format
+// file paths are written relative to modPath
+func WriteIssues(w io.Writer, format string, modPath string, rules []*Rule, issues []*Issue) error {
+	switch format {
+	case OutputFormatText:
+		return writeText(w, modPath, issues)
+	case OutputFormatJSON:
+		return writeJSON(w, modPath, issues)
+	case OutputFormatSARIF:
+		return writeSARIF(w, modPath, rules, issues)
+	}
+	return fmt.Errorf("unsupported output format '%s' - must be one of %s", format, strings.Join(OutputFormats, ", "))
+}
+
+func relativePath(modPath, filename string) string {
+	if relPath, err := filepath.Rel(modPath, filename); err == nil {
+		return filepath.ToSlash(relPath)
+	}
+	return filename
+}
+
+func writeText(w io.Writer, modPath string, issues []*Issue) error {
+	var sb strings.Builder
+	errorCount := 0
+	for _, issue := range issues {
+		severity := constants.Yellow(string(issue.Severity))
+		if issue.Severity == SeverityError {
+			severity = constants.Red(string(issue.Severity))
+			errorCount++
+		}
+		fmt.Fprintf(&sb, "%s:%d:%d: %s: %s [%s]\n",
+			relativePath(modPath, issue.Range.Filename),
+			issue.Range.Start.Line,
+			issue.Range.Start.Column,
+			severity,
+			issue.Message,
+			issue.RuleID)
+	}
+	if len(issues) == 0 {
+		sb.WriteString("No issues found\n")
+	} else {
+		warningCount := len(issues) - errorCount
+		fmt.Fprintf(&sb, "\n%d %s (%d %s, %d %s)\n",
+			len(issues), utils.Pluralize("issue", len(issues)),
+			errorCount, utils.Pluralize("error", errorCount),
+			warningCount, utils.Pluralize("warning", warningCount))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+type jsonIssue struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Resource string   `json:"resource,omitempty"`
+	Message  string   `json:"message"`
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Fixable  bool     `json:"fixable"`
+}
+
+func writeJSON(w io.Writer, modPath string, issues []*Issue) error {
+	res := make([]jsonIssue, len(issues))
+	for i, issue := range issues {
+		res[i] = jsonIssue{
+			Rule:     issue.RuleID,
+			Severity: issue.Severity,
+			Resource: issue.Resource,
+			Message:  issue.Message,
+			File:     relativePath(modPath, issue.Range.Filename),
+			Line:     issue.Range.Start.Line,
+			Column:   issue.Range.Start.Column,
+			Fixable:  issue.Fix != nil,
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(res)
+}
+
+// SARIF 2.1.0 - https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+func writeSARIF(w io.Writer, modPath string, rules []*Rule, issues []*Issue) error {
+	driver := sarifDriver{
+		Name:           "steampipe",
+		Version:        version.SteampipeVersion.String(),
+		InformationURI: "https://steampipe.io",
+		Rules:          make([]sarifRule, len(rules)),
+	}
+	ruleIndex := make(map[string]int, len(rules))
+	for i, rule := range rules {
+		ruleIndex[rule.ID] = i
+		driver.Rules[i] = sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: string(rule.Severity)},
+		}
+	}
+
+	results := make([]sarifResult, len(issues))
+	for i, issue := range issues {
+		results[i] = sarifResult{
+			RuleID:    issue.RuleID,
+			RuleIndex: ruleIndex[issue.RuleID],
+			Level:     string(issue.Severity),
+			Message:   sarifMessage{Text: issue.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: relativePath(modPath, issue.Range.Filename)},
+					Region: sarifRegion{
+						StartLine:   issue.Range.Start.Line,
+						StartColumn: issue.Range.Start.Column,
+						EndLine:     issue.Range.End.Line,
+						EndColumn:   issue.Range.End.Column,
+					},
+				},
+			}},
+		}
+	}
+
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
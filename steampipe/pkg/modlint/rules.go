@@ -0,0 +1,365 @@
+package modlint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// rule sets
+const (
+	RuleSetDefault     = "default"
+	RuleSetAll         = "all"
+	RuleSetCorrectness = "correctness"
+	RuleSetStyle       = "style"
+)
+
+// Rule is a single lint check
+type Rule struct {
+	ID          string
+	Description string
+	Severity    Severity
+	// the rule sets the rule belongs to (other than 'all')
+	Sets []string
+	// does the rule need the schema of the connected database
+	RequiresSchema bool
+	check          func(l *Linter) ([]*Issue, error)
+}
+
+// Rules is the list of all lint rules
+var Rules = []*Rule{
+	{
+		ID:          "unused-variable",
+		Description: "Variables which are not referenced by any resource",
+		Severity:    SeverityWarning,
+		Sets:        []string{RuleSetDefault, RuleSetCorrectness},
+		check:       checkUnusedVariables,
+	},
+	{
+		ID:             "unknown-table",
+		Description:    "Queries which reference tables that do not exist in the connected database",
+		Severity:       SeverityError,
+		Sets:           []string{RuleSetCorrectness},
+		RequiresSchema: true,
+		check:          checkUnknownTables,
+	},
+	{
+		ID:          "arg-param-mismatch",
+		Description: "Args which do not match the params of the query",
+		Severity:    SeverityError,
+		Sets:        []string{RuleSetDefault, RuleSetCorrectness},
+		check:       checkArgParamMismatch,
+	},
+	{
+		ID:          "control-missing-severity",
+		Description: "Controls with no severity",
+		Severity:    SeverityWarning,
+		Sets:        []string{RuleSetDefault, RuleSetStyle},
+		check:       checkControlMissingSeverity,
+	},
+	{
+		ID:          "control-missing-documentation",
+		Description: "Controls with no documentation",
+		Severity:    SeverityWarning,
+		Sets:        []string{RuleSetDefault, RuleSetStyle},
+		check:       checkControlMissingDocumentation,
+	},
+	{
+		ID:          "duplicate-title",
+		Description: "Resources of the same type with the same title",
+		Severity:    SeverityWarning,
+		Sets:        []string{RuleSetDefault, RuleSetStyle},
+		check:       checkDuplicateTitles,
+	},
+	{
+		ID:          "empty-benchmark",
+		Description: "Benchmarks with no children",
+		Severity:    SeverityWarning,
+		Sets:        []string{RuleSetDefault, RuleSetCorrectness},
+		check:       checkEmptyBenchmarks,
+	},
+}
+
+// RuleSets returns the names of all rule sets
+func RuleSets() []string {
+	return []string{RuleSetDefault, RuleSetAll, RuleSetCorrectness, RuleSetStyle}
+}
+
+// SelectRules returns the rules specified by the given rule ids and rule set names, less the excluded rules
+func SelectRules(include []string, exclude []string) ([]*Rule, error) {
+	selected := map[string]bool{}
+	for _, name := range include {
+		ids, err := resolveRuleName(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			selected[id] = true
+		}
+	}
+	for _, name := range exclude {
+		ids, err := resolveRuleName(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			delete(selected, id)
+		}
+	}
+
+	var res []*Rule
+	for _, rule := range Rules {
+		if selected[rule.ID] {
+			res = append(res, rule)
+		}
+	}
+	return res, nil
+}
+
+// resolve a rule id or rule set name into a list of rule ids
+func resolveRuleName(name string) ([]string, error) {
+	var res []string
+	for _, rule := range Rules {
+		if rule.ID == name || name == RuleSetAll || rule.inSet(name) {
+			res = append(res, rule.ID)
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("unknown lint rule or rule set '%s' - rule sets are: %s", name, strings.Join(RuleSets(), ", "))
+	}
+	return res, nil
+}
+
+func (r *Rule) inSet(set string) bool {
+	for _, s := range r.Sets {
+		if s == set {
+			return true
+		}
+	}
+	return false
+}
+
+func checkUnusedVariables(l *Linter) ([]*Issue, error) {
+	// find all variable references in the mod files
+	// (resource references are not recorded for nested blocks or locals, so walk the syntax tree)
+	used := map[string]bool{}
+	for _, filename := range l.files() {
+		body, err := l.parseFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+			if expr, ok := node.(*hclsyntax.ScopeTraversalExpr); ok && expr.Traversal.RootName() == "var" && len(expr.Traversal) > 1 {
+				if attr, ok := expr.Traversal[1].(hcl.TraverseAttr); ok {
+					used[attr.Name] = true
+				}
+			}
+			return nil
+		})
+	}
+
+	var res []*Issue
+	for _, r := range l.resources() {
+		variable, ok := r.(*modconfig.Variable)
+		if !ok || used[variable.ShortName] {
+			continue
+		}
+		// there is no fix - the variable may still be set by a .spvars file, the environment or a dependent mod,
+		// where removing it would cause an error
+		res = append(res, &Issue{
+			Resource: variable.Name(),
+			Message:  fmt.Sprintf("variable '%s' is not used", variable.ShortName),
+			Range:    variable.DeclRange,
+		})
+	}
+	return res, nil
+}
+
+func checkUnknownTables(l *Linter) ([]*Issue, error) {
+	var res []*Issue
+	for _, r := range l.resources() {
+		queryProvider, ok := r.(modconfig.QueryProvider)
+		if !ok || queryProvider.GetSQL() == nil {
+			continue
+		}
+		for _, table := range tablesInSQL(*queryProvider.GetSQL()) {
+			if !l.tableExists(table) {
+				res = append(res, &Issue{
+					Resource: r.Name(),
+					Message:  fmt.Sprintf("%s '%s' references table '%s' which does not exist", r.BlockType(), r.GetUnqualifiedName(), table),
+					Range:    *r.GetDeclRange(),
+				})
+			}
+		}
+	}
+	return res, nil
+}
+
+func checkArgParamMismatch(l *Linter) ([]*Issue, error) {
+	var res []*Issue
+	for _, r := range l.resources() {
+		queryProvider, ok := r.(modconfig.QueryProvider)
+		if !ok || queryProvider.GetArgs() == nil {
+			continue
+		}
+		args := queryProvider.GetArgs()
+		params := queryProvider.GetParams()
+		if len(params) == 0 && queryProvider.GetQuery() != nil {
+			params = queryProvider.GetQuery().GetParams()
+		}
+		paramMap := make(map[string]*modconfig.ParamDef, len(params))
+		for _, p := range params {
+			paramMap[p.Name] = p
+		}
+
+		// named args which do not match a param can be removed
+		var unknownArgs []string
+		for name := range args.ArgMap {
+			if _, ok := paramMap[name]; !ok {
+				unknownArgs = append(unknownArgs, name)
+			}
+		}
+		sort.Strings(unknownArgs)
+		for _, name := range unknownArgs {
+			issue := &Issue{
+				Resource: r.Name(),
+				Message:  fmt.Sprintf("%s '%s' has arg '%s' which does not match any param", r.BlockType(), r.GetUnqualifiedName(), name),
+				Range:    *r.GetDeclRange(),
+			}
+			fix, err := removeArgFix(l, r, name)
+			if err != nil {
+				return nil, err
+			}
+			issue.Fix = fix
+			res = append(res, issue)
+		}
+
+		// if there are runtime dependencies, args may be provided when the resource is executed
+		if len(args.ArgMap) > 0 && len(queryProvider.GetRuntimeDependencies()) == 0 {
+			for _, p := range params {
+				if _, ok := args.ArgMap[p.Name]; !ok && p.Default == nil {
+					res = append(res, &Issue{
+						Resource: r.Name(),
+						Message:  fmt.Sprintf("%s '%s' has no arg for param '%s', which has no default", r.BlockType(), r.GetUnqualifiedName(), p.Name),
+						Range:    *r.GetDeclRange(),
+					})
+				}
+			}
+		}
+
+		// positional args may be used with sql which has no params
+		if len(params) > 0 && len(args.ArgList) > len(params) {
+			res = append(res, &Issue{
+				Resource: r.Name(),
+				Message:  fmt.Sprintf("%s '%s' has %d positional args but the query has only %d params", r.BlockType(), r.GetUnqualifiedName(), len(args.ArgList), len(params)),
+				Range:    *r.GetDeclRange(),
+			})
+		}
+	}
+	return res, nil
+}
+
+func checkControlMissingSeverity(l *Linter) ([]*Issue, error) {
+	var res []*Issue
+	for _, r := range l.resources() {
+		if control, ok := r.(*modconfig.Control); ok && typehelpers.SafeString(control.Severity) == "" {
+			res = append(res, &Issue{
+				Resource: control.Name(),
+				Message:  fmt.Sprintf("control '%s' has no severity", control.GetUnqualifiedName()),
+				Range:    control.DeclRange,
+			})
+		}
+	}
+	return res, nil
+}
+
+func checkControlMissingDocumentation(l *Linter) ([]*Issue, error) {
+	var res []*Issue
+	for _, r := range l.resources() {
+		if control, ok := r.(*modconfig.Control); ok && typehelpers.SafeString(control.Documentation) == "" {
+			res = append(res, &Issue{
+				Resource: control.Name(),
+				Message:  fmt.Sprintf("control '%s' has no documentation", control.GetUnqualifiedName()),
+				Range:    control.DeclRange,
+			})
+		}
+	}
+	return res, nil
+}
+
+func checkDuplicateTitles(l *Linter) ([]*Issue, error) {
+	var res []*Issue
+	// map of block type to map of title to the first resource with that title
+	titles := map[string]map[string]modconfig.HclResource{}
+	for _, r := range l.resources() {
+		title := r.GetTitle()
+		if title == "" {
+			continue
+		}
+		blockTitles, ok := titles[r.BlockType()]
+		if !ok {
+			blockTitles = map[string]modconfig.HclResource{}
+			titles[r.BlockType()] = blockTitles
+		}
+		first, ok := blockTitles[title]
+		if !ok {
+			blockTitles[title] = r
+			continue
+		}
+		res = append(res, &Issue{
+			Resource: r.Name(),
+			Message:  fmt.Sprintf("%s '%s' has the same title as '%s': '%s'", r.BlockType(), r.GetUnqualifiedName(), first.GetUnqualifiedName(), title),
+			Range:    *r.GetDeclRange(),
+		})
+	}
+	return res, nil
+}
+
+func checkEmptyBenchmarks(l *Linter) ([]*Issue, error) {
+	var res []*Issue
+	for _, r := range l.resources() {
+		if benchmark, ok := r.(*modconfig.Benchmark); ok && len(benchmark.GetChildren()) == 0 {
+			res = append(res, &Issue{
+				Resource: benchmark.Name(),
+				Message:  fmt.Sprintf("benchmark '%s' has no children", benchmark.GetUnqualifiedName()),
+				Range:    benchmark.DeclRange,
+			})
+		}
+	}
+	return res, nil
+}
+
+// build a fix which removes the named arg from the args attribute of the resource
+// returns nil if the arg is not defined in an object literal, e.g. if args is set from a variable
+func removeArgFix(l *Linter, r modconfig.HclResource, argName string) (*Fix, error) {
+	block, err := l.findBlock(r.GetDeclRange())
+	if err != nil {
+		return nil, err
+	}
+	attr, ok := block.Body.Attributes["args"]
+	if !ok {
+		return nil, nil
+	}
+	objectExpr, ok := attr.Expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		return nil, nil
+	}
+	for _, item := range objectExpr.Items {
+		key, diags := item.KeyExpr.Value(nil)
+		if diags.HasErrors() || key.Type() != cty.String || key.AsString() != argName {
+			continue
+		}
+		itemRange := hcl.RangeBetween(item.KeyExpr.Range(), item.ValueExpr.Range())
+		return &Fix{
+			Description: fmt.Sprintf("remove arg '%s'", argName),
+			Edits:       []*Edit{deleteItemEdit(l, itemRange)},
+		}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,259 @@
+package modlint
+
+import (
+	"strings"
+)
+
+type sqlTokenType int
+
+const (
+	sqlIdentifier sqlTokenType = iota
+	sqlQuotedIdentifier
+	sqlPunctuation
+	sqlOther
+)
+
+type sqlToken struct {
+	tokenType sqlTokenType
+	value     string
+}
+
+func (t sqlToken) isKeyword(keywords ...string) bool {
+	if t.tokenType != sqlIdentifier {
+		return false
+	}
+	for _, k := range keywords {
+		if strings.EqualFold(t.value, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t sqlToken) isPunctuation(p string) bool {
+	return t.tokenType == sqlPunctuation && t.value == p
+}
+
+func (t sqlToken) isName() bool {
+	return t.tokenType == sqlQuotedIdentifier || t.tokenType == sqlIdentifier
+}
+
+// the name of an identifier - unquoted identifiers are case-insensitive so are lower-cased
+func (t sqlToken) name() string {
+	if t.tokenType == sqlQuotedIdentifier {
+		return t.value
+	}
+	return strings.ToLower(t.value)
+}
+
+// tablesInSQL returns the names of the tables referenced in the FROM and JOIN clauses of the sql,
+// excluding common table expressions and set returning functions
+// this is a heuristic, not a full sql parser - it is intended to find mistyped table names
+func tablesInSQL(sql string) []string {
+	tokens := tokenizeSQL(sql)
+
+	// find the names of common table expressions, i.e. '<name> as (' or '<name>(<columns>) as ('
+	cteNames := map[string]bool{}
+	for i, t := range tokens {
+		if !t.isKeyword("as") || i == 0 || i+1 >= len(tokens) || !tokens[i+1].isPunctuation("(") {
+			continue
+		}
+		nameIdx := i - 1
+		if tokens[nameIdx].isPunctuation(")") {
+			for nameIdx > 0 && !tokens[nameIdx].isPunctuation("(") {
+				nameIdx--
+			}
+			nameIdx--
+		}
+		if nameIdx >= 0 && tokens[nameIdx].isName() {
+			cteNames[tokens[nameIdx].name()] = true
+		}
+	}
+
+	var res []string
+	seen := map[string]bool{}
+	addTable := func(name string) {
+		if !seen[name] && !cteNames[name] {
+			seen[name] = true
+			res = append(res, name)
+		}
+	}
+
+	// the context of each open parenthesis - the top level is a query
+	contexts := []*sqlContext{{query: true}}
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		current := contexts[len(contexts)-1]
+		switch {
+		case t.isPunctuation("("):
+			// is the content a query (rather than a function call or expression)
+			isQuery := i+1 < len(tokens) && tokens[i+1].isKeyword("select", "with", "values")
+			contexts = append(contexts, &sqlContext{query: isQuery})
+		case t.isPunctuation(")"):
+			if len(contexts) > 1 {
+				contexts = contexts[:len(contexts)-1]
+			}
+		case !current.query:
+			continue
+		case t.isKeyword("from", "join"):
+			// ignore 'is distinct from'
+			if i > 0 && tokens[i-1].isKeyword("distinct") {
+				continue
+			}
+			current.inFrom = true
+			i = parseTableRef(tokens, i+1, addTable) - 1
+		case t.isPunctuation(",") && current.inFrom:
+			i = parseTableRef(tokens, i+1, addTable) - 1
+		case isClauseKeyword(t) || t.isKeyword("select"):
+			current.inFrom = false
+		}
+	}
+	return res
+}
+
+type sqlContext struct {
+	// is the content of the context a query
+	query bool
+	// is the current clause a FROM clause, which may contain a comma separated list of tables
+	inFrom bool
+}
+
+// parse the table reference following a FROM, JOIN or comma, calling addTable if it is a table
+// returns the index of the first token after the table reference
+func parseTableRef(tokens []sqlToken, i int, addTable func(string)) int {
+	for i < len(tokens) && tokens[i].isKeyword("lateral", "only") {
+		i++
+	}
+	if i >= len(tokens) || !tokens[i].isName() {
+		return i
+	}
+	name := tokens[i].name()
+	i++
+	if i+1 < len(tokens) && tokens[i].isPunctuation(".") && tokens[i+1].isName() {
+		name += "." + tokens[i+1].name()
+		i += 2
+	}
+	// a function call, e.g. jsonb_array_elements(...)
+	if i < len(tokens) && tokens[i].isPunctuation("(") {
+		return i
+	}
+	addTable(name)
+	return i
+}
+
+// keywords which end a FROM clause
+func isClauseKeyword(t sqlToken) bool {
+	return t.isKeyword("where", "group", "order", "having", "limit", "offset", "union", "intersect", "except", "window", "returning")
+}
+
+func tokenizeSQL(sql string) []sqlToken {
+	var tokens []sqlToken
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			// line comment
+			end := strings.IndexByte(sql[i:], '\n')
+			if end == -1 {
+				return tokens
+			}
+			i += end + 1
+		case strings.HasPrefix(sql[i:], "/*"):
+			// block comment
+			end := strings.Index(sql[i+2:], "*/")
+			if end == -1 {
+				return tokens
+			}
+			i += 2 + end + 2
+		case c == '\'':
+			// string literal - a quote is escaped by doubling it
+			i++
+			for i < len(sql) {
+				if sql[i] == '\'' {
+					if i+1 < len(sql) && sql[i+1] == '\'' {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			i++
+			tokens = append(tokens, sqlToken{tokenType: sqlOther})
+		case c == '"':
+			end := strings.IndexByte(sql[i+1:], '"')
+			if end == -1 {
+				return append(tokens, sqlToken{tokenType: sqlQuotedIdentifier, value: sql[i+1:]})
+			}
+			tokens = append(tokens, sqlToken{tokenType: sqlQuotedIdentifier, value: sql[i+1 : i+1+end]})
+			i += end + 2
+		case c == '$':
+			// either a positional param ($1) or a dollar quoted string ($$...$$ or $tag$...$tag$)
+			j := i + 1
+			for j < len(sql) && isIdentifierChar(sql[j]) && sql[j] != '$' {
+				j++
+			}
+			if j < len(sql) && sql[j] == '$' {
+				tag := sql[i : j+1]
+				end := strings.Index(sql[j+1:], tag)
+				if end == -1 {
+					return append(tokens, sqlToken{tokenType: sqlOther})
+				}
+				i = j + 1 + end + len(tag)
+			} else {
+				i = j
+			}
+			tokens = append(tokens, sqlToken{tokenType: sqlOther})
+		case isIdentifierChar(c) && !isDigit(c):
+			start := i
+			for i < len(sql) && isIdentifierChar(sql[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{tokenType: sqlIdentifier, value: sql[start:i]})
+		case isDigit(c):
+			for i < len(sql) && (isDigit(sql[i]) || sql[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{tokenType: sqlOther})
+		default:
+			tokens = append(tokens, sqlToken{tokenType: sqlPunctuation, value: string(c)})
+			i++
+		}
+	}
+	return tokens
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// non-ascii characters are treated as identifier characters
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+// does the table exist in the connected database
+// an unqualified table may be in any schema, as the search path may be set for the query
+func (l *Linter) tableExists(table string) bool {
+	schemaName, tableName, qualified := strings.Cut(table, ".")
+	if !qualified {
+		tableName = schemaName
+		schemaName = ""
+	}
+	// do not check system tables
+	if schemaName == "pg_catalog" || schemaName == "information_schema" || strings.HasPrefix(tableName, "pg_") {
+		return true
+	}
+	if qualified {
+		_, ok := l.schema.Schemas[schemaName][tableName]
+		return ok
+	}
+	for _, tables := range l.schema.Schemas {
+		if _, ok := tables[tableName]; ok {
+			return true
+		}
+	}
+	return false
+}
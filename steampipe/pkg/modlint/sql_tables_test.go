@@ -0,0 +1,51 @@
+package modlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTablesInSQL(t *testing.T) {
+	tests := map[string]struct {
+		sql      string
+		expected []string
+	}{
+		"simple": {
+			sql:      "select * from aws_s3_bucket",
+			expected: []string{"aws_s3_bucket"},
+		},
+		"qualified and quoted": {
+			sql:      `select * from aws.aws_s3_bucket b join "Mixed"."Case" c on b.name = c.name`,
+			expected: []string{"aws.aws_s3_bucket", "Mixed.Case"},
+		},
+		"comma list with set returning function": {
+			sql:      "select * from aws_iam_role as r, jsonb_array_elements(r.policy -> 'Statement') as s, aws_account a where a.id = 1",
+			expected: []string{"aws_iam_role", "aws_account"},
+		},
+		"cte and subquery": {
+			sql: `with buckets as (select name from aws_s3_bucket), counts(n) as (select count(*) from buckets)
+select * from counts where n in (select 1 from aws_account)`,
+			expected: []string{"aws_s3_bucket", "aws_account"},
+		},
+		"from in expressions": {
+			sql:      "select extract(day from created_at), substring(name from 2), a is distinct from b from aws_ec2_instance",
+			expected: []string{"aws_ec2_instance"},
+		},
+		"comments, strings and params": {
+			sql: `-- select * from commented
+/* from also_commented */
+select 'from not_a_table' as x, $$ from quoted $$ as y from aws_vpc where region = $1`,
+			expected: []string{"aws_vpc"},
+		},
+		"lateral join": {
+			sql:      "select * from aws_vpc v left join lateral (select * from aws_vpc_subnet s where s.vpc_id = v.vpc_id) s on true",
+			expected: []string{"aws_vpc", "aws_vpc_subnet"},
+		},
+	}
+	for name, test := range tests {
+		actual := tablesInSQL(test.sql)
+		if strings.Join(actual, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("%s: expected %v, got %v", name, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package modtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/query/queryresult"
+)
+
+// the normalised form of null values
+const nullValue = "<null>"
+
+// the maximum number of missing/unexpected rows to report
+const maxReportedRows = 5
+
+// compareRows compares the query result with the expected rows
+// only the columns in the expected rows are compared, and row order is ignored
+func compareRows(result *queryresult.SyncQueryResult, expected *records) []string {
+	// find the result column for each expected column
+	resultColumns := make(map[string]int, len(result.Cols))
+	for i, c := range result.Cols {
+		resultColumns[c.Name] = i
+	}
+	columnIdx := make([]int, len(expected.columns))
+	var missingColumns []string
+	for i, c := range expected.columns {
+		idx, ok := resultColumns[c]
+		if !ok {
+			missingColumns = append(missingColumns, c)
+		}
+		columnIdx[i] = idx
+	}
+	if len(missingColumns) > 0 {
+		return []string{fmt.Sprintf("query result does not contain expected %s '%s'", pluralizeColumn(len(missingColumns)), strings.Join(missingColumns, "', '"))}
+	}
+
+	// count the occurrences of each expected row, then remove the actual rows
+	remaining := map[string]int{}
+	for _, row := range expected.rows {
+		values := make([]string, len(expected.columns))
+		for i := range expected.columns {
+			var v any
+			if i < len(row) {
+				v = row[i]
+			}
+			values[i] = normaliseValue(v)
+		}
+		remaining[rowKey(values)]++
+	}
+	var unexpected []string
+	for _, r := range result.Rows {
+		row, ok := r.(*queryresult.RowResult)
+		if !ok || row.Error != nil {
+			continue
+		}
+		values := make([]string, len(columnIdx))
+		for i, idx := range columnIdx {
+			values[i] = normaliseValue(row.Data[idx])
+		}
+		key := rowKey(values)
+		if remaining[key] > 0 {
+			remaining[key]--
+			continue
+		}
+		unexpected = append(unexpected, key)
+	}
+	var missing []string
+	for key, count := range remaining {
+		for i := 0; i < count; i++ {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+
+	var failures []string
+	failures = append(failures, describeRows("missing expected row", missing)...)
+	failures = append(failures, describeRows("unexpected row", unexpected)...)
+	return failures
+}
+
+func describeRows(description string, rows []string) []string {
+	var res []string
+	for i, row := range rows {
+		if i == maxReportedRows {
+			res = append(res, fmt.Sprintf("... and %d more %ss", len(rows)-maxReportedRows, description))
+			break
+		}
+		res = append(res, fmt.Sprintf("%s: %s", description, row))
+	}
+	return res
+}
+
+func rowKey(values []string) string {
+	return "(" + strings.Join(values, ", ") + ")"
+}
+
+func pluralizeColumn(count int) string {
+	if count == 1 {
+		return "column"
+	}
+	return "columns"
+}
+
+// normaliseValue converts an actual or expected value to a string, such that equivalent values are equal
+// regardless of whether they were read from the database, a csv file or a json file
+func normaliseValue(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return nullValue
+	case string:
+		return normaliseString(t)
+	case json.Number:
+		return normaliseString(t.String())
+	case bool:
+		return strconv.FormatBool(t)
+	case int, int16, int32, int64, float32, float64:
+		f, _ := strconv.ParseFloat(fmt.Sprintf("%v", t), 64)
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	case time.Time:
+		return t.UTC().Format(time.RFC3339Nano)
+	case map[string]any, []any:
+		return canonicalJSON(t)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func normaliseString(s string) string {
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	if len(s) > 0 && (s[0] == '{' || s[0] == '[') {
+		var decoded any
+		if err := json.Unmarshal([]byte(s), &decoded); err == nil {
+			return canonicalJSON(decoded)
+		}
+	}
+	return s
+}
+
+// marshal the value to json with sorted keys and consistently formatted numbers
+func canonicalJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	// round trip to convert any json.Number values to float64
+	var decoded any
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return string(b)
+	}
+	b, _ = json.Marshal(decoded)
+	return string(b)
+}
@@ -0,0 +1,72 @@
+package modtest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/query/queryresult"
+)
+
+func newSyncResult(columns []string, rows ...[]any) *queryresult.SyncQueryResult {
+	res := &queryresult.SyncQueryResult{}
+	for _, c := range columns {
+		res.Cols = append(res.Cols, &queryresult.ColumnDef{Name: c})
+	}
+	for _, r := range rows {
+		res.Rows = append(res.Rows, &queryresult.RowResult{Data: r})
+	}
+	return res
+}
+
+func TestNormaliseValue(t *testing.T) {
+	created := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, test := range []struct {
+		actual   any
+		expected any
+	}{
+		{int64(10), "10"},
+		{float64(1), json.Number("1.0")},
+		{true, "true"},
+		{created, "2023-01-02T04:04:05+01:00"},
+		{map[string]any{"b": 1, "a": "x"}, `{"a": "x", "b": 1.0}`},
+		{nil, nil},
+	} {
+		if a, e := normaliseValue(test.actual), normaliseValue(test.expected); a != e {
+			t.Errorf("expected %v to equal %v: got %s and %s", test.actual, test.expected, a, e)
+		}
+	}
+	if normaliseValue("") == normaliseValue(nil) {
+		t.Error("expected empty string and null to differ")
+	}
+}
+
+func TestCompareRows(t *testing.T) {
+	expected := &records{
+		columns: []string{"name", "size"},
+		rows:    [][]any{{"b1", "10"}, {"b2", "20"}},
+	}
+
+	// row order and extra result columns are ignored
+	result := newSyncResult([]string{"region", "size", "name"},
+		[]any{"us-east-1", int64(20), "b2"},
+		[]any{"us-east-1", int64(10), "b1"},
+	)
+	if failures := compareRows(result, expected); len(failures) != 0 {
+		t.Errorf("expected no failures, got %v", failures)
+	}
+
+	result = newSyncResult([]string{"name", "size"},
+		[]any{"b1", int64(10)},
+		[]any{"b3", int64(30)},
+	)
+	failures := compareRows(result, expected)
+	if len(failures) != 2 || failures[0] != "missing expected row: (b2, 20)" || failures[1] != "unexpected row: (b3, 30)" {
+		t.Errorf("unexpected failures %v", failures)
+	}
+
+	result = newSyncResult([]string{"name"}, []any{"b1"})
+	if failures := compareRows(result, expected); len(failures) != 1 {
+		t.Errorf("expected a missing column failure, got %v", failures)
+	}
+}
@@ -0,0 +1,151 @@
+package modtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/db/db_local"
+)
+
+// the postgres types inferred for fixture columns
+const (
+	typeText      = "text"
+	typeBoolean   = "boolean"
+	typeBigint    = "bigint"
+	typeDouble    = "double precision"
+	typeTimestamp = "timestamptz"
+	typeJSONB     = "jsonb"
+)
+
+// LoadFixture loads a csv or json fixture file into a table definition
+// The column types are inferred from the values: boolean, bigint, double precision, timestamptz (RFC 3339 values),
+// jsonb (objects and arrays) or text
+func LoadFixture(table, path string) (*db_local.FixtureTable, error) {
+	data, err := loadRecords(path)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &db_local.FixtureTable{Name: table}
+	for i, name := range data.columns {
+		var values []any
+		for _, row := range data.rows {
+			if i < len(row) {
+				values = append(values, row[i])
+			}
+		}
+		res.Columns = append(res.Columns, db_local.FixtureColumn{Name: name, Type: inferColumnType(values, data.untyped)})
+	}
+	for _, row := range data.rows {
+		values := make([]*string, len(data.columns))
+		for i := range data.columns {
+			if i < len(row) {
+				values[i] = valueAsText(row[i])
+			}
+		}
+		res.Rows = append(res.Rows, values)
+	}
+	return res, nil
+}
+
+// infer the column type which can represent all of the (non null) values
+// if the values are untyped (csv), the type of string values is inferred from their content
+func inferColumnType(values []any, untyped bool) string {
+	columnType := ""
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		t := inferValueType(v, untyped)
+		switch {
+		case columnType == "" || columnType == t:
+			columnType = t
+		case isNumericType(columnType) && isNumericType(t):
+			columnType = typeDouble
+		default:
+			return typeText
+		}
+	}
+	if columnType == "" {
+		// all values are null
+		return typeText
+	}
+	return columnType
+}
+
+func inferValueType(v any, untyped bool) string {
+	switch t := v.(type) {
+	case bool:
+		return typeBoolean
+	case json.Number:
+		if _, err := t.Int64(); err == nil {
+			return typeBigint
+		}
+		return typeDouble
+	case map[string]any, []any:
+		return typeJSONB
+	case string:
+		if _, err := time.Parse(time.RFC3339, t); err == nil {
+			return typeTimestamp
+		}
+		if untyped {
+			return inferTextType(t)
+		}
+	}
+	return typeText
+}
+
+// infer the type of an untyped (csv) value from its content
+func inferTextType(t string) string {
+	// numbers with leading zeros (e.g. account ids) are text
+	if len(t) > 1 && t[0] == '0' && t[1] != '.' {
+		return typeText
+	}
+	if _, err := strconv.ParseInt(t, 10, 64); err == nil {
+		return typeBigint
+	}
+	if _, err := strconv.ParseFloat(t, 64); err == nil {
+		return typeDouble
+	}
+	if strings.EqualFold(t, "true") || strings.EqualFold(t, "false") {
+		return typeBoolean
+	}
+	if len(t) > 0 && (t[0] == '{' || t[0] == '[') && json.Valid([]byte(t)) {
+		return typeJSONB
+	}
+	return typeText
+}
+
+func isNumericType(t string) bool {
+	return t == typeBigint || t == typeDouble
+}
+
+// convert a value to its postgres text representation
+func valueAsText(v any) *string {
+	var res string
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case string:
+		res = t
+	case map[string]any, []any:
+		b, _ := json.Marshal(t)
+		res = string(b)
+	default:
+		res = fmt.Sprintf("%v", t)
+	}
+	return &res
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
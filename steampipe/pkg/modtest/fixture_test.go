@@ -0,0 +1,84 @@
+package modtest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/db/db_local"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFixtureCSV(t *testing.T) {
+	path := writeFile(t, "buckets.csv", `name,account_id,size,ratio,versioning,created,tags
+b1,012345678901,10,0.5,true,2023-01-02T03:04:05Z,"{""env"":""prod""}"
+b2,123456789012,20,1,false,,
+`)
+	fixture, err := LoadFixture("aws_s3_bucket", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedColumns := []db_local.FixtureColumn{
+		{Name: "name", Type: typeText},
+		{Name: "account_id", Type: typeText},
+		{Name: "size", Type: typeBigint},
+		{Name: "ratio", Type: typeDouble},
+		{Name: "versioning", Type: typeBoolean},
+		{Name: "created", Type: typeTimestamp},
+		{Name: "tags", Type: typeJSONB},
+	}
+	if !reflect.DeepEqual(fixture.Columns, expectedColumns) {
+		t.Errorf("expected columns %v, got %v", expectedColumns, fixture.Columns)
+	}
+	if len(fixture.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(fixture.Rows))
+	}
+	if v := fixture.Rows[1][5]; v != nil {
+		t.Errorf("expected empty csv value to be null, got %q", *v)
+	}
+}
+
+func TestLoadFixtureJSON(t *testing.T) {
+	path := writeFile(t, "buckets.json", `[
+  {"name": "b1", "size": 10, "versioning": true, "tags": {"env": "prod"}},
+  {"name": "b2", "size": 2.5, "region": "us-east-1", "count": "10"}
+]`)
+	fixture, err := LoadFixture("aws_s3_bucket", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedColumns := []db_local.FixtureColumn{
+		{Name: "name", Type: typeText},
+		{Name: "size", Type: typeDouble},
+		{Name: "tags", Type: typeJSONB},
+		{Name: "versioning", Type: typeBoolean},
+		{Name: "count", Type: typeText},
+		{Name: "region", Type: typeText},
+	}
+	if !reflect.DeepEqual(fixture.Columns, expectedColumns) {
+		t.Errorf("expected columns %v, got %v", expectedColumns, fixture.Columns)
+	}
+	if v := fixture.Rows[0][2]; v == nil || *v != `{"env":"prod"}` {
+		t.Errorf("expected json object to be marshalled, got %v", v)
+	}
+}
+
+func TestLoadFixtureInvalid(t *testing.T) {
+	for name, path := range map[string]string{
+		"unsupported extension": writeFile(t, "buckets.txt", "name\nb1\n"),
+		"json object":           writeFile(t, "buckets.json", `{"name": "b1"}`),
+		"missing file":          filepath.Join(t.TempDir(), "missing.csv"),
+	} {
+		if _, err := LoadFixture("t", path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package modtest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// records is the content of a csv or json data file
+// csv values are strings (empty values are nil), json values are as decoded (numbers are json.Number)
+type records struct {
+	columns []string
+	rows    [][]any
+	// true if the values are all strings (i.e. csv), so types must be inferred from the text
+	untyped bool
+}
+
+// loadRecords reads a csv file, or a json file containing an array of objects
+func loadRecords(path string) (*records, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var res *records
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		res, err = parseCSV(data)
+	case ".json":
+		res, err = parseJSON(data)
+	default:
+		return nil, fmt.Errorf("unsupported file '%s' - data files must be csv or json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse '%s': %s", path, err.Error())
+	}
+	return res, nil
+}
+
+func parseCSV(data []byte) (*records, error) {
+	lines, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("file is empty - the first line must contain the column names")
+	}
+	res := &records{columns: lines[0], untyped: true}
+	for _, line := range lines[1:] {
+		row := make([]any, len(line))
+		for i, v := range line {
+			if v != "" {
+				row[i] = v
+			}
+		}
+		res.rows = append(res.rows, row)
+	}
+	return res, nil
+}
+
+func parseJSON(data []byte) (*records, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var objects []map[string]any
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, err
+	}
+
+	// the columns are the union of the object keys, in the order they are first seen
+	res := &records{}
+	columnIdx := map[string]int{}
+	for _, object := range objects {
+		// sort the keys of each object to give a stable column order
+		for _, key := range sortedKeys(object) {
+			if _, ok := columnIdx[key]; !ok {
+				columnIdx[key] = len(res.columns)
+				res.columns = append(res.columns, key)
+			}
+		}
+	}
+	for _, object := range objects {
+		row := make([]any, len(res.columns))
+		for key, v := range object {
+			row[columnIdx[key]] = v
+		}
+		res.rows = append(res.rows, row)
+	}
+	return res, nil
+}
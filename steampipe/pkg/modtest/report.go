@@ -0,0 +1,114 @@
+package modtest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/utils"
+)
+
+const (
+	OutputFormatText  = "text"
+	OutputFormatJUnit = "junit"
+)
+
+var OutputFormats = []string{OutputFormatText, OutputFormatJUnit}
+
+// WriteResults writes the test results in the given format
+func WriteResults(w io.Writer, format string, suiteName string, results []*TestResult) error {
+	switch format {
+	case OutputFormatText:
+		return writeText(w, results)
+	case OutputFormatJUnit:
+		return writeJUnit(w, suiteName, results)
+	}
+	return fmt.Errorf("unsupported output format '%s' - must be one of %s", format, strings.Join(OutputFormats, ", "))
+}
+
+func writeText(w io.Writer, results []*TestResult) error {
+	var sb strings.Builder
+	failed := 0
+	for _, r := range results {
+		status := constants.Green("PASS")
+		if !r.Passed() {
+			status = constants.Red("FAIL")
+			failed++
+		}
+		fmt.Fprintf(&sb, "%s %s (%.2fs)\n", status, r.Test.Name(), r.Duration.Seconds())
+		if r.Error != nil {
+			fmt.Fprintf(&sb, "     error: %s\n", r.Error.Error())
+		}
+		for _, failure := range r.Failures {
+			fmt.Fprintf(&sb, "     %s\n", failure)
+		}
+	}
+	fmt.Fprintf(&sb, "\n%d %s, %d passed, %d failed\n", len(results), utils.Pluralize("test", len(results)), len(results)-failed, failed)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+func writeJUnit(w io.Writer, suiteName string, results []*TestResult) error {
+	suite := junitTestSuite{Name: suiteName, Tests: len(results)}
+	var total float64
+	for _, r := range results {
+		total += r.Duration.Seconds()
+		testCase := junitTestCase{
+			Name:      r.Test.Name(),
+			Classname: suiteName,
+			Time:      fmt.Sprintf("%.3f", r.Duration.Seconds()),
+		}
+		if r.Error != nil {
+			suite.Errors++
+			testCase.Error = &junitMessage{Message: r.Error.Error()}
+		} else if len(r.Failures) > 0 {
+			suite.Failures++
+			testCase.Failure = &junitMessage{
+				Message: fmt.Sprintf("%d %s failed", len(r.Failures), utils.Pluralize("assertion", len(r.Failures))),
+				Body:    strings.Join(r.Failures, "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Time = fmt.Sprintf("%.3f", total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,211 @@
+package modtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/control/controlexecute"
+	"github.com/turbot/steampipe/pkg/db/db_common"
+	"github.com/turbot/steampipe/pkg/db/db_local"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"github.com/turbot/steampipe/pkg/workspace"
+)
+
+// TestResult is the outcome of running a test
+type TestResult struct {
+	Test     *modconfig.TestCase
+	Duration time.Duration
+	// the assertions which failed
+	Failures []string
+	// an error which prevented the test from running
+	Error error
+}
+
+func (r *TestResult) Passed() bool {
+	return r.Error == nil && len(r.Failures) == 0
+}
+
+// Runner runs mod tests, loading the fixture tables of each test into the mod test schema
+// NOTE: the client must be created with the mod test schema as its search path
+type Runner struct {
+	workspace *workspace.Workspace
+	client    db_common.Client
+}
+
+func NewRunner(w *workspace.Workspace, client db_common.Client) *Runner {
+	return &Runner{workspace: w, client: client}
+}
+
+// SelectTests returns the tests with the given names, or all tests in the workspace if no names are given
+func SelectTests(w *workspace.Workspace, names []string) ([]*modconfig.TestCase, error) {
+	tests := w.GetResourceMaps().Tests
+	var res []*modconfig.TestCase
+	if len(names) == 0 {
+		for _, test := range tests {
+			res = append(res, test)
+		}
+		if len(res) == 0 {
+			return nil, fmt.Errorf("no tests found in workspace '%s'", w.Path)
+		}
+	} else {
+		for _, name := range names {
+			parsedName, err := modconfig.ParseResourceName(name)
+			if err != nil || parsedName.ItemType != modconfig.BlockTypeTest {
+				return nil, fmt.Errorf("invalid test name '%s' - expected 'test.<name>'", name)
+			}
+			resource, ok := modconfig.GetResource(w, parsedName)
+			if !ok {
+				return nil, fmt.Errorf("test '%s' not found in workspace", name)
+			}
+			res = append(res, resource.(*modconfig.TestCase))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name() < res[j].Name() })
+	return res, nil
+}
+
+// Run runs the tests in turn, then drops the mod test schema
+func (r *Runner) Run(ctx context.Context, tests []*modconfig.TestCase) []*TestResult {
+	defer db_local.DropFixtureSchema(context.Background(), constants.ModTestSchema)
+
+	var res []*TestResult
+	for _, test := range tests {
+		if ctx.Err() != nil {
+			break
+		}
+		res = append(res, r.runTest(ctx, test))
+	}
+	return res
+}
+
+func (r *Runner) runTest(ctx context.Context, test *modconfig.TestCase) *TestResult {
+	res := &TestResult{Test: test}
+	start := time.Now()
+	defer func() { res.Duration = time.Since(start) }()
+
+	if err := r.loadFixtures(ctx, test); err != nil {
+		res.Error = err
+		return res
+	}
+
+	if test.TargetType() == modconfig.BlockTypeQuery {
+		res.Failures, res.Error = r.runQueryTest(ctx, test)
+	} else {
+		res.Failures, res.Error = r.runControlTest(ctx, test)
+	}
+	return res
+}
+
+// (re)create the mod test schema containing the fixture tables of the test
+func (r *Runner) loadFixtures(ctx context.Context, test *modconfig.TestCase) error {
+	tableNames := make([]string, 0, len(test.Fixtures))
+	for table := range test.Fixtures {
+		tableNames = append(tableNames, table)
+	}
+	sort.Strings(tableNames)
+
+	tables := make([]*db_local.FixtureTable, len(tableNames))
+	for i, name := range tableNames {
+		table, err := LoadFixture(name, test.FilePath(test.Fixtures[name]))
+		if err != nil {
+			return fmt.Errorf("failed to load fixture '%s': %s", name, err.Error())
+		}
+		tables[i] = table
+	}
+	return db_local.CreateFixtureSchema(ctx, constants.ModTestSchema, tables)
+}
+
+func (r *Runner) runQueryTest(ctx context.Context, test *modconfig.TestCase) ([]string, error) {
+	resolvedQuery, _, err := r.workspace.ResolveQueryAndArgsFromSQLString(test.TargetName)
+	if err != nil {
+		return nil, err
+	}
+	result, err := r.client.ExecuteSync(ctx, resolvedQuery.ExecuteSQL, resolvedQuery.Args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []string
+	if test.ExpectedRowCount != nil && len(result.Rows) != *test.ExpectedRowCount {
+		failures = append(failures, fmt.Sprintf("expected %d rows, got %d", *test.ExpectedRowCount, len(result.Rows)))
+	}
+	if test.ExpectedRows != nil {
+		expected, err := loadRecords(test.FilePath(*test.ExpectedRows))
+		if err != nil {
+			return failures, fmt.Errorf("failed to load expected rows: %s", err.Error())
+		}
+		failures = append(failures, compareRows(result, expected)...)
+	}
+	return failures, nil
+}
+
+func (r *Runner) runControlTest(ctx context.Context, test *modconfig.TestCase) ([]string, error) {
+	executionTree, err := controlexecute.NewExecutionTree(ctx, r.workspace, r.client, test.TargetName, "")
+	if err != nil {
+		return nil, err
+	}
+	executionTree.Execute(ctx)
+	return checkControlResults(test, executionTree), nil
+}
+
+// checkControlResults compares the control results with the expected statuses and summary
+func checkControlResults(test *modconfig.TestCase, executionTree *controlexecute.ExecutionTree) []string {
+	var failures []string
+
+	// statuses of each resource, across all controls
+	resourceStatuses := map[string][]*controlexecute.ResultRow{}
+	for _, run := range executionTree.ControlRuns {
+		if err := run.GetError(); err != nil {
+			failures = append(failures, fmt.Sprintf("%s failed: %s", run.Control.Name(), err.Error()))
+			continue
+		}
+		for _, row := range run.Rows {
+			resourceStatuses[row.Resource] = append(resourceStatuses[row.Resource], row)
+		}
+	}
+
+	resources := make([]string, 0, len(test.ExpectedStatuses))
+	for resource := range test.ExpectedStatuses {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	for _, resource := range resources {
+		expected := test.ExpectedStatuses[resource]
+		rows := resourceStatuses[resource]
+		if len(rows) == 0 {
+			failures = append(failures, fmt.Sprintf("expected status '%s' for resource '%s', but there are no results for the resource", expected, resource))
+			continue
+		}
+		for _, row := range rows {
+			if row.Status != expected {
+				failures = append(failures, fmt.Sprintf("expected status '%s' for resource '%s', got '%s' from %s: %s", expected, resource, row.Status, row.Control.Name(), row.Reason))
+			}
+		}
+	}
+
+	if len(test.ExpectedSummary) > 0 {
+		summary := executionTree.Root.Summary.Status
+		actual := map[string]int{
+			constants.ControlOk:     summary.Ok,
+			constants.ControlAlarm:  summary.Alarm,
+			constants.ControlInfo:   summary.Info,
+			constants.ControlSkip:   summary.Skip,
+			constants.ControlError:  summary.Error,
+			constants.ControlExempt: summary.Exempt,
+		}
+		statuses := make([]string, 0, len(test.ExpectedSummary))
+		for status := range test.ExpectedSummary {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for _, status := range statuses {
+			if expected := test.ExpectedSummary[status]; actual[status] != expected {
+				failures = append(failures, fmt.Sprintf("expected %d '%s' results, got %d", expected, status, actual[status]))
+			}
+		}
+	}
+	return failures
+}
@@ -0,0 +1,256 @@
+package ociinstaller
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ocicontent "github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/remotes"
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/deislabs/oras/pkg/content"
+	"github.com/deislabs/oras/pkg/oras"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// A plugin bundle is an OCI image layout (https://github.com/opencontainers/image-spec/blob/main/image-layout.md)
+// containing one or more plugin images, each referenced in the index by its actual image ref,
+// e.g. us-docker.pkg.dev/steampipe/plugins/turbot/aws:latest
+// A bundle may be a directory, or a tar (optionally gzipped) of the directory
+
+// layoutResolver is a remotes.Resolver which resolves images from an OCI image layout
+type layoutResolver struct {
+	store *content.OCIStore
+}
+
+func newLayoutResolver(layoutDir string) (*layoutResolver, error) {
+	if _, err := os.Stat(filepath.Join(layoutDir, ocispec.ImageLayoutFile)); err != nil {
+		return nil, fmt.Errorf("%s is not a plugin bundle - it has no %s file", layoutDir, ocispec.ImageLayoutFile)
+	}
+	store, err := content.NewOCIStore(layoutDir)
+	if err != nil {
+		return nil, err
+	}
+	return &layoutResolver{store: store}, nil
+}
+
+func (r *layoutResolver) Resolve(_ context.Context, ref string) (string, ocispec.Descriptor, error) {
+	desc, ok := r.store.ListReferences()[ref]
+	if !ok {
+		// NOTE: the 'not found' suffix is used to identify missing plugins
+		return "", ocispec.Descriptor{}, fmt.Errorf("%s not found", ref)
+	}
+	return ref, desc, nil
+}
+
+func (r *layoutResolver) Fetcher(context.Context, string) (remotes.Fetcher, error) {
+	return remotes.FetcherFunc(func(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+		readerAt, err := r.store.ReaderAt(ctx, desc)
+		if err != nil {
+			return nil, err
+		}
+		return &readerAtCloser{Reader: ocicontent.NewReader(readerAt), Closer: readerAt}, nil
+	}), nil
+}
+
+func (r *layoutResolver) Pusher(context.Context, string) (remotes.Pusher, error) {
+	return nil, errors.New("pushing to a plugin bundle is not supported")
+}
+
+type readerAtCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// OpenPluginBundle returns the OCI image layout directory of a plugin bundle,
+// extracting the bundle to a temporary directory if it is a tar file
+// the returned function must be called to remove the temporary directory
+func OpenPluginBundle(bundlePath string) (string, func(), error) {
+	info, err := os.Stat(bundlePath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open plugin bundle: %s", err.Error())
+	}
+	if info.IsDir() {
+		return bundlePath, func() {}, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "steampipe-plugin-bundle-*")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			log.Printf("[TRACE] failed to remove plugin bundle temp dir '%s': %s", tmpDir, err)
+		}
+	}
+	if err := untarBundle(bundlePath, tmpDir); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to extract plugin bundle %s: %s", bundlePath, err.Error())
+	}
+	return tmpDir, cleanup, nil
+}
+
+// ExportPlugin downloads the image for the given plugin, for all platforms, and adds it to the OCI image layout
+// in layoutDir, creating the layout if needed
+func ExportPlugin(ctx context.Context, imageRef string, layoutDir string) (*SteampipeImageRef, error) {
+	// oras uses containerd, which uses logrus - suppress unwanted warnings
+	logrus.SetLevel(logrus.ErrorLevel)
+
+	if err := os.MkdirAll(layoutDir, 0755); err != nil {
+		return nil, err
+	}
+	store, err := content.NewOCIStore(layoutDir)
+	if err != nil {
+		return nil, err
+	}
+	// remove the ingest dir the content store uses for partially written blobs - it is not part of the layout
+	defer os.RemoveAll(filepath.Join(layoutDir, "ingest"))
+
+	ref := NewSteampipeImageRef(imageRef)
+	resolver := docker.NewResolver(docker.ResolverOptions{})
+	// no media types are specified, so the layers for all platforms are pulled
+	desc, _, err := oras.Pull(ctx, resolver, ref.ActualImageRef(), store,
+		oras.WithPullEmptyNameAllowed(),
+		oras.WithContentProvideIngester(store))
+	if err != nil {
+		return nil, err
+	}
+
+	store.AddReference(ref.ActualImageRef(), desc)
+	if err := store.SaveIndex(); err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// IsTarBundlePath returns whether the plugin bundle path is a tar file (rather than a directory)
+func IsTarBundlePath(bundlePath string) bool {
+	return strings.HasSuffix(bundlePath, ".tar") || isGzipBundlePath(bundlePath)
+}
+
+func isGzipBundlePath(bundlePath string) bool {
+	return strings.HasSuffix(bundlePath, ".tar.gz") || strings.HasSuffix(bundlePath, ".tgz")
+}
+
+// TarBundle writes the plugin bundle in layoutDir to a tar file, gzipped if the file has a .tar.gz or .tgz extension
+func TarBundle(layoutDir string, tarPath string) (err error) {
+	f, err := os.Create(tarPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	var w io.Writer = f
+	if isGzipBundlePath(tarPath) {
+		gzipWriter := gzip.NewWriter(f)
+		defer func() {
+			if closeErr := gzipWriter.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		w = gzipWriter
+	}
+	tarWriter := tar.NewWriter(w)
+	defer func() {
+		if closeErr := tarWriter.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	return filepath.Walk(layoutDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(layoutDir, path)
+		if err != nil || relPath == "." {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relPath)
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tarWriter, file)
+		return err
+	})
+}
+
+func untarBundle(tarPath string, destDir string) error {
+	f, err := os.Open(tarPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if isGzipBundlePath(tarPath) {
+		gzipReader, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		r = gzipReader
+	}
+
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// do not allow entries to be written outside the destination
+		destPath := filepath.Join(destDir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(destPath, filepath.Clean(destDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid file path in bundle: %s", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(destPath, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeBundleFile(destPath, tarReader); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeBundleFile(destPath string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(file, r)
+	return err
+}
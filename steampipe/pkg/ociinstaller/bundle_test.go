@@ -0,0 +1,99 @@
+package ociinstaller
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ocicontent "github.com/containerd/containerd/content"
+	"github.com/deislabs/oras/pkg/content"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestPluginBundle(t *testing.T) {
+	ctx := context.Background()
+	layoutDir := t.TempDir()
+
+	// build a bundle containing a single blob, referenced as the aws plugin
+	store, err := content.NewOCIStore(layoutDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(`{"schemaVersion": 2}`)
+	desc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(data),
+		Size:      int64(len(data)),
+	}
+	if err := ocicontent.WriteBlob(ctx, store, "manifest", bytes.NewReader(data), desc); err != nil {
+		t.Fatal(err)
+	}
+	ref := NewSteampipeImageRef("aws").ActualImageRef()
+	store.AddReference(ref, desc)
+	if err := store.SaveIndex(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, bundleName := range []string{"bundle.tar", "bundle.tar.gz"} {
+		bundlePath := filepath.Join(t.TempDir(), bundleName)
+		if err := TarBundle(layoutDir, bundlePath); err != nil {
+			t.Fatal(err)
+		}
+		bundleDir, cleanup, err := OpenPluginBundle(bundlePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resolver, err := newLayoutResolver(bundleDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, resolvedDesc, err := resolver.Resolve(ctx, ref)
+		if err != nil {
+			t.Fatalf("%s: %s", bundleName, err)
+		}
+		if resolvedDesc.Digest != desc.Digest {
+			t.Errorf("%s: expected digest %s, got %s", bundleName, desc.Digest, resolvedDesc.Digest)
+		}
+		fetcher, _ := resolver.Fetcher(ctx, ref)
+		reader, err := fetcher.Fetch(ctx, resolvedDesc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fetched, _ := io.ReadAll(reader)
+		reader.Close()
+		if !bytes.Equal(fetched, data) {
+			t.Errorf("%s: fetched content does not match", bundleName)
+		}
+
+		if _, _, err := resolver.Resolve(ctx, NewSteampipeImageRef("gcp").ActualImageRef()); err == nil || !strings.HasSuffix(err.Error(), "not found") {
+			t.Errorf("%s: expected not found error resolving a plugin not in the bundle, got %v", bundleName, err)
+		}
+		cleanup()
+		if _, err := os.Stat(bundleDir); !os.IsNotExist(err) {
+			t.Errorf("%s: extracted bundle was not removed", bundleName)
+		}
+	}
+}
+
+func TestOpenPluginBundleInvalidPath(t *testing.T) {
+	bundlePath := filepath.Join(t.TempDir(), "bundle.tar")
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+	tarWriter.WriteHeader(&tar.Header{Name: "../escape", Typeflag: tar.TypeReg, Size: 1, Mode: 0644})
+	tarWriter.Write([]byte("x"))
+	tarWriter.Close()
+	if err := os.WriteFile(bundlePath, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := OpenPluginBundle(bundlePath); err == nil {
+		t.Errorf("expected an error opening a bundle with a file path outside the bundle")
+	}
+}
@@ -19,8 +19,28 @@ import (
 	"github.com/turbot/steampipe/pkg/utils"
 )
 
+type pluginInstallConfig struct {
+	bundleDir string
+}
+
+// PluginInstallOption is an option for InstallPlugin
+type PluginInstallOption func(*pluginInstallConfig)
+
+// WithPluginBundle installs the plugin from the plugin bundle in the given OCI image layout directory,
+// rather than from the registry
+func WithPluginBundle(bundleDir string) PluginInstallOption {
+	return func(c *pluginInstallConfig) {
+		c.bundleDir = bundleDir
+	}
+}
+
 // InstallPlugin installs a plugin from an OCI Image
-func InstallPlugin(ctx context.Context, imageRef string, sub chan struct{}) (*SteampipeImage, error) {
+func InstallPlugin(ctx context.Context, imageRef string, sub chan struct{}, opts ...PluginInstallOption) (*SteampipeImage, error) {
+	config := &pluginInstallConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
 	tempDir := NewTempDir(filepaths.EnsurePluginDir())
 	defer func() {
 		// send a last beacon to signal completion
@@ -32,6 +52,13 @@ func InstallPlugin(ctx context.Context, imageRef string, sub chan struct{}) (*St
 
 	ref := NewSteampipeImageRef(imageRef)
 	imageDownloader := NewOciDownloader()
+	if config.bundleDir != "" {
+		resolver, err := newLayoutResolver(config.bundleDir)
+		if err != nil {
+			return nil, err
+		}
+		imageDownloader.resolver = resolver
+	}
 
 	sub <- struct{}{}
 	image, err := imageDownloader.Download(ctx, ref, ImageTypePlugin, tempDir.Path)
@@ -52,7 +52,7 @@ func NewInitData(ctx context.Context, args []string) *InitData {
 }
 
 func queryExporters() []export.Exporter {
-	return []export.Exporter{&export.SnapshotExporter{}}
+	return append([]export.Exporter{&export.SnapshotExporter{}}, ResultExporters()...)
 }
 
 func (i *InitData) Cancel() {
@@ -1,9 +1,11 @@
 package metaquery
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/c-bata/go-prompt"
+	"github.com/turbot/steampipe/pkg/display"
 )
 
 // CompleterInput is a struct defining input data for the metaquery completer
@@ -43,3 +45,11 @@ func completerFromArgsOf(cmd string) completer {
 func inspectCompleter(input *CompleterInput) []prompt.Suggest {
 	return input.TableSuggestions
 }
+
+func exportCompleter(input *CompleterInput) []prompt.Suggest {
+	suggestions := make([]prompt.Suggest, len(display.ResultWriterFormats))
+	for idx, format := range display.ResultWriterFormats {
+		suggestions[idx] = prompt.Suggest{Text: format, Description: fmt.Sprintf("Export to a .%s file", format), Output: format}
+	}
+	return suggestions
+}
@@ -89,12 +89,16 @@ func init() {
 			title:       constants.CmdOutput,
 			handler:     setViperConfigFromArg(constants.ArgOutput),
 			validator:   composeValidator(exactlyNArgs(1), validatorFromArgsOf(constants.CmdOutput)),
-			description: "Set output format: csv, json, table or line",
+			description: "Set output format: csv, json, ndjson, md, html, table or line",
+			// NOTE: parquet is a binary format, so is only supported for batch queries
 			args: []metaQueryArg{
 				{value: constants.OutputFormatJSON, description: "Set output to JSON"},
 				{value: constants.OutputFormatCSV, description: "Set output to CSV"},
 				{value: constants.OutputFormatTable, description: "Set output to Table"},
 				{value: constants.OutputFormatLine, description: "Set output to Line"},
+				{value: constants.OutputFormatNDJSON, description: "Set output to newline delimited JSON"},
+				{value: constants.OutputFormatMarkdown, description: "Set output to Markdown"},
+				{value: constants.OutputFormatHTML, description: "Set output to HTML"},
 			},
 			completer: completerFromArgsOf(constants.CmdOutput),
 		},
@@ -155,5 +159,30 @@ func init() {
 			},
 			completer: completerFromArgsOf(constants.CmdAutoComplete),
 		},
+		constants.CmdExport: {
+			title:       constants.CmdExport,
+			handler:     exportLastResult,
+			validator:   composeValidator(exactlyNArgs(1), exportTargetValidator),
+			description: "Export the last query result to a file: csv, json, ndjson, md, html or parquet",
+			completer:   exportCompleter,
+		},
+		constants.CmdEdit: {
+			title:       constants.CmdEdit,
+			handler:     editQuery,
+			validator:   noArgs,
+			description: "Edit the current query (or the last query) in $EDITOR and run it on save",
+		},
+		constants.CmdExplain: {
+			title:       constants.CmdExplain,
+			handler:     explainLastQuery,
+			validator:   noArgs,
+			description: "Show the execution plan of the last query, using EXPLAIN ANALYZE",
+		},
+		constants.CmdHistory: {
+			title:       constants.CmdHistory,
+			handler:     showHistory,
+			validator:   atMostNArgs(1),
+			description: "List the query history, optionally filtered by the given text, or re-run the numbered history entry",
+		},
 	}
 }
@@ -15,8 +15,11 @@ import (
 	"github.com/turbot/steampipe/pkg/cmdconfig"
 	"github.com/turbot/steampipe/pkg/constants"
 	"github.com/turbot/steampipe/pkg/display"
+	"github.com/turbot/steampipe/pkg/query/queryhistory"
+	"github.com/turbot/steampipe/pkg/query/queryresult"
 	"github.com/turbot/steampipe/pkg/schema"
 	"github.com/turbot/steampipe/pkg/steampipeconfig"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
 )
 
 var commonCmds = []string{constants.CmdHelp, constants.CmdInspect, constants.CmdExit}
@@ -38,6 +41,19 @@ type HandlerInput struct {
 	Connections *steampipeconfig.ConnectionDataMap
 	Prompt      *prompt.Prompt
 	ClosePrompt func()
+	// the current multi-line query buffer
+	Buffer      []string
+	ClearBuffer func()
+	// the last query executed - may be nil
+	LastQuery *modconfig.ResolvedQuery
+	// LastResult returns a new copy of the result of the last query - nil if no query has been executed
+	LastResult func() *queryresult.Result
+	// ExecuteQuery executes a query and displays the result (or error)
+	ExecuteQuery func(context.Context, *modconfig.ResolvedQuery)
+	// the interactive query history
+	History *queryhistory.QueryHistory
+	// RunQuery runs a query string (or metaquery) as if it had been entered at the prompt
+	RunQuery func(context.Context, string) error
 }
 type PromptControl interface {
 	Clear()
@@ -0,0 +1,62 @@
+package metaquery
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/display"
+)
+
+// list the query history, optionally filtered by the argument
+// if the argument is a number, re-run the history entry with that number instead
+func showHistory(ctx context.Context, input *HandlerInput) error {
+	entries := input.History.Entries()
+
+	var filter string
+	if args := input.args(); len(args) > 0 {
+		filter = args[0]
+		if n, err := strconv.Atoi(filter); err == nil {
+			return rerunHistoryEntry(ctx, input, n)
+		}
+	}
+
+	header := []string{"#", "timestamp", "duration", "rows", "error", "query"}
+	var rows [][]string
+	for i, entry := range entries {
+		if !entry.Matches(filter) {
+			continue
+		}
+		var timestamp, duration, rowCount string
+		// entries migrated from the legacy history format have no execution details
+		if !entry.Timestamp.IsZero() {
+			timestamp = entry.Timestamp.Local().Format("2006-01-02 15:04:05")
+			duration = (time.Duration(entry.DurationMs) * time.Millisecond).String()
+		}
+		if entry.RowCount != nil {
+			rowCount = strconv.Itoa(*entry.RowCount)
+		}
+		rows = append(rows, []string{strconv.Itoa(i + 1), timestamp, duration, rowCount, entry.Error, entry.Query})
+	}
+	if len(rows) == 0 {
+		fmt.Println("No matching history entries")
+		return nil
+	}
+	display.ShowWrappedTable(header, rows, &display.ShowWrappedTableOptions{Truncate: true})
+	return nil
+}
+
+func rerunHistoryEntry(ctx context.Context, input *HandlerInput, n int) error {
+	entries := input.History.Entries()
+	if n < 1 || n > len(entries) {
+		return fmt.Errorf("history entry %d does not exist - there are %d entries", n, len(entries))
+	}
+	query := entries[n-1].Query
+	if cmd, _ := getCmdAndArgs(query); cmd == constants.CmdHistory {
+		return fmt.Errorf("cannot re-run a %s command", constants.CmdHistory)
+	}
+	fmt.Println(query)
+	return input.RunQuery(ctx, query)
+}
@@ -0,0 +1,133 @@
+package metaquery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/steampipe/pkg/display"
+	"github.com/turbot/steampipe/pkg/export"
+	"github.com/turbot/steampipe/pkg/query"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+const explainPrefix = "EXPLAIN (ANALYZE, FORMAT TEXT) "
+
+// export the result of the last query to a file
+// the argument is either a file name, with the format determined from the extension, or a format name
+func exportLastResult(ctx context.Context, input *HandlerInput) error {
+	result := input.LastResult()
+	if result == nil {
+		return fmt.Errorf("there is no query result to export")
+	}
+
+	target := input.args()[0]
+	// if a format name was given, generate the file name
+	if helpers.StringSliceContains(display.ResultWriterFormats, target) {
+		target = export.GenerateDefaultExportFileName("query", "."+target)
+	}
+
+	exportManager := export.NewManager()
+	for _, e := range query.ResultExporters() {
+		if err := exportManager.Register(e); err != nil {
+			return err
+		}
+	}
+	if err := exportManager.DoExport(ctx, "query", result, []string{target}); err != nil {
+		return err
+	}
+	fmt.Printf("File exported to %s\n", target)
+	return nil
+}
+
+// run the last query wrapped in EXPLAIN ANALYZE
+func explainLastQuery(ctx context.Context, input *HandlerInput) error {
+	lastQuery := input.LastQuery
+	if lastQuery == nil {
+		return fmt.Errorf("there is no query to explain")
+	}
+	sql := strings.TrimSuffix(strings.TrimSpace(lastQuery.ExecuteSQL), ";")
+	// if the last query was itself an explain, just run it again
+	if !strings.HasPrefix(sql, explainPrefix) {
+		sql = explainPrefix + sql
+	}
+	input.ExecuteQuery(ctx, &modconfig.ResolvedQuery{ExecuteSQL: sql, RawSQL: sql, Args: lastQuery.Args})
+	return nil
+}
+
+// open the current query buffer (or the last query if the buffer is empty) in an external editor,
+// and execute the query when the editor exits, if the file was saved
+func editQuery(ctx context.Context, input *HandlerInput) error {
+	text := strings.Join(input.Buffer, "\n")
+	if text == "" && input.LastQuery != nil {
+		text = input.LastQuery.RawSQL
+	}
+
+	edited, saved, err := editInExternalEditor(text)
+	if err != nil {
+		return err
+	}
+	edited = strings.TrimSpace(edited)
+	if !saved || edited == "" {
+		return nil
+	}
+
+	input.ClearBuffer()
+	input.ExecuteQuery(ctx, &modconfig.ResolvedQuery{ExecuteSQL: edited, RawSQL: edited})
+	return nil
+}
+
+// getEditor returns the command used to edit queries - $VISUAL or $EDITOR, defaulting to vi
+func getEditor() []string {
+	for _, envVar := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.Fields(os.Getenv(envVar)); len(editor) > 0 {
+			return editor
+		}
+	}
+	return []string{"vi"}
+}
+
+// write the text to a temporary file, open it in the editor and return the edited text,
+// and whether the file was saved
+func editInExternalEditor(text string) (string, bool, error) {
+	f, err := os.CreateTemp("", "steampipe-query-*.sql")
+	if err != nil {
+		return "", false, err
+	}
+	filePath := f.Name()
+	defer os.Remove(filePath)
+
+	_, err = f.WriteString(text)
+	f.Close()
+	if err != nil {
+		return "", false, err
+	}
+	before, err := os.Stat(filePath)
+	if err != nil {
+		return "", false, err
+	}
+
+	editor := getEditor()
+	cmd := exec.Command(editor[0], append(editor[1:], filePath)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", false, fmt.Errorf("editor '%s' failed: %s", path.Base(editor[0]), err.Error())
+	}
+
+	after, err := os.Stat(filePath)
+	if err != nil {
+		return "", false, err
+	}
+	edited, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", false, err
+	}
+	saved := !after.ModTime().Equal(before.ModTime()) || string(edited) != text
+	return string(edited), saved, nil
+}
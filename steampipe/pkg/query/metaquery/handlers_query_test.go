@@ -0,0 +1,112 @@
+package metaquery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/query/queryresult"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+func TestExportTargetValidator(t *testing.T) {
+	for target, valid := range map[string]bool{
+		"csv":           true,
+		"out.parquet":   true,
+		"dir/out.json":  true,
+		"out.txt":       false,
+		"snapshot":      false,
+		"out.sps":       false,
+		"results.jsonl": false,
+	} {
+		res := exportTargetValidator([]string{target})
+		if (res.Err == nil) != valid {
+			t.Errorf("%s: expected valid=%v, got error %v", target, valid, res.Err)
+		}
+	}
+}
+
+func TestExportLastResult(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "out.ndjson")
+	input := &HandlerInput{
+		Query: ".export " + target,
+		LastResult: func() *queryresult.Result {
+			return queryresult.NewResultFromRows([]*queryresult.ColumnDef{{Name: "id", DataType: "INT8"}}, [][]interface{}{{int64(1)}, {int64(2)}})
+		},
+	}
+	if err := exportLastResult(context.Background(), input); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "{\"id\":1}\n{\"id\":2}\n"; string(data) != expected {
+		t.Errorf("expected %q, got %q", expected, string(data))
+	}
+
+	input.LastResult = func() *queryresult.Result { return nil }
+	if err := exportLastResult(context.Background(), input); err == nil {
+		t.Error("expected error exporting with no result")
+	}
+}
+
+func TestExplainLastQuery(t *testing.T) {
+	var executed []*modconfig.ResolvedQuery
+	input := &HandlerInput{
+		Query:        ".explain",
+		LastQuery:    &modconfig.ResolvedQuery{ExecuteSQL: "select * from t where id = $1;", Args: []any{1}},
+		ExecuteQuery: func(_ context.Context, q *modconfig.ResolvedQuery) { executed = append(executed, q) },
+	}
+	if err := explainLastQuery(context.Background(), input); err != nil {
+		t.Fatal(err)
+	}
+	// explaining an explain should not wrap it again
+	input.LastQuery = executed[0]
+	if err := explainLastQuery(context.Background(), input); err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range executed {
+		if expected := "EXPLAIN (ANALYZE, FORMAT TEXT) select * from t where id = $1"; q.ExecuteSQL != expected || len(q.Args) != 1 {
+			t.Errorf("expected %q with 1 arg, got %q with %d", expected, q.ExecuteSQL, len(q.Args))
+		}
+	}
+
+	input.LastQuery = nil
+	if err := explainLastQuery(context.Background(), input); err == nil {
+		t.Error("expected error explaining with no last query")
+	}
+}
+
+func TestEditQuery(t *testing.T) {
+	t.Setenv("VISUAL", "")
+
+	var executed []string
+	cleared := false
+	input := &HandlerInput{
+		Query:        ".edit",
+		Buffer:       []string{"select 1", "from t"},
+		ClearBuffer:  func() { cleared = true },
+		ExecuteQuery: func(_ context.Context, q *modconfig.ResolvedQuery) { executed = append(executed, q.ExecuteSQL) },
+	}
+
+	// an editor which edits and saves the file
+	t.Setenv("EDITOR", "sed -i s/1/2/")
+	if err := editQuery(context.Background(), input); err != nil {
+		t.Fatal(err)
+	}
+	if len(executed) != 1 || executed[0] != "select 2\nfrom t" || !cleared {
+		t.Errorf("unexpected edit result: executed %v, buffer cleared %v", executed, cleared)
+	}
+
+	// an editor which exits without saving
+	executed, cleared = nil, false
+	t.Setenv("EDITOR", "true")
+	if err := editQuery(context.Background(), input); err != nil {
+		t.Fatal(err)
+	}
+	if len(executed) != 0 || cleared {
+		t.Errorf("expected no query to be executed, got %v", executed)
+	}
+}
@@ -2,11 +2,13 @@ package metaquery
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/steampipe/pkg/cmdconfig"
 	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/display"
 	"github.com/turbot/steampipe/pkg/utils"
 )
 
@@ -179,3 +181,15 @@ func buildValidationResult(val []string, validators []validator) ValidationResul
 		ShouldRun: true,
 	}
 }
+
+// validate the .export argument is either a supported format or a file name with a supported extension
+func exportTargetValidator(args []string) ValidationResult {
+	target := args[0]
+	format := strings.TrimPrefix(path.Ext(target), ".")
+	if helpers.StringSliceContains(display.ResultWriterFormats, target) || helpers.StringSliceContains(display.ResultWriterFormats, format) {
+		return ValidationResult{ShouldRun: true}
+	}
+	return ValidationResult{
+		Err: fmt.Errorf("unsupported export format '%s' - supported formats are %s", target, strings.Join(display.ResultWriterFormats, ", ")),
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/turbot/steampipe/pkg/error_helpers"
 	"github.com/turbot/steampipe/pkg/interactive"
 	"github.com/turbot/steampipe/pkg/query"
+	"github.com/turbot/steampipe/pkg/query/queryresult"
 	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
 	"github.com/turbot/steampipe/pkg/utils"
 )
@@ -48,6 +49,12 @@ func RunBatchSession(ctx context.Context, initData *query.InitData) int {
 	// display any initialisation messages/warnings
 	initData.Result.DisplayMessages()
 
+	// a parquet file holds a single result, so the results of multiple queries cannot be written to stdout
+	if viper.GetString(constants.ArgOutput) == constants.OutputFormatParquet && len(initData.Queries) > 1 {
+		error_helpers.ShowError(ctx, fmt.Errorf("parquet output only supports a single query - run each query separately"))
+		return constants.ExitCodeInsufficientOrWrongArguments
+	}
+
 	failures := 0
 	if len(initData.Queries) > 0 {
 		// if we have resolved any queries, run them
@@ -70,7 +77,7 @@ func executeQueries(ctx context.Context, initData *query.InitData) int {
 
 	for i, name := range queryNames {
 		q := initData.Queries[name]
-		if err := executeQuery(ctx, initData.Client, q); err != nil {
+		if err := executeQuery(ctx, initData, name, q); err != nil {
 			failures++
 			error_helpers.ShowWarning(fmt.Sprintf("executeQueries: query %d of %d failed: %v", i+1, len(queryNames), error_helpers.DecodePgError(err)))
 			// if timing flag is enabled, show the time taken for the query to fail
@@ -88,26 +95,71 @@ func executeQueries(ctx context.Context, initData *query.InitData) int {
 	return failures
 }
 
-func executeQuery(ctx context.Context, client db_common.Client, resolvedQuery *modconfig.ResolvedQuery) error {
+func executeQuery(ctx context.Context, initData *query.InitData, name string, resolvedQuery *modconfig.ResolvedQuery) error {
 	utils.LogTime("query.execute.executeQuery start")
 	defer utils.LogTime("query.execute.executeQuery end")
 
 	// the db executor sends result data over resultsStreamer
-	resultsStreamer, err := db_common.ExecuteQuery(ctx, client, resolvedQuery.ExecuteSQL, resolvedQuery.Args...)
+	resultsStreamer, err := db_common.ExecuteQuery(ctx, initData.Client, resolvedQuery.ExecuteSQL, resolvedQuery.Args...)
 	if err != nil {
 		return err
 	}
 
+	exportArgs := viper.GetStringSlice(constants.ArgExport)
+
 	// print the data as it comes
 	for r := range resultsStreamer.Results {
+		// if we are exporting, capture the rows as they are displayed so they can be exported afterwards
+		var capture *queryresult.ResultCapture
+		if len(exportArgs) > 0 {
+			r, capture = queryresult.CaptureResult(r)
+		}
+
 		display.ShowOutput(ctx, r, display.ShowTimingOnOutput(constants.OutputFormatTable))
+
+		if capture != nil {
+			// the output format may not have read the rows (e.g. 'none') - ensure they are all captured
+			for range *r.RowChan {
+			}
+			if err := exportResult(ctx, initData, name, capture, exportArgs); err != nil {
+				error_helpers.ShowErrorWithMessage(ctx, err, "failed to export query result")
+			}
+		}
 		// signal to the resultStreamer that we are done with this result
 		resultsStreamer.AllResultsRead()
 	}
 	return nil
 }
 
-// if we are displaying csv with no header, do not include lines between the query results
+// exportResult exports the captured result to each export target
+func exportResult(ctx context.Context, initData *query.InitData, name string, capture *queryresult.ResultCapture, exportArgs []string) error {
+	var errors []error
+	for _, exportArg := range exportArgs {
+		// each export needs its own copy of the result to read
+		result := capture.NewResult()
+		if err := initData.ExportManager.DoExport(ctx, exportFileNameRoot(initData, name), result, []string{exportArg}); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	return error_helpers.CombineErrors(errors...)
+}
+
+// exportFileNameRoot returns the root of the default export file name for the query
+// command line queries are keyed by their sql, so use 'query' for these
+func exportFileNameRoot(initData *query.InitData, name string) string {
+	if parsedName, err := modconfig.ParseResourceName(name); err == nil {
+		if _, found := modconfig.GetResource(initData.Workspace, parsedName); found {
+			return name
+		}
+	}
+	return "query"
+}
+
+// if we are displaying csv with no header or ndjson, do not include lines between the query results
 func showBlankLineBetweenResults() bool {
-	return !(viper.GetString(constants.ArgOutput) == "csv" && !viper.GetBool(constants.ArgHeader))
+	output := viper.GetString(constants.ArgOutput)
+	if output == constants.OutputFormatNDJSON {
+		return false
+	}
+	return !(output == "csv" && !viper.GetBool(constants.ArgHeader))
 }
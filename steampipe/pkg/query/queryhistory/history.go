@@ -2,7 +2,6 @@ package queryhistory
 
 import (
 	"encoding/json"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,12 +12,12 @@ import (
 
 // QueryHistory :: struct for working with history in the interactive mode
 type QueryHistory struct {
-	history []string
+	history []*HistoryEntry
 }
 
 // New creates a new QueryHistory object
 func New() (*QueryHistory, error) {
-	history := &QueryHistory{history: []string{}}
+	history := &QueryHistory{history: []*HistoryEntry{}}
 	err := history.load()
 	if err != nil {
 		return nil, err
@@ -26,16 +25,19 @@ func New() (*QueryHistory, error) {
 	return history, nil
 }
 
-// Push adds a string to the history queue trimming to maxHistorySize if necessary
-func (q *QueryHistory) Push(query string) {
+// Push adds a query to the history queue trimming to maxHistorySize if necessary
+// It returns the entry for the query, so the result of the execution can be recorded
+func (q *QueryHistory) Push(query string) *HistoryEntry {
 	if len(strings.TrimSpace(query)) == 0 {
 		// do not store a blank query
-		return
+		return nil
 	}
 
 	// do a strict compare to see if we have this same exact query as the most recent history item
-	if lastElement := q.Peek(); lastElement != nil && (*lastElement) == query {
-		return
+	// if so, reuse the entry for this execution
+	if lastElement := q.Peek(); lastElement != nil && lastElement.Query == query {
+		lastElement.reset()
+		return lastElement
 	}
 
 	// limit the history length to HistorySize
@@ -45,26 +47,37 @@ func (q *QueryHistory) Push(query string) {
 	}
 
 	// append the new entry
-	q.history = append(q.history, query)
+	entry := newHistoryEntry(query)
+	q.history = append(q.history, entry)
+	return entry
 }
 
 // Peek returns the last element of the history stack.
 // returns nil if there is no history
-func (q *QueryHistory) Peek() *string {
+func (q *QueryHistory) Peek() *HistoryEntry {
 	if len(q.history) == 0 {
 		return nil
 	}
-	return &q.history[len(q.history)-1]
+	return q.history[len(q.history)-1]
 }
 
 // Persist writes the history to the filesystem
+// The entries are written to QueryHistoryFile, and the queries alone are written to HistoryFile,
+// so the history is still available to older versions
 func (q *QueryHistory) Persist() error {
+	if err := writeHistoryFile(constants.QueryHistoryFile, q.history); err != nil {
+		return err
+	}
+	return writeHistoryFile(constants.HistoryFile, q.Get())
+}
+
+func writeHistoryFile(filename string, history any) error {
 	var file *os.File
 	var err error
 	defer func() {
 		file.Close()
 	}()
-	path := filepath.Join(filepaths.EnsureInternalDir(), constants.HistoryFile)
+	path := filepath.Join(filepaths.EnsureInternalDir(), filename)
 	file, err = os.Create(path)
 	if err != nil {
 		return err
@@ -75,33 +88,77 @@ func (q *QueryHistory) Persist() error {
 	// disable indentation
 	jsonEncoder.SetIndent("", "")
 
-	return jsonEncoder.Encode(q.history)
+	return jsonEncoder.Encode(history)
 }
 
-// Get returns the full history
+// Get returns the queries of the full history
 func (q *QueryHistory) Get() []string {
+	res := make([]string, len(q.history))
+	for i, entry := range q.history {
+		res[i] = entry.Query
+	}
+	return res
+}
+
+// Entries returns the full history, oldest first
+func (q *QueryHistory) Entries() []*HistoryEntry {
 	return q.history
 }
 
+// Search returns the most recent entry before index 'before' whose query contains the search text (case insensitive),
+// and its index. If there is no match, it returns nil and -1
+func (q *QueryHistory) Search(text string, before int) (*HistoryEntry, int) {
+	if before > len(q.history) {
+		before = len(q.history)
+	}
+	for i := before - 1; i >= 0; i-- {
+		if q.history[i].Matches(text) {
+			return q.history[i], i
+		}
+	}
+	return nil, -1
+}
+
 // loads up the history from the file where it is persisted
+// if there is no QueryHistoryFile, the history is migrated from the legacy HistoryFile
 func (q *QueryHistory) load() error {
-	path := filepath.Join(filepaths.EnsureInternalDir(), constants.HistoryFile)
-	file, err := os.Open(path)
+	data, err := readHistoryFile(constants.QueryHistoryFile)
 	if err != nil {
-		// ignore not exists errors
-		if os.IsNotExist(err) {
-			return nil
-		}
 		return err
+	}
+	if data != nil {
+		return json.Unmarshal(data, &q.history)
+	}
 
+	// the legacy history is a list of query strings
+	data, err = readHistoryFile(constants.HistoryFile)
+	if err != nil || data == nil {
+		return err
 	}
-	defer file.Close()
+	var legacyHistory []string
+	if err := json.Unmarshal(data, &legacyHistory); err != nil {
+		return err
+	}
+	for _, query := range legacyHistory {
+		q.history = append(q.history, &HistoryEntry{Query: query})
+	}
+	return nil
+}
 
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&q.history)
-	// ignore EOF (caused by empty file)
-	if err == io.EOF {
-		return nil
+// readHistoryFile returns the contents of the given history file - or nil if the file does not exist or is empty
+func readHistoryFile(filename string) ([]byte, error) {
+	path := filepath.Join(filepaths.EnsureInternalDir(), filename)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// ignore not exists errors
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// ignore empty file
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil
 	}
-	return err
+	return data, nil
 }
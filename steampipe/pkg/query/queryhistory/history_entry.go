@@ -0,0 +1,49 @@
+package queryhistory
+
+import (
+	"strings"
+	"time"
+)
+
+// HistoryEntry is a query executed in the interactive prompt, along with the outcome of its execution
+// NOTE: entries migrated from the legacy history format only have the query set
+type HistoryEntry struct {
+	Query     string    `json:"query"`
+	Timestamp time.Time `json:"timestamp"`
+	// the execution duration in milliseconds
+	DurationMs int64 `json:"duration_ms,omitempty"`
+	// the number of rows returned - nil for metaqueries and failed queries
+	RowCount  *int   `json:"row_count,omitempty"`
+	Workspace string `json:"workspace,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func newHistoryEntry(query string) *HistoryEntry {
+	return &HistoryEntry{
+		Query:     query,
+		Timestamp: time.Now(),
+	}
+}
+
+// SetResult records the outcome of executing the query
+func (e *HistoryEntry) SetResult(duration time.Duration, rowCount *int, err error) {
+	e.DurationMs = duration.Milliseconds()
+	e.RowCount = rowCount
+	e.Error = ""
+	if err != nil {
+		e.Error = err.Error()
+	}
+}
+
+// Matches returns whether the query contains the given text (case insensitive)
+func (e *HistoryEntry) Matches(text string) bool {
+	return strings.Contains(strings.ToLower(e.Query), strings.ToLower(text))
+}
+
+// clear the execution details so the entry can be reused for a new execution of the same query
+func (e *HistoryEntry) reset() {
+	e.Timestamp = time.Now()
+	e.DurationMs = 0
+	e.RowCount = nil
+	e.Error = ""
+}
@@ -0,0 +1,111 @@
+package queryhistory
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/filepaths"
+)
+
+func setupHistoryDir(t *testing.T) string {
+	prev := filepaths.SteampipeDir
+	filepaths.SteampipeDir = t.TempDir()
+	t.Cleanup(func() { filepaths.SteampipeDir = prev })
+	return filepaths.EnsureInternalDir()
+}
+
+func TestLoadLegacyHistory(t *testing.T) {
+	dir := setupHistoryDir(t)
+	legacyPath := filepath.Join(dir, constants.HistoryFile)
+	if err := os.WriteFile(legacyPath, []byte(`["select 1", ".tables"]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	history, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := history.Entries()
+	if len(entries) != 2 || entries[0].Query != "select 1" || entries[1].Query != ".tables" || !entries[0].Timestamp.IsZero() {
+		t.Fatalf("unexpected migrated history: %v", entries)
+	}
+
+	// the entries are persisted to the new file
+	entry := history.Push("select 2")
+	rowCount := 3
+	entry.SetResult(1500*time.Millisecond, &rowCount, nil)
+	if err := history.Persist(); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := reloaded.Peek()
+	if last.Query != "select 2" || last.DurationMs != 1500 || last.RowCount == nil || *last.RowCount != 3 || last.Timestamp.IsZero() {
+		t.Errorf("unexpected reloaded entry: %+v", last)
+	}
+
+	// the legacy file is still readable by older versions
+	data, err := os.ReadFile(legacyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var legacyHistory []string
+	if err := json.Unmarshal(data, &legacyHistory); err != nil {
+		t.Fatalf("legacy history is not a list of queries: %v", err)
+	}
+	if len(legacyHistory) != 3 || legacyHistory[2] != "select 2" {
+		t.Errorf("unexpected legacy history: %v", legacyHistory)
+	}
+}
+
+func TestPush(t *testing.T) {
+	setupHistoryDir(t)
+	history, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.Push("  ") != nil {
+		t.Error("expected blank query not to be stored")
+	}
+	first := history.Push("select 1")
+	first.SetResult(time.Second, nil, os.ErrNotExist)
+	// pushing the same query again reuses the entry, clearing the previous result
+	if second := history.Push("select 1"); second != first || second.Error != "" || second.DurationMs != 0 {
+		t.Errorf("expected the last entry to be reused and reset, got %+v", second)
+	}
+	if len(history.Get()) != 1 {
+		t.Errorf("expected 1 entry, got %v", history.Get())
+	}
+}
+
+func TestSearch(t *testing.T) {
+	setupHistoryDir(t)
+	history, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []string{"select * from aws_s3_bucket", ".tables", "SELECT name FROM aws_iam_user", "select 1"} {
+		history.Push(q)
+	}
+
+	entry, idx := history.Search("aws", len(history.Entries()))
+	if idx != 2 || entry.Query != "SELECT name FROM aws_iam_user" {
+		t.Errorf("expected most recent match at index 2, got %d", idx)
+	}
+	entry, idx = history.Search("aws", idx)
+	if idx != 0 || entry.Query != "select * from aws_s3_bucket" {
+		t.Errorf("expected next match at index 0, got %d", idx)
+	}
+	if entry, idx = history.Search("aws", idx); entry != nil || idx != -1 {
+		t.Errorf("expected no more matches, got %d", idx)
+	}
+	// search is case insensitive
+	if _, idx = history.Search("FROM AWS_S3", len(history.Entries())); idx != 0 {
+		t.Errorf("expected case insensitive match at index 0, got %d", idx)
+	}
+}
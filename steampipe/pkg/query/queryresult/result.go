@@ -34,6 +34,18 @@ func NewResult(cols []*ColumnDef) *Result {
 	}
 }
 
+// NewResultFromRows returns a result which streams the given rows
+func NewResultFromRows(cols []*ColumnDef, rows [][]interface{}) *Result {
+	res := NewResult(cols)
+	go func() {
+		for _, row := range rows {
+			res.StreamRow(row)
+		}
+		res.Close()
+	}()
+	return res
+}
+
 // IsExportSourceData implements ExportSourceData
 func (*Result) IsExportSourceData() {}
 
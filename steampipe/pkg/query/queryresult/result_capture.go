@@ -0,0 +1,55 @@
+package queryresult
+
+import "time"
+
+// ResultCapture records the rows of a result as they are read, so the result can be replayed
+type ResultCapture struct {
+	Cols []*ColumnDef
+	Rows [][]interface{}
+	// any error streamed with the result
+	Error error
+	// the time the last row was received
+	EndTime time.Time
+}
+
+// CaptureResult returns a result which streams the rows of the given result, recording them as they are read
+// NOTE: the capture is only complete once the returned result has been fully read
+func CaptureResult(result *Result) (*Result, *ResultCapture) {
+	capture := &ResultCapture{Cols: result.Cols}
+	rowChan := make(chan *RowResult)
+	res := &Result{
+		RowChan:      &rowChan,
+		Cols:         result.Cols,
+		TimingResult: result.TimingResult,
+	}
+
+	go func() {
+		defer res.Close()
+		for row := range *result.RowChan {
+			if row.Error != nil {
+				capture.Error = row.Error
+			} else {
+				// copy the row data as the sender may reuse the slice
+				capture.Rows = append(capture.Rows, append([]interface{}{}, row.Data...))
+			}
+			*res.RowChan <- row
+		}
+		capture.EndTime = time.Now()
+	}()
+	return res, capture
+}
+
+// NewResult returns a new result which streams the captured rows, followed by the captured error (if any)
+func (c *ResultCapture) NewResult() *Result {
+	res := NewResult(c.Cols)
+	go func() {
+		for _, row := range c.Rows {
+			res.StreamRow(row)
+		}
+		if c.Error != nil {
+			res.StreamError(c.Error)
+		}
+		res.Close()
+	}()
+	return res
+}
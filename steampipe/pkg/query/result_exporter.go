@@ -0,0 +1,53 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/turbot/steampipe/pkg/display"
+	"github.com/turbot/steampipe/pkg/export"
+	"github.com/turbot/steampipe/pkg/query/queryresult"
+)
+
+// ResultExporter exports a query result using one of the display result writer formats
+type ResultExporter struct {
+	export.ExporterBase
+	format string
+}
+
+func NewResultExporter(format string) *ResultExporter {
+	return &ResultExporter{format: format}
+}
+
+// ResultExporters returns an exporter for each of the display result writer formats
+func ResultExporters() []export.Exporter {
+	res := make([]export.Exporter, len(display.ResultWriterFormats))
+	for i, format := range display.ResultWriterFormats {
+		res[i] = NewResultExporter(format)
+	}
+	return res
+}
+
+func (e *ResultExporter) Export(ctx context.Context, input export.ExportSourceData, filePath string) error {
+	result, ok := input.(*queryresult.Result)
+	if !ok {
+		return fmt.Errorf("ResultExporter input must be *queryresult.Result")
+	}
+
+	destination, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer destination.Close()
+
+	return display.WriteResult(ctx, e.format, destination, result)
+}
+
+func (e *ResultExporter) FileExtension() string {
+	return "." + e.format
+}
+
+func (e *ResultExporter) Name() string {
+	return e.format
+}
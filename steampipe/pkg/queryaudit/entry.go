@@ -0,0 +1,201 @@
+package queryaudit
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Entry is an entry of the query audit log
+//
+// Postgres only logs the number of rows returned by a statement and the search path it was run with
+// in the plans logged by the auto_explain module, so these are only set for statements which have a plan
+// (e.g. not for 'set' statements or failed statements) when the service is started with '--query-audit-plans'
+type Entry struct {
+	Time time.Time `json:"time"`
+	// the client host and port, or '[local]' for a unix socket connection
+	ClientAddress string `json:"client_address,omitempty"`
+	User          string `json:"user,omitempty"`
+	Database      string `json:"database,omitempty"`
+	Application   string `json:"application,omitempty"`
+	SessionId     string `json:"session_id,omitempty"`
+	Command       string `json:"command,omitempty"`
+	Statement     string `json:"statement"`
+	// the duration of the statement - not set for failed statements
+	DurationMs *float64 `json:"duration_ms,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	SqlState   string   `json:"sql_state,omitempty"`
+	SearchPath string   `json:"search_path,omitempty"`
+	Rows       *int64   `json:"rows,omitempty"`
+}
+
+// set the search path and number of rows of the entry from the plan of its statement
+func (e *Entry) setPlan(p *plan) {
+	e.SearchPath = p.searchPath
+	e.Rows = p.rows
+}
+
+// the plan of a statement logged by the auto_explain module
+type plan struct {
+	sessionId string
+	statement string
+	// the number of rows returned by the top level plan node - not set if the statement was not run to completion
+	rows       *int64
+	searchPath string
+}
+
+// the search path used when it is not set - auto_explain only logs settings which differ from this
+const defaultSearchPath = `"$user", public`
+
+// the columns of the Postgres CSV log used by the audit log
+// https://www.postgresql.org/docs/14/runtime-config-logging.html#RUNTIME-CONFIG-LOGGING-CSVLOG
+const (
+	csvLogTime            = 0
+	csvLogUser            = 1
+	csvLogDatabase        = 2
+	csvLogConnectionFrom  = 4
+	csvLogSessionId       = 5
+	csvLogCommandTag      = 7
+	csvLogErrorSeverity   = 11
+	csvLogSqlState        = 12
+	csvLogMessage         = 13
+	csvLogQuery           = 19
+	csvLogApplicationName = 22
+	// the minimum number of columns of a CSV log record
+	csvLogMinColumns = 23
+)
+
+const csvLogTimeFormat = "2006-01-02 15:04:05.000 MST"
+
+// matches the statement messages logged by 'log_min_duration_statement'
+// these are 'statement' for the simple query protocol and 'execute <name>' for the extended query protocol
+// (the parse and bind steps of the extended protocol are also logged - these are ignored)
+var durationStatementRegex = regexp.MustCompile(`(?s)^duration: ([0-9.]+) ms  (?:statement|execute [^:]*): (.*)$`)
+
+// matches the plan messages logged by the auto_explain module in JSON format
+var durationPlanRegex = regexp.MustCompile(`(?s)^duration: [0-9.]+ ms  plan:\n(.*)$`)
+
+// build an audit entry from a record of the Postgres CSV log
+// returns false if the record is not for a statement
+func newEntry(record []string) (*Entry, bool) {
+	if len(record) < csvLogMinColumns {
+		return nil, false
+	}
+
+	entry := &Entry{
+		ClientAddress: record[csvLogConnectionFrom],
+		User:          record[csvLogUser],
+		Database:      record[csvLogDatabase],
+		Application:   record[csvLogApplicationName],
+		SessionId:     record[csvLogSessionId],
+		Command:       record[csvLogCommandTag],
+	}
+
+	switch record[csvLogErrorSeverity] {
+	case "LOG":
+		match := durationStatementRegex.FindStringSubmatch(record[csvLogMessage])
+		if match == nil {
+			return nil, false
+		}
+		duration, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return nil, false
+		}
+		entry.DurationMs = &duration
+		entry.Statement = match[2]
+	case "ERROR", "FATAL", "PANIC":
+		// errors which are not caused by a statement (e.g. authentication failures) are not included
+		if record[csvLogQuery] == "" {
+			return nil, false
+		}
+		entry.Statement = record[csvLogQuery]
+		entry.Error = record[csvLogMessage]
+		entry.SqlState = record[csvLogSqlState]
+	default:
+		return nil, false
+	}
+
+	if t, err := time.Parse(csvLogTimeFormat, record[csvLogTime]); err == nil {
+		entry.Time = t.UTC()
+	}
+	return entry, true
+}
+
+// build a plan from a record of the Postgres CSV log
+// returns false if the record is not a plan logged by the auto_explain module
+func newPlan(record []string) (*plan, bool) {
+	if len(record) < csvLogMinColumns || record[csvLogErrorSeverity] != "LOG" {
+		return nil, false
+	}
+	match := durationPlanRegex.FindStringSubmatch(record[csvLogMessage])
+	if match == nil {
+		return nil, false
+	}
+	var explain struct {
+		QueryText string `json:"Query Text"`
+		Plan      struct {
+			ActualRows *float64 `json:"Actual Rows"`
+		} `json:"Plan"`
+		Settings map[string]string `json:"Settings"`
+	}
+	if err := json.Unmarshal([]byte(match[1]), &explain); err != nil {
+		return nil, false
+	}
+
+	p := &plan{
+		sessionId:  record[csvLogSessionId],
+		statement:  explain.QueryText,
+		searchPath: defaultSearchPath,
+	}
+	if explain.Plan.ActualRows != nil {
+		rows := int64(*explain.Plan.ActualRows)
+		p.rows = &rows
+	}
+	if searchPath, ok := explain.Settings["search_path"]; ok {
+		p.searchPath = searchPath
+	}
+	return p, true
+}
+
+// planMatcher sets the search path and row count of statement entries from the plans of their statements
+//
+// the plan of a statement is logged when the executor finishes, which is before the statement entry is logged
+// for the simple query protocol, but may be after it for the extended query protocol
+type planMatcher struct {
+	// the plan of each session which has not been matched with its statement entry yet
+	plans map[string]*plan
+	// the last statement entry of each session which has not been matched with its plan yet
+	entries map[string]*Entry
+}
+
+func newPlanMatcher() *planMatcher {
+	return &planMatcher{
+		plans:   make(map[string]*plan),
+		entries: make(map[string]*Entry),
+	}
+}
+
+func (m *planMatcher) addPlan(p *plan) {
+	if entry, ok := m.entries[p.sessionId]; ok && entry.Statement == p.statement {
+		entry.setPlan(p)
+		delete(m.entries, p.sessionId)
+		return
+	}
+	m.plans[p.sessionId] = p
+}
+
+func (m *planMatcher) addEntry(entry *Entry) {
+	// failed statements do not have a plan
+	if entry.Error != "" {
+		return
+	}
+	p, ok := m.plans[entry.SessionId]
+	delete(m.plans, entry.SessionId)
+	if ok && p.statement == entry.Statement {
+		entry.setPlan(p)
+		delete(m.entries, entry.SessionId)
+		return
+	}
+	m.entries[entry.SessionId] = entry
+}
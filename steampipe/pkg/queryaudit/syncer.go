@@ -0,0 +1,240 @@
+package queryaudit
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// the database CSV logs written by Postgres
+	csvLogGlob = "database-*.csv"
+	// the audit log files - one file is written for each day
+	auditFilePrefix    = "query-audit-"
+	auditFileExtension = ".jsonl"
+	auditFileDayFormat = "2006-01-02"
+)
+
+// Syncer converts the statement entries of the database CSV logs into the JSONL query audit log
+type Syncer struct {
+	// the directory containing the database CSV logs
+	logDir string
+	// the directory the audit log files are written to
+	auditDir string
+	// the file recording how much of each CSV log has been converted
+	statePath string
+	// the maximum age of audit log files - zero means files are never deleted
+	retention time.Duration
+}
+
+func NewSyncer(logDir, auditDir, statePath string, retention time.Duration) *Syncer {
+	return &Syncer{
+		logDir:    logDir,
+		auditDir:  auditDir,
+		statePath: statePath,
+		retention: retention,
+	}
+}
+
+// Run syncs the audit log at the given interval until the context is cancelled
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Sync(); err != nil {
+			log.Printf("[WARN] query audit sync failed: %s", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync appends any new statement entries of the database CSV logs to the audit log,
+// then deletes audit log files older than the retention period
+func (s *Syncer) Sync() error {
+	offsets, err := s.loadState()
+	if err != nil {
+		return err
+	}
+
+	csvLogs, err := filepath.Glob(filepath.Join(s.logDir, csvLogGlob))
+	if err != nil {
+		return err
+	}
+	// the log names contain the date, so this is the order they were written in
+	sort.Strings(csvLogs)
+
+	// the state of CSV logs which no longer exist is dropped
+	newOffsets := make(map[string]int64, len(csvLogs))
+	for _, csvLog := range csvLogs {
+		name := filepath.Base(csvLog)
+		entries, offset, err := readCsvLog(csvLog, offsets[name])
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %s", name, err.Error())
+		}
+		if err := s.writeEntries(entries); err != nil {
+			return err
+		}
+		newOffsets[name] = offset
+		// save the state after each file so entries are not written twice if a later file fails
+		if err := s.saveState(newOffsets); err != nil {
+			return err
+		}
+	}
+
+	return s.deleteExpiredFiles()
+}
+
+// read the statement entries of the CSV log from the given offset,
+// returning the entries and the offset after the last complete record read
+func readCsvLog(path string, offset int64) ([]*Entry, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, offset, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, offset, err
+	}
+	// if the file is smaller than the offset it has been replaced, so read it from the start
+	if stat.Size() < offset {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, offset, err
+	}
+	// Postgres may be part way through writing a record - only read up to the last line break
+	data = data[:bytes.LastIndexByte(data, '\n')+1]
+
+	var entries []*Entry
+	// plans are only matched with statement entries read at the same time - the plan and the statement entry are
+	// logged together, so they are rarely read by different syncs
+	matcher := newPlanMatcher()
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	readOffset := int64(0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// a line break in a quoted field which has not been completely written yet - read it next time
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				break
+			}
+			return nil, offset, err
+		}
+		readOffset = reader.InputOffset()
+		if entry, ok := newEntry(record); ok {
+			matcher.addEntry(entry)
+			entries = append(entries, entry)
+		} else if p, ok := newPlan(record); ok {
+			matcher.addPlan(p)
+		}
+	}
+	return entries, offset + readOffset, nil
+}
+
+// append the entries to the audit log file for the day of each entry
+func (s *Syncer) writeEntries(entries []*Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	byFile := make(map[string][]byte)
+	var fileNames []string
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		fileName := auditFilePrefix + entry.Time.Format(auditFileDayFormat) + auditFileExtension
+		if _, ok := byFile[fileName]; !ok {
+			fileNames = append(fileNames, fileName)
+		}
+		byFile[fileName] = append(append(byFile[fileName], line...), '\n')
+	}
+
+	for _, fileName := range fileNames {
+		f, err := os.OpenFile(filepath.Join(s.auditDir, fileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(byFile[fileName])
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Syncer) deleteExpiredFiles() error {
+	if s.retention == 0 {
+		return nil
+	}
+	files, err := os.ReadDir(s.auditDir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		name := file.Name()
+		if !strings.HasPrefix(name, auditFilePrefix) || !strings.HasSuffix(name, auditFileExtension) {
+			continue
+		}
+		fi, err := file.Info()
+		if err != nil {
+			continue
+		}
+		if time.Since(fi.ModTime()) > s.retention {
+			if err := os.Remove(filepath.Join(s.auditDir, name)); err != nil {
+				log.Printf("[WARN] failed to delete expired query audit file %s: %s", name, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// load the offset of each CSV log which has been converted, keyed by file name
+func (s *Syncer) loadState() (map[string]int64, error) {
+	offsets := make(map[string]int64)
+	data, err := os.ReadFile(s.statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return offsets, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &offsets); err != nil {
+		return nil, fmt.Errorf("failed to parse query audit state file: %s", err.Error())
+	}
+	return offsets, nil
+}
+
+func (s *Syncer) saveState(offsets map[string]int64) error {
+	data, err := json.Marshal(offsets)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.statePath, data, 0644)
+}
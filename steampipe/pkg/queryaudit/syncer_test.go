@@ -0,0 +1,173 @@
+package queryaudit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// build a Postgres CSV log record
+func csvLogRecord(t *testing.T, severity, sqlState, message, query string) []byte {
+	record := make([]string, 26)
+	record[csvLogTime] = "2022-12-05 10:00:00.123 UTC"
+	record[csvLogUser] = "steampipe"
+	record[csvLogDatabase] = "steampipe"
+	record[csvLogConnectionFrom] = "10.0.0.1:54321"
+	record[csvLogSessionId] = "638dc0a0.1a2b"
+	record[csvLogCommandTag] = "SELECT"
+	record[csvLogErrorSeverity] = severity
+	record[csvLogSqlState] = sqlState
+	record[csvLogMessage] = message
+	record[csvLogQuery] = query
+	record[csvLogApplicationName] = "psql"
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(record); err != nil {
+		t.Fatal(err)
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+func readAuditFile(t *testing.T, path string) []*Entry {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []*Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, &entry)
+	}
+	return entries
+}
+
+func TestSync(t *testing.T) {
+	logDir := t.TempDir()
+	auditDir := t.TempDir()
+	syncer := NewSyncer(logDir, auditDir, filepath.Join(t.TempDir(), "state.json"), 0)
+
+	var log []byte
+	log = append(log, csvLogRecord(t, "LOG", "00000", "connection authorized: user=steampipe database=steampipe", "")...)
+	log = append(log, csvLogRecord(t, "LOG", "00000", "duration: 1.500 ms  statement: select\n  1", "")...)
+	log = append(log, csvLogRecord(t, "LOG", "00000", "duration: 0.010 ms  parse <unnamed>: select $1", "")...)
+	log = append(log, csvLogRecord(t, "LOG", "00000", "duration: 2.000 ms  execute <unnamed>: select $1", "")...)
+	log = append(log, csvLogRecord(t, "ERROR", "42P01", `relation "foo" does not exist`, "select * from foo")...)
+	last := csvLogRecord(t, "LOG", "00000", "duration: 3.000 ms  statement: select\n  2", "")
+
+	// write the log with the last record partially written - this is read by the next sync
+	logPath := filepath.Join(logDir, "database-2022-12-05.csv")
+	split := bytes.IndexByte(last, '\n') + 1
+	if err := os.WriteFile(logPath, append(log, last[:split]...), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := syncer.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	auditPath := filepath.Join(auditDir, "query-audit-2022-12-05.jsonl")
+	entries := readAuditFile(t, auditPath)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if e := entries[0]; e.Statement != "select\n  1" || e.DurationMs == nil || *e.DurationMs != 1.5 || e.ClientAddress != "10.0.0.1:54321" || e.User != "steampipe" || e.Application != "psql" {
+		t.Errorf("unexpected statement entry: %+v", e)
+	}
+	if e := entries[1]; e.Statement != "select $1" || e.DurationMs == nil || *e.DurationMs != 2 {
+		t.Errorf("unexpected execute entry: %+v", e)
+	}
+	if e := entries[2]; e.Statement != "select * from foo" || e.Error != `relation "foo" does not exist` || e.SqlState != "42P01" || e.DurationMs != nil {
+		t.Errorf("unexpected error entry: %+v", e)
+	}
+
+	// complete the last record - only this should be added by the next sync
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(last[split:]); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := syncer.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries = readAuditFile(t, auditPath)
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(entries))
+	}
+	if e := entries[3]; e.Statement != "select\n  2" {
+		t.Errorf("unexpected statement entry: %+v", e)
+	}
+}
+
+func TestSyncPlans(t *testing.T) {
+	logDir := t.TempDir()
+	auditDir := t.TempDir()
+	syncer := NewSyncer(logDir, auditDir, filepath.Join(t.TempDir(), "state.json"), 0)
+
+	simplePlan := `duration: 1.400 ms  plan:
+{
+  "Query Text": "select * from aws_s3_bucket",
+  "Plan": {
+    "Node Type": "Foreign Scan",
+    "Actual Rows": 12,
+    "Actual Loops": 1
+  },
+  "Settings": {
+    "search_path": "public, aws, internal"
+  }
+}`
+	executePlan := `duration: 1.900 ms  plan:
+{
+  "Query Text": "select $1",
+  "Plan": {
+    "Node Type": "Result",
+    "Actual Rows": 1,
+    "Actual Loops": 1
+  }
+}`
+
+	var log []byte
+	// the plan is logged before the statement for the simple query protocol
+	log = append(log, csvLogRecord(t, "LOG", "00000", simplePlan, "")...)
+	log = append(log, csvLogRecord(t, "LOG", "00000", "duration: 1.500 ms  statement: select * from aws_s3_bucket", "")...)
+	// statements which are not planned have no plan
+	log = append(log, csvLogRecord(t, "LOG", "00000", "duration: 0.100 ms  statement: set search_path to public", "")...)
+	// the plan may be logged after the statement for the extended query protocol
+	log = append(log, csvLogRecord(t, "LOG", "00000", "duration: 2.000 ms  execute <unnamed>: select $1", "")...)
+	log = append(log, csvLogRecord(t, "LOG", "00000", executePlan, "")...)
+
+	if err := os.WriteFile(filepath.Join(logDir, "database-2022-12-05.csv"), log, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := syncer.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := readAuditFile(t, filepath.Join(auditDir, "query-audit-2022-12-05.jsonl"))
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if e := entries[0]; e.SearchPath != "public, aws, internal" || e.Rows == nil || *e.Rows != 12 {
+		t.Errorf("unexpected statement entry: %+v", e)
+	}
+	if e := entries[1]; e.SearchPath != "" || e.Rows != nil {
+		t.Errorf("unexpected set entry: %+v", e)
+	}
+	if e := entries[2]; e.SearchPath != defaultSearchPath || e.Rows == nil || *e.Rows != 1 {
+		t.Errorf("unexpected execute entry: %+v", e)
+	}
+}
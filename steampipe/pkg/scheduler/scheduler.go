@@ -0,0 +1,206 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/control"
+	"github.com/turbot/steampipe/pkg/control/controlexecute"
+	"github.com/turbot/steampipe/pkg/db/db_local"
+	"github.com/turbot/steampipe/pkg/query"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+	"github.com/turbot/steampipe/pkg/workspace"
+)
+
+const (
+	RunStatusComplete = "complete"
+	RunStatusError    = "error"
+)
+
+// Scheduler runs the schedules defined in the workspace, recording the outcome of each run
+// in the steampipe_internal.scheduled_run table
+type Scheduler struct {
+	Schedules []*modconfig.Schedule
+	// the variables passed to the service - schedule variables are appended to these for each run
+	serviceVariables []string
+}
+
+// NewScheduler loads the workspace and returns a Scheduler for the schedules it defines
+func NewScheduler(ctx context.Context) (*Scheduler, error) {
+	w, err := workspace.LoadWorkspacePromptingForVariables(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workspace: %s", err.Error())
+	}
+
+	s := &Scheduler{
+		serviceVariables: viper.GetStringSlice(constants.ArgVariable),
+	}
+	for _, schedule := range w.GetResourceMaps().Schedules {
+		s.Schedules = append(s.Schedules, schedule)
+	}
+	if len(s.Schedules) == 0 {
+		return nil, fmt.Errorf("no schedules found in workspace '%s'", w.Path)
+	}
+	sort.Slice(s.Schedules, func(i, j int) bool { return s.Schedules[i].Name() < s.Schedules[j].Name() })
+
+	// ensure the run history table exists
+	if err := db_local.EnsureInternalTables(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ScheduleNames returns the names of all schedules
+func (s *Scheduler) ScheduleNames() []string {
+	res := make([]string, len(s.Schedules))
+	for i, schedule := range s.Schedules {
+		res[i] = schedule.Name()
+	}
+	return res
+}
+
+// Run executes schedules as they become due, until the context is cancelled
+// Schedules are run one at a time - if a run overlaps the next due time of a schedule, that run is skipped
+func (s *Scheduler) Run(ctx context.Context) {
+	nextRun := make(map[string]time.Time, len(s.Schedules))
+	now := time.Now()
+	for _, schedule := range s.Schedules {
+		nextRun[schedule.Name()] = schedule.Expression.Next(now)
+		log.Printf("[INFO] schedule %s next run at %s", schedule.Name(), nextRun[schedule.Name()])
+	}
+
+	for {
+		due := earliest(nextRun)
+		if due.IsZero() {
+			// nothing left to run
+			log.Printf("[INFO] no further scheduled runs")
+			<-ctx.Done()
+			return
+		}
+
+		timer := time.NewTimer(time.Until(due))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		for _, schedule := range s.Schedules {
+			if ctx.Err() != nil {
+				return
+			}
+			name := schedule.Name()
+			if t := nextRun[name]; t.IsZero() || t.After(now) {
+				continue
+			}
+			s.runSchedule(ctx, schedule)
+			nextRun[name] = schedule.Expression.Next(time.Now())
+		}
+	}
+}
+
+func (s *Scheduler) runSchedule(ctx context.Context, schedule *modconfig.Schedule) {
+	log.Printf("[INFO] running schedule %s (%s)", schedule.Name(), schedule.TargetName)
+
+	record := &db_local.ScheduledRunRecord{
+		Schedule:  schedule.Name(),
+		Target:    schedule.TargetName,
+		Exports:   schedule.Export,
+		StartedAt: time.Now(),
+	}
+
+	// set the args for this run - these are read by the check and query initialisation
+	viper.Set(constants.ArgVariable, append(append([]string{}, s.serviceVariables...), schedule.VariableArgs()...))
+	viper.Set(constants.ArgExport, schedule.Export)
+	// query initialisation overrides max-parallel - restore it after the run
+	maxParallel := viper.GetInt(constants.ArgMaxParallel)
+	defer func() {
+		viper.Set(constants.ArgVariable, s.serviceVariables)
+		viper.Set(constants.ArgExport, nil)
+		viper.Set(constants.ArgMaxParallel, maxParallel)
+	}()
+
+	var err error
+	if schedule.TargetType() == modconfig.BlockTypeQuery {
+		record.RowCount, err = runQuery(ctx, schedule)
+	} else {
+		record.Summary, err = runControls(ctx, schedule)
+	}
+	record.CompletedAt = time.Now()
+	record.Status = RunStatusComplete
+	if err != nil {
+		log.Printf("[WARN] schedule %s failed: %s", schedule.Name(), err.Error())
+		record.Status = RunStatusError
+		record.ErrorMessage = err.Error()
+	}
+
+	if err := db_local.InsertScheduledRun(ctx, record); err != nil {
+		log.Printf("[WARN] failed to record run of schedule %s: %s", schedule.Name(), err.Error())
+	}
+}
+
+// run a benchmark or control schedule, returning the summary of the run
+func runControls(ctx context.Context, schedule *modconfig.Schedule) (*controlexecute.GroupSummary, error) {
+	initData := control.NewInitData(ctx)
+	defer initData.Cleanup(ctx)
+	if initData.Result.Error != nil {
+		return nil, initData.Result.Error
+	}
+
+	executionTree, err := controlexecute.NewExecutionTree(ctx, initData.Workspace, initData.Client, schedule.TargetName, initData.ControlFilterWhereClause)
+	if err != nil {
+		return nil, err
+	}
+	executionTree.Execute(ctx)
+
+	if err := initData.ExportManager.DoExport(ctx, schedule.TargetName, executionTree, schedule.Export); err != nil {
+		return executionTree.Root.Summary, err
+	}
+	return executionTree.Root.Summary, nil
+}
+
+// run a query schedule, returning the number of rows returned
+func runQuery(ctx context.Context, schedule *modconfig.Schedule) (int, error) {
+	initData := query.NewInitData(ctx, []string{schedule.TargetName})
+	if initData.Loaded == nil {
+		// initialisation failed before it started
+		return 0, initData.Result.Error
+	}
+	defer initData.Cleanup(ctx)
+
+	<-initData.Loaded
+	if initData.Result.Error != nil {
+		return 0, initData.Result.Error
+	}
+
+	rowCount := 0
+	for _, resolvedQuery := range initData.Queries {
+		result, err := initData.Client.ExecuteSync(ctx, resolvedQuery.ExecuteSQL, resolvedQuery.Args...)
+		if err != nil {
+			return rowCount, err
+		}
+		rowCount += len(result.Rows)
+	}
+	return rowCount, nil
+}
+
+// return the earliest non-zero time
+func earliest(times map[string]time.Time) time.Time {
+	var res time.Time
+	for _, t := range times {
+		if t.IsZero() {
+			continue
+		}
+		if res.IsZero() || t.Before(res) {
+			res = t
+		}
+	}
+	return res
+}
@@ -0,0 +1,198 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/process"
+	"github.com/spf13/viper"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/filepaths"
+	"github.com/turbot/steampipe/pkg/utils"
+)
+
+type ServiceState string
+
+const (
+	ServiceStateRunning       ServiceState = "running"
+	ServiceStateError         ServiceState = "error"
+	ServiceStateStructVersion              = 20221201
+)
+
+type SchedulerServiceState struct {
+	State         ServiceState `json:"state"`
+	Error         string       `json:"error"`
+	Pid           int          `json:"pid"`
+	ModLocation   string       `json:"mod_location"`
+	Schedules     []string     `json:"schedules"`
+	StructVersion int64        `json:"struct_version"`
+}
+
+func loadServiceStateFile() (*SchedulerServiceState, error) {
+	state := &SchedulerServiceState{}
+	stateBytes, err := os.ReadFile(filepaths.SchedulerServiceStateFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	err = json.Unmarshal(stateBytes, state)
+	return state, err
+}
+
+func GetSchedulerServiceState() (*SchedulerServiceState, error) {
+	state, err := loadServiceStateFile()
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, nil
+	}
+	pidExists, err := utils.PidExists(state.Pid)
+	if err != nil {
+		return nil, err
+	}
+	if !pidExists {
+		return nil, os.Remove(filepaths.SchedulerServiceStateFilePath())
+	}
+	return state, nil
+}
+
+func StopSchedulerService(ctx context.Context) error {
+	state, err := GetSchedulerServiceState()
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return nil
+	}
+	process, err := process.NewProcessWithContext(ctx, int32(state.Pid))
+	if err != nil {
+		return err
+	}
+	err = process.SendSignalWithContext(ctx, syscall.SIGINT)
+	if err != nil {
+		return err
+	}
+	return os.Remove(filepaths.SchedulerServiceStateFilePath())
+}
+
+// RunForService spawns an execution of the 'steampipe service scheduler' command.
+// It is used when starting/restarting the steampipe service with the --scheduler flag set
+func RunForService(ctx context.Context, modLocation string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	// remove the state file (if any)
+	os.Remove(filepaths.SchedulerServiceStateFilePath())
+
+	// NOTE: args must be specified <arg>=<arg val>, as each entry in this array is a separate arg passed to cobra
+	args := []string{
+		"service",
+		"scheduler",
+		fmt.Sprintf("--%s=%s", constants.ArgInstallDir, filepaths.SteampipeDir),
+		fmt.Sprintf("--%s=%s", constants.ArgModLocation, modLocation),
+	}
+
+	for _, variableArg := range viper.GetStringSlice(constants.ArgVariable) {
+		args = append(args, fmt.Sprintf("--%s=%s", constants.ArgVariable, variableArg))
+	}
+
+	for _, varFile := range viper.GetStringSlice(constants.ArgVarFile) {
+		args = append(args, fmt.Sprintf("--%s=%s", constants.ArgVarFile, varFile))
+	}
+	cmd := exec.Command(
+		self,
+		args...,
+	)
+	cmd.Env = os.Environ()
+	// run in the mod location, so exports with default file names are written to the workspace
+	cmd.Dir = modLocation
+
+	// set group pgid attributes on the command to ensure the process is not shutdown when its parent terminates
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid:    true,
+		Foreground: false,
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	return waitForSchedulerService(ctx)
+}
+
+// when started as a service, 'steampipe service scheduler' always writes a
+// state file in 'internal' with the outcome - even on failures
+// this function polls for the file and loads up the error, if any
+func waitForSchedulerService(ctx context.Context) error {
+	utils.LogTime("scheduler.waitForSchedulerService start")
+	defer utils.LogTime("scheduler.waitForSchedulerService end")
+
+	pingTimer := time.NewTicker(constants.ServicePingInterval)
+	timeoutAt := time.After(constants.SchedulerServiceStartTimeout)
+	defer pingTimer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-pingTimer.C:
+			// poll for the state file.
+			state, err := loadServiceStateFile()
+			if err != nil {
+				return err
+			}
+			if state == nil {
+				// no state file yet - the scheduler is still loading the workspace
+				continue
+			}
+
+			// check the state file for an error
+			if len(state.Error) > 0 {
+				// there was an error during start up
+				// remove the state file, since we don't need it anymore
+				os.Remove(filepaths.SchedulerServiceStateFilePath())
+				return errors.New(state.Error)
+			}
+			return nil
+		case <-timeoutAt:
+			return fmt.Errorf("scheduler startup timed out")
+		}
+	}
+}
+
+func WriteServiceStateFile(state *SchedulerServiceState) error {
+	// set struct version
+	state.StructVersion = ServiceStateStructVersion
+	stateBytes, err := json.MarshalIndent(state, "", " ")
+	if err != nil {
+		return err
+	}
+	// write to a temporary file and rename it, so that 'waitForSchedulerService' never reads a partially written file
+	statePath := filepaths.SchedulerServiceStateFilePath()
+	tmpFile, err := os.CreateTemp(filepath.Dir(statePath), filepath.Base(statePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	_, err = tmpFile.Write(stateBytes)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), statePath)
+}
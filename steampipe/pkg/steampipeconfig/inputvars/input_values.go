@@ -20,6 +20,9 @@ type InputValue struct {
 	// SourceType is either ValueFromConfig or ValueFromFile. It is not
 	// populated for other source types, and so should not be used.
 	SourceRange tfdiags.SourceRange
+
+	// Sensitive is set if the value was read from a secret, and so must not be displayed
+	Sensitive bool
 }
 
 // ValueSourceType describes what broad category of source location provided
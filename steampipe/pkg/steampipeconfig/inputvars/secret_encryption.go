@@ -0,0 +1,130 @@
+package inputvars
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/filepaths"
+)
+
+// the prefix of an encrypted secret file - this identifies the format in case it changes in future
+const encryptedSecretPrefix = "steampipe-secret:v1:"
+
+// the size of the AES-256 key used to encrypt secrets
+const secretsKeySize = 32
+
+// EncryptSecret encrypts the plaintext using the secrets key, creating the key file if no key exists.
+// The result may be written to a file and referenced in a variable value using 'encrypted://<path>'
+func EncryptSecret(plaintext []byte) ([]byte, error) {
+	key, err := getSecretsKey(true)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newSecretsCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return []byte(encryptedSecretPrefix + base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// DecryptSecret decrypts data created by EncryptSecret
+func DecryptSecret(data []byte) ([]byte, error) {
+	encoded := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(encoded, encryptedSecretPrefix) {
+		return nil, fmt.Errorf("not an encrypted secret file")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encoded, encryptedSecretPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("encrypted secret is not valid: %s", err.Error())
+	}
+
+	key, err := getSecretsKey(false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newSecretsCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted secret is not valid")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret - was it encrypted with a different key?")
+	}
+	return plaintext, nil
+}
+
+func newSecretsCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// get the secrets key - this is read from STEAMPIPE_SECRETS_KEY if set, otherwise from the secrets key file
+// if create is set and there is no key, a key file is created
+func getSecretsKey(create bool) ([]byte, error) {
+	if encodedKey, ok := os.LookupEnv(constants.EnvSecretsKey); ok {
+		key, err := decodeSecretsKey(encodedKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not valid: %s", constants.EnvSecretsKey, err.Error())
+		}
+		return key, nil
+	}
+
+	keyPath := filepaths.SecretsKeyFilePath()
+	if !filehelpers.FileExists(keyPath) {
+		if !create {
+			return nil, fmt.Errorf("no secrets key found - set %s or encrypt a value using 'steampipe variable encrypt'", constants.EnvSecretsKey)
+		}
+		return createSecretsKeyFile(keyPath)
+	}
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	key, err := decodeSecretsKey(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("secrets key file %s is not valid: %s", keyPath, err.Error())
+	}
+	return key, nil
+}
+
+func createSecretsKeyFile(keyPath string) ([]byte, error) {
+	key := make([]byte, secretsKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	// the key file is only readable by the current user
+	if err := os.WriteFile(keyPath, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write secrets key file: %s", err.Error())
+	}
+	return key, nil
+}
+
+func decodeSecretsKey(encodedKey string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedKey))
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != secretsKeySize {
+		return nil, fmt.Errorf("key must be %d bytes, base64 encoded", secretsKeySize)
+	}
+	return key, nil
+}
@@ -0,0 +1,184 @@
+package inputvars
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/tfdiags"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig/var_config"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// the schemes which may be used in a variable value to reference a secret
+const (
+	// file:///path/to/file - the contents of a file
+	SecretSchemeFile = "file://"
+	// env://NAME - the value of an environment variable
+	SecretSchemeEnv = "env://"
+	// exec://command - the output of a command
+	SecretSchemeExec = "exec://"
+	// encrypted:///path/to/file - the decrypted contents of a file created by 'steampipe variable encrypt'
+	SecretSchemeEncrypted = "encrypted://"
+)
+
+var secretSchemes = []string{SecretSchemeFile, SecretSchemeEnv, SecretSchemeExec, SecretSchemeEncrypted}
+
+// SecretEscape is the prefix which escapes a value which would otherwise reference a secret,
+// e.g. `\env://NAME` is the literal value `env://NAME`
+const SecretEscape = `\`
+
+// the maximum time an 'exec://' command may run for
+const secretCommandTimeout = 30 * time.Second
+
+// IsSecretReference returns whether the given variable value references a secret
+func IsSecretReference(value string) bool {
+	return secretScheme(value) != ""
+}
+
+func secretScheme(value string) string {
+	for _, scheme := range secretSchemes {
+		if strings.HasPrefix(value, scheme) {
+			return scheme
+		}
+	}
+	return ""
+}
+
+// secretsAllowedFrom returns whether secret references are resolved in values from the given source.
+// Values from files which are loaded automatically from the mod location may come from an untrusted mod,
+// so they may not reference secrets - otherwise running a cloned mod could run arbitrary commands or
+// read arbitrary files and environment variables
+func secretsAllowedFrom(source ValueSourceType) bool {
+	switch source {
+	case ValueFromCLIArg, ValueFromNamedFile, ValueFromEnvVar, ValueFromInput:
+		return true
+	}
+	return false
+}
+
+// if the input value is a string which references a secret, replace it with the secret value
+// (parsed using the parsing mode of the variable) and mark the value as sensitive
+func resolveSecretValue(name string, val *InputValue, mode var_config.VariableParsingMode) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if !val.Value.IsKnown() || val.Value.IsNull() || !val.Value.Type().Equals(cty.String) {
+		return diags
+	}
+	ref := val.Value.AsString()
+
+	// an escaped secret reference is a literal value
+	if escaped := strings.TrimPrefix(ref, SecretEscape); escaped != ref && IsSecretReference(escaped) {
+		val.Value = cty.StringVal(escaped)
+		return diags
+	}
+	if !IsSecretReference(ref) {
+		return diags
+	}
+	if !secretsAllowedFrom(val.SourceType) {
+		return diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Variable secret not resolved",
+			fmt.Sprintf("The value of variable %q references a secret, but secrets may only be referenced by values set with --var, --var-file or %s environment variables. The value is used as-is.", name, constants.EnvInputVarPrefix),
+		))
+	}
+
+	secret, err := resolveSecretReference(ref)
+	if err != nil {
+		return diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to resolve variable secret",
+			fmt.Sprintf("The value of variable %q could not be read from '%s': %s.", name, ref, err),
+		))
+	}
+
+	parsed, parseDiags := mode.Parse(name, secret)
+	if parseDiags.HasErrors() {
+		// do not include the parse diagnostics as they may contain the secret
+		return diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid variable secret",
+			fmt.Sprintf("The secret referenced by '%s' is not a valid value for variable %q.", ref, name),
+		))
+	}
+	val.Value = parsed
+	val.Sensitive = true
+	return diags
+}
+
+// return the secret referenced by the given value
+func resolveSecretReference(ref string) (string, error) {
+	scheme := secretScheme(ref)
+	location := strings.TrimPrefix(ref, scheme)
+	if location == "" {
+		return "", fmt.Errorf("no secret location specified")
+	}
+
+	switch scheme {
+	case SecretSchemeFile:
+		data, err := os.ReadFile(secretFilePath(location))
+		if err != nil {
+			return "", err
+		}
+		return trimTrailingNewline(string(data)), nil
+	case SecretSchemeEnv:
+		value, ok := os.LookupEnv(location)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", location)
+		}
+		return value, nil
+	case SecretSchemeExec:
+		return execSecretCommand(location)
+	case SecretSchemeEncrypted:
+		data, err := os.ReadFile(secretFilePath(location))
+		if err != nil {
+			return "", err
+		}
+		plaintext, err := DecryptSecret(data)
+		if err != nil {
+			return "", err
+		}
+		return string(plaintext), nil
+	}
+	return "", fmt.Errorf("unsupported secret scheme")
+}
+
+// run the command using the shell, returning its output
+func execSecretCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("command timed out after %s", secretCommandTimeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", err.Error(), msg)
+		}
+		return "", err
+	}
+	return trimTrailingNewline(stdout.String()), nil
+}
+
+// convert the location of a file secret into a path - a leading '~' is expanded to the home directory
+func secretFilePath(location string) string {
+	if location == "~" || strings.HasPrefix(location, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, location[1:])
+		}
+	}
+	return location
+}
+
+func trimTrailingNewline(s string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(s, "\n"), "\r")
+}
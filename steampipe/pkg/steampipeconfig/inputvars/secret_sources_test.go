@@ -0,0 +1,200 @@
+package inputvars
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig/var_config"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestResolveSecretValue(t *testing.T) {
+	key := make([]byte, secretsKeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(constants.EnvSecretsKey, base64.StdEncoding.EncodeToString(key))
+	t.Setenv("TEST_SECRET", "from-env")
+
+	dir := t.TempDir()
+	plainPath := filepath.Join(dir, "plain")
+	if err := os.WriteFile(plainPath, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := EncryptSecret([]byte("from-encrypted-file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedPath := filepath.Join(dir, "encrypted")
+	if err := os.WriteFile(encryptedPath, encrypted, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		value             cty.Value
+		mode              var_config.VariableParsingMode
+		source            ValueSourceType
+		expected          cty.Value
+		expectedSensitive bool
+		expectError       bool
+		expectWarning     bool
+	}{
+		"not a secret": {
+			value:    cty.StringVal("plain value"),
+			mode:     var_config.VariableParseLiteral,
+			expected: cty.StringVal("plain value"),
+		},
+		"not a string": {
+			value:    cty.NumberIntVal(1),
+			mode:     var_config.VariableParseHCL,
+			expected: cty.NumberIntVal(1),
+		},
+		"env": {
+			value:             cty.StringVal("env://TEST_SECRET"),
+			mode:              var_config.VariableParseLiteral,
+			expected:          cty.StringVal("from-env"),
+			expectedSensitive: true,
+		},
+		"env not set": {
+			value:       cty.StringVal("env://TEST_SECRET_NOT_SET"),
+			mode:        var_config.VariableParseLiteral,
+			expectError: true,
+		},
+		"file": {
+			value:             cty.StringVal("file://" + plainPath),
+			mode:              var_config.VariableParseLiteral,
+			expected:          cty.StringVal("from-file"),
+			expectedSensitive: true,
+		},
+		"exec": {
+			value:             cty.StringVal("exec://echo from-exec"),
+			mode:              var_config.VariableParseLiteral,
+			expected:          cty.StringVal("from-exec"),
+			expectedSensitive: true,
+		},
+		"exec failure": {
+			value:       cty.StringVal("exec://exit 1"),
+			mode:        var_config.VariableParseLiteral,
+			expectError: true,
+		},
+		"exec parsed as hcl": {
+			value:             cty.StringVal(`exec://echo '["a", "b"]'`),
+			mode:              var_config.VariableParseHCL,
+			expected:          cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			expectedSensitive: true,
+		},
+		"encrypted file": {
+			value:             cty.StringVal("encrypted://" + encryptedPath),
+			mode:              var_config.VariableParseLiteral,
+			expected:          cty.StringVal("from-encrypted-file"),
+			expectedSensitive: true,
+		},
+		"encrypted file is not encrypted": {
+			value:       cty.StringVal("encrypted://" + plainPath),
+			mode:        var_config.VariableParseLiteral,
+			expectError: true,
+		},
+		"escaped": {
+			value:    cty.StringVal(`\env://TEST_SECRET`),
+			mode:     var_config.VariableParseLiteral,
+			expected: cty.StringVal("env://TEST_SECRET"),
+		},
+		"escaped not a secret": {
+			value:    cty.StringVal(`\plain value`),
+			mode:     var_config.VariableParseLiteral,
+			expected: cty.StringVal(`\plain value`),
+		},
+		"named file": {
+			value:             cty.StringVal("env://TEST_SECRET"),
+			mode:              var_config.VariableParseLiteral,
+			source:            ValueFromNamedFile,
+			expected:          cty.StringVal("from-env"),
+			expectedSensitive: true,
+		},
+		"env var": {
+			value:             cty.StringVal("env://TEST_SECRET"),
+			mode:              var_config.VariableParseLiteral,
+			source:            ValueFromEnvVar,
+			expected:          cty.StringVal("from-env"),
+			expectedSensitive: true,
+		},
+		"exec from auto file": {
+			value:         cty.StringVal("exec://echo from-exec"),
+			mode:          var_config.VariableParseLiteral,
+			source:        ValueFromAutoFile,
+			expected:      cty.StringVal("exec://echo from-exec"),
+			expectWarning: true,
+		},
+		"file from config": {
+			value:         cty.StringVal("file://" + plainPath),
+			mode:          var_config.VariableParseLiteral,
+			source:        ValueFromConfig,
+			expected:      cty.StringVal("file://" + plainPath),
+			expectWarning: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		source := tc.source
+		if source == ValueFromUnknown {
+			source = ValueFromCLIArg
+		}
+		val := &InputValue{Value: tc.value, SourceType: source}
+		diags := resolveSecretValue("v", val, tc.mode)
+		if tc.expectError {
+			if !diags.HasErrors() {
+				t.Errorf("%s: expected an error", name)
+			}
+			continue
+		}
+		if diags.HasErrors() {
+			t.Errorf("%s: unexpected error: %s", name, diags.Err())
+			continue
+		}
+		if !val.Value.RawEquals(tc.expected) {
+			t.Errorf("%s: expected %#v, got %#v", name, tc.expected, val.Value)
+		}
+		if val.Sensitive != tc.expectedSensitive {
+			t.Errorf("%s: expected sensitive %v, got %v", name, tc.expectedSensitive, val.Sensitive)
+		}
+		if hasWarning := len(diags) > 0; hasWarning != tc.expectWarning {
+			t.Errorf("%s: expected warning %v, got %v", name, tc.expectWarning, hasWarning)
+		}
+	}
+}
+
+func TestParseVariableValuesDoesNotResolveUndeclaredSecrets(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "marker")
+	unparsed := map[string]UnparsedVariableValue{
+		"undeclared": unparsedVariableValueString{
+			str:        "exec://touch " + marker,
+			name:       "undeclared",
+			sourceType: ValueFromNamedFile,
+		},
+	}
+
+	ParseVariableValues(unparsed, nil, nil, false)
+
+	if _, err := os.Stat(marker); err == nil {
+		t.Errorf("expected the secret of an undeclared variable not to be resolved")
+	}
+}
+
+func TestDecryptSecretWithDifferentKey(t *testing.T) {
+	t.Setenv(constants.EnvSecretsKey, base64.StdEncoding.EncodeToString(make([]byte, secretsKeySize)))
+	encrypted, err := EncryptSecret([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey := make([]byte, secretsKeySize)
+	otherKey[0] = 1
+	t.Setenv(constants.EnvSecretsKey, base64.StdEncoding.EncodeToString(otherKey))
+	if _, err := DecryptSecret(encrypted); err == nil {
+		t.Errorf("expected decryption with a different key to fail")
+	}
+}
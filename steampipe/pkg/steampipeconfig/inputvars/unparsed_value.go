@@ -106,6 +106,13 @@ func ParseVariableValues(inputValuesUnparsed map[string]UnparsedVariableValue, v
 			continue
 		}
 
+		// if the value references a secret, resolve it
+		secretDiags := resolveSecretValue(name, val, mode)
+		diags = diags.Append(secretDiags)
+		if secretDiags.HasErrors() {
+			continue
+		}
+
 		ret[name] = val
 	}
 
@@ -49,7 +49,16 @@ func GetVariableValues(ctx context.Context, parseCtx *parse.ModParseContext, var
 			inputValue.SourceTypeString(),
 			inputValue.SourceRange)
 
-		// set variable value string in our workspace map
+		// a value read from a secret is sensitive
+		if inputValue.Sensitive {
+			variable.Sensitive = true
+		}
+
+		// set variable value string in our workspace map - this is included in snapshots, so mask sensitive values
+		if variable.Sensitive {
+			variableMap.VariableValues[name] = modconfig.SensitiveValueMask
+			continue
+		}
 		variableMap.VariableValues[name], err = type_conversion.CtyToString(inputValue.Value)
 		if err != nil {
 			return nil, err
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/types"
@@ -37,6 +38,10 @@ type Benchmark struct {
 	Type    *string    `cty:"type" hcl:"type" column:"type,text" json:"-"`
 	Display *string    `cty:"display" hcl:"display" json:"-"`
 
+	// the default timeout and retry count of the descendant controls
+	Timeout *string `cty:"timeout" hcl:"timeout" column:"timeout,text" json:"-"`
+	Retry   *int    `cty:"retry" hcl:"retry" column:"retry,integer" json:"-"`
+
 	References []*ResourceReference `json:"-"`
 	Mod        *Mod                 `cty:"mod" json:"-"`
 	DeclRange  hcl.Range            `json:"-"`
@@ -82,7 +87,12 @@ func (*Benchmark) BlockType() string {
 // OnDecoded implements HclResource
 func (b *Benchmark) OnDecoded(block *hcl.Block, resourceMapProvider ResourceMapsProvider) hcl.Diagnostics {
 	b.setBaseProperties(resourceMapProvider)
-	return nil
+	return validateTimeoutAndRetry(b.FullName, b.Timeout, b.Retry, &b.DeclRange)
+}
+
+// GetTimeout returns the default timeout of the descendant controls, or zero if no timeout is set
+func (b *Benchmark) GetTimeout() time.Duration {
+	return parseTimeout(b.Timeout)
 }
 
 // AddReference implements ResourceWithMetadata
@@ -257,6 +267,14 @@ func (b *Benchmark) Diff(other *Benchmark) *DashboardTreeItemDiffs {
 		res.AddPropertyDiff("Type")
 	}
 
+	if !utils.SafeStringsEqual(b.Timeout, other.Timeout) {
+		res.AddPropertyDiff("Timeout")
+	}
+
+	if !utils.SafeIntEqual(b.Retry, other.Retry) {
+		res.AddPropertyDiff("Retry")
+	}
+
 	if len(b.ChildNameStrings) != len(other.ChildNameStrings) {
 		res.AddPropertyDiff("Childen")
 	} else {
@@ -318,6 +336,14 @@ func (b *Benchmark) setBaseProperties(resourceMapProvider ResourceMapsProvider)
 		b.Display = b.Base.Display
 	}
 
+	if b.Timeout == nil {
+		b.Timeout = b.Base.Timeout
+	}
+
+	if b.Retry == nil {
+		b.Retry = b.Base.Retry
+	}
+
 	b.Tags = utils.MergeMaps(b.Tags, b.Base.Tags)
 	if b.Title == nil {
 		b.Title = b.Base.Title
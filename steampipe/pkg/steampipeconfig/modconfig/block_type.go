@@ -28,11 +28,15 @@ const (
 	BlockTypeLegacyRequires = "requires"
 	BlockTypeCategory       = "category"
 	BlockTypeWith           = "with"
+	BlockTypeExemption      = "exemption"
+	BlockTypeSchedule       = "schedule"
+	BlockTypeTest           = "test"
 
 	// config blocks
 	BlockTypeConnection       = "connection"
 	BlockTypeOptions          = "options"
 	BlockTypeWorkspaceProfile = "workspace"
+	BlockTypeNotifier         = "notifier"
 
 	ResourceTypeSnapshot = "snapshot"
 )
@@ -111,6 +115,9 @@ var ValidResourceItemTypes = []string{
 	BlockTypeOptions,
 	BlockTypeWorkspaceProfile,
 	BlockTypeWith,
+	BlockTypeExemption,
+	BlockTypeSchedule,
+	BlockTypeTest,
 	// local is not an actual block name but is a resource type
 	"local",
 	// references
@@ -24,6 +24,13 @@ func (m ConfigMap) SetIntItem(argValue *int, argName string) {
 	}
 }
 
+// SetBoolItem checks is bool pointer is non-nul and if so, add to map with given key
+func (m ConfigMap) SetBoolItem(argValue *bool, argName string) {
+	if argValue != nil {
+		m[argName] = *argValue
+	}
+}
+
 // PopulateConfigMapForOptions populates the config map for a given options object
 // NOTE: this mutates configMap
 func (m ConfigMap) PopulateConfigMapForOptions(o options.Options) {
@@ -3,6 +3,7 @@ package modconfig
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/types"
@@ -29,6 +30,12 @@ type Control struct {
 	Severity         *string           `cty:"severity" hcl:"severity"  column:"severity,text" json:"severity,omitempty"`
 	Tags             map[string]string `cty:"tags" hcl:"tags,optional"  column:"tags,jsonb" json:"-"`
 	Title            *string           `cty:"title" hcl:"title"  column:"title,text" json:"-"`
+	// the weight of the control in the compliance score of its benchmarks - defaults to 1
+	Weight *int `cty:"weight" hcl:"weight" column:"weight,integer" json:"weight,omitempty"`
+	// the maximum duration of a single attempt to run the control, e.g. "30s" - inherited from the parent benchmark if not set
+	Timeout *string `cty:"timeout" hcl:"timeout" column:"timeout,text" json:"timeout,omitempty"`
+	// the number of times to retry the control if it fails with a retryable error - inherited from the parent benchmark if not set
+	Retry *int `cty:"retry" hcl:"retry" column:"retry,integer" json:"retry,omitempty"`
 
 	// QueryProvider
 	SQL                   *string     `cty:"sql" hcl:"sql" column:"sql,text" json:"sql,omitempty"`
@@ -75,7 +82,10 @@ func (c *Control) Equals(other *Control) bool {
 		typehelpers.SafeString(c.SearchPathPrefix) == typehelpers.SafeString(other.SearchPathPrefix) &&
 		typehelpers.SafeString(c.Severity) == typehelpers.SafeString(other.Severity) &&
 		typehelpers.SafeString(c.SQL) == typehelpers.SafeString(other.SQL) &&
-		typehelpers.SafeString(c.Title) == typehelpers.SafeString(other.Title)
+		typehelpers.SafeString(c.Title) == typehelpers.SafeString(other.Title) &&
+		utils.SafeIntEqual(c.Weight, other.Weight) &&
+		typehelpers.SafeString(c.Timeout) == typehelpers.SafeString(other.Timeout) &&
+		utils.SafeIntEqual(c.Retry, other.Retry)
 	if !res {
 		return res
 	}
@@ -252,10 +262,33 @@ func (c *Control) OnDecoded(block *hcl.Block, resourceMapProvider ResourceMapsPr
 			Subject:  &c.DeclRange,
 		}}
 	}
+	if c.Weight != nil && *c.Weight < 0 {
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("%s has a negative weight - weight must be zero or greater", c.FullName),
+			Subject:  &c.DeclRange,
+		}}
+	}
+	if diags := validateTimeoutAndRetry(c.FullName, c.Timeout, c.Retry, &c.DeclRange); diags.HasErrors() {
+		return diags
+	}
 
 	return nil
 }
 
+// GetWeight returns the weight of the control in the compliance score, defaulting to 1
+func (c *Control) GetWeight() int {
+	if c.Weight == nil {
+		return 1
+	}
+	return *c.Weight
+}
+
+// GetTimeout returns the timeout of the control, or zero if no timeout is set
+func (c *Control) GetTimeout() time.Duration {
+	return parseTimeout(c.Timeout)
+}
+
 // AddReference implements ResourceWithMetadata
 func (c *Control) AddReference(ref *ResourceReference) {
 	c.References = append(c.References, ref)
@@ -375,6 +408,15 @@ func (c *Control) Diff(other *Control) *DashboardTreeItemDiffs {
 	if !utils.SafeStringsEqual(c.Severity, other.Severity) {
 		res.AddPropertyDiff("Severity")
 	}
+	if !utils.SafeIntEqual(c.Weight, other.Weight) {
+		res.AddPropertyDiff("Weight")
+	}
+	if !utils.SafeStringsEqual(c.Timeout, other.Timeout) {
+		res.AddPropertyDiff("Timeout")
+	}
+	if !utils.SafeIntEqual(c.Retry, other.Retry) {
+		res.AddPropertyDiff("Retry")
+	}
 	if len(c.Tags) != len(other.Tags) {
 		res.AddPropertyDiff("Tags")
 	} else {
@@ -416,6 +458,15 @@ func (c *Control) setBaseProperties(resourceMapProvider ResourceMapsProvider) {
 	if c.Severity == nil {
 		c.Severity = c.Base.Severity
 	}
+	if c.Weight == nil {
+		c.Weight = c.Base.Weight
+	}
+	if c.Timeout == nil {
+		c.Timeout = c.Base.Timeout
+	}
+	if c.Retry == nil {
+		c.Retry = c.Base.Retry
+	}
 	if c.SQL == nil {
 		c.SQL = c.Base.SQL
 	}
@@ -443,3 +494,34 @@ func (c *Control) setBaseProperties(resourceMapProvider ResourceMapsProvider) {
 	}
 	c.MergeRuntimeDependencies(c.Base)
 }
+
+// validate the timeout and retry properties of a control or benchmark
+func validateTimeoutAndRetry(name string, timeout *string, retry *int, declRange *hcl.Range) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	if timeout != nil {
+		if d, err := time.ParseDuration(*timeout); err != nil || d <= 0 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("%s has an invalid timeout '%s' - timeout must be a positive duration, e.g. '30s' or '5m'", name, *timeout),
+				Subject:  declRange,
+			})
+		}
+	}
+	if retry != nil && *retry < 0 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("%s has a negative retry count - retry must be zero or greater", name),
+			Subject:  declRange,
+		})
+	}
+	return diags
+}
+
+// parse a validated timeout, returning zero if it is not set
+func parseTimeout(timeout *string) time.Duration {
+	if timeout == nil {
+		return 0
+	}
+	d, _ := time.ParseDuration(*timeout)
+	return d
+}
@@ -0,0 +1,224 @@
+package modconfig
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe/pkg/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// the formats supported for the exemption 'expires' property
+var exemptionExpiryFormats = []string{"2006-01-02", time.RFC3339}
+
+// Exemption is a struct representing an exemption resource
+// An exemption accepts alarms for a set of controls (optionally filtered by resource and dimension values)
+// until an (optional) expiry date
+type Exemption struct {
+	ResourceWithMetadataBase
+
+	ShortName       string `hcl:"name,label" json:"name"`
+	FullName        string `cty:"name" json:"-"`
+	UnqualifiedName string `json:"-"`
+
+	Title       *string           `cty:"title" hcl:"title" column:"title,text" json:"title,omitempty"`
+	Description *string           `cty:"description" hcl:"description" column:"description,text" json:"description,omitempty"`
+	Tags        map[string]string `cty:"tags" hcl:"tags,optional" column:"tags,jsonb" json:"tags,omitempty"`
+	// the controls and benchmarks this exemption applies to
+	Controls NamedItemList `cty:"controls" hcl:"controls" json:"-"`
+	// resource name patterns - '*' matches any sequence of characters
+	Resources []string `cty:"resources" hcl:"resources,optional" column:"resources,jsonb" json:"resources,omitempty"`
+	// dimension values which must ALL match
+	Dimensions map[string]string `cty:"dimensions" hcl:"dimensions,optional" column:"dimensions,jsonb" json:"dimensions,omitempty"`
+	Reason     *string           `cty:"reason" hcl:"reason" column:"reason,text" json:"reason"`
+	Expires    *string           `cty:"expires" hcl:"expires" column:"expires,text" json:"expires,omitempty"`
+
+	// the parsed expiry time
+	ExpiresAt *time.Time `json:"-"`
+	// the names of the targeted controls and benchmarks
+	ControlNames []string `json:"controls"`
+
+	Mod       *Mod      `cty:"mod" json:"-"`
+	DeclRange hcl.Range `json:"-"`
+
+	resourcePatterns []*regexp.Regexp
+}
+
+func NewExemption(block *hcl.Block, mod *Mod, shortName string) HclResource {
+	e := &Exemption{
+		ShortName:       shortName,
+		FullName:        fmt.Sprintf("%s.%s.%s", mod.ShortName, block.Type, shortName),
+		UnqualifiedName: fmt.Sprintf("%s.%s", block.Type, shortName),
+		Mod:             mod,
+		DeclRange:       block.DefRange,
+	}
+	e.SetAnonymous(block)
+	return e
+}
+
+// Name implements HclResource
+// return name in format: '<modname>.exemption.<shortName>'
+func (e *Exemption) Name() string {
+	return e.FullName
+}
+
+// GetUnqualifiedName implements HclResource
+func (e *Exemption) GetUnqualifiedName() string {
+	return e.UnqualifiedName
+}
+
+// GetTitle implements HclResource
+func (e *Exemption) GetTitle() string {
+	return typehelpers.SafeString(e.Title)
+}
+
+// GetDescription implements HclResource
+func (e *Exemption) GetDescription() string {
+	return typehelpers.SafeString(e.Description)
+}
+
+// GetTags implements HclResource
+func (e *Exemption) GetTags() map[string]string {
+	if e.Tags != nil {
+		return e.Tags
+	}
+	return map[string]string{}
+}
+
+// GetReason returns the reason for the exemption
+func (e *Exemption) GetReason() string {
+	return typehelpers.SafeString(e.Reason)
+}
+
+// CtyValue implements HclResource
+func (e *Exemption) CtyValue() (cty.Value, error) {
+	return getCtyValue(e)
+}
+
+// GetDeclRange implements HclResource
+func (e *Exemption) GetDeclRange() *hcl.Range {
+	return &e.DeclRange
+}
+
+// BlockType implements HclResource
+func (*Exemption) BlockType() string {
+	return BlockTypeExemption
+}
+
+// OnDecoded implements HclResource
+func (e *Exemption) OnDecoded(block *hcl.Block, _ ResourceMapsProvider) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	e.ControlNames = e.Controls.StringList()
+	if len(e.ControlNames) == 0 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("%s must specify at least one control or benchmark", e.Name()),
+			Subject:  &e.DeclRange,
+		})
+	}
+
+	if e.Expires != nil {
+		expiresAt, err := parseExemptionExpiry(*e.Expires)
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("%s has invalid 'expires' value '%s'", e.Name(), *e.Expires),
+				Detail:   "expiry must be a date in the format YYYY-MM-DD, or an RFC 3339 timestamp",
+				Subject:  &e.DeclRange,
+			})
+		}
+		e.ExpiresAt = expiresAt
+	}
+
+	e.resourcePatterns = make([]*regexp.Regexp, len(e.Resources))
+	for i, r := range e.Resources {
+		e.resourcePatterns[i] = wildcardToRegexp(r)
+	}
+	return diags
+}
+
+// IsExpired returns whether the exemption has expired at the given time
+func (e *Exemption) IsExpired(now time.Time) bool {
+	return e.ExpiresAt != nil && !now.Before(*e.ExpiresAt)
+}
+
+// AppliesToControl returns whether the exemption targets the given control,
+// either directly or via one of its ancestor benchmarks
+func (e *Exemption) AppliesToControl(control *Control) bool {
+	for _, target := range e.ControlNames {
+		if target == control.Name() {
+			return true
+		}
+		for _, path := range control.GetPaths() {
+			for _, ancestor := range path {
+				if target == ancestor {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// MatchesResult returns whether the given resource and dimension values match the
+// resource patterns and dimension values of the exemption
+func (e *Exemption) MatchesResult(resource string, dimensions map[string]string) bool {
+	if len(e.resourcePatterns) > 0 {
+		matched := false
+		for _, p := range e.resourcePatterns {
+			if p.MatchString(resource) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for k, v := range e.Dimensions {
+		if dimensions[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Exemption) Equals(other *Exemption) bool {
+	if other == nil {
+		return false
+	}
+	return e.FullName == other.FullName &&
+		utils.SafeStringsEqual(e.Title, other.Title) &&
+		utils.SafeStringsEqual(e.Description, other.Description) &&
+		utils.SafeStringsEqual(e.Reason, other.Reason) &&
+		utils.SafeStringsEqual(e.Expires, other.Expires) &&
+		reflect.DeepEqual(e.Tags, other.Tags) &&
+		reflect.DeepEqual(e.ControlNames, other.ControlNames) &&
+		reflect.DeepEqual(e.Resources, other.Resources) &&
+		reflect.DeepEqual(e.Dimensions, other.Dimensions)
+}
+
+func parseExemptionExpiry(expires string) (*time.Time, error) {
+	var err error
+	for _, format := range exemptionExpiryFormats {
+		var t time.Time
+		if t, err = time.Parse(format, expires); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, err
+}
+
+// convert a pattern where '*' matches any sequence of characters into an anchored regular expression
+func wildcardToRegexp(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
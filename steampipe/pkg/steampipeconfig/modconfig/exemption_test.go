@@ -0,0 +1,119 @@
+package modconfig
+
+import (
+	"testing"
+	"time"
+)
+
+type exemptionMatchTest struct {
+	resources  []string
+	dimensions map[string]string
+
+	resource         string
+	resultDimensions map[string]string
+	expected         bool
+}
+
+var testCasesExemptionMatch = map[string]exemptionMatchTest{
+	"no filters": {
+		resource: "arn:aws:s3:::my-bucket",
+		expected: true,
+	},
+	"exact resource": {
+		resources: []string{"arn:aws:s3:::my-bucket"},
+		resource:  "arn:aws:s3:::my-bucket",
+		expected:  true,
+	},
+	"exact resource no match": {
+		resources: []string{"arn:aws:s3:::my-bucket"},
+		resource:  "arn:aws:s3:::my-bucket-2",
+		expected:  false,
+	},
+	"wildcard resource": {
+		resources: []string{"arn:aws:s3:::logs-*"},
+		resource:  "arn:aws:s3:::logs-2022",
+		expected:  true,
+	},
+	"wildcard resource no match": {
+		resources: []string{"arn:aws:s3:::logs-*"},
+		resource:  "arn:aws:s3:::data-2022",
+		expected:  false,
+	},
+	"regex characters are literal": {
+		resources: []string{"arn:aws:s3:::my.bucket"},
+		resource:  "arn:aws:s3:::myxbucket",
+		expected:  false,
+	},
+	"second resource pattern matches": {
+		resources: []string{"foo", "bar*"},
+		resource:  "barbaz",
+		expected:  true,
+	},
+	"dimension match": {
+		dimensions:       map[string]string{"region": "us-east-1"},
+		resource:         "foo",
+		resultDimensions: map[string]string{"region": "us-east-1", "account_id": "123"},
+		expected:         true,
+	},
+	"dimension no match": {
+		dimensions:       map[string]string{"region": "us-east-1", "account_id": "456"},
+		resource:         "foo",
+		resultDimensions: map[string]string{"region": "us-east-1", "account_id": "123"},
+		expected:         false,
+	},
+	"resource and dimension match": {
+		resources:        []string{"f*"},
+		dimensions:       map[string]string{"region": "us-east-1"},
+		resource:         "foo",
+		resultDimensions: map[string]string{"region": "us-east-1"},
+		expected:         true,
+	},
+}
+
+func TestExemptionMatchesResult(t *testing.T) {
+	for name, test := range testCasesExemptionMatch {
+		e := &Exemption{
+			FullName:   "m.exemption.e1",
+			Controls:   NamedItemList{{Name: "m.control.c1"}},
+			Resources:  test.resources,
+			Dimensions: test.dimensions,
+		}
+		if diags := e.OnDecoded(nil, nil); diags.HasErrors() {
+			t.Errorf("Test: '%s'' FAILED : unexpected error %s", name, diags.Error())
+			continue
+		}
+		if actual := e.MatchesResult(test.resource, test.resultDimensions); actual != test.expected {
+			t.Errorf("Test: '%s'' FAILED : expected %v, got %v", name, test.expected, actual)
+		}
+	}
+}
+
+func TestExemptionExpiry(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	testCases := map[string]struct {
+		expires  string
+		expected bool
+	}{
+		"date in past":        {"2022-09-30", true},
+		"date in future":      {"2022-10-02", false},
+		"timestamp in past":   {"2022-10-01T11:00:00Z", true},
+		"timestamp in future": {"2022-10-01T13:00:00Z", false},
+	}
+	for name, test := range testCases {
+		expires := test.expires
+		e := &Exemption{FullName: "m.exemption.e1", Controls: NamedItemList{{Name: "m.control.c1"}}, Expires: &expires}
+		if diags := e.OnDecoded(nil, nil); diags.HasErrors() {
+			t.Errorf("Test: '%s'' FAILED : unexpected error %s", name, diags.Error())
+			continue
+		}
+		if actual := e.IsExpired(now); actual != test.expected {
+			t.Errorf("Test: '%s'' FAILED : expected expired=%v, got %v", name, test.expected, actual)
+		}
+	}
+
+	invalid := "next tuesday"
+	e := &Exemption{FullName: "m.exemption.e1", Controls: NamedItemList{{Name: "m.control.c1"}}, Expires: &invalid}
+	if diags := e.OnDecoded(nil, nil); !diags.HasErrors() {
+		t.Error("expected error for invalid expiry")
+	}
+}
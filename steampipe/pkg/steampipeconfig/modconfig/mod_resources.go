@@ -29,6 +29,9 @@ type ResourceMaps struct {
 	DashboardTables       map[string]*DashboardTable
 	DashboardTexts        map[string]*DashboardText
 	DashboardNodes        map[string]*DashboardNode
+	Exemptions            map[string]*Exemption
+	Schedules             map[string]*Schedule
+	Tests                 map[string]*TestCase
 	GlobalDashboardInputs map[string]*DashboardInput
 	Locals                map[string]*Local
 	Mods                  map[string]*Mod
@@ -70,6 +73,9 @@ func emptyModResources() *ResourceMaps {
 		DashboardTexts:        make(map[string]*DashboardText),
 		DashboardNodes:        make(map[string]*DashboardNode),
 		DashboardCategories:   make(map[string]*DashboardCategory),
+		Exemptions:            make(map[string]*Exemption),
+		Schedules:             make(map[string]*Schedule),
+		Tests:                 make(map[string]*TestCase),
 		GlobalDashboardInputs: make(map[string]*DashboardInput),
 		Locals:                make(map[string]*Local),
 		Mods:                  make(map[string]*Mod),
@@ -152,6 +158,45 @@ func (m *ResourceMaps) Equals(other *ResourceMaps) bool {
 		}
 	}
 
+	for name, exemption := range m.Exemptions {
+		if otherExemption, ok := other.Exemptions[name]; !ok {
+			return false
+		} else if !exemption.Equals(otherExemption) {
+			return false
+		}
+	}
+	for name := range other.Exemptions {
+		if _, ok := m.Exemptions[name]; !ok {
+			return false
+		}
+	}
+
+	for name, schedule := range m.Schedules {
+		if otherSchedule, ok := other.Schedules[name]; !ok {
+			return false
+		} else if !schedule.Equals(otherSchedule) {
+			return false
+		}
+	}
+	for name := range other.Schedules {
+		if _, ok := m.Schedules[name]; !ok {
+			return false
+		}
+	}
+
+	for name, test := range m.Tests {
+		if otherTest, ok := other.Tests[name]; !ok {
+			return false
+		} else if !test.Equals(otherTest) {
+			return false
+		}
+	}
+	for name := range other.Tests {
+		if _, ok := m.Tests[name]; !ok {
+			return false
+		}
+	}
+
 	for name, variable := range m.Variables {
 		if otherVariable, ok := other.Variables[name]; !ok {
 			return false
@@ -411,6 +456,9 @@ func (m *ResourceMaps) Empty() bool {
 		len(m.DashboardInputs)+
 		len(m.DashboardTables)+
 		len(m.DashboardTexts)+
+		len(m.Exemptions)+
+		len(m.Schedules)+
+		len(m.Tests)+
 		len(m.References) == 0
 }
 
@@ -513,6 +561,21 @@ func (m *ResourceMaps) WalkResources(resourceFunc func(item HclResource) (bool,
 			return err
 		}
 	}
+	for _, r := range m.Exemptions {
+		if continueWalking, err := resourceFunc(r); err != nil || !continueWalking {
+			return err
+		}
+	}
+	for _, r := range m.Schedules {
+		if continueWalking, err := resourceFunc(r); err != nil || !continueWalking {
+			return err
+		}
+	}
+	for _, r := range m.Tests {
+		if continueWalking, err := resourceFunc(r); err != nil || !continueWalking {
+			return err
+		}
+	}
 	for _, r := range m.GlobalDashboardInputs {
 		if continueWalking, err := resourceFunc(r); err != nil || !continueWalking {
 			return err
@@ -690,6 +753,30 @@ func (m *ResourceMaps) AddResource(item HclResource) hcl.Diagnostics {
 		}
 		m.DashboardTexts[name] = r
 
+	case *Exemption:
+		name := r.Name()
+		if existing, ok := m.Exemptions[name]; ok {
+			diags = append(diags, checkForDuplicate(existing, item)...)
+			break
+		}
+		m.Exemptions[name] = r
+
+	case *Schedule:
+		name := r.Name()
+		if existing, ok := m.Schedules[name]; ok {
+			diags = append(diags, checkForDuplicate(existing, item)...)
+			break
+		}
+		m.Schedules[name] = r
+
+	case *TestCase:
+		name := r.Name()
+		if existing, ok := m.Tests[name]; ok {
+			diags = append(diags, checkForDuplicate(existing, item)...)
+			break
+		}
+		m.Tests[name] = r
+
 	case *Variable:
 		// NOTE: add variable by unqualified name
 		name := r.UnqualifiedName
@@ -771,6 +858,15 @@ func (m *ResourceMaps) Merge(others []*ResourceMaps) *ResourceMaps {
 		for k, v := range source.DashboardTexts {
 			res.DashboardTexts[k] = v
 		}
+		for k, v := range source.Exemptions {
+			res.Exemptions[k] = v
+		}
+		for k, v := range source.Schedules {
+			res.Schedules[k] = v
+		}
+		for k, v := range source.Tests {
+			res.Tests[k] = v
+		}
 		for k, v := range source.GlobalDashboardInputs {
 			res.GlobalDashboardInputs[k] = v
 		}
@@ -0,0 +1,115 @@
+package modconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
+)
+
+// supported notifier types
+const (
+	NotifierTypeSlack   = "slack"
+	NotifierTypeTeams   = "teams"
+	NotifierTypeWebhook = "webhook"
+	NotifierTypeEmail   = "email"
+)
+
+// control severities, in ascending order
+var notifierSeverities = []string{"low", "medium", "high", "critical"}
+
+// Notifier is a struct representing a notifier block in a workspace profile
+// A notifier sends a summary of check results (and the alarm rows) to a chat service, webhook or email recipients
+type Notifier struct {
+	Name string `hcl:"name,label"`
+	Type string `hcl:"type"`
+	// the minimum severity of alarm which will trigger a notification - if not set, all alarms are notified
+	Severity *string `hcl:"severity,optional"`
+
+	// slack, teams and webhook properties
+	Url     *string           `hcl:"url,optional"`
+	Headers map[string]string `hcl:"headers,optional"`
+
+	// email properties
+	SmtpHost     *string  `hcl:"smtp_host,optional"`
+	SmtpPort     *int     `hcl:"smtp_port,optional"`
+	SmtpUsername *string  `hcl:"smtp_username,optional"`
+	SmtpPassword *string  `hcl:"smtp_password,optional"`
+	From         *string  `hcl:"from,optional"`
+	To           []string `hcl:"to,optional"`
+	Subject      *string  `hcl:"subject,optional"`
+
+	DeclRange hcl.Range
+}
+
+func NewNotifier(block *hcl.Block) *Notifier {
+	return &Notifier{
+		Name:      block.Labels[0],
+		DeclRange: block.DefRange,
+	}
+}
+
+// Validate checks the notifier has the properties required for its type
+func (n *Notifier) Validate() hcl.Diagnostics {
+	var errors []string
+	switch n.Type {
+	case NotifierTypeSlack, NotifierTypeTeams, NotifierTypeWebhook:
+		if n.Url == nil {
+			errors = append(errors, fmt.Sprintf("'url' must be set for %s notifiers", n.Type))
+		}
+	case NotifierTypeEmail:
+		if n.SmtpHost == nil {
+			errors = append(errors, "'smtp_host' must be set for email notifiers")
+		}
+		if n.From == nil {
+			errors = append(errors, "'from' must be set for email notifiers")
+		}
+		if len(n.To) == 0 {
+			errors = append(errors, "'to' must be set for email notifiers")
+		}
+	default:
+		errors = append(errors, fmt.Sprintf("invalid type '%s' - must be one of %s", n.Type, strings.Join([]string{NotifierTypeSlack, NotifierTypeTeams, NotifierTypeWebhook, NotifierTypeEmail}, ", ")))
+	}
+	if n.Severity != nil && !helpers.StringSliceContains(notifierSeverities, *n.Severity) {
+		errors = append(errors, fmt.Sprintf("invalid severity '%s' - must be one of %s", *n.Severity, strings.Join(notifierSeverities, ", ")))
+	}
+
+	var diags hcl.Diagnostics
+	for _, e := range errors {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("notifier '%s': %s", n.Name, e),
+			Subject:  &n.DeclRange,
+		})
+	}
+	return diags
+}
+
+// MeetsSeverityThreshold returns whether an alarm with the given severity should be notified
+// alarms with no (or an unknown) severity are only notified if the notifier has no severity threshold
+func (n *Notifier) MeetsSeverityThreshold(severity string) bool {
+	if n.Severity == nil {
+		return true
+	}
+	return severityIndex(severity) >= severityIndex(*n.Severity)
+}
+
+// SmtpAddress returns the address of the SMTP server, defaulting the port to 25
+func (n *Notifier) SmtpAddress() string {
+	port := 25
+	if n.SmtpPort != nil {
+		port = *n.SmtpPort
+	}
+	return fmt.Sprintf("%s:%d", typehelpers.SafeString(n.SmtpHost), port)
+}
+
+func severityIndex(severity string) int {
+	for i, s := range notifierSeverities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
@@ -45,6 +45,12 @@ func GetResource(provider ResourceMapsProvider, parsedName *ParsedResourceName)
 		resource, found = resourceMaps.DashboardTables[longName]
 	case BlockTypeText:
 		resource, found = resourceMaps.DashboardTexts[longName]
+	case BlockTypeExemption:
+		resource, found = resourceMaps.Exemptions[longName]
+	case BlockTypeSchedule:
+		resource, found = resourceMaps.Schedules[longName]
+	case BlockTypeTest:
+		resource, found = resourceMaps.Tests[longName]
 	case BlockTypeInput:
 		// this function only supports global inputs
 		// if the input has a parent dashboard, you must use GetDashboardInput
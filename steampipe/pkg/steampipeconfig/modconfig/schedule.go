@@ -0,0 +1,171 @@
+package modconfig
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/hashicorp/hcl/v2"
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe/pkg/cron"
+	"github.com/turbot/steampipe/pkg/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Schedule is a struct representing a schedule resource
+// A schedule runs a benchmark, control or query on a cron schedule when the service scheduler is running,
+// optionally exporting the results to the given export targets
+type Schedule struct {
+	ResourceWithMetadataBase
+
+	ShortName       string `hcl:"name,label" json:"name"`
+	FullName        string `cty:"name" json:"-"`
+	UnqualifiedName string `json:"-"`
+
+	Title       *string           `cty:"title" hcl:"title" column:"title,text" json:"title,omitempty"`
+	Description *string           `cty:"description" hcl:"description" column:"description,text" json:"description,omitempty"`
+	Tags        map[string]string `cty:"tags" hcl:"tags,optional" column:"tags,jsonb" json:"tags,omitempty"`
+	Cron        string            `cty:"cron" hcl:"cron" column:"cron,text" json:"cron"`
+	// the benchmark, control or query to run
+	Target *NamedItem `cty:"target" hcl:"target" json:"-"`
+	// export targets, in the same format as the --export arg
+	Export []string `cty:"export" hcl:"export,optional" column:"export,jsonb" json:"export,omitempty"`
+	// variable values to use for the run, keyed by variable name
+	Variables map[string]string `cty:"variables" hcl:"variables,optional" column:"variables,jsonb" json:"variables,omitempty"`
+
+	// the name of the target resource
+	TargetName string `json:"target"`
+	// the parsed cron expression
+	Expression *cron.Expression `json:"-"`
+
+	Mod       *Mod      `cty:"mod" json:"-"`
+	DeclRange hcl.Range `json:"-"`
+}
+
+func NewSchedule(block *hcl.Block, mod *Mod, shortName string) HclResource {
+	s := &Schedule{
+		ShortName:       shortName,
+		FullName:        fmt.Sprintf("%s.%s.%s", mod.ShortName, block.Type, shortName),
+		UnqualifiedName: fmt.Sprintf("%s.%s", block.Type, shortName),
+		Mod:             mod,
+		DeclRange:       block.DefRange,
+	}
+	s.SetAnonymous(block)
+	return s
+}
+
+// Name implements HclResource
+// return name in format: '<modname>.schedule.<shortName>'
+func (s *Schedule) Name() string {
+	return s.FullName
+}
+
+// GetUnqualifiedName implements HclResource
+func (s *Schedule) GetUnqualifiedName() string {
+	return s.UnqualifiedName
+}
+
+// GetTitle implements HclResource
+func (s *Schedule) GetTitle() string {
+	return typehelpers.SafeString(s.Title)
+}
+
+// GetDescription implements HclResource
+func (s *Schedule) GetDescription() string {
+	return typehelpers.SafeString(s.Description)
+}
+
+// GetTags implements HclResource
+func (s *Schedule) GetTags() map[string]string {
+	if s.Tags != nil {
+		return s.Tags
+	}
+	return map[string]string{}
+}
+
+// CtyValue implements HclResource
+func (s *Schedule) CtyValue() (cty.Value, error) {
+	return getCtyValue(s)
+}
+
+// GetDeclRange implements HclResource
+func (s *Schedule) GetDeclRange() *hcl.Range {
+	return &s.DeclRange
+}
+
+// BlockType implements HclResource
+func (*Schedule) BlockType() string {
+	return BlockTypeSchedule
+}
+
+// OnDecoded implements HclResource
+func (s *Schedule) OnDecoded(block *hcl.Block, _ ResourceMapsProvider) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	expression, err := cron.Parse(s.Cron)
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("%s has invalid 'cron' value", s.Name()),
+			Detail:   err.Error(),
+			Subject:  &s.DeclRange,
+		})
+	}
+	s.Expression = expression
+
+	if s.Target != nil {
+		s.TargetName = s.Target.Name
+	}
+	targetType := s.TargetType()
+	switch targetType {
+	case BlockTypeBenchmark, BlockTypeControl:
+	case BlockTypeQuery:
+		// query results may only be exported as snapshots, which is not supported for scheduled runs
+		if len(s.Export) > 0 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("%s has a query target - 'export' is only supported for benchmark and control targets", s.Name()),
+				Subject:  &s.DeclRange,
+			})
+		}
+	default:
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("%s has invalid target '%s' - target must be a benchmark, control or query", s.Name(), s.TargetName),
+			Subject:  &s.DeclRange,
+		})
+	}
+
+	return diags
+}
+
+// TargetType returns the block type of the target
+func (s *Schedule) TargetType() string {
+	parsedName, err := ParseResourceName(s.TargetName)
+	if err != nil {
+		return ""
+	}
+	return parsedName.ItemType
+}
+
+// VariableArgs returns the schedule variables in the format used by the --var arg
+func (s *Schedule) VariableArgs() []string {
+	res := make([]string, 0, len(s.Variables))
+	for k, v := range s.Variables {
+		res = append(res, fmt.Sprintf("%s=%s", k, v))
+	}
+	return res
+}
+
+func (s *Schedule) Equals(other *Schedule) bool {
+	if other == nil {
+		return false
+	}
+	return s.FullName == other.FullName &&
+		s.Cron == other.Cron &&
+		s.TargetName == other.TargetName &&
+		utils.SafeStringsEqual(s.Title, other.Title) &&
+		utils.SafeStringsEqual(s.Description, other.Description) &&
+		reflect.DeepEqual(s.Tags, other.Tags) &&
+		reflect.DeepEqual(s.Export, other.Export) &&
+		reflect.DeepEqual(s.Variables, other.Variables)
+}
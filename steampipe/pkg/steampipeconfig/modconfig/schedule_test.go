@@ -0,0 +1,63 @@
+package modconfig
+
+import (
+	"testing"
+)
+
+type scheduleDecodeTest struct {
+	cron      string
+	target    string
+	export    []string
+	expectErr bool
+}
+
+var testCasesScheduleDecode = map[string]scheduleDecodeTest{
+	"benchmark with export": {
+		cron:   "0 2 * * *",
+		target: "m.benchmark.b1",
+		export: []string{"csv", "json"},
+	},
+	"control": {
+		cron:   "@hourly",
+		target: "m.control.c1",
+	},
+	"query": {
+		cron:   "*/5 * * * *",
+		target: "m.query.q1",
+	},
+	"query with export": {
+		cron:      "*/5 * * * *",
+		target:    "m.query.q1",
+		export:    []string{"csv"},
+		expectErr: true,
+	},
+	"dashboard target": {
+		cron:      "0 2 * * *",
+		target:    "m.dashboard.d1",
+		expectErr: true,
+	},
+	"invalid cron": {
+		cron:      "0 25 * * *",
+		target:    "m.benchmark.b1",
+		expectErr: true,
+	},
+}
+
+func TestScheduleOnDecoded(t *testing.T) {
+	for name, test := range testCasesScheduleDecode {
+		s := &Schedule{
+			FullName: "m.schedule.s1",
+			Cron:     test.cron,
+			Target:   &NamedItem{Name: test.target},
+			Export:   test.export,
+		}
+		diags := s.OnDecoded(nil, nil)
+		if diags.HasErrors() != test.expectErr {
+			t.Errorf("Test: '%s'' FAILED : expected error %v, got %v", name, test.expectErr, diags.Error())
+			continue
+		}
+		if !test.expectErr && s.TargetName != test.target {
+			t.Errorf("Test: '%s'' FAILED : expected target %s, got %s", name, test.target, s.TargetName)
+		}
+	}
+}
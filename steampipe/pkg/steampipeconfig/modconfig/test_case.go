@@ -0,0 +1,203 @@
+package modconfig
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe/pkg/constants"
+	"github.com/turbot/steampipe/pkg/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// TestCase is a struct representing a test resource
+// A test loads fixture data into scratch tables, runs a benchmark, control or query against them and
+// asserts the expected control statuses or query rows
+type TestCase struct {
+	ResourceWithMetadataBase
+
+	ShortName       string `hcl:"name,label" json:"name"`
+	FullName        string `cty:"name" json:"-"`
+	UnqualifiedName string `json:"-"`
+
+	Title       *string           `cty:"title" hcl:"title" column:"title,text" json:"title,omitempty"`
+	Description *string           `cty:"description" hcl:"description" column:"description,text" json:"description,omitempty"`
+	Tags        map[string]string `cty:"tags" hcl:"tags,optional" column:"tags,jsonb" json:"tags,omitempty"`
+	// the benchmark, control or query to run
+	Target *NamedItem `cty:"target" hcl:"target" json:"-"`
+	// the fixture data files (csv or json), keyed by table name - paths are relative to the mod location
+	Fixtures map[string]string `cty:"fixtures" hcl:"fixtures,optional" column:"fixtures,jsonb" json:"fixtures,omitempty"`
+
+	// benchmark and control targets: the expected status of each resource, keyed by resource
+	ExpectedStatuses map[string]string `cty:"expected_statuses" hcl:"expected_statuses,optional" column:"expected_statuses,jsonb" json:"expected_statuses,omitempty"`
+	// benchmark and control targets: the expected number of results with each status, keyed by status
+	ExpectedSummary map[string]int `cty:"expected_summary" hcl:"expected_summary,optional" column:"expected_summary,jsonb" json:"expected_summary,omitempty"`
+	// query targets: a file (csv or json) containing the expected rows - path is relative to the mod location
+	ExpectedRows *string `cty:"expected_rows" hcl:"expected_rows" column:"expected_rows,text" json:"expected_rows,omitempty"`
+	// query targets: the expected number of rows
+	ExpectedRowCount *int `cty:"expected_row_count" hcl:"expected_row_count" column:"expected_row_count,integer" json:"expected_row_count,omitempty"`
+
+	// the name of the target resource
+	TargetName string `json:"target"`
+
+	Mod       *Mod      `cty:"mod" json:"-"`
+	DeclRange hcl.Range `json:"-"`
+}
+
+var validTestStatuses = []string{
+	constants.ControlOk,
+	constants.ControlAlarm,
+	constants.ControlInfo,
+	constants.ControlSkip,
+	constants.ControlError,
+	constants.ControlExempt,
+}
+
+func NewTestCase(block *hcl.Block, mod *Mod, shortName string) HclResource {
+	t := &TestCase{
+		ShortName:       shortName,
+		FullName:        fmt.Sprintf("%s.%s.%s", mod.ShortName, block.Type, shortName),
+		UnqualifiedName: fmt.Sprintf("%s.%s", block.Type, shortName),
+		Mod:             mod,
+		DeclRange:       block.DefRange,
+	}
+	t.SetAnonymous(block)
+	return t
+}
+
+// Name implements HclResource
+// return name in format: '<modname>.test.<shortName>'
+func (t *TestCase) Name() string {
+	return t.FullName
+}
+
+// GetUnqualifiedName implements HclResource
+func (t *TestCase) GetUnqualifiedName() string {
+	return t.UnqualifiedName
+}
+
+// GetTitle implements HclResource
+func (t *TestCase) GetTitle() string {
+	return typehelpers.SafeString(t.Title)
+}
+
+// GetDescription implements HclResource
+func (t *TestCase) GetDescription() string {
+	return typehelpers.SafeString(t.Description)
+}
+
+// GetTags implements HclResource
+func (t *TestCase) GetTags() map[string]string {
+	if t.Tags != nil {
+		return t.Tags
+	}
+	return map[string]string{}
+}
+
+// CtyValue implements HclResource
+func (t *TestCase) CtyValue() (cty.Value, error) {
+	return getCtyValue(t)
+}
+
+// GetDeclRange implements HclResource
+func (t *TestCase) GetDeclRange() *hcl.Range {
+	return &t.DeclRange
+}
+
+// BlockType implements HclResource
+func (*TestCase) BlockType() string {
+	return BlockTypeTest
+}
+
+// OnDecoded implements HclResource
+func (t *TestCase) OnDecoded(block *hcl.Block, _ ResourceMapsProvider) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	addError := func(format string, a ...any) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("%s %s", t.Name(), fmt.Sprintf(format, a...)),
+			Subject:  &t.DeclRange,
+		})
+	}
+
+	if t.Target != nil {
+		t.TargetName = t.Target.Name
+	}
+
+	// fixture tables are created in a scratch schema, so must not be schema qualified
+	for table := range t.Fixtures {
+		if strings.Contains(table, ".") {
+			addError("has invalid fixture table name '%s' - fixture tables must not be schema qualified", table)
+		}
+	}
+
+	hasControlExpectations := len(t.ExpectedStatuses)+len(t.ExpectedSummary) > 0
+	hasQueryExpectations := t.ExpectedRows != nil || t.ExpectedRowCount != nil
+	switch t.TargetType() {
+	case BlockTypeBenchmark, BlockTypeControl:
+		if hasQueryExpectations {
+			addError("has a %s target - 'expected_rows' and 'expected_row_count' are only supported for query targets", t.TargetType())
+		}
+		if !hasControlExpectations {
+			addError("must specify 'expected_statuses' or 'expected_summary'")
+		}
+		for resource, status := range t.ExpectedStatuses {
+			if !helpers.StringSliceContains(validTestStatuses, status) {
+				addError("has invalid expected status '%s' for resource '%s' - status must be one of %s", status, resource, strings.Join(validTestStatuses, ", "))
+			}
+		}
+		for status := range t.ExpectedSummary {
+			if !helpers.StringSliceContains(validTestStatuses, status) {
+				addError("has invalid 'expected_summary' status '%s' - status must be one of %s", status, strings.Join(validTestStatuses, ", "))
+			}
+		}
+	case BlockTypeQuery:
+		if hasControlExpectations {
+			addError("has a query target - 'expected_statuses' and 'expected_summary' are only supported for benchmark and control targets")
+		}
+		if !hasQueryExpectations {
+			addError("must specify 'expected_rows' or 'expected_row_count'")
+		}
+	default:
+		addError("has invalid target '%s' - target must be a benchmark, control or query", t.TargetName)
+	}
+
+	return diags
+}
+
+// TargetType returns the block type of the target
+func (t *TestCase) TargetType() string {
+	parsedName, err := ParseResourceName(t.TargetName)
+	if err != nil {
+		return ""
+	}
+	return parsedName.ItemType
+}
+
+// FilePath returns the path of a fixture or expected rows file - relative paths are resolved from the mod location
+func (t *TestCase) FilePath(path string) string {
+	if filepath.IsAbs(path) || t.Mod == nil {
+		return path
+	}
+	return filepath.Join(t.Mod.ModPath, path)
+}
+
+func (t *TestCase) Equals(other *TestCase) bool {
+	if other == nil {
+		return false
+	}
+	return t.FullName == other.FullName &&
+		t.TargetName == other.TargetName &&
+		utils.SafeStringsEqual(t.Title, other.Title) &&
+		utils.SafeStringsEqual(t.Description, other.Description) &&
+		utils.SafeStringsEqual(t.ExpectedRows, other.ExpectedRows) &&
+		utils.SafeIntEqual(t.ExpectedRowCount, other.ExpectedRowCount) &&
+		reflect.DeepEqual(t.Tags, other.Tags) &&
+		reflect.DeepEqual(t.Fixtures, other.Fixtures) &&
+		reflect.DeepEqual(t.ExpectedStatuses, other.ExpectedStatuses) &&
+		reflect.DeepEqual(t.ExpectedSummary, other.ExpectedSummary)
+}
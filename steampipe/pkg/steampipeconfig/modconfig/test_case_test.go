@@ -0,0 +1,92 @@
+package modconfig
+
+import (
+	"testing"
+)
+
+type testCaseDecodeTest struct {
+	target           string
+	fixtures         map[string]string
+	expectedStatuses map[string]string
+	expectedSummary  map[string]int
+	expectedRows     *string
+	expectedRowCount *int
+	expectErr        bool
+}
+
+var testCaseExpectedRows = "expected.csv"
+var testCaseExpectedRowCount = 2
+
+var testCasesTestCaseDecode = map[string]testCaseDecodeTest{
+	"benchmark with statuses": {
+		target:           "m.benchmark.b1",
+		fixtures:         map[string]string{"aws_s3_bucket": "fixtures/buckets.csv"},
+		expectedStatuses: map[string]string{"arn:aws:s3:::b1": "ok", "arn:aws:s3:::b2": "alarm"},
+	},
+	"control with summary": {
+		target:          "m.control.c1",
+		expectedSummary: map[string]int{"ok": 1, "alarm": 1},
+	},
+	"query with rows": {
+		target:       "m.query.q1",
+		expectedRows: &testCaseExpectedRows,
+	},
+	"query with row count": {
+		target:           "m.query.q1",
+		expectedRowCount: &testCaseExpectedRowCount,
+	},
+	"control without expectations": {
+		target:    "m.control.c1",
+		expectErr: true,
+	},
+	"control with row count": {
+		target:           "m.control.c1",
+		expectedSummary:  map[string]int{"ok": 1},
+		expectedRowCount: &testCaseExpectedRowCount,
+		expectErr:        true,
+	},
+	"control with invalid status": {
+		target:           "m.control.c1",
+		expectedStatuses: map[string]string{"r1": "passed"},
+		expectErr:        true,
+	},
+	"query with statuses": {
+		target:           "m.query.q1",
+		expectedRows:     &testCaseExpectedRows,
+		expectedStatuses: map[string]string{"r1": "ok"},
+		expectErr:        true,
+	},
+	"qualified fixture table": {
+		target:           "m.query.q1",
+		fixtures:         map[string]string{"aws.aws_s3_bucket": "fixtures/buckets.csv"},
+		expectedRowCount: &testCaseExpectedRowCount,
+		expectErr:        true,
+	},
+	"dashboard target": {
+		target:           "m.dashboard.d1",
+		expectedRowCount: &testCaseExpectedRowCount,
+		expectErr:        true,
+	},
+}
+
+func TestTestCaseOnDecoded(t *testing.T) {
+	for name, test := range testCasesTestCaseDecode {
+		tc := &TestCase{
+			FullName:         "m.test.t1",
+			Target:           &NamedItem{Name: test.target},
+			Fixtures:         test.fixtures,
+			ExpectedStatuses: test.expectedStatuses,
+			ExpectedSummary:  test.expectedSummary,
+			ExpectedRows:     test.expectedRows,
+			ExpectedRowCount: test.expectedRowCount,
+		}
+		diags := tc.OnDecoded(nil, nil)
+		if diags.HasErrors() != test.expectErr {
+			t.Errorf("Test: '%s'' FAILED : expected error %v, got %v", name, test.expectErr, diags.Error())
+			continue
+		}
+		if !test.expectErr && tc.TargetName != test.target {
+			t.Errorf("Test: '%s'' FAILED : expected target %s, got %s", name, test.target, tc.TargetName)
+		}
+	}
+}
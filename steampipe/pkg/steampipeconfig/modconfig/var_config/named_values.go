@@ -25,7 +25,7 @@ type Variable struct {
 	Type        cty.Type
 	ParsingMode VariableParsingMode
 	//Validations []*VariableValidation
-	//Sensitive   bool
+	Sensitive bool
 
 	DescriptionSet bool
 	//SensitiveSet   bool
@@ -64,6 +64,11 @@ func DecodeVariableBlock(block *hcl.Block, content *hcl.BodyContent, override bo
 		v.DescriptionSet = true
 	}
 
+	if attr, exists := content.Attributes["sensitive"]; exists {
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &v.Sensitive)
+		diags = append(diags, valDiags...)
+	}
+
 	if attr, exists := content.Attributes["type"]; exists {
 		ty, parseMode, tyDiags := decodeVariableType(attr.Expr)
 		diags = append(diags, tyDiags...)
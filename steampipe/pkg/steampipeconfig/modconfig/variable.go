@@ -13,6 +13,9 @@ import (
 	"github.com/zclconf/go-cty/cty/convert"
 )
 
+// SensitiveValueMask is displayed in place of the value of a sensitive variable
+const SensitiveValueMask = "(sensitive)"
+
 // Variable is a struct representing a Variable resource
 type Variable struct {
 	ResourceWithMetadataBase
@@ -29,6 +32,8 @@ type Variable struct {
 	DefaultGo  interface{} `json:"value_default"`
 	ValueGo    interface{} `json:"value"`
 	ModName    string      `json:"mod_name"`
+	// if set, the value must not be displayed - set if the variable is declared sensitive or the value is read from a secret
+	Sensitive bool `column:"sensitive,boolean" json:"sensitive,omitempty"`
 
 	// set after value resolution `column:"value,jsonb"`
 	Value                      cty.Value                      `column:"value,jsonb" json:"-"`
@@ -65,6 +70,7 @@ func NewVariable(v *var_config.Variable, mod *Mod) *Variable {
 		ModName:         mod.ShortName,
 		DefaultGo:       defaultGo,
 		TypeString:      type_conversion.CtyTypeToHclType(v.Type, v.Default.Type()),
+		Sensitive:       v.Sensitive,
 	}
 }
 
@@ -153,6 +159,21 @@ func (v *Variable) SetInputValue(value cty.Value, sourceType string, sourceRange
 	return nil
 }
 
+// Masked returns the variable to display - if the variable is sensitive, this is a copy with the value and default masked
+func (v *Variable) Masked() *Variable {
+	if !v.Sensitive {
+		return v
+	}
+	res := *v
+	res.Value = cty.StringVal(SensitiveValueMask)
+	res.ValueGo = SensitiveValueMask
+	if !v.Default.IsNull() {
+		res.Default = cty.StringVal(SensitiveValueMask)
+		res.DefaultGo = SensitiveValueMask
+	}
+	return &res
+}
+
 // AddParent implements ModTreeItem
 func (v *Variable) AddParent(parent ModTreeItem) error {
 	v.parents = append(v.parents, parent)
@@ -10,21 +10,25 @@ import (
 )
 
 type WorkspaceProfile struct {
-	ProfileName       string            `hcl:"name,label" cty:"name"`
-	CloudHost         *string           `hcl:"cloud_host,optional" cty:"cloud_host"`
-	CloudToken        *string           `hcl:"cloud_token,optional" cty:"cloud_token"`
-	InstallDir        *string           `hcl:"install_dir,optional" cty:"install_dir"`
-	ModLocation       *string           `hcl:"mod_location,optional" cty:"mod_location"`
-	SnapshotLocation  *string           `hcl:"snapshot_location,optional" cty:"snapshot_location"`
-	WorkspaceDatabase *string           `hcl:"workspace_database,optional" cty:"workspace_database"`
-	QueryTimeout      *int              `hcl:"query_timeout,optional" cty:"query_timeout"`
-	Base              *WorkspaceProfile `hcl:"base"`
+	ProfileName           string            `hcl:"name,label" cty:"name"`
+	CloudHost             *string           `hcl:"cloud_host,optional" cty:"cloud_host"`
+	CloudToken            *string           `hcl:"cloud_token,optional" cty:"cloud_token"`
+	InstallDir            *string           `hcl:"install_dir,optional" cty:"install_dir"`
+	ModLocation           *string           `hcl:"mod_location,optional" cty:"mod_location"`
+	SnapshotLocation      *string           `hcl:"snapshot_location,optional" cty:"snapshot_location"`
+	WorkspaceDatabase     *string           `hcl:"workspace_database,optional" cty:"workspace_database"`
+	QueryTimeout          *int              `hcl:"query_timeout,optional" cty:"query_timeout"`
+	CheckHistory          *bool             `hcl:"check_history,optional" cty:"check_history"`
+	CheckHistoryRetention *string           `hcl:"check_history_retention,optional" cty:"check_history_retention"`
+	Base                  *WorkspaceProfile `hcl:"base"`
 
 	// options
 	GeneralOptions    *options.General
 	TerminalOptions   *options.Terminal
 	ConnectionOptions *options.Connection
-	DeclRange         hcl.Range
+	// notifiers, keyed by name
+	Notifiers map[string]*Notifier
+	DeclRange hcl.Range
 }
 
 func NewWorkspaceProfile(block *hcl.Block) *WorkspaceProfile {
@@ -64,6 +68,31 @@ func (p *WorkspaceProfile) SetOptions(opts options.Options, block *hcl.Block) hc
 	return diags
 }
 
+// AddNotifier adds a notifier to the profile, validating it and checking for duplicates
+func (p *WorkspaceProfile) AddNotifier(notifier *Notifier) hcl.Diagnostics {
+	if p.Notifiers == nil {
+		p.Notifiers = make(map[string]*Notifier)
+	}
+	if _, ok := p.Notifiers[notifier.Name]; ok {
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("duplicate notifier '%s'", notifier.Name),
+			Subject:  &notifier.DeclRange,
+		}}
+	}
+	diags := notifier.Validate()
+	if !diags.HasErrors() {
+		p.Notifiers[notifier.Name] = notifier
+	}
+	return diags
+}
+
+// GetNotifier returns the notifier with the given name
+func (p *WorkspaceProfile) GetNotifier(name string) (*Notifier, bool) {
+	notifier, ok := p.Notifiers[name]
+	return notifier, ok
+}
+
 func duplicateOptionsBlockDiag(block *hcl.Block) *hcl.Diagnostic {
 	return &hcl.Diagnostic{
 		Severity: hcl.DiagError,
@@ -111,6 +140,22 @@ func (p *WorkspaceProfile) setBaseProperties() {
 	if p.QueryTimeout == nil {
 		p.QueryTimeout = p.Base.QueryTimeout
 	}
+	if p.CheckHistory == nil {
+		p.CheckHistory = p.Base.CheckHistory
+	}
+	if p.CheckHistoryRetention == nil {
+		p.CheckHistoryRetention = p.Base.CheckHistoryRetention
+	}
+	// inherit the notifiers of the base which are not overridden by a notifier of the same name
+	for name, notifier := range p.Base.Notifiers {
+		if _, ok := p.Notifiers[name]; ok {
+			continue
+		}
+		if p.Notifiers == nil {
+			p.Notifiers = make(map[string]*Notifier)
+		}
+		p.Notifiers[name] = notifier
+	}
 }
 
 // ConfigMap creates a config map containing all options to pass to viper
@@ -125,6 +170,8 @@ func (p *WorkspaceProfile) ConfigMap() map[string]interface{} {
 	res.SetStringItem(p.SnapshotLocation, constants.ArgSnapshotLocation)
 	res.SetStringItem(p.WorkspaceDatabase, constants.ArgWorkspaceDatabase)
 	res.SetIntItem(p.QueryTimeout, constants.ArgDatabaseQueryTimeout)
+	res.SetBoolItem(p.CheckHistory, constants.ArgCheckHistory)
+	res.SetStringItem(p.CheckHistoryRetention, constants.ArgHistoryRetention)
 
 	// now add options
 	// build flat config map with order or precedence (low to high): general, terminal, connection
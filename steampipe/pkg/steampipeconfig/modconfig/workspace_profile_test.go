@@ -0,0 +1,45 @@
+package modconfig
+
+import (
+	"testing"
+
+	"github.com/turbot/steampipe/pkg/utils"
+)
+
+func TestWorkspaceProfileInheritsBaseNotifiers(t *testing.T) {
+	base := &WorkspaceProfile{
+		ProfileName: "base",
+		Notifiers: map[string]*Notifier{
+			"slack": {Name: "slack", Type: NotifierTypeSlack, Url: utils.ToStringPointer("https://base.example.com/slack")},
+			"email": {Name: "email", Type: NotifierTypeEmail},
+		},
+	}
+	child := &WorkspaceProfile{
+		ProfileName: "child",
+		Base:        base,
+		Notifiers: map[string]*Notifier{
+			"slack": {Name: "slack", Type: NotifierTypeSlack, Url: utils.ToStringPointer("https://child.example.com/slack")},
+		},
+	}
+	child.OnDecoded()
+
+	if len(child.Notifiers) != 2 {
+		t.Fatalf("expected 2 notifiers, got %d", len(child.Notifiers))
+	}
+	if url := *child.Notifiers["slack"].Url; url != "https://child.example.com/slack" {
+		t.Errorf("expected the child slack notifier to override the base notifier, got url %s", url)
+	}
+	if child.Notifiers["email"] != base.Notifiers["email"] {
+		t.Errorf("expected the base email notifier to be inherited")
+	}
+	if len(base.Notifiers) != 2 {
+		t.Errorf("expected the base notifiers to be unchanged, got %d notifiers", len(base.Notifiers))
+	}
+
+	// a profile with no notifiers inherits all of the base notifiers
+	other := &WorkspaceProfile{ProfileName: "other", Base: base}
+	other.OnDecoded()
+	if len(other.Notifiers) != 2 {
+		t.Errorf("expected 2 notifiers, got %d", len(other.Notifiers))
+	}
+}
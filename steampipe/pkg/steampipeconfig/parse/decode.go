@@ -221,6 +221,9 @@ func resourceForBlock(block *hcl.Block, parseCtx *ModParseContext) (modconfig.Hc
 		modconfig.BlockTypeEdge:      modconfig.NewDashboardEdge,
 		modconfig.BlockTypeCategory:  modconfig.NewDashboardCategory,
 		modconfig.BlockTypeWith:      modconfig.NewDashboardWith,
+		modconfig.BlockTypeExemption: modconfig.NewExemption,
+		modconfig.BlockTypeSchedule:  modconfig.NewSchedule,
+		modconfig.BlockTypeTest:      modconfig.NewTestCase,
 	}
 
 	factoryFunc, ok := factoryFuncs[block.Type]
@@ -671,6 +674,12 @@ func decodeBenchmark(block *hcl.Block, parseCtx *ModParseContext) (*modconfig.Be
 	diags = decodeProperty(content, "display", &benchmark.Display, parseCtx)
 	res.handleDecodeDiags(diags)
 
+	diags = decodeProperty(content, "timeout", &benchmark.Timeout, parseCtx)
+	res.handleDecodeDiags(diags)
+
+	diags = decodeProperty(content, "retry", &benchmark.Retry, parseCtx)
+	res.handleDecodeDiags(diags)
+
 	// now add children
 	if res.Success() {
 		supportedChildren := []string{modconfig.BlockTypeBenchmark, modconfig.BlockTypeControl}
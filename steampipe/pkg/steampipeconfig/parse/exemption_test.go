@@ -0,0 +1,112 @@
+package parse
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/steampipe/pkg/steampipeconfig/modconfig"
+)
+
+const exemptionTestControl = `
+control "c1" {
+  title = "C1"
+  sql   = "select 'ok' as status, 'r' as resource, 'ok' as reason"
+}
+`
+
+type exemptionParseTest struct {
+	source      string
+	expectedErr string
+}
+
+var testCasesExemptionParse = map[string]exemptionParseTest{
+	"all properties": {
+		source: `
+exemption "e1" {
+  title      = "Public website"
+  controls   = [control.c1]
+  resources  = ["arn:aws:s3:::website-*"]
+  dimensions = { region = "us-east-1" }
+  reason     = "bucket hosts a public website"
+  expires    = "2030-01-31"
+}
+`,
+	},
+	"no controls": {
+		source: `
+exemption "e1" {
+  controls = []
+  reason   = "no controls"
+}
+`,
+		expectedErr: "must specify at least one control or benchmark",
+	},
+	"invalid expires": {
+		source: `
+exemption "e1" {
+  controls = [control.c1]
+  reason   = "bad expiry"
+  expires  = "next tuesday"
+}
+`,
+		expectedErr: "invalid 'expires' value 'next tuesday'",
+	},
+	"unknown property": {
+		source: `
+exemption "e1" {
+  controls = [control.c1]
+  reason   = "typo"
+  resource = "arn:aws:s3:::website"
+}
+`,
+		expectedErr: "Unsupported argument",
+	},
+}
+
+func TestParseExemption(t *testing.T) {
+	for name, test := range testCasesExemptionParse {
+		modPath := t.TempDir()
+		mod := modconfig.NewMod("m", modPath, hcl.Range{})
+		parseCtx := NewModParseContext(nil, modPath, 0, nil)
+		parseCtx.CurrentMod = mod
+		fileData := map[string][]byte{
+			filepath.Join(modPath, "exemptions.sp"): []byte(exemptionTestControl + test.source),
+		}
+
+		mod, err := ParseMod(modPath, fileData, nil, parseCtx)
+		if test.expectedErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+				t.Errorf("Test: '%s'' FAILED : expected error containing '%s', got %v", name, test.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test: '%s'' FAILED : %s", name, err.Error())
+			continue
+		}
+
+		e, ok := mod.ResourceMaps.Exemptions["m.exemption.e1"]
+		if !ok {
+			t.Errorf("Test: '%s'' FAILED : exemption not found in mod", name)
+			continue
+		}
+		if len(e.ControlNames) != 1 || e.ControlNames[0] != "m.control.c1" {
+			t.Errorf("Test: '%s'' FAILED : unexpected controls %v", name, e.ControlNames)
+		}
+		if !e.AppliesToControl(mod.ResourceMaps.Controls["m.control.c1"]) {
+			t.Errorf("Test: '%s'' FAILED : expected exemption to apply to control c1", name)
+		}
+		if e.GetReason() != "bucket hosts a public website" {
+			t.Errorf("Test: '%s'' FAILED : unexpected reason '%s'", name, e.GetReason())
+		}
+		if e.ExpiresAt == nil || e.ExpiresAt.Format("2006-01-02") != "2030-01-31" {
+			t.Errorf("Test: '%s'' FAILED : unexpected expiry %v", name, e.ExpiresAt)
+		}
+		if !e.MatchesResult("arn:aws:s3:::website-1", map[string]string{"region": "us-east-1"}) ||
+			e.MatchesResult("arn:aws:s3:::website-1", map[string]string{"region": "eu-west-1"}) {
+			t.Errorf("Test: '%s'' FAILED : resources and dimensions were not decoded: %v %v", name, e.Resources, e.Dimensions)
+		}
+	}
+}
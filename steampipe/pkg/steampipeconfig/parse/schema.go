@@ -31,6 +31,10 @@ var WorkspaceProfileBlockSchema = &hcl.BodySchema{
 			Type:       "options",
 			LabelNames: []string{"type"},
 		},
+		{
+			Type:       modconfig.BlockTypeNotifier,
+			LabelNames: []string{"name"},
+		},
 	},
 }
 
@@ -134,6 +138,18 @@ var WorkspaceBlockSchema = &hcl.BodySchema{
 			Type:       modconfig.BlockTypeCategory,
 			LabelNames: []string{"name"},
 		},
+		{
+			Type:       modconfig.BlockTypeExemption,
+			LabelNames: []string{"name"},
+		},
+		{
+			Type:       modconfig.BlockTypeSchedule,
+			LabelNames: []string{"name"},
+		},
+		{
+			Type:       modconfig.BlockTypeTest,
+			LabelNames: []string{"name"},
+		},
 	},
 }
 
@@ -273,6 +289,9 @@ var BenchmarkBlockSchema = &hcl.BodySchema{
 		{Name: "base"},
 		{Name: "type"},
 		{Name: "display"},
+		// control execution defaults
+		{Name: "timeout"},
+		{Name: "retry"},
 	},
 }
 
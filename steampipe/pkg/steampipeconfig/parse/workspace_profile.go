@@ -152,11 +152,23 @@ func decodeWorkspaceProfile(block *hcl.Block, parseCtx *WorkspaceProfileParseCon
 				diags = append(diags, moreDiags...)
 			}
 
+		case modconfig.BlockTypeNotifier:
+			notifier := modconfig.NewNotifier(block)
+			moreDiags := gohcl.DecodeBody(block.Body, parseCtx.EvalCtx, notifier)
+			if moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				break
+			}
+			moreDiags = resource.AddNotifier(notifier)
+			if moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+			}
+
 		default:
 			// this should never happen
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
-				Summary:  fmt.Sprintf("invalid block type '%s' - only 'options' blocks are supported for Connections", block.Type),
+				Summary:  fmt.Sprintf("invalid block type '%s' - only 'options' and 'notifier' blocks are supported for workspace profiles", block.Type),
 				Subject:  &block.DefRange,
 			})
 		}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseRetentionAge parses a retention age, e.g. the max age of snapshots or log files - either a duration, e.g. '12h', or a number of days, e.g. '30d'
+func ParseRetentionAge(age string) (time.Duration, error) {
+	if age == "" {
+		return 0, nil
+	}
+	var res time.Duration
+	if strings.HasSuffix(age, "d") {
+		count, err := strconv.Atoi(strings.TrimSuffix(age, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid retention age '%s'", age)
+		}
+		res = time.Duration(count) * 24 * time.Hour
+	} else {
+		var err error
+		res, err = time.ParseDuration(age)
+		if err != nil {
+			return 0, fmt.Errorf("invalid retention age '%s' - must be a duration such as '12h' or a number of days such as '30d'", age)
+		}
+	}
+	if res <= 0 {
+		return 0, fmt.Errorf("retention age '%s' must be positive", age)
+	}
+	return res, nil
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRetentionAge(t *testing.T) {
+	testCases := map[string]time.Duration{
+		"":    0,
+		"30d": 30 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+		"90m": 90 * time.Minute,
+		"xd":  -1,
+		"0d":  -1,
+		"-1h": -1,
+		"12":  -1,
+	}
+	for age, expected := range testCases {
+		got, err := ParseRetentionAge(age)
+		if expected == -1 {
+			if err == nil {
+				t.Errorf("expected error parsing '%s'", age)
+			}
+			continue
+		}
+		if err != nil || got != expected {
+			t.Errorf("parsing '%s': expected %v, got %v (%v)", age, expected, got, err)
+		}
+	}
+}
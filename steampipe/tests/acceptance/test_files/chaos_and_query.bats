@@ -154,6 +154,13 @@ load "$LIB_BATS_SUPPORT/load.bash"
   assert_output --partial 'Time:'
 }
 
+@test "timer on with a query timeout - the session is reused after the timeout context of each query is released" {
+  run steampipe query "select 1 as first_query" "select 2 as second_query" --timing --query-timeout 60
+  assert_success
+  assert_output --partial 'second_query'
+  assert_equal "$(echo "$output" | grep -c 'Time:')" "2"
+}
+
 @test "select query install directory" {
   run steampipe query --output csv "select 1" --install-dir '~/.steampipe_test'
   assert_success
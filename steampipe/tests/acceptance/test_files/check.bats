@@ -8,6 +8,15 @@ load "$LIB_BATS_SUPPORT/load.bash"
   cd -
 }
 
+@test "steampipe check check_rendering_benchmark on a single session with a query timeout" {
+  cd $CONTROL_RENDERING_TEST_MOD
+  # every control query runs on the same session, after the timeout context of the previous control is released
+  run steampipe check benchmark.control_check_rendering_benchmark --max-parallel 1 --query-timeout 60
+  assert_equal $status 0
+  refute_output --partial 'conn closed'
+  cd -
+}
+
 @test "steampipe check long control title" {
   cd $CONTROL_RENDERING_TEST_MOD
   export STEAMPIPE_CHECK_DISPLAY_WIDTH=100
@@ -2,10 +2,13 @@ import "./utils/registerComponents";
 import Dashboard from "./components/dashboards/layout/Dashboard";
 import DashboardHeader from "./components/DashboardHeader";
 import DashboardList from "./components/DashboardList";
+import EmbeddedSnapshot from "./components/EmbeddedSnapshot";
 import SnapshotHeader from "./components/SnapshotHeader";
 import useAnalytics from "./hooks/useAnalytics";
 import WorkspaceErrorModal from "./components/dashboards/WorkspaceErrorModal";
+import { DashboardDataModeCloudSnapshot } from "./types";
 import { DashboardProvider } from "./hooks/useDashboard";
+import { getEmbeddedSnapshot } from "./utils/snapshot";
 import { FullHeightThemeWrapper, useTheme } from "./hooks/useTheme";
 import { Route, Routes } from "react-router-dom";
 import { useBreakpoint } from "./hooks/useBreakpoint";
@@ -25,11 +28,48 @@ const Dashboards = ({ analyticsContext, breakpointContext, themeContext }) => (
   </DashboardProvider>
 );
 
+const EmbeddedSnapshotDashboard = ({
+  analyticsContext,
+  breakpointContext,
+  snapshot,
+  themeContext,
+}) => (
+  <DashboardProvider
+    analyticsContext={analyticsContext}
+    breakpointContext={breakpointContext}
+    dataOptions={{ dataMode: DashboardDataModeCloudSnapshot }}
+    themeContext={themeContext}
+  >
+    <EmbeddedSnapshot snapshot={snapshot} />
+    <WorkspaceErrorModal />
+    <Dashboard />
+  </DashboardProvider>
+);
+
 const DashboardApp = ({
   analyticsContext,
   breakpointContext,
   themeContext,
 }) => {
+  const embeddedSnapshot = getEmbeddedSnapshot();
+  if (embeddedSnapshot) {
+    return (
+      <Routes>
+        <Route
+          path="*"
+          element={
+            <EmbeddedSnapshotDashboard
+              analyticsContext={analyticsContext}
+              breakpointContext={breakpointContext}
+              snapshot={embeddedSnapshot}
+              themeContext={themeContext}
+            />
+          }
+        />
+      </Routes>
+    );
+  }
+
   const dashboards = (
     <Dashboards
       analyticsContext={analyticsContext}
@@ -0,0 +1,40 @@
+import { DashboardActions, DashboardDataModeCloudSnapshot } from "../../types";
+import { migrateSnapshotFileToExecutionCompleteEvent } from "../../utils/snapshot";
+import { useDashboard } from "../../hooks/useDashboard";
+import { useEffect } from "react";
+
+// Loads a snapshot embedded in a static HTML export into the dashboard state,
+// so it can be rendered without a dashboard server
+const EmbeddedSnapshot = ({ snapshot }) => {
+  const { dispatch } = useDashboard();
+
+  useEffect(() => {
+    try {
+      const event = migrateSnapshotFileToExecutionCompleteEvent(snapshot);
+      dispatch({
+        type: DashboardActions.SELECT_DASHBOARD,
+        dashboard: null,
+        dataMode: DashboardDataModeCloudSnapshot,
+        recordInputsHistory: false,
+      });
+      dispatch({
+        type: DashboardActions.EXECUTION_COMPLETE,
+        ...event,
+      });
+      dispatch({
+        type: DashboardActions.SET_DASHBOARD_INPUTS,
+        value: event.snapshot.inputs,
+        recordInputsHistory: false,
+      });
+    } catch (err: any) {
+      dispatch({
+        type: DashboardActions.WORKSPACE_ERROR,
+        error: "Unable to load snapshot:" + err.message,
+      });
+    }
+  }, [dispatch, snapshot]);
+
+  return null;
+};
+
+export default EmbeddedSnapshot;
@@ -1,38 +1,9 @@
 import { DashboardActions, DashboardDataModeCLISnapshot } from "../../types";
-import { LATEST_EXECUTION_SCHEMA_VERSION } from "../../constants/versions";
+import { migrateSnapshotFileToExecutionCompleteEvent } from "../../utils/snapshot";
 import { useDashboard } from "../../hooks/useDashboard";
 import { useNavigate } from "react-router-dom";
 import { useRef } from "react";
 
-const migrateSnapshotFileToExecutionCompleteEvent = (snapshot) => {
-  switch (snapshot.schema_version) {
-    default:
-      const {
-        layout,
-        panels,
-        inputs,
-        variables,
-        search_path,
-        start_time,
-        end_time,
-      } = snapshot;
-      return {
-        action: DashboardActions.EXECUTION_COMPLETE,
-        schema_version: LATEST_EXECUTION_SCHEMA_VERSION,
-        snapshot: {
-          schema_version: LATEST_EXECUTION_SCHEMA_VERSION,
-          layout,
-          panels,
-          inputs,
-          variables,
-          search_path,
-          start_time,
-          end_time,
-        },
-      };
-  }
-};
-
 const OpenSnapshotButton = () => {
   const { dispatch } = useDashboard();
   const fileInputRef = useRef<HTMLInputElement | null>(null);
@@ -3,11 +3,15 @@ import ErrorBoundary from "./components/ErrorBoundary";
 import React from "react";
 import { AnalyticsProvider } from "./hooks/useAnalytics";
 import { BreakpointProvider } from "./hooks/useBreakpoint";
-import { BrowserRouter as Router } from "react-router-dom";
+import { BrowserRouter, MemoryRouter } from "react-router-dom";
 import { createRoot } from "react-dom/client";
+import { getEmbeddedSnapshot } from "./utils/snapshot";
 import { ThemeProvider } from "./hooks/useTheme";
 import "./styles/index.css";
 
+// A static HTML export is opened from a file, so the URL cannot be used for routing
+const Router = getEmbeddedSnapshot() ? MemoryRouter : BrowserRouter;
+
 const container = document.getElementById("root");
 // @ts-ignore
 const root = createRoot(container);
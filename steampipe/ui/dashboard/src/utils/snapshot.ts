@@ -1,4 +1,5 @@
-import { PanelDefinition } from "../types";
+import { DashboardActions, PanelDefinition } from "../types";
+import { LATEST_EXECUTION_SCHEMA_VERSION } from "../constants/versions";
 
 const stripObjectProperties = (obj) => {
   if (!obj) {
@@ -50,4 +51,43 @@ const stripSnapshotDataForExport = (snapshot) => {
   }
 };
 
-export { stripSnapshotDataForExport };
+const migrateSnapshotFileToExecutionCompleteEvent = (snapshot) => {
+  switch (snapshot.schema_version) {
+    default:
+      const {
+        layout,
+        panels,
+        inputs,
+        variables,
+        search_path,
+        start_time,
+        end_time,
+      } = snapshot;
+      return {
+        action: DashboardActions.EXECUTION_COMPLETE,
+        schema_version: LATEST_EXECUTION_SCHEMA_VERSION,
+        snapshot: {
+          schema_version: LATEST_EXECUTION_SCHEMA_VERSION,
+          layout,
+          panels,
+          inputs,
+          variables,
+          search_path,
+          start_time,
+          end_time,
+        },
+      };
+  }
+};
+
+// A snapshot embedded in the page by a static HTML export (steampipe dashboard --export html)
+const getEmbeddedSnapshot = () => {
+  // @ts-ignore
+  return window.__STEAMPIPE_SNAPSHOT__ || null;
+};
+
+export {
+  getEmbeddedSnapshot,
+  migrateSnapshotFileToExecutionCompleteEvent,
+  stripSnapshotDataForExport,
+};